@@ -0,0 +1,46 @@
+package api
+
+import (
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/flags"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+
+	"go.uber.org/zap"
+)
+
+var featureFlags *flags.Flags
+
+// creates the feature flags and makes them the default of flags.Enabled
+//
+//	flags are defined under flags.definitions and redefined whenever the config file changes
+//	rows of kisan.feature_flags override them, polled every flags.pollinterval
+//	without postgres only the definitions apply
+func newFlags(repoObj repo.DataObject) *flags.Flags {
+	var store flags.Store
+	if repoObj.Databases.PgDB != nil {
+		store = flags.NewStore(repoObj.Databases.PgDB)
+	} else {
+		logger.Log().Warn("postgres is not connected, feature flag overrides are disabled")
+	}
+	f := flags.New(store, flags.Config{PollInterval: config.App().Flags.PollInterval})
+	f.Define(flagDefinitions(config.App().Flags))
+	config.Watch(func(app *config.AppConfig) {
+		f.Define(flagDefinitions(app.Flags))
+		logger.Log().Info("feature flags redefined", zap.Int("flags", len(app.Flags.Definitions)))
+	})
+	flags.SetDefault(f)
+	return f
+}
+
+func flagDefinitions(c config.FlagsConfig) []flags.Flag {
+	definitions := make([]flags.Flag, 0, len(c.Definitions))
+	for name, definition := range c.Definitions {
+		flag := flags.Flag{Name: name, Description: definition.Description, Enabled: definition.Enabled}
+		for _, rule := range definition.Rules {
+			flag.Rules = append(flag.Rules, flags.Rule(rule))
+		}
+		definitions = append(definitions, flag)
+	}
+	return definitions
+}
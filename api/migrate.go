@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/repo/migrations"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// commands of the migrate subcommand
+const (
+	MigrateUp     = "up"
+	MigrateDown   = "down"
+	MigrateStatus = "status"
+	MigrateSeed   = "seed"
+)
+
+// Migrate runs one migrate command against postgres and reports what it did to w
+//
+//	up applies the pending migrations
+//	down rolls back the last steps migrations
+//	status lists the migrations and when they were applied
+//	seed loads the sample data, only in the environments of repo.migrations.fixtures.environments
+func Migrate(w io.Writer, command string, steps int) error {
+	ctx = context.Background()
+	initLogger()
+
+	pgDB, err := repo.PostgreSqlConnect()
+	if err != nil {
+		return err
+	}
+	databases = append(databases, pgDB)
+	defer CloseDatabase()
+	migrator, err := newMigrator(pgDB)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case MigrateUp:
+		done, err := migrator.Up(ctx)
+		for _, migration := range done {
+			fmt.Fprintf(w, "applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Fprintln(w, "schema is up to date")
+		}
+		return err
+	case MigrateDown:
+		done, err := migrator.Down(ctx, steps)
+		for _, migration := range done {
+			fmt.Fprintf(w, "rolled back %04d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+	case MigrateStatus:
+		statuses, err := migrator.Status(ctx)
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d_%-32s %s\n", status.Version, status.Name, applied)
+		}
+		return err
+	case MigrateSeed:
+		if !fixturesAllowed() {
+			return fmt.Errorf("fixtures are disabled in environment %s", config.Environment())
+		}
+		done, err := migrator.Seed(ctx)
+		for _, name := range done {
+			fmt.Fprintf(w, "loaded %s\n", name)
+		}
+		return err
+	default:
+		return fmt.Errorf("unknown migrate command %q, use up, down, status or seed", command)
+	}
+}
+
+// migrateOnStart applies the pending migrations when repo.migrations.auto is set and loads the
+// fixtures in the environments that allow them
+func migrateOnStart(pgDB *gorm.DB) error {
+	if !config.App().Repo.Migrations.Auto {
+		return nil
+	}
+	migrator, err := newMigrator(pgDB)
+	if err != nil {
+		return err
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		logger.Log().Error("failed to migrate the database", zap.Error(err))
+		return err
+	}
+	if fixturesAllowed() {
+		if _, err := migrator.Seed(ctx); err != nil {
+			logger.Log().Error("failed to load fixtures", zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+func newMigrator(pgDB *gorm.DB) (*migrations.Migrator, error) {
+	sqlDB, err := pgDB.DB()
+	if err != nil {
+		return nil, err
+	}
+	return migrations.New(sqlDB)
+}
+
+// fixturesAllowed reports whether the sample data may be loaded in the running environment
+func fixturesAllowed() bool {
+	for _, environment := range config.App().Repo.Migrations.Fixtures.Environments {
+		if environment == config.Environment() {
+			return true
+		}
+	}
+	return false
+}
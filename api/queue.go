@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/queue"
+	"kisaanSathi/pkg/repo"
+	recommendation "kisaanSathi/pkg/services/recommendation/handler"
+	recommendationModels "kisaanSathi/pkg/services/recommendation/models"
+
+	"go.uber.org/zap"
+)
+
+var taskQueue *queue.Queue
+
+// creates the task queue and registers the task handlers
+//
+//	workers, poll interval, lease and backoff are read from queue
+//	no tasks are handled without postgres
+func newQueue(repoObj repo.DataObject) *queue.Queue {
+	c := config.App().Queue
+	if repoObj.Databases.PgDB == nil {
+		logger.Log().Warn("postgres is not connected, the task queue is disabled")
+		return queue.New(nil, queue.Config{})
+	}
+	q := queue.New(queue.NewStore(repoObj.Databases.PgDB), queue.Config{
+		Workers:      c.Workers,
+		PollInterval: c.PollInterval,
+		Lease:        c.Lease,
+		Backoff:      c.Backoff,
+		MaxBackoff:   c.MaxBackoff,
+	})
+
+	recommendationController := recommendation.RecommendationController(repoObj)
+	handle(queue.Register(q, recommendationModels.WarmClimate, recommendationController.WarmClimate))
+	return q
+}
+
+func handle(err error) {
+	if err != nil {
+		logger.Log().Error("task handler is not registered", zap.Error(err))
+	}
+}
+
+// drains the task queue, tasks still running after queue.draintimeout go back to the queue
+func drainQueue() {
+	if taskQueue == nil {
+		return
+	}
+	timeout := config.App().Queue.DrainTimeout
+	logger.Log().Info("Draining task queue START")
+	defer logger.Log().Info("Draining task queue END")
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := taskQueue.Drain(timeoutCtx); err != nil {
+		logger.Log().Warn("task queue drain timed out, unfinished tasks were returned to the queue", zap.Error(err))
+	}
+}
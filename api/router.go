@@ -27,6 +27,8 @@ func getRouter(obj serv.ServiceLayer, logger *zap.Logger) *gin.Engine {
 	router.Use(customLogger(logger))
 	router.Use(gin.Recovery())
 	router.GET("/health", obj.GetMFHealth)
+	router.GET("/health/live", obj.GetLiveness)
+	router.GET("/health/ready", obj.GetReadiness)
 	//router.Use(middlewares.AuthMiddleware())
 	//router.Use(middlewares.AuthMiddlewareSession(obj))
 	//NOTE : ADD ALLL ROUTES BELOW THIS POINT
@@ -34,14 +36,114 @@ func getRouter(obj serv.ServiceLayer, logger *zap.Logger) *gin.Engine {
 	v1 := router.Group("/v1")
 	v1.GET("/forecast", obj.GetForecast)
 	v1.GET("/mandibhav", obj.GetMandiBhav)
+	v1.GET("/mandibhav/forecast", obj.GetPriceForecast)
 	v1.GET("/feeds", obj.GetFeeds)
 	user := v1.Group("/user")
 	{
 		user.POST("/login", obj.Login)
 		user.POST("/logout", obj.Logout)
 		user.POST("/register", obj.Register)
+		user.POST("/otp", obj.SendOTP)
+		user.POST("/otp/verify", obj.VerifyOTP)
+		user.GET("/me", obj.GetProfile)
+		user.PATCH("/me", obj.UpdateProfile)
 		//user.POST("/refreshtoken", obj.RefreshToken)
 	}
+	schemes := v1.Group("/schemes")
+	{
+		schemes.GET("", obj.ListSchemes)
+		schemes.GET("/bookmarks", obj.ListSchemeBookmarks)
+		schemes.POST("/:id/bookmark", obj.BookmarkScheme)
+		schemes.DELETE("/:id/bookmark", obj.RemoveSchemeBookmark)
+	}
+	notifications := v1.Group("/notifications")
+	{
+		notifications.GET("", obj.ListNotifications)
+		notifications.GET("/unread-count", obj.GetUnreadNotificationCount)
+		notifications.POST("/read-all", obj.MarkAllNotificationsRead)
+		notifications.POST("/:id/read", obj.MarkNotificationRead)
+		notifications.GET("/preferences", obj.GetNotificationPreferences)
+		notifications.PUT("/preferences", obj.SaveNotificationPreferences)
+		notifications.GET("/preferences/preview", obj.PreviewNotificationPreferences)
+	}
+	devices := v1.Group("/devices")
+	{
+		devices.POST("", obj.RegisterDevice)
+		devices.DELETE("/:token", obj.UnregisterDevice)
+	}
+	v1.POST("/sms/delivery-report", obj.SMSDeliveryReport)
+	farms := v1.Group("/farms")
+	{
+		farms.GET("", obj.ListFarms)
+		farms.POST("", obj.CreateFarm)
+		farms.GET("/:id", obj.GetFarm)
+		farms.PATCH("/:id", obj.UpdateFarm)
+		farms.DELETE("/:id", obj.DeleteFarm)
+		farms.GET("/:id/crop-cycles", obj.ListCropCycles)
+		farms.POST("/:id/crop-cycles", obj.CreateCropCycle)
+		farms.PATCH("/:id/crop-cycles/:cycleId", obj.UpdateCropCycle)
+		farms.GET("/:id/calendar", obj.GetFarmCalendar)
+		farms.POST("/:id/calendar/:taskId/complete", obj.CompleteFarmTask)
+		farms.GET("/:id/crop-cycles/:cycleId/ledger", obj.GetLedger)
+		farms.POST("/:id/crop-cycles/:cycleId/ledger/expenses", obj.AddExpense)
+		farms.DELETE("/:id/crop-cycles/:cycleId/ledger/expenses/:entryId", obj.DeleteExpense)
+		farms.POST("/:id/crop-cycles/:cycleId/ledger/sales", obj.AddSale)
+		farms.DELETE("/:id/crop-cycles/:cycleId/ledger/sales/:entryId", obj.DeleteSale)
+		farms.GET("/:id/crop-cycles/:cycleId/report", obj.GetCycleReport)
+		farms.GET("/:id/soil-tests", obj.ListSoilTests)
+		farms.POST("/:id/soil-tests", obj.AddSoilTest)
+		farms.POST("/:id/soil-tests/upload", obj.UploadSoilTests)
+		farms.POST("/:id/fertilizer-plan", obj.GetFertilizerPlan)
+	}
+	v1.GET("/ledger/report", obj.GetSeasonReport)
+	v1.GET("/recommendations/crops", obj.RecommendCrops)
+	listings := v1.Group("/listings")
+	{
+		listings.GET("", obj.SearchListings)
+		listings.POST("", obj.CreateListing)
+		listings.GET("/mine", obj.ListMyListings)
+		listings.GET("/:id", obj.GetListing)
+		listings.POST("/:id/status", obj.UpdateListingStatus)
+		listings.POST("/:id/offers", obj.MakeOffer)
+	}
+
+	offers := v1.Group("/offers")
+	{
+		offers.GET("", obj.ListOfferThreads)
+		offers.GET("/unread", obj.GetOfferUnreadCounts)
+		offers.GET("/stream", obj.StreamOfferMessages)
+		offers.GET("/:id/messages", obj.GetOfferMessages)
+		offers.POST("/:id/messages", obj.SendOfferMessage)
+		offers.POST("/:id/counter", obj.CounterOffer)
+		offers.POST("/:id/accept", obj.AcceptOffer)
+		offers.POST("/:id/reject", obj.RejectOffer)
+	}
+	equipment := v1.Group("/equipment")
+	{
+		equipment.GET("", obj.SearchEquipment)
+		equipment.POST("", obj.CreateEquipment)
+		equipment.GET("/mine", obj.ListMyEquipment)
+		equipment.GET("/:id", obj.GetEquipment)
+		equipment.POST("/:id/active", obj.SetEquipmentActive)
+		equipment.GET("/:id/calendar", obj.GetEquipmentCalendar)
+		equipment.POST("/:id/availability", obj.AddEquipmentSlot)
+		equipment.DELETE("/:id/availability/:slotId", obj.DeleteEquipmentSlot)
+		equipment.POST("/:id/bookings", obj.BookEquipment)
+	}
+	bookings := v1.Group("/bookings")
+	{
+		bookings.GET("", obj.ListMyBookings)
+		bookings.GET("/:id", obj.GetBooking)
+		bookings.POST("/:id/status", obj.UpdateBookingStatus)
+	}
+	admin := v1.Group("/admin", obj.RequireAdmin)
+	{
+		admin.GET("/jobs", obj.ListJobs)
+		admin.GET("/jobs/:name/runs", obj.ListJobRuns)
+		admin.POST("/jobs/:name/trigger", obj.TriggerJob)
+		admin.GET("/flags", obj.ListFlags)
+		admin.GET("/mandibhav/forecast/backtest", obj.GetForecastBacktest)
+	}
 
 	saveCurlCommands(router)
 	return router
@@ -65,6 +167,8 @@ func saveCurlCommands(r *gin.Engine) {
 		case "POST":
 			data := "" //paylaod of api request body
 			curlCommand = fmt.Sprintf("curl -X POST \"%s\" -H \"Content-Type: application/json\" -d '{%s}' \n", url, data)
+		default:
+			curlCommand = fmt.Sprintf("curl -X %s \"%s\"\n", route.Method, url)
 		}
 
 		_, err := file.WriteString(curlCommand)
@@ -101,7 +205,7 @@ func customLogger(logger *zap.Logger) gin.HandlerFunc {
 		}
 		c.Next()
 
-		if c.FullPath() != "/health" {
+		if !strings.HasPrefix(c.FullPath(), "/health") {
 			latency := time.Since(start).Milliseconds()
 			userID := c.GetString(config.USERID)
 			uID := c.GetString(config.REQUESTID)
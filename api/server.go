@@ -6,9 +6,9 @@ import (
 	"kisaanSathi/pkg/logger"
 	serv "kisaanSathi/pkg/services"
 
-	"fmt"
 	"kisaanSathi/pkg/repo"
 	"log"
+	"net"
 	"net/http"
 	"strconv"
 	"time"
@@ -26,33 +26,49 @@ var databases []*gorm.DB
 //
 //	initializes logs
 //	creates global context
-//	connects databases
+//	connects databases and registers them to be closed on shutdown
+//	migrates the schema when repo.migrations.auto is set
 //	connects redis
-//	creates versioned service objects
+//	loads the feature flags and reloads them as the config file and kisan.feature_flags change
+//	creates the job scheduler and versioned service objects
+//	starts the job scheduler and the task queue workers
 func Start() error {
 	ctx = context.Background()
+	initLogger()
 
-	config := config.GetConfig()
-	logLevel, err := strconv.Atoi(config.GetString("log.Level"))
-	if err != nil {
-		log.Fatal("Invalid log config: ", err)
-	}
-	logger.LoggerInit(config.GetString("log.path"), zapcore.Level(logLevel))
-	
 	repoObj, err := repo.NewRepoObject(ctx)
+	databases = append(databases, repoObj.Databases.Connections()...)
 	if err != nil {
 		logger.Log().Error("Failed to create repo object", zap.Error(err))
 		return err
 	}
-	serviceObj := serv.NewServiceObject(repoObj)
+	if repoObj.Databases.PgDB != nil {
+		if err := migrateOnStart(repoObj.Databases.PgDB); err != nil {
+			return err
+		}
+	} else {
+		logger.Log().Warn("postgres is not connected, migrations are skipped")
+	}
+	featureFlags = newFlags(repoObj)
+	featureFlags.Start()
+	scheduler = newScheduler(repoObj)
+	serviceObj := serv.NewServiceObject(repoObj, scheduler)
 	startRouter(serviceObj)
+	scheduler.Start()
+	taskQueue = newQueue(repoObj)
+	taskQueue.Start()
 	return nil
 }
 
+// initLogger reads the log level and path from the config
+func initLogger() {
+	c := config.App().Log
+	logger.LoggerInit(c.Path, zapcore.Level(c.Level))
+}
 
 func startRouter(obj serv.ServiceLayer) {
 	srv = &http.Server{
-		Addr:    fmt.Sprintf(":%d", config.GetConfig().GetInt("server.port")),
+		Addr:    net.JoinHostPort(config.App().Server.Host, strconv.Itoa(config.App().Server.Port)),
 		Handler: getRouter(obj, logger.Log()), //getRouter set the api specs for version-1 routes
 	}
 	// run api router
@@ -66,7 +82,7 @@ func startRouter(obj serv.ServiceLayer) {
 
 // stops the router running in the go routine.
 //
-//	uses Shutdown() function of native http server library
+//	uses Shutdown() function of native http server library, then drains the task queue
 //	internally defaults a 5 seconds context timeout.
 //		timeoutCtx,_ := context.WithTimeout(ctx, 5*time.Second)
 //		srv.Shutdown(timeoutCtx)
@@ -78,6 +94,7 @@ func ShutdownRouter() {
 	if err := srv.Shutdown(timeoutCtx); err != nil {
 		logger.Log().Fatal("Server forced to shutdown", zap.Error(err))
 	}
+	drainQueue()
 	// catching ctx.Done(). timeout of 5 seconds.
 	select {
 	case <-timeoutCtx.Done():
@@ -106,3 +123,13 @@ func CloseDatabase() {
 		}
 	}
 }
+
+// closes the shared redis client
+//
+//	logs error if unable to close
+//		function used: repo.CloseRedis()
+func CloseRedis() {
+	if err := repo.CloseRedis(context.Background()); err != nil {
+		logger.Log().Error("unable to close redis", zap.Error(err))
+	}
+}
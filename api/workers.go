@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/jobs"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	farm "kisaanSathi/pkg/services/farm/handler"
+	market "kisaanSathi/pkg/services/market/handler"
+	notification "kisaanSathi/pkg/services/notification/handler"
+	scheme "kisaanSathi/pkg/services/scheme/handler"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var scheduler *jobs.Scheduler
+
+// creates the job scheduler and registers the background jobs
+//
+//	cron expressions are read in jobs.timezone, Asia/Kolkata by default
+//	each job's schedule, timeout, attempts and backoff can be overridden under jobs.overrides.<name>
+//	no jobs are registered without postgres
+func newScheduler(repoObj repo.DataObject) *jobs.Scheduler {
+	// the timezone is validated by config.Load
+	loc, err := time.LoadLocation(config.App().Jobs.Timezone)
+	if err != nil {
+		logger.Log().Error("invalid jobs.timezone, using UTC", zap.Error(err))
+		loc = time.UTC
+	}
+	if repoObj.Databases.PgDB == nil {
+		logger.Log().Warn("postgres is not connected, background jobs are disabled")
+		return jobs.NewScheduler(nil, repoObj.Cache, loc)
+	}
+	s := jobs.NewScheduler(jobs.NewStore(repoObj.Databases.PgDB), repoObj.Cache, loc)
+
+	schemeController := scheme.SchemeController(repoObj)
+	register(s, jobs.Job{Name: "scheme-deadline-reminders", Schedule: "0 * * * *", Run: func(c context.Context) error {
+		_, err := schemeController.SendDeadlineReminders(c, time.Now())
+		return err
+	}})
+
+	farmController := farm.FarmController(repoObj)
+	register(s, jobs.Job{Name: "crop-task-reminders", Schedule: "30 * * * *", Run: func(c context.Context) error {
+		_, err := farmController.SendTaskReminders(c, time.Now())
+		return err
+	}})
+
+	marketController := market.MarketController(repoObj)
+	register(s, jobs.Job{Name: "listing-expiry", Schedule: "*/15 * * * *", Run: func(c context.Context) error {
+		_, err := marketController.ExpireListings(c, time.Now())
+		return err
+	}})
+
+	dispatcher, err := notification.Dispatcher(repoObj)
+	if err != nil {
+		logger.Log().Error("push delivery is disabled", zap.Error(err))
+	} else {
+		register(s, jobs.Job{Name: "push-delivery", Schedule: "@every 30s", Timeout: time.Minute, Run: func(c context.Context) error {
+			_, err := dispatcher.DeliverPending(c)
+			return err
+		}})
+	}
+
+	return s
+}
+
+// register applies the jobs.overrides.<name> overrides to the job and adds it to the scheduler
+func register(s *jobs.Scheduler, job jobs.Job) {
+	override := config.App().Jobs.Overrides[job.Name]
+	if override.Schedule != "" {
+		job.Schedule = override.Schedule
+	}
+	if override.Timeout > 0 {
+		job.Timeout = override.Timeout
+	}
+	if override.Attempts > 0 {
+		job.Attempts = override.Attempts
+	}
+	if override.Backoff > 0 {
+		job.Backoff = override.Backoff
+	}
+	if override.MaxBackoff > 0 {
+		job.MaxBackoff = override.MaxBackoff
+	}
+	if err := s.Register(job); err != nil {
+		logger.Log().Error("job is disabled", zap.String("job", job.Name), zap.Error(err))
+	}
+}
+
+// stops the job scheduler and waits for running jobs to record their outcome, then stops
+// polling the feature flags
+func StopWorkers() {
+	logger.Log().Info("Stopping background workers START")
+	defer logger.Log().Info("Stopping background workers END")
+	if scheduler != nil {
+		scheduler.Stop()
+	}
+	if featureFlags != nil {
+		featureFlags.Stop()
+	}
+}
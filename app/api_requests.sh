@@ -1,7 +1,81 @@
 curl -X GET "http://localhost:8080/v1/forecast"
 curl -X GET "http://localhost:8080/v1/feeds"
 curl -X GET "http://localhost:8080/v1/mandibhav"
+curl -X GET "http://localhost:8080/v1/mandibhav/forecast"
 curl -X GET "http://localhost:8080/health"
+curl -X GET "http://localhost:8080/health/live"
+curl -X GET "http://localhost:8080/health/ready"
 curl -X POST "http://localhost:8080/v1/user/login" -H "Content-Type: application/json" -d '{}' 
 curl -X POST "http://localhost:8080/v1/user/logout" -H "Content-Type: application/json" -d '{}' 
 curl -X POST "http://localhost:8080/v1/user/register" -H "Content-Type: application/json" -d '{}' 
+curl -X POST "http://localhost:8080/v1/user/otp" -H "Content-Type: application/json" -d '{}' 
+curl -X POST "http://localhost:8080/v1/user/otp/verify" -H "Content-Type: application/json" -d '{}' 
+curl -X GET "http://localhost:8080/v1/user/me"
+curl -X PATCH "http://localhost:8080/v1/user/me"
+curl -X GET "http://localhost:8080/v1/schemes"
+curl -X GET "http://localhost:8080/v1/schemes/bookmarks"
+curl -X POST "http://localhost:8080/v1/schemes/:id/bookmark" -H "Content-Type: application/json" -d '{}' 
+curl -X DELETE "http://localhost:8080/v1/schemes/:id/bookmark"
+curl -X GET "http://localhost:8080/v1/notifications"
+curl -X GET "http://localhost:8080/v1/notifications/unread-count"
+curl -X POST "http://localhost:8080/v1/notifications/read-all" -H "Content-Type: application/json" -d '{}' 
+curl -X POST "http://localhost:8080/v1/notifications/:id/read" -H "Content-Type: application/json" -d '{}' 
+curl -X GET "http://localhost:8080/v1/notifications/preferences"
+curl -X PUT "http://localhost:8080/v1/notifications/preferences"
+curl -X GET "http://localhost:8080/v1/notifications/preferences/preview"
+curl -X POST "http://localhost:8080/v1/devices" -H "Content-Type: application/json" -d '{}' 
+curl -X DELETE "http://localhost:8080/v1/devices/:token"
+curl -X POST "http://localhost:8080/v1/sms/delivery-report" -H "Content-Type: application/json" -d '{}' 
+curl -X GET "http://localhost:8080/v1/farms"
+curl -X POST "http://localhost:8080/v1/farms" -H "Content-Type: application/json" -d '{}' 
+curl -X GET "http://localhost:8080/v1/farms/:id"
+curl -X PATCH "http://localhost:8080/v1/farms/:id"
+curl -X DELETE "http://localhost:8080/v1/farms/:id"
+curl -X GET "http://localhost:8080/v1/farms/:id/crop-cycles"
+curl -X POST "http://localhost:8080/v1/farms/:id/crop-cycles" -H "Content-Type: application/json" -d '{}' 
+curl -X PATCH "http://localhost:8080/v1/farms/:id/crop-cycles/:cycleId"
+curl -X GET "http://localhost:8080/v1/farms/:id/calendar"
+curl -X POST "http://localhost:8080/v1/farms/:id/calendar/:taskId/complete" -H "Content-Type: application/json" -d '{}' 
+curl -X GET "http://localhost:8080/v1/farms/:id/crop-cycles/:cycleId/ledger"
+curl -X POST "http://localhost:8080/v1/farms/:id/crop-cycles/:cycleId/ledger/expenses" -H "Content-Type: application/json" -d '{}' 
+curl -X DELETE "http://localhost:8080/v1/farms/:id/crop-cycles/:cycleId/ledger/expenses/:entryId"
+curl -X POST "http://localhost:8080/v1/farms/:id/crop-cycles/:cycleId/ledger/sales" -H "Content-Type: application/json" -d '{}' 
+curl -X DELETE "http://localhost:8080/v1/farms/:id/crop-cycles/:cycleId/ledger/sales/:entryId"
+curl -X GET "http://localhost:8080/v1/farms/:id/crop-cycles/:cycleId/report"
+curl -X GET "http://localhost:8080/v1/ledger/report"
+curl -X GET "http://localhost:8080/v1/farms/:id/soil-tests"
+curl -X POST "http://localhost:8080/v1/farms/:id/soil-tests" -H "Content-Type: application/json" -d '{}' 
+curl -X POST "http://localhost:8080/v1/farms/:id/soil-tests/upload" -H "Content-Type: application/json" -d '{}' 
+curl -X POST "http://localhost:8080/v1/farms/:id/fertilizer-plan" -H "Content-Type: application/json" -d '{}' 
+curl -X GET "http://localhost:8080/v1/recommendations/crops"
+curl -X GET "http://localhost:8080/v1/listings"
+curl -X POST "http://localhost:8080/v1/listings" -H "Content-Type: application/json" -d '{}' 
+curl -X GET "http://localhost:8080/v1/listings/mine"
+curl -X GET "http://localhost:8080/v1/listings/:id"
+curl -X POST "http://localhost:8080/v1/listings/:id/status" -H "Content-Type: application/json" -d '{}' 
+curl -X POST "http://localhost:8080/v1/listings/:id/offers" -H "Content-Type: application/json" -d '{}' 
+curl -X GET "http://localhost:8080/v1/offers"
+curl -X GET "http://localhost:8080/v1/offers/unread"
+curl -N -X GET "http://localhost:8080/v1/offers/stream"
+curl -X GET "http://localhost:8080/v1/offers/:id/messages"
+curl -X POST "http://localhost:8080/v1/offers/:id/messages" -H "Content-Type: application/json" -d '{}' 
+curl -X POST "http://localhost:8080/v1/offers/:id/counter" -H "Content-Type: application/json" -d '{}' 
+curl -X POST "http://localhost:8080/v1/offers/:id/accept" -H "Content-Type: application/json" -d '{}' 
+curl -X POST "http://localhost:8080/v1/offers/:id/reject" -H "Content-Type: application/json" -d '{}' 
+curl -X GET "http://localhost:8080/v1/equipment"
+curl -X POST "http://localhost:8080/v1/equipment" -H "Content-Type: application/json" -d '{}' 
+curl -X GET "http://localhost:8080/v1/equipment/mine"
+curl -X GET "http://localhost:8080/v1/equipment/:id"
+curl -X POST "http://localhost:8080/v1/equipment/:id/active" -H "Content-Type: application/json" -d '{}' 
+curl -X GET "http://localhost:8080/v1/equipment/:id/calendar"
+curl -X POST "http://localhost:8080/v1/equipment/:id/availability" -H "Content-Type: application/json" -d '{}' 
+curl -X DELETE "http://localhost:8080/v1/equipment/:id/availability/:slotId"
+curl -X POST "http://localhost:8080/v1/equipment/:id/bookings" -H "Content-Type: application/json" -d '{}' 
+curl -X GET "http://localhost:8080/v1/bookings"
+curl -X GET "http://localhost:8080/v1/bookings/:id"
+curl -X POST "http://localhost:8080/v1/bookings/:id/status" -H "Content-Type: application/json" -d '{}' 
+curl -X GET "http://localhost:8080/v1/admin/jobs"
+curl -X GET "http://localhost:8080/v1/admin/jobs/:name/runs"
+curl -X POST "http://localhost:8080/v1/admin/jobs/:name/trigger" -H "Content-Type: application/json" -d '{}' 
+curl -X GET "http://localhost:8080/v1/admin/flags"
+curl -X GET "http://localhost:8080/v1/admin/mandibhav/forecast/backtest"
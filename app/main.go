@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"kisaanSathi/api"
 	"kisaanSathi/pkg/config"
@@ -13,24 +14,86 @@ import (
 )
 
 func main() {
-	var environment string
-	host := os.Getenv("SERVER_HOST")
-	if host != "" {
-		environment = "server"
-	} else {
-		// Check if a custom environment file is provided
-		if len(os.Args) == 2 {
-			environment = os.Args[1] // developer custom file
-		} else {
-			environment = "local" // default to local environment
-		}
-		config.Load(environment)
-		if err := api.Start(); err != nil {
-			log.Fatal("Failed to start server, err:", err)
-			os.Exit(1)
-		}
-
-		addShutdownHook()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		migrate(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 2 && os.Args[1] == "config" && os.Args[2] == "print" {
+		printConfig(os.Args[3:])
+		return
+	}
+
+	environment := defaultEnvironment()
+	// Check if a custom environment file is provided
+	if environment != config.ServerEnvironment && len(os.Args) == 2 {
+		environment = os.Args[1] // developer custom file
+	}
+	if err := config.Load(environment); err != nil {
+		log.Fatal("Invalid configuration, err: ", err)
+	}
+	if err := api.Start(); err != nil {
+		log.Fatal("Failed to start server, err:", err)
+	}
+
+	addShutdownHook()
+}
+
+// defaultEnvironment is server when SERVER_HOST is set, local otherwise
+func defaultEnvironment() string {
+	if os.Getenv("SERVER_HOST") != "" {
+		return config.ServerEnvironment
+	}
+	return "local" // default to local environment
+}
+
+// migrate runs a schema migration command and exits
+//
+//	kisaanSathi migrate [-env local] [-steps 1] up|down|status|seed
+func migrate(args []string) {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	environment := flags.String("env", defaultEnvironment(), "configuration file to load")
+	steps := flags.Int("steps", 1, "number of migrations to roll back with down")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: kisaanSathi migrate [-env local] [-steps 1] up|down|status|seed")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if err := config.Load(*environment); err != nil {
+		log.Fatal("Invalid configuration, err: ", err)
+	}
+	if err := api.Migrate(os.Stdout, flags.Arg(0), *steps); err != nil {
+		log.Fatal("migrate ", flags.Arg(0), " failed, err: ", err)
+	}
+}
+
+// printConfig prints the loaded configuration as yaml and exits, it fails like the server would
+// on a missing variable or an invalid value
+//
+//	kisaanSathi config print [-env local] [--redact]
+func printConfig(args []string) {
+	flags := flag.NewFlagSet("config print", flag.ExitOnError)
+	environment := flags.String("env", defaultEnvironment(), "configuration file to load")
+	redact := flags.Bool("redact", false, "hide passwords, tokens and keys")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: kisaanSathi config print [-env local] [--redact]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if err := config.Load(*environment); err != nil {
+		log.Fatal("Invalid configuration, err: ", err)
+	}
+	if err := config.Print(os.Stdout, config.App(), *redact); err != nil {
+		log.Fatal("config print failed, err: ", err)
 	}
 }
 
@@ -47,7 +110,9 @@ func addShutdownHook() {
 
 	// Shutdown the server
 	api.ShutdownRouter()
+	api.StopWorkers()
 	api.CloseDatabase()
+	api.CloseRedis()
 
 	ctx := context.Background()
 
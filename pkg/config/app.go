@@ -0,0 +1,352 @@
+package config
+
+import "time"
+
+// AppConfig is the typed configuration read by Load from the yaml file of the environment with
+// the KISAAN_ environment variables laid over it, e.g. KISAAN_REPO_REDIS_HOST for repo.redis.host.
+//
+//	default is the value of a key left out of both
+//	validate holds the go-playground/validator rules checked by Load
+//	redact hides the value from config print --redact
+type AppConfig struct {
+	Version        string               `mapstructure:"version"`
+	Server         ServerConfig         `mapstructure:"server"`
+	Repo           RepoConfig           `mapstructure:"repo"`
+	Health         HealthConfig         `mapstructure:"health"`
+	Log            LogConfig            `mapstructure:"log"`
+	Admin          AdminConfig          `mapstructure:"admin"`
+	Jobs           JobsConfig           `mapstructure:"jobs"`
+	Queue          QueueConfig          `mapstructure:"queue"`
+	Flags          FlagsConfig          `mapstructure:"flags"`
+	Farm           FarmConfig           `mapstructure:"farm"`
+	Thirdparty     ThirdpartyConfig     `mapstructure:"thirdparty"`
+	Mandi          MandiConfig          `mapstructure:"mandi"`
+	Forecast       ForecastConfig       `mapstructure:"forecast"`
+	Market         MarketConfig         `mapstructure:"market"`
+	Recommendation RecommendationConfig `mapstructure:"recommendation"`
+	Notification   NotificationConfig   `mapstructure:"notification"`
+	AES            AESConfig            `mapstructure:"aes"`
+	UCC            UCCConfig            `mapstructure:"ucc"`
+}
+
+type ServerConfig struct {
+	// Host is also read from SERVER_HOST, empty listens on every interface
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port" default:"8080" validate:"min=1,max=65535"`
+}
+
+type RepoConfig struct {
+	Redis      RedisConfig      `mapstructure:"redis"`
+	Databases  DatabasesConfig  `mapstructure:"databases"`
+	Migrations MigrationsConfig `mapstructure:"migrations"`
+}
+
+type DatabasesConfig struct {
+	Postgres PostgresConfig `mapstructure:"postgres"`
+}
+
+// PostgresConfig is the primary database, the stores need it so Host is required. Replicas
+// share the user, password and database of the primary.
+type PostgresConfig struct {
+	Host           string          `mapstructure:"host" validate:"required"`
+	Port           string          `mapstructure:"port" default:"5432"`
+	User           string          `mapstructure:"user"`
+	Password       string          `mapstructure:"password" redact:"true"`
+	DB             string          `mapstructure:"db"`
+	SSLMode        string          `mapstructure:"sslmode" default:"prefer" validate:"oneof=disable allow prefer require verify-ca verify-full"`
+	SearchPath     string          `mapstructure:"searchpath" default:"kisan"`
+	ConnectTimeout time.Duration   `mapstructure:"connecttimeout" default:"5s" validate:"gte=0"`
+	Pool           PoolConfig      `mapstructure:"pool"`
+	Replicas       []ReplicaConfig `mapstructure:"replicas" validate:"dive"`
+}
+
+// PoolConfig bounds the connections kept by each *sql.DB
+type PoolConfig struct {
+	MaxOpen     int           `mapstructure:"maxopen" default:"20" validate:"gte=0"`
+	MaxIdle     int           `mapstructure:"maxidle" default:"5" validate:"gte=0"`
+	MaxLifetime time.Duration `mapstructure:"maxlifetime" default:"30m" validate:"gte=0"`
+	MaxIdleTime time.Duration `mapstructure:"maxidletime" default:"5m" validate:"gte=0"`
+}
+
+type ReplicaConfig struct {
+	Host string `mapstructure:"host" validate:"required"`
+	Port string `mapstructure:"port"`
+}
+
+// RedisConfig selects the cache. Host and port address a single server; sentinel and cluster
+// modes take the addresses of the sentinels or the seed nodes in Addrs.
+type RedisConfig struct {
+	Mode       string   `mapstructure:"mode" default:"redis" validate:"oneof=redis sentinel cluster memory"`
+	Host       string   `mapstructure:"host" default:"127.0.0.1"`
+	Port       int      `mapstructure:"port" default:"6379" validate:"min=0,max=65535"`
+	Addrs      []string `mapstructure:"addrs" validate:"required_if=Mode sentinel,required_if=Mode cluster,dive,hostname_port"`
+	MasterName string   `mapstructure:"mastername" validate:"required_if=Mode sentinel"`
+	// Username selects an ACL user, empty for the default user
+	Username         string        `mapstructure:"username"`
+	Password         string        `mapstructure:"password" redact:"true"`
+	SentinelUsername string        `mapstructure:"sentinelusername"`
+	SentinelPassword string        `mapstructure:"sentinelpassword" redact:"true"`
+	DB               int           `mapstructure:"db" validate:"min=0"`
+	DialTimeout      time.Duration `mapstructure:"dialtimeout" validate:"gte=0"`
+	ReadTimeout      time.Duration `mapstructure:"readtimeout" validate:"gte=0"`
+	WriteTimeout     time.Duration `mapstructure:"writetimeout" validate:"gte=0"`
+	TLS              RedisTLS      `mapstructure:"tls"`
+	Pool             RedisPool     `mapstructure:"pool"`
+	Local            LocalTier     `mapstructure:"local"`
+}
+
+type RedisTLS struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ServerName defaults to the host being dialled
+	ServerName string `mapstructure:"servername"`
+	// CAFile is a PEM bundle trusted in addition to the system roots
+	CAFile             string `mapstructure:"cafile"`
+	InsecureSkipVerify bool   `mapstructure:"insecureskipverify"`
+}
+
+// RedisPool sizes the connection pool of each server, zero values keep the go-redis defaults
+type RedisPool struct {
+	Size        int           `mapstructure:"size" validate:"gte=0"`
+	MinIdle     int           `mapstructure:"minidle" validate:"gte=0"`
+	MaxConnAge  time.Duration `mapstructure:"maxconnage" validate:"gte=0"`
+	IdleTimeout time.Duration `mapstructure:"idletimeout" validate:"gte=0"`
+	Timeout     time.Duration `mapstructure:"timeout" validate:"gte=0"`
+}
+
+// LocalTier is the in-process tier used by GetOrLoad, off when Size is 0
+type LocalTier struct {
+	Size int           `mapstructure:"size" validate:"gte=0"`
+	TTL  time.Duration `mapstructure:"ttl" default:"1m" validate:"gte=0"`
+}
+
+type MigrationsConfig struct {
+	// Auto applies pending migrations on start
+	Auto     bool           `mapstructure:"auto"`
+	Fixtures FixturesConfig `mapstructure:"fixtures"`
+}
+
+type FixturesConfig struct {
+	// Environments load the sample data of pkg/repo/migrations/fixtures
+	Environments []string `mapstructure:"environments"`
+}
+
+type HealthConfig struct {
+	// CacheTTL reuses readiness reports, 0 probes on every request
+	CacheTTL time.Duration     `mapstructure:"cachettl" default:"5s" validate:"gte=0"`
+	Timeout  time.Duration     `mapstructure:"timeout" default:"2s" validate:"gt=0"`
+	Mandi    HealthMandiConfig `mapstructure:"mandi"`
+}
+
+type HealthMandiConfig struct {
+	// MaxAge reports the mandi ingest degraded when the latest price is older
+	MaxAge time.Duration `mapstructure:"maxage" default:"48h" validate:"gt=0"`
+}
+
+type LogConfig struct {
+	Path string `mapstructure:"path"`
+	// Level is a zapcore level, -1 for debug
+	Level int `mapstructure:"level" default:"-1" validate:"min=-1,max=5"`
+}
+
+type AdminConfig struct {
+	// Token is sent as X-Admin-Token to /v1/admin, the admin endpoints are closed while empty
+	Token string `mapstructure:"token" redact:"true"`
+}
+
+type JobsConfig struct {
+	// Timezone the cron expressions are read in
+	Timezone string `mapstructure:"timezone" default:"Asia/Kolkata" validate:"timezone"`
+	// Overrides of the schedule and retries of a job by its name
+	Overrides map[string]JobConfig `mapstructure:"overrides" validate:"dive"`
+}
+
+// JobConfig overrides the settings of a job, zero values keep the job's own
+type JobConfig struct {
+	Schedule   string        `mapstructure:"schedule"`
+	Timeout    time.Duration `mapstructure:"timeout" validate:"gte=0"`
+	Attempts   int           `mapstructure:"attempts" validate:"gte=0"`
+	Backoff    time.Duration `mapstructure:"backoff" validate:"gte=0"`
+	MaxBackoff time.Duration `mapstructure:"maxbackoff" validate:"gte=0"`
+}
+
+type QueueConfig struct {
+	// Workers is the number of tasks run at a time by each replica
+	Workers      int           `mapstructure:"workers" default:"4" validate:"min=1"`
+	PollInterval time.Duration `mapstructure:"pollinterval" default:"1s" validate:"gt=0"`
+	// Lease bounds a task run, another replica takes over a task whose lease runs out
+	Lease      time.Duration `mapstructure:"lease" default:"5m" validate:"gt=0"`
+	Backoff    time.Duration `mapstructure:"backoff" default:"10s" validate:"gt=0"`
+	MaxBackoff time.Duration `mapstructure:"maxbackoff" default:"1h" validate:"gtefield=Backoff"`
+	// DrainTimeout returns running tasks to the queue when shutdown takes longer
+	DrainTimeout time.Duration `mapstructure:"draintimeout" default:"10s" validate:"gt=0"`
+}
+
+type FlagsConfig struct {
+	// PollInterval reloads the overrides of kisan.feature_flags
+	PollInterval time.Duration `mapstructure:"pollinterval" default:"30s" validate:"gt=0"`
+	// Definitions are the feature flags by name, a flag defined nowhere is off
+	Definitions map[string]FlagConfig `mapstructure:"definitions" validate:"dive"`
+}
+
+// FlagConfig turns a feature on for the users matched by any of its rules, for everyone without rules
+type FlagConfig struct {
+	Description string     `mapstructure:"description"`
+	Enabled     bool       `mapstructure:"enabled"`
+	Rules       []FlagRule `mapstructure:"rules" validate:"dive"`
+}
+
+// FlagRule matches the users of one of Roles in one of Districts, an empty list matches everyone.
+// Percentage rolls the rule out to a stable share of the matched users, all of them when unset.
+type FlagRule struct {
+	Roles      []string `mapstructure:"roles" validate:"dive,oneof=farmer advisor scientist buyer"`
+	Districts  []string `mapstructure:"districts" validate:"dive,required"`
+	Percentage *int     `mapstructure:"percentage" validate:"omitempty,min=0,max=100"`
+}
+
+type FarmConfig struct {
+	Fertilizer FertilizerConfig `mapstructure:"fertilizer"`
+}
+
+type FertilizerConfig struct {
+	// Crops are the targeted yield equations keyed by lower case crop name, the built-in table when empty
+	Crops map[string]FertilizerCrop `mapstructure:"crops" validate:"dive"`
+}
+
+type FertilizerCrop struct {
+	N NutrientEquation `mapstructure:"n"`
+	P NutrientEquation `mapstructure:"p"`
+	K NutrientEquation `mapstructure:"k"`
+}
+
+// NutrientEquation gives the dose (kg/ha) = yield * target (q/ha) - soil * soil test (kg/ha), capped at max
+type NutrientEquation struct {
+	Yield float64 `mapstructure:"yield" validate:"gte=0"`
+	Soil  float64 `mapstructure:"soil" validate:"gte=0"`
+	Max   float64 `mapstructure:"max" validate:"gte=0"`
+}
+
+type ThirdpartyConfig struct {
+	RestAPI       RestAPIConfig `mapstructure:"restapi"`
+	PriceForecast ServiceConfig `mapstructure:"priceforecast"`
+}
+
+type RestAPIConfig struct {
+	RetryCount int `mapstructure:"retrycount" default:"1" validate:"gte=0"`
+	// RetryWaitTime and Timeout are in milliseconds
+	RetryWaitTime int `mapstructure:"retrywaittime" default:"100" validate:"gte=0"`
+	Timeout       int `mapstructure:"timeout" default:"500" validate:"gt=0"`
+}
+
+// ServiceConfig addresses an external http service, Timeout is in milliseconds
+type ServiceConfig struct {
+	URL     string `mapstructure:"url" validate:"omitempty,url"`
+	APIKey  string `mapstructure:"apikey" redact:"true"`
+	Timeout int64  `mapstructure:"timeout" validate:"gte=0"`
+}
+
+type MandiConfig struct {
+	Forecast MandiForecastConfig `mapstructure:"forecast"`
+}
+
+type MandiForecastConfig struct {
+	HistoryDays int           `mapstructure:"historydays" default:"1095" validate:"gt=0"`
+	CacheTTL    time.Duration `mapstructure:"cachettl" default:"6h" validate:"gte=0"`
+	// Model answers without the model service: seasonal-naive, moving-average or holt-winters
+	Model         string              `mapstructure:"model" validate:"omitempty,oneof=seasonal-naive moving-average holt-winters"`
+	MovingAverage MovingAverageConfig `mapstructure:"movingaverage"`
+	HoltWinters   HoltWintersConfig   `mapstructure:"holtwinters"`
+}
+
+type MovingAverageConfig struct {
+	Window int `mapstructure:"window" default:"28" validate:"gt=0"`
+}
+
+// HoltWintersConfig fixes the smoothing parameters, those left at 0 are fitted to each series
+type HoltWintersConfig struct {
+	Alpha float64 `mapstructure:"alpha" validate:"gte=0,lte=1"`
+	Beta  float64 `mapstructure:"beta" validate:"gte=0,lte=1"`
+	Gamma float64 `mapstructure:"gamma" validate:"gte=0,lte=1"`
+	Delta float64 `mapstructure:"delta" validate:"gte=0,lte=1"`
+	// Damping of the trend, between 0.8 and 1, 0.98 when 0
+	Damping float64 `mapstructure:"damping" validate:"gte=0,lte=1"`
+}
+
+type ForecastConfig struct {
+	Climate ClimateConfig `mapstructure:"climate"`
+}
+
+type ClimateConfig struct {
+	// URL is the open-meteo archive when empty
+	URL   string `mapstructure:"url" validate:"omitempty,url"`
+	Years int    `mapstructure:"years" default:"5" validate:"gt=0"`
+	// Timeout is in milliseconds
+	Timeout int64 `mapstructure:"timeout" default:"10000" validate:"gt=0"`
+}
+
+type MarketConfig struct {
+	Listing ListingConfig `mapstructure:"listing"`
+}
+
+type ListingConfig struct {
+	// Days a listing stays open unless the farmer asks otherwise
+	Days int `mapstructure:"days" default:"14" validate:"gt=0"`
+}
+
+type RecommendationConfig struct {
+	Weights WeightsConfig `mapstructure:"weights"`
+}
+
+// WeightsConfig are the relative weights of the crop suitability score, the defaults when all are 0
+type WeightsConfig struct {
+	Soil        float64 `mapstructure:"soil" validate:"gte=0"`
+	Rainfall    float64 `mapstructure:"rainfall" validate:"gte=0"`
+	Temperature float64 `mapstructure:"temperature" validate:"gte=0"`
+	Season      float64 `mapstructure:"season" validate:"gte=0"`
+	Market      float64 `mapstructure:"market" validate:"gte=0"`
+}
+
+type NotificationConfig struct {
+	Push PushConfig `mapstructure:"push"`
+	SMS  SMSConfig  `mapstructure:"sms"`
+}
+
+type PushConfig struct {
+	// Provider is fcm or fake, push is disabled while it is empty
+	Provider    string        `mapstructure:"provider" validate:"omitempty,oneof=fcm fake"`
+	BatchSize   int           `mapstructure:"batchsize" default:"100" validate:"gt=0"`
+	MaxAttempts int           `mapstructure:"maxattempts" default:"5" validate:"gt=0"`
+	Backoff     time.Duration `mapstructure:"backoff" default:"30s" validate:"gt=0"`
+	MaxBackoff  time.Duration `mapstructure:"maxbackoff" default:"1h" validate:"gtefield=Backoff"`
+	Lease       time.Duration `mapstructure:"lease" default:"5m" validate:"gt=0"`
+	FCM         FCMConfig     `mapstructure:"fcm"`
+}
+
+type FCMConfig struct {
+	ProjectID       string `mapstructure:"projectid"`
+	CredentialsFile string `mapstructure:"credentialsfile"`
+	// Timeout is in milliseconds
+	Timeout int64 `mapstructure:"timeout" default:"5000" validate:"gt=0"`
+}
+
+type SMSConfig struct {
+	// Provider is http or mock, sms is disabled while it is empty
+	Provider    string `mapstructure:"provider" validate:"omitempty,oneof=http mock"`
+	URL         string `mapstructure:"url" validate:"omitempty,url"`
+	APIKey      string `mapstructure:"apikey" redact:"true"`
+	SenderID    string `mapstructure:"senderid" default:"KISAAN"`
+	CallbackURL string `mapstructure:"callbackurl" validate:"omitempty,url"`
+	// Timeout is in milliseconds
+	Timeout int64 `mapstructure:"timeout" default:"5000" validate:"gt=0"`
+	// DLRToken authenticates the delivery reports of the gateway
+	DLRToken string `mapstructure:"dlrtoken" redact:"true"`
+}
+
+type AESConfig struct {
+	IV        string `mapstructure:"iv" redact:"true"`
+	SecretKey string `mapstructure:"secretkey" redact:"true"`
+}
+
+type UCCConfig struct {
+	// Whitelist is a | separated list
+	Whitelist string `mapstructure:"whitelist"`
+}
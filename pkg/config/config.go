@@ -1,19 +1,38 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-playground/validator/v10"
 	"github.com/spf13/viper"
 )
 
 var (
 	config       *viper.Viper
+	app          atomic.Pointer[AppConfig]
+	environment  string
+	paths        []string
 	UccWhitelist = make(map[string]bool)
 )
 
+// watchers are notified of each reload of the file
+var (
+	watchMu  sync.Mutex
+	watching bool
+	watchers []func(*AppConfig)
+)
+
 const (
 	USERID        = "userId"
 	REQUESTID     = "requestID"
@@ -27,77 +46,232 @@ const (
 	ISENCRYPT     = "isEncrypt"
 )
 
-// Init is an exported method that takes the environment starts the viper
-// (external lib) and returns the configuration struct.
-func Load(env string, configPaths ...string) {
-	var err error
-	config = viper.New()
-	config.SetConfigType("yaml")
-	config.SetConfigName(env)
-	config.AddConfigPath("app/")
-	config.AddConfigPath(".")
-	if len(configPaths) != 0 {
-		for _, path := range configPaths {
-			config.AddConfigPath(path)
+// EnvPrefix prefixes the environment variables laid over the configuration file
+const EnvPrefix = "KISAAN"
+
+// ServerEnvironment reads the configuration from the environment, its yaml file is optional
+const ServerEnvironment = "server"
+
+// Load reads the yaml file of the environment into AppConfig
+//
+//	values of the form $NAME or ${NAME} are read from the environment variable NAME
+//	KISAAN_ variables override the file, KISAAN_REPO_REDIS_HOST sets repo.redis.host
+//	keys left out take the default of their AppConfig field
+//	the deprecated job intervals, such as scheme.reminder.interval, become jobs.overrides schedules
+//	the result is checked against the validate rules of AppConfig
+//
+// The file is looked up in app/, the working directory and configPaths. Every missing variable and
+// invalid value is reported in the returned error.
+func Load(env string, configPaths ...string) error {
+	v, cfg, err := read(env, configPaths...)
+	if err != nil {
+		return err
+	}
+
+	// a new load is watched again by the next Watch
+	watchMu.Lock()
+	environment, paths, config = env, configPaths, v
+	watching, watchers = false, nil
+	watchMu.Unlock()
+	app.Store(cfg)
+	UccWhitelist = make(map[string]bool)
+	for _, ucc := range strings.Split(cfg.UCC.Whitelist, "|") {
+		UccWhitelist[strings.TrimSpace(ucc)] = true
+	}
+	log.Println("application running with", env, "configuration")
+	return nil
+}
+
+func read(env string, configPaths ...string) (*viper.Viper, *AppConfig, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetConfigName(env)
+	v.AddConfigPath("app/")
+	v.AddConfigPath(".")
+	for _, path := range configPaths {
+		v.AddConfigPath(path)
+	}
+	if err := v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) || env != ServerEnvironment {
+			return nil, nil, fmt.Errorf("error on parsing configuration file: %w", err)
 		}
+		log.Println("no server.yaml, the configuration is read from the environment")
 	}
-	err = config.ReadInConfig()
+	missing := resolvePlaceholders(v)
+
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	v.AutomaticEnv()
+	bindKeys(v, "", reflect.TypeOf(AppConfig{}))
+	if err := v.BindEnv("server.host", EnvPrefix+"_SERVER_HOST", "SERVER_HOST"); err != nil {
+		return nil, nil, err
+	}
+
+	cfg := &AppConfig{}
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	errs := append(missing, applyLegacyJobs(v, cfg)...)
+	if err := errors.Join(append(errs, Validate(cfg))...); err != nil {
+		return nil, nil, err
+	}
+	return v, cfg, nil
+}
+
+// Watch reloads the configuration whenever its file changes and passes every reload that loads
+// and validates to onChange; App returns the reloaded configuration from then on. A reload that
+// fails is logged and the previous configuration kept. Settings read once at start, such as the
+// connections and the ucc whitelist, still need a restart.
+func Watch(onChange func(*AppConfig)) {
+	watchMu.Lock()
+	defer watchMu.Unlock()
+	watchers = append(watchers, onChange)
+	if watching || config == nil || config.ConfigFileUsed() == "" {
+		return
+	}
+	watching = true
+	v, env, configPaths := config, environment, paths
+	v.OnConfigChange(func(event fsnotify.Event) {
+		reload(v, env, configPaths)
+	})
+	v.WatchConfig()
+}
+
+// reload reads the file again, unless it was replaced by a later Load
+func reload(loaded *viper.Viper, env string, configPaths []string) {
+	_, cfg, err := read(env, configPaths...)
 	if err != nil {
-		log.Fatal("error on parsing configuration file", err)
+		log.Println("configuration is not reloaded:", err)
 		return
 	}
-	if env == "server" {
-		for _, v := range config.AllKeys() {
-			if strings.ToLower(v) == "version" {
-				continue // skipping first line
-			}
-			key := config.GetString(v)
-			if key == "" {
-				resp, err := setStringSlice(v)
-				if err != nil {
-					log.Fatal(err)
-				}
-				config.Set(v, resp)
-			} else {
-				key = strings.ReplaceAll(key, "$", "")
-				if ev, ok := os.LookupEnv(key); ok {
-					config.Set(v, ev)
-				} else {
-					log.Fatal("env value for key [", key, "] is missing")
-				}
-			}
 
-		}
-		log.Println("application running with server.yaml")
-	} else {
-		log.Println("application running locally")
+	watchMu.Lock()
+	if config != loaded {
+		watchMu.Unlock()
+		return
 	}
-	uccs := config.GetString("ucc.whitelist")
-	for _, v := range strings.Split(uccs, "|") {
-		UccWhitelist[strings.TrimSpace(v)] = true
+	app.Store(cfg)
+	onChange := slices.Clone(watchers)
+	watchMu.Unlock()
+	log.Println("configuration reloaded")
+	for _, notify := range onChange {
+		notify(cfg)
 	}
-	fmt.Println("Ucc is whitelisted for below users", UccWhitelist)
 }
 
+// GetConfig is the raw configuration as loaded at start, prefer the typed App
 func GetConfig() *viper.Viper {
 	return config
 }
 
-func setStringSlice(key string) ([]string, error) {
-	var (
-		resp []string
-		err  error
-	)
+// App is the configuration read by Load, or its latest reload once Watch is called
+func App() *AppConfig {
+	return app.Load()
+}
+
+// Environment is the name of the configuration file loaded, server when it is read from the environment
+func Environment() string {
+	return environment
+}
 
-	keys := config.GetStringSlice(key)
+// resolvePlaceholders replaces $NAME and ${NAME} values of the file, and list items, with the
+// environment variable NAME and returns an error for each variable that is not set. The file is
+// merged back so KISAAN_ variables still override the resolved values.
+func resolvePlaceholders(v *viper.Viper) []error {
+	var missing []error
+	var resolve func(key string, value interface{}) interface{}
+	resolve = func(key string, value interface{}) interface{} {
+		switch value := value.(type) {
+		case string:
+			name, ok := placeholder(value)
+			if !ok {
+				return value
+			}
+			if resolved, ok := os.LookupEnv(name); ok {
+				return resolved
+			}
+			missing = append(missing, fmt.Errorf("%s: environment variable %s is not set", key, name))
+			return ""
+		case []interface{}:
+			for i, item := range value {
+				value[i] = resolve(key, item)
+			}
+		case map[string]interface{}:
+			for name, item := range value {
+				value[name] = resolve(strings.TrimPrefix(key+"."+name, "."), item)
+			}
+		}
+		return value
+	}
+	settings := v.AllSettings()
+	resolve("", settings)
+	if err := v.MergeConfigMap(settings); err != nil {
+		missing = append(missing, err)
+	}
+	return missing
+}
 
-	for _, k := range keys {
-		k = strings.ReplaceAll(k, "$", "")
-		if ev, ok := os.LookupEnv(k); ok {
-			resp = append(resp, ev)
-		} else {
-			return resp, fmt.Errorf("env value for key [ %v ] is missing", k)
+func placeholder(value string) (string, bool) {
+	if !strings.HasPrefix(value, "$") {
+		return "", false
+	}
+	name := strings.TrimPrefix(value, "$")
+	if strings.HasPrefix(name, "{") && strings.HasSuffix(name, "}") {
+		name = name[1 : len(name)-1]
+	}
+	return name, name != ""
+}
+
+// bindKeys binds every leaf of the struct to its KISAAN_ variable, so the overlay reaches keys
+// missing from the file, and registers the defaults
+func bindKeys(v *viper.Viper, prefix string, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := prefix + keyOf(field)
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Duration(0)) {
+			bindKeys(v, key+".", field.Type)
+			continue
+		}
+		if field.Type.Kind() == reflect.Map {
+			continue
+		}
+		_ = v.BindEnv(key)
+		if value, ok := field.Tag.Lookup("default"); ok {
+			v.SetDefault(key, value)
+		}
+	}
+}
+
+func keyOf(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ","); name != "" {
+		return name
+	}
+	return strings.ToLower(field.Name)
+}
+
+// Validate checks the validate rules of the configuration, errors name the keys
+func Validate(cfg *AppConfig) error {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(keyOf)
+	err := validate.Struct(cfg)
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return err
+	}
+	errs := make([]error, 0, len(invalid))
+	for _, fieldErr := range invalid {
+		// the namespace starts with the struct name
+		_, key, _ := strings.Cut(fieldErr.Namespace(), ".")
+		rule := fieldErr.Tag()
+		if fieldErr.Param() != "" {
+			rule += "=" + fieldErr.Param()
+		}
+		value := fmt.Sprint(fieldErr.Value())
+		if text, ok := fieldErr.Value().(string); ok {
+			value = strconv.Quote(text)
 		}
+		errs = append(errs, fmt.Errorf("%s: %s fails %s", key, value, rule))
 	}
-	return resp, err
+	return errors.Join(errs...)
 }
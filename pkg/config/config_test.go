@@ -0,0 +1,180 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfig writes the yaml of environment env to a temporary directory and returns the directory.
+// The required postgres host comes from the environment so that the yaml only holds what is tested.
+func writeConfig(t *testing.T, env string, yaml string) string {
+	t.Helper()
+	t.Setenv("KISAAN_REPO_DATABASES_POSTGRES_HOST", "127.0.0.1")
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, env+".yaml"), []byte(yaml), 0o600))
+	return dir
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	dir := writeConfig(t, "test", "server:\n  port: 9090\n")
+
+	require.NoError(t, Load("test", dir))
+
+	cfg := App()
+	assert.Equal(t, 9090, cfg.Server.Port)
+	assert.Equal(t, "redis", cfg.Repo.Redis.Mode)
+	assert.Equal(t, 6379, cfg.Repo.Redis.Port)
+	assert.Equal(t, "prefer", cfg.Repo.Databases.Postgres.SSLMode)
+	assert.Equal(t, 4, cfg.Queue.Workers)
+	assert.Equal(t, 10*time.Second, cfg.Queue.DrainTimeout)
+	assert.Equal(t, "Asia/Kolkata", cfg.Jobs.Timezone)
+	assert.Equal(t, -1, cfg.Log.Level)
+	assert.Equal(t, "test", Environment())
+}
+
+func TestLoad_EnvOverlay(t *testing.T) {
+	dir := writeConfig(t, "test", "repo:\n  redis:\n    host: 127.0.0.1\n")
+	t.Setenv("KISAAN_REPO_REDIS_HOST", "redis.internal")
+	t.Setenv("KISAAN_QUEUE_LEASE", "2m")
+	t.Setenv("SERVER_HOST", "10.0.0.5")
+
+	require.NoError(t, Load("test", dir))
+
+	assert.Equal(t, "redis.internal", App().Repo.Redis.Host)
+	assert.Equal(t, 2*time.Minute, App().Queue.Lease, "keys missing from the file are overlaid too")
+	assert.Equal(t, "10.0.0.5", App().Server.Host)
+}
+
+func TestLoad_Placeholders(t *testing.T) {
+	dir := writeConfig(t, "test", "repo:\n  databases:\n    postgres:\n      password: $PG_PASSWORD\n      user: ${PG_USER}\n")
+	t.Setenv("PG_PASSWORD", "s3cret")
+	t.Setenv("PG_USER", "kisan")
+
+	require.NoError(t, Load("test", dir))
+
+	assert.Equal(t, "s3cret", App().Repo.Databases.Postgres.Password)
+	assert.Equal(t, "kisan", App().Repo.Databases.Postgres.User)
+}
+
+func TestLoad_MissingPlaceholder(t *testing.T) {
+	dir := writeConfig(t, "test", "admin:\n  token: $KISAAN_TEST_MISSING_TOKEN\n")
+
+	err := Load("test", dir)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "admin.token: environment variable KISAAN_TEST_MISSING_TOKEN is not set")
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := writeConfig(t, "test", "repo:\n  redis:\n    mode: sentinel\n    addrs: [\"10.0.0.1:26379\"]\nqueue:\n  workers: 0\n")
+
+	err := Load("test", dir)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `repo.redis.mastername: "" fails required_if=Mode sentinel`)
+	assert.Contains(t, err.Error(), "queue.workers: 0 fails min=1", "every invalid key is reported")
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	t.Setenv("KISAAN_SERVER_PORT", "7070")
+	t.Setenv("KISAAN_REPO_DATABASES_POSTGRES_HOST", "db.internal")
+
+	assert.Error(t, Load("test", t.TempDir()))
+	require.NoError(t, Load(ServerEnvironment, t.TempDir()), "the server environment may come from the environment alone")
+	assert.Equal(t, 7070, App().Server.Port)
+}
+
+func TestLoad_PostgresHostRequired(t *testing.T) {
+	dir := writeConfig(t, "test", "server:\n  port: 9090\n")
+	t.Setenv("KISAAN_REPO_DATABASES_POSTGRES_HOST", "")
+
+	err := Load("test", dir)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `repo.databases.postgres.host: "" fails required`)
+}
+
+func TestLoad_LegacyJobIntervals(t *testing.T) {
+	dir := writeConfig(t, "test", "scheme:\n  reminder:\n    interval: 2h\nmarket:\n  expiry:\n    interval: 5m\njobs:\n  overrides:\n    listing-expiry:\n      schedule: \"*/10 * * * *\"\n")
+	t.Setenv("KISAAN_NOTIFICATION_PUSH_INTERVAL", "45s")
+
+	require.NoError(t, Load("test", dir))
+
+	overrides := App().Jobs.Overrides
+	assert.Equal(t, "@every 2h0m0s", overrides["scheme-deadline-reminders"].Schedule)
+	assert.Equal(t, "@every 45s", overrides["push-delivery"].Schedule)
+	assert.Equal(t, "*/10 * * * *", overrides["listing-expiry"].Schedule, "jobs.overrides wins")
+	assert.NotContains(t, overrides, "crop-task-reminders")
+
+	dir = writeConfig(t, "test", "farm:\n  reminder:\n    interval: hourly\n")
+	err := Load("test", dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `farm.reminder.interval: "hourly" is not a positive duration`)
+}
+
+func TestLoad_LegacyJobOverrides(t *testing.T) {
+	dir := writeConfig(t, "test", "jobs:\n  listing-expiry:\n    schedule: \"*/5 * * * *\"\n    attempts: 2\n  push-delivery:\n    timeout: 2m\n  overrides:\n    push-delivery:\n      timeout: 3m\n      backoff: 10s\n")
+
+	require.NoError(t, Load("test", dir))
+
+	overrides := App().Jobs.Overrides
+	assert.Equal(t, JobConfig{Schedule: "*/5 * * * *", Attempts: 2}, overrides["listing-expiry"])
+	assert.Equal(t, JobConfig{Timeout: 3 * time.Minute, Backoff: 10 * time.Second}, overrides["push-delivery"], "jobs.overrides wins")
+
+	dir = writeConfig(t, "test", "jobs:\n  timezone: UTC\n  retries: 3\n")
+	err := Load("test", dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "jobs.retries: unknown key")
+}
+
+func TestPrint_Redact(t *testing.T) {
+	cfg := &AppConfig{}
+	cfg.Repo.Databases.Postgres.Password = "s3cret"
+	cfg.Repo.Databases.Postgres.User = "kisan"
+	cfg.Queue.Lease = 5 * time.Minute
+
+	var out bytes.Buffer
+	require.NoError(t, Print(&out, cfg, true))
+	assert.NotContains(t, out.String(), "s3cret")
+	assert.Contains(t, out.String(), "password: "+Redacted)
+	assert.Contains(t, out.String(), "user: kisan")
+	assert.Contains(t, out.String(), "lease: 5m0s")
+	assert.Contains(t, out.String(), `apikey: ""`, "empty secrets stay empty")
+
+	out.Reset()
+	require.NoError(t, Print(&out, cfg, false))
+	assert.Contains(t, out.String(), "password: s3cret")
+}
+
+func TestWatch_Reloads(t *testing.T) {
+	dir := writeConfig(t, "watch", "flags:\n  definitions:\n    sms-fallback:\n      enabled: true\n")
+	require.NoError(t, Load("watch", dir))
+	reloads := make(chan *AppConfig, 4)
+	Watch(func(cfg *AppConfig) { reloads <- cfg })
+
+	rewrite := func(yaml string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "watch.yaml"), []byte(yaml), 0o600))
+	}
+	rewrite("flags:\n  definitions:\n    sms-fallback:\n      enabled: true\n      rules:\n        - { districts: [Nashik], percentage: 20 }\n")
+	select {
+	case cfg := <-reloads:
+		rules := cfg.Flags.Definitions["sms-fallback"].Rules
+		require.Len(t, rules, 1)
+		assert.Equal(t, []string{"Nashik"}, rules[0].Districts)
+		assert.Equal(t, 20, *rules[0].Percentage)
+		assert.Same(t, cfg, App())
+	case <-time.After(5 * time.Second):
+		t.Fatal("the change was not reloaded")
+	}
+
+	reloaded := App()
+	rewrite("flags:\n  definitions:\n    sms-fallback:\n      rules:\n        - { percentage: 120 }\n")
+	time.Sleep(500 * time.Millisecond)
+	assert.Same(t, reloaded, App(), "an invalid file keeps the previous configuration")
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// legacyIntervals are the keys the background jobs were run every interval of before they moved
+// to the scheduler, by the job they now configure
+var legacyIntervals = []struct {
+	key string
+	job string
+}{
+	{key: "scheme.reminder.interval", job: "scheme-deadline-reminders"},
+	{key: "farm.reminder.interval", job: "crop-task-reminders"},
+	{key: "market.expiry.interval", job: "listing-expiry"},
+	{key: "notification.push.interval", job: "push-delivery"},
+}
+
+// applyLegacyJobs reads the deprecated job settings of v into the overrides of cfg, a setting under
+// jobs.overrides wins. A deprecation warning is logged for each one that is set.
+//
+//	jobs.<name> was where the overrides of a job were kept before jobs.overrides.<name>
+//	the intervals of legacyIntervals become "@every <interval>" schedules
+func applyLegacyJobs(v *viper.Viper, cfg *AppConfig) []error {
+	var errs []error
+	for name := range v.GetStringMap("jobs") {
+		if name == "timezone" || name == "overrides" {
+			continue
+		}
+		key := "jobs." + name
+		if _, ok := v.Get(key).(map[string]interface{}); !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown key, job overrides go under jobs.overrides", key))
+			continue
+		}
+		var legacy JobConfig
+		if err := v.UnmarshalKey(key, &legacy); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		log.Printf("%s is deprecated, move it to jobs.overrides.%s", key, name)
+		override := cfg.Jobs.Overrides[name]
+		if override.Schedule == "" {
+			override.Schedule = legacy.Schedule
+		}
+		if override.Timeout == 0 {
+			override.Timeout = legacy.Timeout
+		}
+		if override.Attempts == 0 {
+			override.Attempts = legacy.Attempts
+		}
+		if override.Backoff == 0 {
+			override.Backoff = legacy.Backoff
+		}
+		if override.MaxBackoff == 0 {
+			override.MaxBackoff = legacy.MaxBackoff
+		}
+		setOverride(cfg, name, override)
+	}
+	for _, legacy := range legacyIntervals {
+		if !v.IsSet(legacy.key) {
+			continue
+		}
+		interval, err := time.ParseDuration(v.GetString(legacy.key))
+		if err != nil || interval <= 0 {
+			errs = append(errs, fmt.Errorf("%s: %q is not a positive duration", legacy.key, v.GetString(legacy.key)))
+			continue
+		}
+		log.Printf("%s is deprecated, set jobs.overrides.%s.schedule instead", legacy.key, legacy.job)
+		override := cfg.Jobs.Overrides[legacy.job]
+		if override.Schedule != "" {
+			continue
+		}
+		override.Schedule = "@every " + interval.String()
+		setOverride(cfg, legacy.job, override)
+	}
+	return errs
+}
+
+func setOverride(cfg *AppConfig, job string, override JobConfig) {
+	if cfg.Jobs.Overrides == nil {
+		cfg.Jobs.Overrides = make(map[string]JobConfig)
+	}
+	cfg.Jobs.Overrides[job] = override
+}
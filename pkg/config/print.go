@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Redacted replaces the secrets printed with redact
+const Redacted = "xxxxx"
+
+// Print writes the configuration as yaml in the layout of the configuration file. With redact
+// the values of the fields tagged redact are replaced by Redacted, empty ones stay empty.
+func Print(w io.Writer, cfg *AppConfig, redact bool) error {
+	node := toNode(reflect.ValueOf(*cfg), redact)
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func toNode(value reflect.Value, redact bool) *yaml.Node {
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		return scalar(time.Duration(value.Int()).String())
+	}
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+		}
+		return toNode(value.Elem(), redact)
+	case reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			item := toNode(value.Field(i), redact)
+			if redact && field.Tag.Get("redact") == "true" && !value.Field(i).IsZero() {
+				item = scalar(Redacted)
+			}
+			node.Content = append(node.Content, scalar(keyOf(field)), item)
+		}
+		return node
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		keys := make([]string, 0, value.Len())
+		for _, key := range value.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			node.Content = append(node.Content, scalar(key), toNode(value.MapIndex(reflect.ValueOf(key)), redact))
+		}
+		return node
+	case reflect.Slice:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for i := 0; i < value.Len(); i++ {
+			node.Content = append(node.Content, toNode(value.Index(i), redact))
+		}
+		return node
+	case reflect.String:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value.String()}
+	case reflect.Bool:
+		return scalar(strconv.FormatBool(value.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return scalar(strconv.FormatInt(value.Int(), 10))
+	case reflect.Float32, reflect.Float64:
+		return scalar(strconv.FormatFloat(value.Float(), 'f', -1, 64))
+	default:
+		return scalar(fmt.Sprint(value.Interface()))
+	}
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+}
@@ -0,0 +1,90 @@
+package flags
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// sources of a flag
+const (
+	SourceConfig   = "config"
+	SourceDatabase = "database"
+)
+
+// Flag turns a feature on for the users matched by any of its rules, or for everyone when it has
+// none. A disabled flag is off for everyone.
+type Flag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Enabled     bool   `json:"enabled"`
+	Rules       []Rule `json:"rules"`
+	// Source is config for flags.definitions and database for rows of kisan.feature_flags
+	Source string `json:"source"`
+}
+
+// Rule matches the users of one of Roles in one of Districts, an empty list matches everyone.
+// Percentage rolls the rule out to a stable share of the matched users, all of them when nil.
+type Rule struct {
+	Roles      []string `json:"roles,omitempty"`
+	Districts  []string `json:"districts,omitempty"`
+	Percentage *int     `json:"percentage,omitempty"`
+}
+
+// User is who a flag is evaluated for, ID is 0 for anonymous requests
+type User struct {
+	ID       int64  `gorm:"column:id"`
+	Role     string `gorm:"column:role"`
+	District string `gorm:"column:district"`
+}
+
+// on evaluates the flag for the user, attributes are only loaded for rules on role or district
+func (f Flag) on(id int64, attributes func() User) bool {
+	if !f.Enabled {
+		return false
+	}
+	if len(f.Rules) == 0 {
+		return true
+	}
+	for _, rule := range f.Rules {
+		if rule.matches(f.Name, id, attributes) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) matches(flag string, id int64, attributes func() User) bool {
+	if len(r.Roles) > 0 && !contains(r.Roles, attributes().Role) {
+		return false
+	}
+	if len(r.Districts) > 0 && !contains(r.Districts, attributes().District) {
+		return false
+	}
+	if r.Percentage == nil || *r.Percentage >= 100 {
+		return true
+	}
+	// anonymous users have no stable bucket and stay out of partial rollouts
+	return id > 0 && bucket(flag, id) < *r.Percentage
+}
+
+func contains(values []string, value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return false
+	}
+	for _, candidate := range values {
+		if strings.EqualFold(strings.TrimSpace(candidate), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// bucket places the user in 0..99 for the flag, so raising the percentage of a rollout keeps the
+// users already in it and different flags roll out to different users
+func bucket(flag string, id int64) int {
+	h := fnv.New32a()
+	h.Write([]byte(flag + ":" + strconv.FormatInt(id, 10)))
+	return int(h.Sum32() % 100)
+}
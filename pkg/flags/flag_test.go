@@ -0,0 +1,127 @@
+package flags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func percent(p int) *int {
+	return &p
+}
+
+func TestFlag_On(t *testing.T) {
+	farmerInNashik := User{ID: 42, Role: "farmer", District: "Nashik"}
+	testCases := []struct {
+		desc     string
+		flag     Flag
+		id       int64
+		user     User
+		expected bool
+	}{
+		{
+			desc:     "Disabled",
+			flag:     Flag{Name: "sms-fallback", Enabled: false},
+			id:       42,
+			user:     farmerInNashik,
+			expected: false,
+		}, {
+			desc:     "EnabledForEveryone",
+			flag:     Flag{Name: "sms-fallback", Enabled: true},
+			expected: true,
+		}, {
+			desc:     "DisabledWithMatchingRule",
+			flag:     Flag{Name: "sms-fallback", Enabled: false, Rules: []Rule{{Roles: []string{"farmer"}}}},
+			id:       42,
+			user:     farmerInNashik,
+			expected: false,
+		}, {
+			desc:     "RoleMatches",
+			flag:     Flag{Name: "sms-fallback", Enabled: true, Rules: []Rule{{Roles: []string{"advisor", "farmer"}}}},
+			id:       42,
+			user:     farmerInNashik,
+			expected: true,
+		}, {
+			desc:     "RoleDoesNotMatch",
+			flag:     Flag{Name: "sms-fallback", Enabled: true, Rules: []Rule{{Roles: []string{"buyer"}}}},
+			id:       42,
+			user:     farmerInNashik,
+			expected: false,
+		}, {
+			desc:     "DistrictMatchesIgnoringCase",
+			flag:     Flag{Name: "sms-fallback", Enabled: true, Rules: []Rule{{Districts: []string{"nashik "}}}},
+			id:       42,
+			user:     farmerInNashik,
+			expected: true,
+		}, {
+			desc:     "RoleAndDistrictBothNeeded",
+			flag:     Flag{Name: "sms-fallback", Enabled: true, Rules: []Rule{{Roles: []string{"farmer"}, Districts: []string{"Pune"}}}},
+			id:       42,
+			user:     farmerInNashik,
+			expected: false,
+		}, {
+			desc:     "AnyRuleMatches",
+			flag:     Flag{Name: "sms-fallback", Enabled: true, Rules: []Rule{{Districts: []string{"Pune"}}, {Roles: []string{"farmer"}}}},
+			id:       42,
+			user:     farmerInNashik,
+			expected: true,
+		}, {
+			desc:     "NoDistrictDoesNotMatch",
+			flag:     Flag{Name: "sms-fallback", Enabled: true, Rules: []Rule{{Districts: []string{"Nashik"}}}},
+			id:       42,
+			user:     User{ID: 42, Role: "farmer"},
+			expected: false,
+		}, {
+			desc:     "FullRollout",
+			flag:     Flag{Name: "sms-fallback", Enabled: true, Rules: []Rule{{Percentage: percent(100)}}},
+			expected: true,
+		}, {
+			desc:     "NoRollout",
+			flag:     Flag{Name: "sms-fallback", Enabled: true, Rules: []Rule{{Percentage: percent(0)}}},
+			id:       42,
+			user:     farmerInNashik,
+			expected: false,
+		}, {
+			desc:     "AnonymousOutOfPartialRollout",
+			flag:     Flag{Name: "sms-fallback", Enabled: true, Rules: []Rule{{Percentage: percent(99)}}},
+			expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.desc, func(t *testing.T) {
+			user := testCase.user
+			assert.Equal(t, testCase.expected, testCase.flag.on(testCase.id, func() User { return user }))
+		})
+	}
+}
+
+func TestFlag_OnLoadsAttributesOnlyForTheirRules(t *testing.T) {
+	loads := 0
+	attributes := func() User {
+		loads++
+		return User{ID: 42, Role: "farmer"}
+	}
+
+	assert.True(t, Flag{Name: "feed-ranking", Enabled: true, Rules: []Rule{{Percentage: percent(100)}}}.on(42, attributes))
+	assert.Equal(t, 0, loads)
+
+	assert.True(t, Flag{Name: "feed-ranking", Enabled: true, Rules: []Rule{{Roles: []string{"farmer"}}}}.on(42, attributes))
+	assert.Equal(t, 1, loads)
+}
+
+func TestFlag_PercentageRollout(t *testing.T) {
+	flag := Flag{Name: "feed-ranking", Enabled: true, Rules: []Rule{{Percentage: percent(25)}}}
+	wider := Flag{Name: "feed-ranking", Enabled: true, Rules: []Rule{{Percentage: percent(50)}}}
+	noAttributes := func() User { return User{} }
+
+	on := 0
+	for id := int64(1); id <= 10000; id++ {
+		if flag.on(id, noAttributes) {
+			on++
+			assert.True(t, wider.on(id, noAttributes), "user %d stays in when the rollout widens", id)
+		}
+		assert.Equal(t, flag.on(id, noAttributes), flag.on(id, noAttributes), "user %d gets a stable answer", id)
+	}
+	assert.InDelta(t, 2500, on, 250)
+}
@@ -0,0 +1,274 @@
+package flags
+
+import (
+	"context"
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/utils"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// DefaultPollInterval applies when Config leaves it out
+const DefaultPollInterval = 30 * time.Second
+
+// userKey caches the attributes of the request's user in the gin context
+const userKey = "flags.user"
+
+type Config struct {
+	// PollInterval reloads the overrides of the store
+	PollInterval time.Duration
+}
+
+// Flags evaluates the flags defined in config with the overrides of the store laid over them. The
+// definitions are replaced on each config reload and the overrides polled, so flags change without
+// a restart. A flag defined nowhere is off.
+type Flags struct {
+	store Store
+	cfg   Config
+
+	mu        sync.RWMutex
+	defined   map[string]Flag
+	overrides map[string]Flag
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	start  sync.Once
+}
+
+// New evaluates flags with the overrides of store, nil to use the definitions alone
+func New(store Store, cfg Config) *Flags {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Flags{
+		store:     store,
+		cfg:       cfg,
+		defined:   make(map[string]Flag),
+		overrides: make(map[string]Flag),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Define replaces the flags defined in config
+func (f *Flags) Define(definitions []Flag) {
+	defined := make(map[string]Flag, len(definitions))
+	for _, flag := range definitions {
+		flag.Source = SourceConfig
+		defined[flag.Name] = flag
+	}
+	f.mu.Lock()
+	f.defined = defined
+	f.mu.Unlock()
+}
+
+// Refresh reloads the overrides of the store, the previous ones are kept when it fails
+func (f *Flags) Refresh(ctx context.Context) error {
+	if f.store == nil {
+		return nil
+	}
+	flags, err := f.store.Overrides(ctx)
+	if err != nil {
+		return err
+	}
+	overrides := make(map[string]Flag, len(flags))
+	for _, flag := range flags {
+		overrides[flag.Name] = flag
+	}
+	f.mu.Lock()
+	f.overrides = overrides
+	f.mu.Unlock()
+	return nil
+}
+
+// Start loads the overrides and polls the store for changes until Stop
+func (f *Flags) Start() {
+	if f.store == nil {
+		return
+	}
+	f.start.Do(func() {
+		if err := f.Refresh(f.ctx); err != nil {
+			logger.Log().Error("feature flag overrides are not loaded", zap.Error(err))
+		}
+		f.wg.Add(1)
+		go f.poll()
+	})
+}
+
+func (f *Flags) poll() {
+	defer f.wg.Done()
+	ticker := time.NewTicker(f.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := f.Refresh(f.ctx); err != nil && f.ctx.Err() == nil {
+			logger.Log().Warn("feature flag overrides are not reloaded, keeping the previous ones", zap.Error(err))
+		}
+	}
+}
+
+// Stop ends the polling
+func (f *Flags) Stop() {
+	f.cancel()
+	f.wg.Wait()
+}
+
+// Lookup returns the flag of the name, its override when there is one
+func (f *Flags) Lookup(name string) (Flag, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if flag, ok := f.overrides[name]; ok {
+		return flag, true
+	}
+	flag, ok := f.defined[name]
+	return flag, ok
+}
+
+// List returns the flags in effect by name
+func (f *Flags) List() []Flag {
+	f.mu.RLock()
+	flags := make([]Flag, 0, len(f.defined)+len(f.overrides))
+	for name, flag := range f.defined {
+		if _, ok := f.overrides[name]; !ok {
+			flags = append(flags, flag)
+		}
+	}
+	for _, flag := range f.overrides {
+		flags = append(flags, flag)
+	}
+	f.mu.RUnlock()
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+	return flags
+}
+
+// Enabled reports whether the flag is on for the user of the request. In a gin context the user
+// is read like utils.GetUserID and their role and district loaded once per request.
+func (f *Flags) Enabled(ctx context.Context, name string) bool {
+	flag, ok := f.Lookup(name)
+	if !ok {
+		return false
+	}
+	id := requestUserID(ctx)
+	return flag.on(id, f.attributes(ctx, id))
+}
+
+// EnabledFor reports whether the flag is on for the user, for work done outside of a request
+func (f *Flags) EnabledFor(ctx context.Context, name string, userID int64) bool {
+	flag, ok := f.Lookup(name)
+	if !ok {
+		return false
+	}
+	return flag.on(userID, f.attributes(ctx, userID))
+}
+
+// EnabledForOr is EnabledFor for behaviour that predates its flag, it reports undefined when the
+// flag is neither defined in config nor overridden
+func (f *Flags) EnabledForOr(ctx context.Context, name string, userID int64, undefined bool) bool {
+	flag, ok := f.Lookup(name)
+	if !ok {
+		return undefined
+	}
+	return flag.on(userID, f.attributes(ctx, userID))
+}
+
+func requestUserID(ctx context.Context) int64 {
+	if c, ok := ctx.(*gin.Context); ok {
+		id, err := utils.GetUserID(c)
+		if err != nil {
+			return 0
+		}
+		return id
+	}
+	switch value := ctx.Value(config.USERID).(type) {
+	case int64:
+		return value
+	case string:
+		id, _ := strconv.ParseInt(value, 10, 64)
+		return id
+	}
+	return 0
+}
+
+// attributes loads the role and district of the user on first use, an anonymous user or one that
+// cannot be loaded has neither and only matches rules without them
+func (f *Flags) attributes(ctx context.Context, id int64) func() User {
+	var (
+		once sync.Once
+		user = User{ID: id}
+	)
+	return func() User {
+		once.Do(func() {
+			if id <= 0 || f.store == nil {
+				return
+			}
+			c, isGin := ctx.(*gin.Context)
+			if isGin {
+				if cached, ok := c.Get(userKey); ok {
+					if cachedUser, ok := cached.(User); ok && cachedUser.ID == id {
+						user = cachedUser
+						return
+					}
+				}
+			}
+			loaded, err := f.store.User(ctx, id)
+			if err != nil {
+				logger.Log(ctx).Warn("feature flag rules on role and district do not match, the user is not loaded", zap.Int64("userId", id), zap.Error(err))
+				return
+			}
+			user = loaded
+			if isGin {
+				c.Set(userKey, user)
+			}
+		})
+		return user
+	}
+}
+
+var (
+	global atomic.Pointer[Flags]
+	none   = New(nil, Config{})
+)
+
+// SetDefault makes f the flags of Enabled and EnabledFor
+func SetDefault(f *Flags) {
+	global.Store(f)
+}
+
+// Default returns the flags set by SetDefault, or flags that are all off
+func Default() *Flags {
+	if f := global.Load(); f != nil {
+		return f
+	}
+	return none
+}
+
+// Enabled reports whether the flag is on for the user of the request, see Flags.Enabled
+func Enabled(ctx context.Context, name string) bool {
+	return Default().Enabled(ctx, name)
+}
+
+// EnabledFor reports whether the flag is on for the user, see Flags.EnabledFor
+func EnabledFor(ctx context.Context, name string, userID int64) bool {
+	return Default().EnabledFor(ctx, name, userID)
+}
+
+// EnabledForOr reports whether the flag is on for the user, undefined when it is defined nowhere,
+// see Flags.EnabledForOr
+func EnabledForOr(ctx context.Context, name string, userID int64, undefined bool) bool {
+	return Default().EnabledForOr(ctx, name, userID, undefined)
+}
@@ -0,0 +1,120 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/config"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type fakeStore struct {
+	overrides []Flag
+	err       error
+	users     map[int64]User
+	loads     int
+}
+
+func (f *fakeStore) Overrides(ctx context.Context) ([]Flag, error) {
+	return f.overrides, f.err
+}
+
+func (f *fakeStore) User(ctx context.Context, id int64) (User, error) {
+	f.loads++
+	user, ok := f.users[id]
+	if !ok {
+		return User{}, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func requestAs(userID string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/v1/feeds", nil)
+	if userID != "" {
+		c.Set(config.USERID, userID)
+	}
+	return c
+}
+
+func TestFlags_OverrideReplacesDefinition(t *testing.T) {
+	store := &fakeStore{overrides: []Flag{{Name: "sms-fallback", Enabled: true, Rules: []Rule{{Districts: []string{"Nashik"}}}, Source: SourceDatabase}}}
+	f := New(store, Config{})
+	f.Define([]Flag{{Name: "sms-fallback", Enabled: true}, {Name: "feed-ranking", Enabled: false}})
+	assert.NoError(t, f.Refresh(context.Background()))
+
+	flag, ok := f.Lookup("sms-fallback")
+	assert.True(t, ok)
+	assert.Equal(t, SourceDatabase, flag.Source)
+	assert.Equal(t, []Flag{
+		{Name: "feed-ranking", Source: SourceConfig},
+		{Name: "sms-fallback", Enabled: true, Rules: []Rule{{Districts: []string{"Nashik"}}}, Source: SourceDatabase},
+	}, f.List())
+
+	store.err = errors.New("connection refused")
+	assert.Error(t, f.Refresh(context.Background()))
+	flag, _ = f.Lookup("sms-fallback")
+	assert.Equal(t, SourceDatabase, flag.Source, "overrides are kept while the store is down")
+}
+
+func TestFlags_EnabledReadsTheRequestUser(t *testing.T) {
+	store := &fakeStore{users: map[int64]User{
+		42: {ID: 42, Role: "farmer", District: "Nashik"},
+		43: {ID: 43, Role: "farmer", District: "Pune"},
+	}}
+	f := New(store, Config{})
+	f.Define([]Flag{
+		{Name: "feed-ranking", Enabled: true, Rules: []Rule{{Districts: []string{"Nashik"}}}},
+		{Name: "sms-fallback", Enabled: true, Rules: []Rule{{Roles: []string{"farmer"}}}},
+	})
+
+	c := requestAs("42")
+	assert.True(t, f.Enabled(c, "feed-ranking"))
+	assert.True(t, f.Enabled(c, "sms-fallback"))
+	assert.False(t, f.Enabled(c, "new-onboarding"), "undefined flags are off")
+	assert.Equal(t, 1, store.loads, "the user is loaded once per request")
+
+	assert.False(t, f.Enabled(requestAs("43"), "feed-ranking"))
+	assert.False(t, f.Enabled(requestAs(""), "feed-ranking"), "anonymous requests have no district")
+
+	header := requestAs("")
+	header.Request.Header.Set(config.USERID, "42")
+	assert.True(t, f.Enabled(header, "feed-ranking"), "the user id falls back to the header")
+}
+
+func TestFlags_EnabledFor(t *testing.T) {
+	store := &fakeStore{users: map[int64]User{42: {ID: 42, Role: "farmer", District: "Nashik"}}}
+	f := New(store, Config{})
+	f.Define([]Flag{{Name: "sms-fallback", Enabled: true, Rules: []Rule{{Districts: []string{"Nashik"}}}}})
+
+	assert.True(t, f.EnabledFor(context.Background(), "sms-fallback", 42))
+	assert.False(t, f.EnabledFor(context.Background(), "sms-fallback", 7), "users that cannot be loaded match no district")
+}
+
+func TestFlags_EnabledForOr(t *testing.T) {
+	store := &fakeStore{users: map[int64]User{42: {ID: 42, Role: "farmer", District: "Nashik"}}}
+	f := New(store, Config{})
+
+	assert.True(t, f.EnabledForOr(context.Background(), "sms-fallback", 42, true), "an undefined flag reports the default")
+	assert.False(t, f.EnabledForOr(context.Background(), "sms-fallback", 42, false))
+
+	f.Define([]Flag{{Name: "sms-fallback", Enabled: true, Rules: []Rule{{Districts: []string{"Pune"}}}}})
+	assert.False(t, f.EnabledForOr(context.Background(), "sms-fallback", 42, true), "a defined flag is evaluated")
+}
+
+func TestDefault(t *testing.T) {
+	assert.False(t, Enabled(context.Background(), "sms-fallback"), "flags are off until a default is set")
+	assert.True(t, EnabledForOr(context.Background(), "sms-fallback", 42, true))
+
+	f := New(nil, Config{})
+	f.Define([]Flag{{Name: "sms-fallback", Enabled: true}})
+	SetDefault(f)
+	t.Cleanup(func() { SetDefault(nil) })
+
+	assert.True(t, Enabled(context.Background(), "sms-fallback"))
+	assert.True(t, EnabledFor(context.Background(), "sms-fallback", 42))
+}
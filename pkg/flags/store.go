@@ -0,0 +1,92 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type flagStore struct {
+	store *gorm.DB
+}
+
+// Store reads the overrides of kisan.feature_flags and the attributes rules match on
+type Store interface {
+	// Overrides returns the flags of kisan.feature_flags, each replaces the definition of its name
+	Overrides(ctx context.Context) ([]Flag, error)
+	// User returns the role and district of the user, gorm.ErrRecordNotFound when there is none
+	User(ctx context.Context, id int64) (User, error)
+}
+
+func NewStore(db *gorm.DB) Store {
+	return &flagStore{
+		store: db,
+	}
+}
+
+type flagRow struct {
+	Name        string `gorm:"column:name"`
+	Description string `gorm:"column:description"`
+	Enabled     bool   `gorm:"column:enabled"`
+	Rules       string `gorm:"column:rules"`
+}
+
+func (g *flagStore) Overrides(c context.Context) ([]Flag, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var rows []flagRow
+	err := g.store.WithContext(c).Raw(`SELECT name, COALESCE(description, '') AS description, enabled, rules::text AS rules
+		FROM kisan.feature_flags
+		ORDER BY name`).Scan(&rows).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	overrides := make([]Flag, 0, len(rows))
+	for _, row := range rows {
+		flag := Flag{Name: row.Name, Description: row.Description, Enabled: row.Enabled, Source: SourceDatabase}
+		if err := json.Unmarshal([]byte(row.Rules), &flag.Rules); err != nil {
+			// a flag with unreadable rules is off rather than on for everyone
+			logger.Log(c).Error("invalid rules of feature flag, it is off", zap.String("flag", row.Name), zap.Error(err))
+			flag.Enabled, flag.Rules = false, nil
+		}
+		if err := validate(flag.Rules); err != nil {
+			logger.Log(c).Error("invalid rules of feature flag, it is off", zap.String("flag", row.Name), zap.Error(err))
+			flag.Enabled = false
+		}
+		overrides = append(overrides, flag)
+	}
+	return overrides, nil
+}
+
+func (g *flagStore) User(c context.Context, id int64) (User, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var users []User
+	err := g.store.WithContext(c).Raw(`SELECT id, COALESCE(role, '') AS role, COALESCE(district, '') AS district
+		FROM kisan.users
+		WHERE id = ?`, id).Scan(&users).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return User{}, err
+	}
+	if len(users) == 0 {
+		return User{}, gorm.ErrRecordNotFound
+	}
+	return users[0], nil
+}
+
+func validate(rules []Rule) error {
+	for i, rule := range rules {
+		if rule.Percentage != nil && (*rule.Percentage < 0 || *rule.Percentage > 100) {
+			return fmt.Errorf("rule %d: percentage %d is not between 0 and 100", i, *rule.Percentage)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package flags
+
+import (
+	"context"
+	"kisaanSathi/pkg/utils"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestOverrides(t *testing.T) {
+	_, gormDB, mock := utils.NewMockDB()
+	store := NewStore(gormDB)
+	mock.ExpectQuery(`^SELECT name, (.+) FROM kisan.feature_flags`).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "description", "enabled", "rules"}).
+			AddRow("feed-ranking", "ranked feed", true, `[{"roles": ["farmer"], "districts": ["Nashik"], "percentage": 20}]`).
+			AddRow("sms-fallback", "", true, `{"roles": ["farmer"]}`).
+			AddRow("voice-advisory", "", true, `[{"percentage": 120}]`))
+
+	overrides, err := store.Overrides(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Flag{
+		{Name: "feed-ranking", Description: "ranked feed", Enabled: true, Rules: []Rule{{Roles: []string{"farmer"}, Districts: []string{"Nashik"}, Percentage: percent(20)}}, Source: SourceDatabase},
+		{Name: "sms-fallback", Enabled: false, Source: SourceDatabase},
+		{Name: "voice-advisory", Enabled: false, Rules: []Rule{{Percentage: percent(120)}}, Source: SourceDatabase},
+	}, overrides, "flags with invalid rules are off")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUser(t *testing.T) {
+	_, gormDB, mock := utils.NewMockDB()
+	store := NewStore(gormDB)
+	mock.ExpectQuery(`^SELECT id, (.+) FROM kisan.users WHERE id = (.+)`).
+		WithArgs(42).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "role", "district"}).AddRow(42, "farmer", "Nashik"))
+	mock.ExpectQuery(`^SELECT id, (.+) FROM kisan.users WHERE id = (.+)`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "role", "district"}))
+
+	user, err := store.User(context.Background(), 42)
+	assert.NoError(t, err)
+	assert.Equal(t, User{ID: 42, Role: "farmer", District: "Nashik"}, user)
+
+	_, err = store.User(context.Background(), 7)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScheduleNext(t *testing.T) {
+	ist, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skip("no tz database")
+	}
+	from := time.Date(2024, 3, 5, 10, 7, 30, 0, ist) // a tuesday
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 3, 5, 10, 15, 0, 0, ist)},
+		{"0 */6 * * *", time.Date(2024, 3, 5, 12, 0, 0, 0, ist)},
+		{"30 9 * * 1-5", time.Date(2024, 3, 6, 9, 30, 0, 0, ist)},
+		{"0 0 * * 7", time.Date(2024, 3, 10, 0, 0, 0, 0, ist)},
+		{"0 0 1,15 * *", time.Date(2024, 3, 15, 0, 0, 0, 0, ist)},
+		{"0 0 13 * 5", time.Date(2024, 3, 8, 0, 0, 0, 0, ist)},
+		{"@monthly", time.Date(2024, 4, 1, 0, 0, 0, 0, ist)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, ist)},
+	}
+	for _, tc := range cases {
+		schedule, err := ParseSchedule(tc.spec, ist)
+		if assert.NoError(t, err, tc.spec) {
+			assert.True(t, tc.want.Equal(schedule.Next(from)), "%s: got %s", tc.spec, schedule.Next(from))
+		}
+	}
+}
+
+func TestParseScheduleEvery(t *testing.T) {
+	schedule, err := ParseSchedule("@every 30s", time.UTC)
+	assert.NoError(t, err)
+	from := time.Date(2024, 3, 5, 10, 7, 31, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 3, 5, 10, 8, 0, 0, time.UTC), schedule.Next(from))
+}
+
+func TestParseScheduleNeverMatches(t *testing.T) {
+	schedule, err := ParseSchedule("0 0 31 2 *", time.UTC)
+	assert.NoError(t, err)
+	assert.True(t, schedule.Next(time.Now()).IsZero())
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "@every 10ms", "@fortnightly"} {
+		_, err := ParseSchedule(spec, time.UTC)
+		assert.ErrorIs(t, err, ErrInvalidSchedule, spec)
+	}
+}
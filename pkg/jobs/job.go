@@ -0,0 +1,108 @@
+package jobs
+
+import (
+	"context"
+	"time"
+)
+
+// what started a run
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// statuses of a run
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// defaults of a job that leaves them out
+const (
+	DefaultTimeout    = 5 * time.Minute
+	DefaultAttempts   = 1
+	DefaultBackoff    = 10 * time.Second
+	DefaultMaxBackoff = 5 * time.Minute
+)
+
+// Job is a task run on a schedule by one replica at a time
+type Job struct {
+	Name string
+	// Schedule is a cron expression, a descriptor such as @daily, or "@every 30s"
+	Schedule string
+	// Timeout bounds each attempt
+	Timeout time.Duration
+	// Attempts is the number of tries of a run, retries wait Backoff doubling up to MaxBackoff
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Run        func(ctx context.Context) error
+}
+
+func (j *Job) setDefaults() {
+	if j.Timeout <= 0 {
+		j.Timeout = DefaultTimeout
+	}
+	if j.Attempts <= 0 {
+		j.Attempts = DefaultAttempts
+	}
+	if j.Backoff <= 0 {
+		j.Backoff = DefaultBackoff
+	}
+	if j.MaxBackoff <= 0 {
+		j.MaxBackoff = DefaultMaxBackoff
+	}
+}
+
+// budget is the longest a run may take over all its attempts
+func (j *Job) budget() time.Duration {
+	total := time.Duration(j.Attempts) * j.Timeout
+	for attempt := 1; attempt < j.Attempts; attempt++ {
+		total += j.backoff(attempt)
+	}
+	return total
+}
+
+// backoff is the wait after the failed attempt, the first attempt is 1
+func (j *Job) backoff(attempt int) time.Duration {
+	wait := j.Backoff
+	for i := 1; i < attempt && wait < j.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > j.MaxBackoff {
+		wait = j.MaxBackoff
+	}
+	return wait
+}
+
+// Run is a row of the run history
+type Run struct {
+	ID          int64      `json:"id" gorm:"column:id"`
+	Job         string     `json:"job" gorm:"column:job"`
+	TriggeredBy string     `json:"triggeredBy" gorm:"column:triggered_by"`
+	Instance    string     `json:"instance" gorm:"column:instance"`
+	Status      string     `json:"status" gorm:"column:status"`
+	Attempts    int        `json:"attempts" gorm:"column:attempts"`
+	ScheduledAt *time.Time `json:"scheduledAt,omitempty" gorm:"column:scheduled_at"`
+	StartedAt   time.Time  `json:"startedAt" gorm:"column:started_at"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty" gorm:"column:finished_at"`
+	Error       string     `json:"error,omitempty" gorm:"column:error"`
+}
+
+// Status is a registered job with its next run and the latest run recorded by any replica
+type Status struct {
+	Name     string     `json:"name"`
+	Schedule string     `json:"schedule"`
+	Timeout  string     `json:"timeout"`
+	Attempts int        `json:"attempts"`
+	NextRun  *time.Time `json:"nextRun,omitempty"`
+	// Running is set while this instance runs the job
+	Running bool `json:"running"`
+	LastRun *Run `json:"lastRun,omitempty"`
+}
+
+// RunsRequest pages back through the history of a job
+type RunsRequest struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
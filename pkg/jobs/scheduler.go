@@ -0,0 +1,351 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrJobNotFound  = errors.New("job not found")
+	ErrJobRunning   = errors.New("job is already running")
+	ErrDuplicateJob = errors.New("job is already registered")
+)
+
+// minSlotLock keeps the lock of a scheduled run long enough to cover clock skew between replicas
+const minSlotLock = time.Minute
+
+type entry struct {
+	job      Job
+	schedule Schedule
+}
+
+// Scheduler runs the registered jobs on their schedules. Every replica runs a scheduler; for each
+// scheduled time the replica that takes its lock in redis runs the job, and a second lock keeps
+// runs of the same job from overlapping across replicas. Runs are recorded in the store.
+type Scheduler struct {
+	entries  map[string]*entry
+	store    Store
+	locks    repo.RedisInterface
+	instance string
+	loc      *time.Location
+	now      func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[string]bool
+	started bool
+}
+
+// NewScheduler records runs in store and takes its locks in locks; either may be nil to keep no
+// history or to run every job on every replica. Cron expressions are evaluated in loc.
+func NewScheduler(store Store, locks repo.RedisInterface, loc *time.Location) *Scheduler {
+	if loc == nil {
+		loc = time.Local
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		entries:  make(map[string]*entry),
+		store:    store,
+		locks:    locks,
+		instance: instanceName(),
+		loc:      loc,
+		now:      time.Now,
+		ctx:      ctx,
+		cancel:   cancel,
+		running:  make(map[string]bool),
+	}
+}
+
+func instanceName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// Register adds a job, jobs registered after Start are not scheduled
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("a job needs a name and a run function")
+	}
+	schedule, err := ParseSchedule(job.Schedule, s.loc)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	job.setDefaults()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[job.Name]; ok {
+		return fmt.Errorf("%w [%s]", ErrDuplicateJob, job.Name)
+	}
+	s.entries[job.Name] = &entry{job: job, schedule: schedule}
+	return nil
+}
+
+// Start schedules every registered job until Stop
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go s.loop(e)
+	}
+	logger.Log().Info("job scheduler started", zap.Int("jobs", len(s.entries)), zap.String("instance", s.instance))
+}
+
+// Stop cancels the running jobs and waits for them to record their outcome
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// loop waits for each scheduled time of the job and runs it when this replica wins the time's
+// lock. Times missed while a run takes longer than the interval are skipped.
+func (s *Scheduler) loop(e *entry) {
+	defer s.wg.Done()
+	next := e.schedule.Next(s.now())
+	for !next.IsZero() {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.runScheduled(e, next)
+
+		now := s.now()
+		if next.Before(now) {
+			next = now
+		}
+		next = e.schedule.Next(next)
+	}
+}
+
+func (s *Scheduler) runScheduled(e *entry, at time.Time) {
+	ctx := s.ctx
+	if s.locks != nil {
+		ttl := e.job.budget()
+		if ttl < minSlotLock {
+			ttl = minSlotLock
+		}
+		key := fmt.Sprintf("jobs:%s:slot:%d", e.job.Name, at.Unix())
+		won, err := s.locks.TryLock(ctx, key, s.instance, ttl)
+		if err != nil {
+			logger.Log(ctx).Error("job lock failed, skipping the run", zap.String("job", e.job.Name), zap.Error(err))
+			return
+		}
+		if !won {
+			logger.Log(ctx).Debug("job run taken by another replica", zap.String("job", e.job.Name), zap.Time("at", at))
+			return
+		}
+	}
+	scheduledAt := at.UTC()
+	run, release, err := s.begin(ctx, e, TriggerSchedule, &scheduledAt)
+	if err != nil {
+		logger.Log(ctx).Warn("job skipped", zap.String("job", e.job.Name), zap.Error(err))
+		return
+	}
+	s.perform(ctx, e, run, release)
+}
+
+// Trigger starts a run of the job now, in the background. The returned run is still running.
+// The run releases its locks with ctx after Trigger returns, so ctx must stay valid after the
+// caller is done: a gin handler passes c.Copy(), as gin reuses its context for the next request.
+func (s *Scheduler) Trigger(ctx context.Context, name string) (*Run, error) {
+	s.mu.Lock()
+	e, ok := s.entries[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w [%s]", ErrJobNotFound, name)
+	}
+	// the caller going away, as a request does once it is answered, must not cut the release short
+	run, release, err := s.begin(context.WithoutCancel(ctx), e, TriggerManual, nil)
+	if err != nil {
+		return nil, err
+	}
+	started := *run
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.perform(s.ctx, e, run, release)
+	}()
+	return &started, nil
+}
+
+// begin takes the job's running locks and records the start of the run, release gives the locks back
+func (s *Scheduler) begin(ctx context.Context, e *entry, trigger string, scheduledAt *time.Time) (*Run, func(), error) {
+	name := e.job.Name
+	s.mu.Lock()
+	if s.running[name] {
+		s.mu.Unlock()
+		return nil, nil, fmt.Errorf("%w [%s]", ErrJobRunning, name)
+	}
+	s.running[name] = true
+	s.mu.Unlock()
+
+	release := func() {
+		s.mu.Lock()
+		delete(s.running, name)
+		s.mu.Unlock()
+	}
+	run := &Run{
+		Job:         name,
+		TriggeredBy: trigger,
+		Instance:    s.instance,
+		Status:      StatusRunning,
+		ScheduledAt: scheduledAt,
+		StartedAt:   s.now().UTC(),
+	}
+
+	if s.locks != nil {
+		key := "jobs:" + name + ":running"
+		token := fmt.Sprintf("%s:%d", s.instance, run.StartedAt.UnixNano())
+		held, err := s.locks.TryLock(ctx, key, token, e.job.budget()+minSlotLock)
+		if err != nil || !held {
+			release()
+			if err != nil {
+				return nil, nil, err
+			}
+			return nil, nil, fmt.Errorf("%w [%s] on another replica", ErrJobRunning, name)
+		}
+		local := release
+		release = func() {
+			if err := s.locks.Unlock(context.WithoutCancel(ctx), key, token); err != nil {
+				logger.Log(ctx).Warn("job lock was not released, it expires on its own", zap.String("job", name), zap.Error(err))
+			}
+			local()
+		}
+	}
+
+	if s.store != nil {
+		if err := s.store.StartRun(ctx, run); err != nil {
+			logger.Log(ctx).Error("job run is not recorded", zap.String("job", name), zap.Error(err))
+		}
+	}
+	return run, release, nil
+}
+
+// perform runs the attempts of the run and records how it ended
+func (s *Scheduler) perform(ctx context.Context, e *entry, run *Run, release func()) {
+	defer release()
+	job := e.job
+	logger.Log(ctx).Info("job started", zap.String("job", job.Name), zap.String("trigger", run.TriggeredBy), zap.Int64("runId", run.ID))
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		run.Attempts = attempt
+		err = attemptRun(ctx, job)
+		if err == nil || attempt >= job.Attempts || ctx.Err() != nil {
+			break
+		}
+		wait := job.backoff(attempt)
+		logger.Log(ctx).Warn("job attempt failed, retrying", zap.String("job", job.Name), zap.Int("attempt", attempt), zap.Duration("backoff", wait), zap.Error(err))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	finishedAt := s.now().UTC()
+	run.FinishedAt = &finishedAt
+	run.Status = StatusSucceeded
+	if err != nil {
+		run.Status = StatusFailed
+		run.Error = err.Error()
+		logger.Log(ctx).Error("job failed", zap.String("job", job.Name), zap.Int("attempts", run.Attempts), zap.Error(err))
+	} else {
+		logger.Log(ctx).Info("job finished", zap.String("job", job.Name), zap.Int("attempts", run.Attempts), zap.Duration("took", finishedAt.Sub(run.StartedAt)))
+	}
+	if s.store != nil && run.ID != 0 {
+		// the outcome is recorded even when the run was cut short by shutdown
+		if err := s.store.FinishRun(context.WithoutCancel(ctx), run); err != nil {
+			logger.Log(ctx).Error("job outcome is not recorded", zap.String("job", job.Name), zap.Error(err))
+		}
+	}
+}
+
+// attemptRun runs the job once within its timeout, a panic fails the attempt
+func attemptRun(ctx context.Context, job Job) (err error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, job.Timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return job.Run(attemptCtx)
+}
+
+// Jobs lists the registered jobs by name with their next run and the latest recorded run
+func (s *Scheduler) Jobs(ctx context.Context) ([]Status, error) {
+	var last map[string]Run
+	if s.store != nil {
+		var err error
+		if last, err = s.store.LastRuns(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	statuses := make([]Status, 0, len(s.entries))
+	for name, e := range s.entries {
+		status := Status{
+			Name:     name,
+			Schedule: e.job.Schedule,
+			Timeout:  e.job.Timeout.String(),
+			Attempts: e.job.Attempts,
+			Running:  s.running[name],
+		}
+		if next := e.schedule.Next(now); !next.IsZero() {
+			status.NextRun = &next
+		}
+		if run, ok := last[name]; ok {
+			status.LastRun = &run
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses, nil
+}
+
+// Runs returns the recorded runs of the job, latest first
+func (s *Scheduler) Runs(ctx context.Context, name string, limit int) ([]Run, error) {
+	s.mu.Lock()
+	_, ok := s.entries[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w [%s]", ErrJobNotFound, name)
+	}
+	if s.store == nil {
+		return []Run{}, nil
+	}
+	return s.store.ListRuns(ctx, name, limit)
+}
@@ -0,0 +1,205 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStore struct {
+	mu       sync.Mutex
+	started  []Run
+	finished []Run
+}
+
+func (f *fakeStore) StartRun(ctx context.Context, run *Run) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	run.ID = int64(len(f.started) + 1)
+	f.started = append(f.started, *run)
+	return nil
+}
+
+func (f *fakeStore) FinishRun(ctx context.Context, run *Run) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.finished = append(f.finished, *run)
+	return nil
+}
+
+func (f *fakeStore) LastRuns(ctx context.Context) (map[string]Run, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	last := make(map[string]Run)
+	for _, run := range f.finished {
+		last[run.Job] = run
+	}
+	return last, nil
+}
+
+func (f *fakeStore) ListRuns(ctx context.Context, job string, limit int) ([]Run, error) {
+	return nil, nil
+}
+
+func (f *fakeStore) runs() []Run {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Run(nil), f.finished...)
+}
+
+func TestMain(m *testing.M) {
+	logger.LoggerInit("", -1)
+	os.Exit(m.Run())
+}
+
+func newTestScheduler(store Store, locks repo.RedisInterface) *Scheduler {
+	s := NewScheduler(store, locks, time.UTC)
+	s.instance = "test"
+	return s
+}
+
+func TestPerformRetriesWithBackoff(t *testing.T) {
+	store := &fakeStore{}
+	s := newTestScheduler(store, repo.NewMemoryCache())
+	var calls int32
+	err := s.Register(Job{Name: "flaky", Schedule: "@hourly", Attempts: 3, Backoff: time.Millisecond, Run: func(ctx context.Context) error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	}})
+	assert.NoError(t, err)
+
+	s.runScheduled(s.entries["flaky"], time.Now())
+
+	runs := store.runs()
+	if assert.Len(t, runs, 1) {
+		assert.Equal(t, StatusSucceeded, runs[0].Status)
+		assert.Equal(t, 3, runs[0].Attempts)
+		assert.Equal(t, TriggerSchedule, runs[0].TriggeredBy)
+		assert.NotNil(t, runs[0].ScheduledAt)
+	}
+}
+
+func TestPerformTimeoutFailsTheRun(t *testing.T) {
+	store := &fakeStore{}
+	s := newTestScheduler(store, nil)
+	assert.NoError(t, s.Register(Job{Name: "slow", Schedule: "@hourly", Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}))
+
+	s.runScheduled(s.entries["slow"], time.Now())
+
+	runs := store.runs()
+	if assert.Len(t, runs, 1) {
+		assert.Equal(t, StatusFailed, runs[0].Status)
+		assert.Contains(t, runs[0].Error, context.DeadlineExceeded.Error())
+	}
+}
+
+func TestBackoffDoublesUpToMax(t *testing.T) {
+	job := Job{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, job.backoff(1))
+	assert.Equal(t, 2*time.Second, job.backoff(2))
+	assert.Equal(t, 4*time.Second, job.backoff(3))
+	assert.Equal(t, 5*time.Second, job.backoff(4))
+}
+
+func TestScheduledRunIsTakenByOneReplica(t *testing.T) {
+	locks := repo.NewMemoryCache()
+	store := &fakeStore{}
+	var calls int32
+	job := Job{Name: "once", Schedule: "@hourly", Run: func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}}
+	first, second := newTestScheduler(store, locks), newTestScheduler(store, locks)
+	second.instance = "other"
+	assert.NoError(t, first.Register(job))
+	assert.NoError(t, second.Register(job))
+
+	at := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	first.runScheduled(first.entries["once"], at)
+	second.runScheduled(second.entries["once"], at)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Len(t, store.runs(), 1)
+}
+
+func TestTrigger(t *testing.T) {
+	locks := repo.NewMemoryCache()
+	store := &fakeStore{}
+	s := newTestScheduler(store, locks)
+	release := make(chan struct{})
+	assert.NoError(t, s.Register(Job{Name: "manual", Schedule: "@daily", Run: func(ctx context.Context) error {
+		<-release
+		return nil
+	}}))
+
+	_, err := s.Trigger(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+
+	run, err := s.Trigger(context.Background(), "manual")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusRunning, run.Status)
+	assert.Equal(t, TriggerManual, run.TriggeredBy)
+
+	_, err = s.Trigger(context.Background(), "manual")
+	assert.ErrorIs(t, err, ErrJobRunning)
+
+	// another replica sees the running lock
+	other := newTestScheduler(store, locks)
+	other.instance = "other"
+	assert.NoError(t, other.Register(Job{Name: "manual", Schedule: "@daily", Run: func(ctx context.Context) error { return nil }}))
+	_, err = other.Trigger(context.Background(), "manual")
+	assert.ErrorIs(t, err, ErrJobRunning)
+
+	statuses, err := s.Jobs(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, statuses, 1) {
+		assert.True(t, statuses[0].Running)
+		assert.NotNil(t, statuses[0].NextRun)
+	}
+
+	close(release)
+	s.Stop()
+	statuses, err = s.Jobs(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, statuses[0].Running)
+	if assert.NotNil(t, statuses[0].LastRun) {
+		assert.Equal(t, StatusSucceeded, statuses[0].LastRun.Status)
+	}
+}
+
+func TestRegisterRejectsDuplicatesAndBadSchedules(t *testing.T) {
+	s := newTestScheduler(nil, nil)
+	run := func(ctx context.Context) error { return nil }
+	assert.NoError(t, s.Register(Job{Name: "a", Schedule: "@hourly", Run: run}))
+	assert.ErrorIs(t, s.Register(Job{Name: "a", Schedule: "@hourly", Run: run}), ErrDuplicateJob)
+	assert.ErrorIs(t, s.Register(Job{Name: "b", Schedule: "every hour", Run: run}), ErrInvalidSchedule)
+}
+
+func TestStartAndStop(t *testing.T) {
+	s := newTestScheduler(&fakeStore{}, repo.NewMemoryCache())
+	ran := make(chan struct{}, 10)
+	assert.NoError(t, s.Register(Job{Name: "tick", Schedule: "@every 1s", Run: func(ctx context.Context) error {
+		ran <- struct{}{}
+		return nil
+	}}))
+	s.Start()
+	select {
+	case <-ran:
+	case <-time.After(3 * time.Second):
+		t.Fatal("job did not run")
+	}
+	s.Stop()
+}
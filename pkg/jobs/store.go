@@ -0,0 +1,111 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DefaultRunsPageSize is the number of runs listed when the request does not say
+const DefaultRunsPageSize = 20
+
+// ErrRunNotRecorded is returned when the run row could not be written
+var ErrRunNotRecorded = errors.New("run was not recorded")
+
+type jobStore struct {
+	store *gorm.DB
+}
+
+// Store keeps the run history in kisan.job_runs
+type Store interface {
+	// StartRun inserts the run and sets its id
+	StartRun(ctx context.Context, run *Run) error
+	FinishRun(ctx context.Context, run *Run) error
+	// LastRuns returns the latest run of each job that has one
+	LastRuns(ctx context.Context) (map[string]Run, error)
+	// ListRuns returns the runs of the job, latest first
+	ListRuns(ctx context.Context, job string, limit int) ([]Run, error)
+}
+
+func NewStore(db *gorm.DB) Store {
+	return &jobStore{
+		store: db,
+	}
+}
+
+func (g *jobStore) StartRun(c context.Context, run *Run) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var ids []int64
+	err := g.store.WithContext(c).Raw(`INSERT INTO kisan.job_runs (job, triggered_by, instance, status, attempts, scheduled_at, started_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`, run.Job, run.TriggeredBy, run.Instance, run.Status, run.Attempts, run.ScheduledAt, run.StartedAt).Scan(&ids).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return err
+	}
+	if len(ids) == 0 {
+		return ErrRunNotRecorded
+	}
+	run.ID = ids[0]
+	return nil
+}
+
+func (g *jobStore) FinishRun(c context.Context, run *Run) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	err := g.store.WithContext(c).Exec(`UPDATE kisan.job_runs
+		SET status = ?, attempts = ?, finished_at = ?, error = NULLIF(?, '')
+		WHERE id = ?`, run.Status, run.Attempts, run.FinishedAt, run.Error, run.ID).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (g *jobStore) LastRuns(c context.Context) (map[string]Run, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var runs []Run
+	err := g.store.WithContext(c).Raw(`SELECT DISTINCT ON (job) id, job, triggered_by, instance, status, attempts,
+			scheduled_at, started_at, finished_at, COALESCE(error, '') AS error
+		FROM kisan.job_runs
+		ORDER BY job, started_at DESC, id DESC`).Scan(&runs).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	last := make(map[string]Run, len(runs))
+	for _, run := range runs {
+		last[run.Job] = run
+	}
+	return last, nil
+}
+
+func (g *jobStore) ListRuns(c context.Context, job string, limit int) ([]Run, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	if limit <= 0 {
+		limit = DefaultRunsPageSize
+	}
+	var runs []Run
+	err := g.store.WithContext(c).Raw(`SELECT id, job, triggered_by, instance, status, attempts,
+			scheduled_at, started_at, finished_at, COALESCE(error, '') AS error
+		FROM kisan.job_runs
+		WHERE job = ?
+		ORDER BY started_at DESC, id DESC
+		LIMIT ?`, job, limit).Scan(&runs).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return runs, nil
+}
@@ -29,6 +29,14 @@ func Log(data ...context.Context) *zap.Logger {
 	return fallbackLogger
 }
 
+// Replace swaps the logger returned by Log, tests use it to observe what gets logged.
+// The returned func puts the previous logger back.
+func Replace(l *zap.Logger) func() {
+	previous := logObject
+	logObject = l
+	return func() { logObject = previous }
+}
+
 func LoggerInit(logFilePath string, level zapcore.Level) {
 	var (
 		err error
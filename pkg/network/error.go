@@ -25,6 +25,8 @@ type apiError struct {
 	SetCacheError       *Error
 	PostgresDBConnError *Error
 	RedisConnError      *Error
+	Forbidden           *Error
+	Conflict            *Error
 	// Add more errors as needed
 }
 
@@ -47,6 +49,8 @@ func newApiErrorsRegistry() *apiError {
 		DelDBError:          &Error{Code: 1010, Type: "DelDBError", ShortError: "Failed to delete data from table"},
 		GetCacheError:       &Error{Code: 1011, Type: "GetCacheError", ShortError: "Failed to get data from cache"},
 		SetCacheError:       &Error{Code: 1012, Type: "SetCacheError", ShortError: "Failed to set data into cache"},
+		Forbidden:           &Error{Code: 1013, Type: "Forbidden", ShortError: "Not allowed for this user"},
+		Conflict:            &Error{Code: 1014, Type: "Conflict", ShortError: "Conflicts with the current state"},
 	}
 }
 
@@ -0,0 +1,295 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrDuplicateKind is returned when a second handler is registered for a kind
+var ErrDuplicateKind = errors.New("task kind is already registered")
+
+// defaults of a Config that leaves them out
+const (
+	DefaultWorkers      = 4
+	DefaultPollInterval = time.Second
+	DefaultLease        = 5 * time.Minute
+	DefaultBackoff      = 10 * time.Second
+	DefaultMaxBackoff   = time.Hour
+)
+
+// Config of the workers
+type Config struct {
+	// Workers is the number of tasks run at a time by this instance
+	Workers int
+	// PollInterval is the wait before looking for due tasks again when none were found
+	PollInterval time.Duration
+	// Lease bounds a run of a task, another instance takes over a task whose lease runs out
+	Lease time.Duration
+	// Backoff is the wait before the second attempt, doubling for each further one up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+func (c *Config) setDefaults() {
+	if c.Workers <= 0 {
+		c.Workers = DefaultWorkers
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = DefaultPollInterval
+	}
+	if c.Lease <= 0 {
+		c.Lease = DefaultLease
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = DefaultBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultMaxBackoff
+	}
+}
+
+type handler func(ctx context.Context, payload json.RawMessage) error
+
+// Queue runs the tasks of the registered kinds. Every replica runs one; a task is claimed by a
+// single worker with FOR UPDATE SKIP LOCKED and leased to it until it reports the outcome.
+type Queue struct {
+	store    Store
+	config   Config
+	worker   string
+	handlers map[string]handler
+	now      func() time.Time
+
+	// ctx is given to the handlers, it is cancelled when Drain runs out of time
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	started  bool
+	stop     chan struct{}
+	stopOnce sync.Once
+	polling  sync.WaitGroup
+	inflight sync.WaitGroup
+	slots    chan struct{}
+}
+
+// New creates a queue taking its tasks from store
+func New(store Store, config Config) *Queue {
+	config.setDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Queue{
+		store:    store,
+		config:   config,
+		worker:   workerName(),
+		handlers: make(map[string]handler),
+		now:      time.Now,
+		ctx:      ctx,
+		cancel:   cancel,
+		stop:     make(chan struct{}),
+		slots:    make(chan struct{}, config.Workers),
+	}
+}
+
+func workerName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// Register sets the handler of a kind. A payload that cannot be decoded into T dead-letters the
+// task; so does an error wrapped by Permanent. Kinds registered after Start are not claimed.
+func Register[T any](q *Queue, kind Kind[T], handle func(ctx context.Context, payload T) error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.handlers[string(kind)]; ok {
+		return fmt.Errorf("%w [%s]", ErrDuplicateKind, kind)
+	}
+	q.handlers[string(kind)] = func(ctx context.Context, body json.RawMessage) error {
+		var payload T
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return Permanent(fmt.Errorf("invalid payload: %w", err))
+		}
+		return handle(ctx, payload)
+	}
+	return nil
+}
+
+// Start claims and runs tasks until Drain, it does nothing when no kind is registered
+func (q *Queue) Start() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.started || len(q.handlers) == 0 {
+		return
+	}
+	q.started = true
+	kinds := make([]string, 0, len(q.handlers))
+	for kind := range q.handlers {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	q.polling.Add(1)
+	go q.poll(kinds)
+	logger.Log().Info("task queue started", zap.Strings("kinds", kinds), zap.Int("workers", q.config.Workers), zap.String("worker", q.worker))
+}
+
+// Drain stops claiming tasks and waits for the running ones. Tasks still running when ctx is done
+// are cancelled and returned to the queue without counting the attempt.
+func (q *Queue) Drain(ctx context.Context) error {
+	q.stopOnce.Do(func() { close(q.stop) })
+	q.polling.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		q.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// poll claims as many due tasks as there are free workers, waiting PollInterval when it finds fewer
+func (q *Queue) poll(kinds []string) {
+	defer q.polling.Done()
+	for {
+		// wait for a free worker, then take every other free one
+		select {
+		case <-q.stop:
+			return
+		case q.slots <- struct{}{}:
+		}
+		free := 1
+	take:
+		for free < q.config.Workers {
+			select {
+			case q.slots <- struct{}{}:
+				free++
+			default:
+				break take
+			}
+		}
+
+		tasks, err := q.store.Claim(q.ctx, kinds, q.worker, free, q.config.Lease)
+		if err != nil {
+			logger.Log().Error("claiming tasks failed", zap.Error(err))
+			tasks = nil
+		}
+		for i := len(tasks); i < free; i++ {
+			<-q.slots
+		}
+		for _, task := range tasks {
+			q.inflight.Add(1)
+			go q.process(task)
+		}
+		if len(tasks) == free {
+			continue
+		}
+
+		timer := time.NewTimer(q.config.PollInterval)
+		select {
+		case <-q.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// process runs a claimed task and records its outcome
+func (q *Queue) process(task Task) {
+	defer q.inflight.Done()
+	defer func() { <-q.slots }()
+	// the outcome is recorded even when the handlers were cancelled by Drain
+	ctx := context.WithoutCancel(q.ctx)
+	fields := []zap.Field{zap.Int64("taskId", task.ID), zap.String("kind", task.Kind), zap.Int("attempt", task.Attempts)}
+
+	if task.Attempts > task.MaxAttempts {
+		// every lease ran out without an outcome, the worker died running it each time
+		q.bury(ctx, task, errors.New("task lease expired on its last attempt"))
+		return
+	}
+	q.mu.Lock()
+	handle := q.handlers[task.Kind]
+	q.mu.Unlock()
+
+	err := q.run(handle, task)
+	switch {
+	case err == nil:
+		if err := q.store.Complete(ctx, task); err != nil {
+			outcomeFailed("task outcome is not recorded, it runs again after its lease", fields, err)
+		}
+	case q.ctx.Err() != nil:
+		logger.Log().Warn("task cut short by shutdown, returning it to the queue", append(fields, zap.Error(err))...)
+		if err := q.store.Release(ctx, task); err != nil {
+			outcomeFailed("task is not released, it runs again after its lease", fields, err)
+		}
+	case IsPermanent(err) || task.Attempts >= task.MaxAttempts:
+		q.bury(ctx, task, err)
+	default:
+		runAt := q.now().Add(q.backoff(task.Attempts))
+		logger.Log().Warn("task failed, retrying", append(fields, zap.Time("runAt", runAt), zap.Error(err))...)
+		if err := q.store.Retry(ctx, task, runAt, err.Error()); err != nil {
+			outcomeFailed("task retry is not recorded, it runs again after its lease", fields, err)
+		}
+	}
+}
+
+// run calls the handler within the lease, a panic fails the attempt
+func (q *Queue) run(handle handler, task Task) (err error) {
+	ctx, cancel := context.WithTimeout(q.ctx, q.config.Lease)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task panicked: %v", r)
+		}
+	}()
+	if handle == nil {
+		return Permanent(fmt.Errorf("no handler for task kind %s", task.Kind))
+	}
+	return handle(ctx, task.Payload)
+}
+
+func (q *Queue) bury(ctx context.Context, task Task, err error) {
+	logger.Log().Error("task dead-lettered", zap.Int64("taskId", task.ID), zap.String("kind", task.Kind), zap.Int("attempts", task.Attempts), zap.Error(err))
+	if err := q.store.Bury(ctx, task, err.Error()); err != nil {
+		outcomeFailed("dead letter is not recorded, the task runs again after its lease", []zap.Field{zap.Int64("taskId", task.ID)}, err)
+	}
+}
+
+// outcomeFailed logs an outcome the store did not record. A lost lease is expected after a run
+// outlasted it: the task was claimed again and the new claim records the outcome.
+func outcomeFailed(msg string, fields []zap.Field, err error) {
+	if errors.Is(err, ErrLeaseLost) {
+		logger.Log().Warn("task outcome dropped, another worker holds the task now", append(fields, zap.Error(err))...)
+		return
+	}
+	logger.Log().Error(msg, append(fields, zap.Error(err))...)
+}
+
+// backoff is the wait after the failed attempt, the first attempt is 1
+func (q *Queue) backoff(attempt int) time.Duration {
+	wait := q.config.Backoff
+	for i := 1; i < attempt && wait < q.config.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > q.config.MaxBackoff {
+		wait = q.config.MaxBackoff
+	}
+	return wait
+}
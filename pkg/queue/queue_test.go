@@ -0,0 +1,300 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStore keeps the tasks in memory and claims them the way kisan.tasks does
+type fakeStore struct {
+	mu    sync.Mutex
+	tasks map[int64]*Task
+	next  int64
+	now   func() time.Time
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{tasks: make(map[int64]*Task), now: time.Now}
+}
+
+func (f *fakeStore) Enqueue(ctx context.Context, task *Task) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.next++
+	task.ID, task.Status, task.CreatedAt = f.next, StatusPending, f.now()
+	copied := *task
+	f.tasks[task.ID] = &copied
+	return nil
+}
+
+func (f *fakeStore) Claim(ctx context.Context, kinds []string, worker string, limit int, lease time.Duration) ([]Task, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var due []*Task
+	for _, task := range f.tasks {
+		if task.Status == StatusPending && !task.RunAt.After(f.now()) && contains(kinds, task.Kind) {
+			due = append(due, task)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].Priority != due[j].Priority {
+			return due[i].Priority > due[j].Priority
+		}
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	claimed := make([]Task, 0, len(due))
+	for _, task := range due {
+		task.Status = StatusRunning
+		task.Attempts++
+		task.LockedBy = worker
+		claimed = append(claimed, *task)
+	}
+	return claimed, nil
+}
+
+// held finds the task while the claim of task holds its lease, as the outcome statements check it
+func (f *fakeStore) held(task Task) (*Task, error) {
+	stored, ok := f.tasks[task.ID]
+	if !ok || stored.Status != StatusRunning || stored.LockedBy != task.LockedBy || stored.Attempts != task.Attempts {
+		return nil, ErrLeaseLost
+	}
+	return stored, nil
+}
+
+func (f *fakeStore) Complete(ctx context.Context, task Task) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.held(task); err != nil {
+		return err
+	}
+	delete(f.tasks, task.ID)
+	return nil
+}
+
+func (f *fakeStore) Retry(ctx context.Context, task Task, runAt time.Time, lastError string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, err := f.held(task)
+	if err != nil {
+		return err
+	}
+	stored.Status, stored.RunAt, stored.LastError, stored.LockedBy = StatusPending, runAt, lastError, ""
+	return nil
+}
+
+func (f *fakeStore) Release(ctx context.Context, task Task) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, err := f.held(task)
+	if err != nil {
+		return err
+	}
+	stored.Status, stored.LockedBy = StatusPending, ""
+	stored.Attempts--
+	return nil
+}
+
+func (f *fakeStore) Bury(ctx context.Context, task Task, lastError string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, err := f.held(task)
+	if err != nil {
+		return err
+	}
+	stored.Status, stored.LastError, stored.LockedBy = StatusDead, lastError, ""
+	return nil
+}
+
+func (f *fakeStore) task(id int64) (Task, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	task, ok := f.tasks[id]
+	if !ok {
+		return Task{}, false
+	}
+	return *task, true
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+type resize struct {
+	Image string `json:"image"`
+	Width int    `json:"width"`
+}
+
+var resizeKind = Kind[resize]("test.resize")
+
+func TestMain(m *testing.M) {
+	logger.LoggerInit("", -1)
+	os.Exit(m.Run())
+}
+
+func enqueue(t *testing.T, store Store, payload string, opts Options) int64 {
+	task := &Task{Kind: string(resizeKind), Payload: []byte(payload), Priority: opts.Priority, MaxAttempts: opts.MaxAttempts, RunAt: time.Now().Add(opts.Delay)}
+	if task.MaxAttempts == 0 {
+		task.MaxAttempts = DefaultMaxAttempts
+	}
+	assert.NoError(t, store.Enqueue(context.Background(), task))
+	return task.ID
+}
+
+// eventually waits for the condition the workers bring about
+func eventually(t *testing.T, condition func() bool) {
+	assert.Eventually(t, condition, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestRunsTasksByPriority(t *testing.T) {
+	store := newFakeStore()
+	low := enqueue(t, store, `{"image":"low"}`, Options{Priority: PriorityLow})
+	high := enqueue(t, store, `{"image":"high"}`, Options{Priority: PriorityHigh})
+	delayed := enqueue(t, store, `{"image":"later"}`, Options{Delay: time.Hour})
+
+	q := New(store, Config{Workers: 1, PollInterval: 5 * time.Millisecond})
+	var mu sync.Mutex
+	var order []string
+	assert.NoError(t, Register(q, resizeKind, func(ctx context.Context, payload resize) error {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, payload.Image)
+		return nil
+	}))
+	q.Start()
+	eventually(t, func() bool {
+		_, lowLeft := store.task(low)
+		_, highLeft := store.task(high)
+		return !lowLeft && !highLeft
+	})
+	assert.NoError(t, q.Drain(context.Background()))
+
+	assert.Equal(t, []string{"high", "low"}, order)
+	task, ok := store.task(delayed)
+	assert.True(t, ok)
+	assert.Equal(t, StatusPending, task.Status)
+}
+
+func TestRetriesThenDeadLetters(t *testing.T) {
+	store := newFakeStore()
+	id := enqueue(t, store, `{"image":"a.jpg"}`, Options{MaxAttempts: 2})
+
+	q := New(store, Config{Workers: 2, PollInterval: 5 * time.Millisecond, Backoff: time.Millisecond})
+	assert.NoError(t, Register(q, resizeKind, func(ctx context.Context, payload resize) error {
+		return errors.New("resizer down")
+	}))
+	q.Start()
+	eventually(t, func() bool {
+		task, _ := store.task(id)
+		return task.Status == StatusDead
+	})
+	assert.NoError(t, q.Drain(context.Background()))
+
+	task, _ := store.task(id)
+	assert.Equal(t, 2, task.Attempts)
+	assert.Equal(t, "resizer down", task.LastError)
+}
+
+func TestPermanentErrorsAndBadPayloadsDeadLetterAtOnce(t *testing.T) {
+	store := newFakeStore()
+	permanentID := enqueue(t, store, `{"image":"gone.jpg"}`, Options{})
+	badID := enqueue(t, store, `{"image":42}`, Options{})
+
+	q := New(store, Config{PollInterval: 5 * time.Millisecond})
+	assert.NoError(t, Register(q, resizeKind, func(ctx context.Context, payload resize) error {
+		return Permanent(errors.New("image was deleted"))
+	}))
+	q.Start()
+	eventually(t, func() bool {
+		permanent, _ := store.task(permanentID)
+		bad, _ := store.task(badID)
+		return permanent.Status == StatusDead && bad.Status == StatusDead
+	})
+	assert.NoError(t, q.Drain(context.Background()))
+
+	permanent, _ := store.task(permanentID)
+	bad, _ := store.task(badID)
+	assert.Equal(t, 1, permanent.Attempts)
+	assert.Equal(t, 1, bad.Attempts)
+	assert.Contains(t, bad.LastError, "invalid payload")
+}
+
+func TestDrainReleasesTasksCutShort(t *testing.T) {
+	store := newFakeStore()
+	id := enqueue(t, store, `{"image":"slow.jpg"}`, Options{})
+
+	q := New(store, Config{PollInterval: 5 * time.Millisecond})
+	started := make(chan struct{})
+	assert.NoError(t, Register(q, resizeKind, func(ctx context.Context, payload resize) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	q.Start()
+	<-started
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, q.Drain(drainCtx), context.DeadlineExceeded)
+
+	task, _ := store.task(id)
+	assert.Equal(t, StatusPending, task.Status)
+	assert.Equal(t, 0, task.Attempts)
+}
+
+func TestOutcomeOfALostLeaseIsDropped(t *testing.T) {
+	store := newFakeStore()
+	id := enqueue(t, store, `{"image":"slow.jpg"}`, Options{})
+
+	q := New(store, Config{PollInterval: 5 * time.Millisecond})
+	started, finish := make(chan struct{}), make(chan struct{})
+	assert.NoError(t, Register(q, resizeKind, func(ctx context.Context, payload resize) error {
+		close(started)
+		<-finish
+		return nil
+	}))
+	q.Start()
+	<-started
+
+	// the run outlasted its lease and another worker claimed the task
+	store.mu.Lock()
+	store.tasks[id].Attempts++
+	store.tasks[id].LockedBy = "other-worker"
+	store.mu.Unlock()
+	close(finish)
+	assert.NoError(t, q.Drain(context.Background()))
+
+	task, ok := store.task(id)
+	assert.True(t, ok, "the late outcome does not delete the task of the new claim")
+	assert.Equal(t, StatusRunning, task.Status)
+	assert.Equal(t, "other-worker", task.LockedBy)
+}
+
+func TestRegisterRejectsDuplicateKinds(t *testing.T) {
+	q := New(newFakeStore(), Config{})
+	handler := func(ctx context.Context, payload resize) error { return nil }
+	assert.NoError(t, Register(q, resizeKind, handler))
+	assert.ErrorIs(t, Register(q, resizeKind, handler), ErrDuplicateKind)
+}
+
+func TestBackoffDoublesUpToMax(t *testing.T) {
+	q := New(nil, Config{Backoff: time.Second, MaxBackoff: 3 * time.Second})
+	assert.Equal(t, time.Second, q.backoff(1))
+	assert.Equal(t, 2*time.Second, q.backoff(2))
+	assert.Equal(t, 3*time.Second, q.backoff(3))
+}
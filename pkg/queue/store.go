@@ -0,0 +1,142 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrTaskNotQueued is returned when the task row could not be written
+var ErrTaskNotQueued = errors.New("task was not queued")
+
+// ErrLeaseLost is returned for the outcome of a task whose lease ran out and was claimed again,
+// the outcome belongs to the worker that holds the task now
+var ErrLeaseLost = errors.New("task lease was lost")
+
+type taskStore struct {
+	store *gorm.DB
+}
+
+// Store keeps the tasks in kisan.tasks
+type Store interface {
+	// Enqueue inserts the task and sets its id and creation time
+	Enqueue(ctx context.Context, task *Task) error
+	// Claim leases up to limit due tasks of the kinds to the worker, highest priority first. Tasks
+	// whose lease ran out without an outcome are due again.
+	Claim(ctx context.Context, kinds []string, worker string, limit int, lease time.Duration) ([]Task, error)
+	// The outcomes below are recorded only while the claim of task, its worker and attempt, still
+	// holds the lease; otherwise they return ErrLeaseLost and leave the task alone.
+
+	// Complete deletes a finished task
+	Complete(ctx context.Context, task Task) error
+	// Retry returns the task to the queue to run at runAt
+	Retry(ctx context.Context, task Task, runAt time.Time, lastError string) error
+	// Release returns the task to the queue without counting the attempt, for tasks cut short by shutdown
+	Release(ctx context.Context, task Task) error
+	// Bury keeps the task as a dead letter
+	Bury(ctx context.Context, task Task, lastError string) error
+}
+
+func NewStore(db *gorm.DB) Store {
+	return &taskStore{
+		store: db,
+	}
+}
+
+func (g *taskStore) Enqueue(c context.Context, task *Task) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var tasks []Task
+	err := g.store.WithContext(c).Raw(`INSERT INTO kisan.tasks (kind, payload, priority, max_attempts, run_at)
+		VALUES (?, ?::jsonb, ?, ?, ?)
+		RETURNING id, status, created_at`, task.Kind, string(task.Payload), task.Priority, task.MaxAttempts, task.RunAt).Scan(&tasks).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return err
+	}
+	if len(tasks) == 0 {
+		return ErrTaskNotQueued
+	}
+	task.ID, task.Status, task.CreatedAt = tasks[0].ID, tasks[0].Status, tasks[0].CreatedAt
+	return nil
+}
+
+func (g *taskStore) Claim(c context.Context, kinds []string, worker string, limit int, lease time.Duration) ([]Task, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var tasks []Task
+	err := g.store.WithContext(c).Raw(`UPDATE kisan.tasks t
+		SET status = 'running', attempts = t.attempts + 1, locked_until = now() + make_interval(secs => ?),
+			locked_by = ?, updated_at = now()
+		WHERE t.id IN (
+			SELECT id FROM kisan.tasks
+			WHERE kind IN ? AND ((status = 'pending' AND run_at <= now()) OR (status = 'running' AND locked_until < now()))
+			ORDER BY priority DESC, run_at, id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING t.id, t.kind, t.payload, t.priority, t.status, t.attempts, t.max_attempts, t.run_at,
+			COALESCE(t.last_error, '') AS last_error, t.created_at, COALESCE(t.locked_by, '') AS locked_by`, lease.Seconds(), worker, kinds, limit).Scan(&tasks).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// leaseHeld limits an outcome to the claim that is still running the task
+const leaseHeld = `locked_by = ? AND status = 'running' AND attempts = ?`
+
+func (g *taskStore) Complete(c context.Context, task Task) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	return g.outcome(c, task, g.store.WithContext(c).Exec(`DELETE FROM kisan.tasks WHERE id = ? AND `+leaseHeld,
+		task.ID, task.LockedBy, task.Attempts))
+}
+
+func (g *taskStore) Retry(c context.Context, task Task, runAt time.Time, lastError string) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	return g.outcome(c, task, g.store.WithContext(c).Exec(`UPDATE kisan.tasks
+		SET status = 'pending', run_at = ?, last_error = NULLIF(?, ''), locked_until = NULL, locked_by = NULL, updated_at = now()
+		WHERE id = ? AND `+leaseHeld, runAt, lastError, task.ID, task.LockedBy, task.Attempts))
+}
+
+func (g *taskStore) Release(c context.Context, task Task) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	return g.outcome(c, task, g.store.WithContext(c).Exec(`UPDATE kisan.tasks
+		SET status = 'pending', attempts = GREATEST(attempts - 1, 0), locked_until = NULL, locked_by = NULL, updated_at = now()
+		WHERE id = ? AND `+leaseHeld, task.ID, task.LockedBy, task.Attempts))
+}
+
+func (g *taskStore) Bury(c context.Context, task Task, lastError string) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	return g.outcome(c, task, g.store.WithContext(c).Exec(`UPDATE kisan.tasks
+		SET status = 'dead', last_error = NULLIF(?, ''), locked_until = NULL, locked_by = NULL, updated_at = now(), finished_at = now()
+		WHERE id = ? AND `+leaseHeld, lastError, task.ID, task.LockedBy, task.Attempts))
+}
+
+// outcome checks the statement recording the outcome of task, no row means the lease was lost
+func (g *taskStore) outcome(c context.Context, task Task, result *gorm.DB) error {
+	if result.Error != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		logger.Log(c).Warn("task lease was lost, the outcome is not recorded", zap.Int64("taskId", task.ID), zap.String("worker", task.LockedBy), zap.Int("attempt", task.Attempts))
+		return ErrLeaseLost
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package queue
+
+import (
+	"context"
+	"kisaanSathi/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimSkipsLockedRows(t *testing.T) {
+	_, gormDB, mock := utils.NewMockDB()
+	store := NewStore(gormDB)
+	mock.ExpectQuery(`^UPDATE kisan.tasks t (.+) FOR UPDATE SKIP LOCKED (.+) RETURNING (.+)$`).
+		WithArgs(300.0, "worker-1", "test.resize", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "payload", "priority", "status", "attempts", "max_attempts", "run_at", "last_error", "created_at", "locked_by"}).
+			AddRow(7, "test.resize", []byte(`{"image":"a.jpg"}`), 10, "running", 1, 5, time.Now(), "", time.Now(), "worker-1"))
+
+	tasks, err := store.Claim(context.Background(), []string{"test.resize"}, "worker-1", 2, 5*time.Minute)
+
+	assert.NoError(t, err)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, int64(7), tasks[0].ID)
+		assert.JSONEq(t, `{"image":"a.jpg"}`, string(tasks[0].Payload))
+		assert.Equal(t, "worker-1", tasks[0].LockedBy)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutcomesCheckTheLease(t *testing.T) {
+	_, gormDB, mock := utils.NewMockDB()
+	store := NewStore(gormDB)
+	task := Task{ID: 7, LockedBy: "worker-1", Attempts: 2}
+	runAt := time.Now()
+
+	mock.ExpectExec(`^DELETE FROM kisan.tasks WHERE id = (.+) AND locked_by = (.+) AND status = 'running' AND attempts = (.+)$`).
+		WithArgs(7, "worker-1", 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, store.Complete(context.Background(), task))
+
+	mock.ExpectExec(`^UPDATE kisan.tasks (.+) WHERE id = (.+) AND locked_by = (.+) AND status = 'running' AND attempts = (.+)$`).
+		WithArgs(runAt, "resizer down", 7, "worker-1", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, store.Retry(context.Background(), task, runAt, "resizer down"), ErrLeaseLost)
+
+	mock.ExpectExec(`^UPDATE kisan.tasks (.+) WHERE id = (.+) AND locked_by = (.+) AND status = 'running' AND attempts = (.+)$`).
+		WithArgs(7, "worker-1", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, store.Release(context.Background(), task), ErrLeaseLost)
+
+	mock.ExpectExec(`^UPDATE kisan.tasks (.+) WHERE id = (.+) AND locked_by = (.+) AND status = 'running' AND attempts = (.+)$`).
+		WithArgs("image was deleted", 7, "worker-1", 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, store.Bury(context.Background(), task, "image was deleted"))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,103 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// statuses of a task, finished tasks are deleted
+const (
+	StatusPending = "pending"
+	StatusRunning = "running"
+	StatusDead    = "dead"
+)
+
+// priorities of a task, higher runs first
+const (
+	PriorityLow    = -10
+	PriorityNormal = 0
+	PriorityHigh   = 10
+)
+
+// DefaultMaxAttempts is the number of tries of a task enqueued without MaxAttempts
+const DefaultMaxAttempts = 5
+
+// Task is a row of kisan.tasks
+type Task struct {
+	ID          int64           `json:"id" gorm:"column:id"`
+	Kind        string          `json:"kind" gorm:"column:kind"`
+	Payload     json.RawMessage `json:"payload" gorm:"column:payload"`
+	Priority    int             `json:"priority" gorm:"column:priority"`
+	Status      string          `json:"status" gorm:"column:status"`
+	Attempts    int             `json:"attempts" gorm:"column:attempts"`
+	MaxAttempts int             `json:"maxAttempts" gorm:"column:max_attempts"`
+	RunAt       time.Time       `json:"runAt" gorm:"column:run_at"`
+	LastError   string          `json:"lastError,omitempty" gorm:"column:last_error"`
+	CreatedAt   time.Time       `json:"createdAt" gorm:"column:created_at"`
+	// LockedBy is the worker holding the lease of a running task
+	LockedBy string `json:"lockedBy,omitempty" gorm:"column:locked_by"`
+}
+
+// Kind names a task whose payload is a T, it ties Enqueue to the handler registered for the kind
+//
+//	var ResizeImage = queue.Kind[ResizeRequest]("image.resize")
+type Kind[T any] string
+
+// Options of an enqueued task, the zero value runs it now at normal priority
+type Options struct {
+	Priority int
+	// Delay holds the task back for this long
+	Delay time.Duration
+	// MaxAttempts is the number of tries before the task is dead-lettered, DefaultMaxAttempts when 0
+	MaxAttempts int
+}
+
+// Enqueue adds a task of the kind with its payload. Pass the transaction of the business write as
+// db so the task is only queued when that write commits.
+func Enqueue[T any](ctx context.Context, db *gorm.DB, kind Kind[T], payload T, opts Options) (int64, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("task %s: %w", kind, err)
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	task := &Task{
+		Kind:        string(kind),
+		Payload:     body,
+		Priority:    opts.Priority,
+		MaxAttempts: opts.MaxAttempts,
+		RunAt:       time.Now().Add(opts.Delay),
+	}
+	if err := NewStore(db).Enqueue(ctx, task); err != nil {
+		return 0, err
+	}
+	return task.ID, nil
+}
+
+// permanent marks an error that retrying cannot fix
+type permanent struct {
+	err error
+}
+
+func (p permanent) Error() string { return p.err.Error() }
+func (p permanent) Unwrap() error { return p.err }
+
+// Permanent wraps an error returned by a handler to dead-letter the task without further attempts
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanent{err: err}
+}
+
+// IsPermanent reports whether the error was wrapped by Permanent
+func IsPermanent(err error) bool {
+	var p permanent
+	return errors.As(err, &p)
+}
@@ -0,0 +1,27 @@
+package repo
+
+import (
+	"strconv"
+	"strings"
+)
+
+var arrayElementEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// TextArray encodes values as a postgres text[] literal. Raw queries take it with ?::text[],
+// gorm would expand the slice itself into a value list.
+func TextArray(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = `"` + arrayElementEscaper.Replace(value) + `"`
+	}
+	return "{" + strings.Join(quoted, ",") + "}"
+}
+
+// IntArray encodes values as a postgres int[] literal, for ?::int[] in raw queries
+func IntArray(values []int) string {
+	items := make([]string, len(values))
+	for i, value := range values {
+		items[i] = strconv.Itoa(value)
+	}
+	return "{" + strings.Join(items, ",") + "}"
+}
@@ -0,0 +1,19 @@
+package repo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextArray(t *testing.T) {
+	assert.Equal(t, "{}", TextArray(nil))
+	assert.Equal(t, `{"price","scheme"}`, TextArray([]string{"price", "scheme"}))
+	assert.Equal(t, `{"a,b","say \"hi\"","c:\\d","NULL",""}`, TextArray([]string{"a,b", `say "hi"`, `c:\d`, "NULL", ""}),
+		"elements are quoted so commas, quotes, backslashes and NULL stay text")
+}
+
+func TestIntArray(t *testing.T) {
+	assert.Equal(t, "{}", IntArray(nil))
+	assert.Equal(t, "{7,3,1,-2}", IntArray([]int{7, 3, 1, -2}))
+}
@@ -2,15 +2,13 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"kisaanSathi/pkg/config"
-	"kisaanSathi/pkg/logger"
 	"strings"
 	"time"
 
 	rdc "github.com/go-redis/cache/v8"
 	rd "github.com/go-redis/redis/v8"
-	"go.uber.org/zap"
 )
 
 type RedisInterface interface {
@@ -52,9 +50,10 @@ type RedisInterface interface {
 	DeleteKey(ctx context.Context, key string) error
 
 	//get the TTL for a key set in redis
+	//	in milliseconds like PTTL: -2 when the key does not exist, -1 when it has no expiry
 	GetTTL(ctx context.Context, key string) int
 
-	//check if a key exists in redis
+	//check if a key of any type exists in redis
 	KeyExists(ctx context.Context, key string) bool
 
 	//add values into redis as a redis hash
@@ -99,57 +98,65 @@ type RedisInterface interface {
 	GetRedisHashValue(ctx context.Context, key string, args ...string) (map[string]string, error)
 
 	DeleteRedisHash(ctx context.Context, key string, fields ...string) error
-}
 
-type redisStruct struct {
-	Url    string
-	Port   int
-	Client *rd.Client
-	Cache  *rdc.Cache
-	//Context context.Context
-}
+	//atomically add incr to the integer in a field of a redis hash and return the new value
+	//	a missing hash or field counts from 0
+	//	HINCRBY key field incr
+	HIncrBy(ctx context.Context, key string, field string, incr int64) (int64, error)
 
-var redisObj *redisStruct = nil
+	//atomically add 1 to a counter and return the new value, used to count attempts in a window
+	//	a missing counter counts from 0 and expires window after that first increment
+	//	INCR key, PEXPIRE key window when the result is 1
+	IncrWindow(ctx context.Context, key string, window time.Duration) (int64, error)
 
-func GetRedisObject(ctx context.Context) (RedisInterface, error) {
-	url := config.GetConfig().GetString("repo.redis.host")
-	port := config.GetConfig().GetInt("repo.redis.port")
-	redisClient := rd.NewClient(&rd.Options{
-		Addr:     fmt.Sprintf("%s:%d", url, port),
-		Password: "", // no password set
-		DB:       0,  // use default DB
-	})
-	//check connection
-	pong, err := redisClient.Ping(ctx).Result()
-	if err != nil {
-		return nil, err
-	}
-	logger.Log(ctx).Info("redis connected: ", zap.String("result", pong))
+	//check that redis answers, used by the readiness probe
+	Ping(ctx context.Context) error
 
-	redisCache := rdc.New(&rdc.Options{
-		Redis: redisClient,
-	})
+	//get and set a value through the in-process tier of repo.redis.local before redis
+	//	entries in the local tier live for repo.redis.local.ttl on each instance and are not
+	//	invalidated by writes of other instances, use it only for data that may be briefly stale
+	//	prefer the typed repo.GetOrLoad to calling these directly
+	GetTiered(ctx context.Context, key string, value interface{}) error
+	SetTiered(ctx context.Context, key string, value interface{}, ttl time.Duration) error
 
-	if redisObj == nil {
-		redisObj = &redisStruct{
-			Url:    url,
-			Port:   port,
-			Client: redisClient,
-			Cache:  redisCache,
-			//Context: ctx,
-		}
-	}
+	//take a lock held by token until it is unlocked or ttl passes
+	//	reports false without error when someone else holds the lock
+	//	SET key token NX PX ttl
+	TryLock(ctx context.Context, key string, token string, ttl time.Duration) (bool, error)
 
-	return redisObj, nil
+	//release a lock, only when it is still held by token
+	Unlock(ctx context.Context, key string, token string) error
 }
 
-func CloseRedis(ctx context.Context) error {
-	logger.Log(ctx).Info("Closing redis connection START")
-	defer logger.Log(ctx).Info("Closing redis connection END")
-	if redisObj != nil {
-		return redisObj.Client.Close()
-	}
-	return nil
+// unlockScript deletes the lock only when it still holds the caller's token
+var unlockScript = rd.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// incrWindowScript starts the expiry of a counter with its first increment only
+var incrWindowScript = rd.NewScript(`local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count`)
+
+// GetTTL results for keys without a remaining time to live, as redis reports them
+const (
+	ttlMissing    = -2
+	ttlPersistent = -1
+)
+
+// IsCacheMiss reports whether a read failed only because the key is not cached
+func IsCacheMiss(err error) bool {
+	return errors.Is(err, rdc.ErrCacheMiss) || errors.Is(err, rd.Nil)
+}
+
+type redisStruct struct {
+	Client rd.UniversalClient
+	Cache  *rdc.Cache
+	// Local is the in-process tier of the cache, nil when repo.redis.local.size is 0
+	Local rdc.LocalCache
 }
 
 func (obj *redisStruct) SetValue(ctx context.Context, key string, value interface{}, timeout int, writeIfNotSet bool) error {
@@ -159,13 +166,15 @@ func (obj *redisStruct) SetValue(ctx context.Context, key string, value interfac
 		Value: value,
 		TTL:   time.Duration(timeout) * time.Millisecond,
 		SetNX: writeIfNotSet,
+		// raw values are shared state such as otps, they are never served stale from the local tier
+		SkipLocalCache: true,
 	}
 	err := obj.Cache.Set(cacheItem)
 	return err
 }
 
 func (obj *redisStruct) GetValue(ctx context.Context, key string, value interface{}) error {
-	return obj.Cache.Get(ctx, key, &value)
+	return obj.Cache.GetSkippingLocalCache(ctx, key, value)
 }
 
 func (obj *redisStruct) DeleteKey(ctx context.Context, key string) error {
@@ -173,15 +182,20 @@ func (obj *redisStruct) DeleteKey(ctx context.Context, key string) error {
 }
 
 func (obj *redisStruct) GetTTL(ctx context.Context, key string) int {
-	dur := obj.Client.TTL(ctx, key)
+	dur := obj.Client.PTTL(ctx, key)
 	if dur.Err() != nil {
 		return 0
 	}
+	// go-redis keeps the -2 and -1 replies as nanoseconds
+	switch dur.Val() {
+	case ttlMissing, ttlPersistent:
+		return int(dur.Val())
+	}
 	return int(dur.Val().Milliseconds())
 }
 
 func (obj *redisStruct) KeyExists(ctx context.Context, key string) bool {
-	return obj.Cache.Exists(ctx, key)
+	return obj.Client.Exists(ctx, key).Val() > 0
 }
 
 func (obj *redisStruct) SetRedisHash(ctx context.Context, key string, kvpairs map[string]string) error {
@@ -239,3 +253,42 @@ func (obj *redisStruct) DeleteRedisHash(ctx context.Context, key string, fields
 	}
 	return nil
 }
+
+func (obj *redisStruct) HIncrBy(ctx context.Context, key string, field string, incr int64) (int64, error) {
+	if strings.TrimSpace(key) == "" {
+		return 0, fmt.Errorf("key cannot be blank")
+	}
+	return obj.Client.HIncrBy(ctx, key, field, incr).Result()
+}
+
+func (obj *redisStruct) IncrWindow(ctx context.Context, key string, window time.Duration) (int64, error) {
+	if strings.TrimSpace(key) == "" {
+		return 0, fmt.Errorf("key cannot be blank")
+	}
+	return incrWindowScript.Run(ctx, obj.Client, []string{key}, window.Milliseconds()).Int64()
+}
+
+func (obj *redisStruct) Ping(ctx context.Context) error {
+	return obj.Client.Ping(ctx).Err()
+}
+
+func (obj *redisStruct) GetTiered(ctx context.Context, key string, value interface{}) error {
+	return obj.Cache.Get(ctx, key, value)
+}
+
+func (obj *redisStruct) SetTiered(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return obj.Cache.Set(&rdc.Item{
+		Ctx:   ctx,
+		Key:   key,
+		Value: value,
+		TTL:   ttl,
+	})
+}
+
+func (obj *redisStruct) TryLock(ctx context.Context, key string, token string, ttl time.Duration) (bool, error) {
+	return obj.Client.SetNX(ctx, key, token, ttl).Result()
+}
+
+func (obj *redisStruct) Unlock(ctx context.Context, key string, token string) error {
+	return unlockScript.Run(ctx, obj.Client, []string{key}, token).Err()
+}
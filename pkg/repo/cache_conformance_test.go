@@ -0,0 +1,276 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	rdc "github.com/go-redis/cache/v8"
+	rd "github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/suite"
+)
+
+// the conformance suite runs against redis too when KISAAN_TEST_REDIS holds its address
+const testRedisEnv = "KISAAN_TEST_REDIS"
+
+type crop struct {
+	Name    string
+	Season  string
+	Prices  []int
+	Organic bool
+}
+
+type cacheSuite struct {
+	suite.Suite
+	newCache func() RedisInterface
+	cache    RedisInterface
+	prefix   string
+	ctx      context.Context
+}
+
+func TestMemoryCacheConformance(t *testing.T) {
+	suite.Run(t, &cacheSuite{newCache: NewMemoryCache})
+}
+
+func TestRedisCacheConformance(t *testing.T) {
+	addr := os.Getenv(testRedisEnv)
+	if addr == "" {
+		t.Skipf("set %s to run against redis", testRedisEnv)
+	}
+	client := rd.NewClient(&rd.Options{Addr: addr})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("redis at %s: %v", addr, err)
+	}
+	defer client.Close()
+	suite.Run(t, &cacheSuite{newCache: func() RedisInterface {
+		return &redisStruct{Client: client, Cache: rdc.New(&rdc.Options{Redis: client})}
+	}})
+}
+
+func (s *cacheSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.cache = s.newCache()
+	s.prefix = fmt.Sprintf("conformance:%d:", time.Now().UnixNano())
+}
+
+func (s *cacheSuite) TearDownTest() {
+	for _, key := range []string{"crop", "otp", "hash", "short", "forever", "skipped", "tiered", "string", "lock"} {
+		_ = s.cache.DeleteKey(s.ctx, s.key(key))
+	}
+}
+
+func (s *cacheSuite) key(name string) string {
+	return s.prefix + name
+}
+
+func (s *cacheSuite) TestSetAndGetValue() {
+	stored := crop{Name: "wheat", Season: "rabi", Prices: []int{2275, 2425}, Organic: true}
+	s.Require().NoError(s.cache.SetValue(s.ctx, s.key("crop"), stored, 60000, false))
+
+	got := crop{}
+	s.Require().NoError(s.cache.GetValue(s.ctx, s.key("crop"), &got))
+	s.Equal(stored, got)
+
+	err := s.cache.GetValue(s.ctx, s.key("missing"), &got)
+	s.True(IsCacheMiss(err), "got %v", err)
+}
+
+func (s *cacheSuite) TestSetNX() {
+	s.Require().NoError(s.cache.SetValue(s.ctx, s.key("otp"), "123456", 60000, true))
+	s.Require().NoError(s.cache.SetValue(s.ctx, s.key("otp"), "654321", 60000, true))
+
+	var otp string
+	s.Require().NoError(s.cache.GetValue(s.ctx, s.key("otp"), &otp))
+	s.Equal("123456", otp, "set if not set keeps the first value")
+
+	s.Require().NoError(s.cache.DeleteKey(s.ctx, s.key("otp")))
+	s.Require().NoError(s.cache.SetValue(s.ctx, s.key("otp"), "654321", 60000, true))
+	s.Require().NoError(s.cache.GetValue(s.ctx, s.key("otp"), &otp))
+	s.Equal("654321", otp)
+}
+
+func (s *cacheSuite) TestTTL() {
+	s.Require().NoError(s.cache.SetValue(s.ctx, s.key("crop"), "maize", 5000, false))
+	ttl := s.cache.GetTTL(s.ctx, s.key("crop"))
+	s.Greater(ttl, 4000)
+	s.LessOrEqual(ttl, 5000)
+
+	s.Equal(-2, s.cache.GetTTL(s.ctx, s.key("missing")))
+
+	s.Require().NoError(s.cache.SetRedisHash(s.ctx, s.key("hash"), map[string]string{"name": "urea"}))
+	s.Equal(-1, s.cache.GetTTL(s.ctx, s.key("hash")), "hashes are kept without expiry")
+
+	s.Require().NoError(s.cache.SetValue(s.ctx, s.key("forever"), "x", 0, false))
+	s.Greater(s.cache.GetTTL(s.ctx, s.key("forever")), int((59 * time.Minute).Milliseconds()), "no timeout is an hour")
+
+	s.Require().NoError(s.cache.SetValue(s.ctx, s.key("skipped"), "x", -1, false))
+	s.False(s.cache.KeyExists(s.ctx, s.key("skipped")), "a negative timeout is not written")
+}
+
+func (s *cacheSuite) TestExpiry() {
+	s.Require().NoError(s.cache.SetValue(s.ctx, s.key("short"), "bajra", 1000, false))
+	s.True(s.cache.KeyExists(s.ctx, s.key("short")))
+
+	time.Sleep(1100 * time.Millisecond)
+	s.False(s.cache.KeyExists(s.ctx, s.key("short")))
+	s.Equal(-2, s.cache.GetTTL(s.ctx, s.key("short")))
+	var value string
+	s.True(IsCacheMiss(s.cache.GetValue(s.ctx, s.key("short"), &value)))
+	s.Require().NoError(s.cache.SetValue(s.ctx, s.key("short"), "jowar", 1000, true), "an expired key can be set if not set")
+	s.Require().NoError(s.cache.GetValue(s.ctx, s.key("short"), &value))
+	s.Equal("jowar", value)
+}
+
+func (s *cacheSuite) TestHashes() {
+	key := s.key("hash")
+	s.Require().NoError(s.cache.SetRedisHash(s.ctx, key, map[string]string{"name": "elephant", "family": "mammal"}))
+	s.Require().NoError(s.cache.SetRedisHash(s.ctx, key, map[string]string{"scname": "Loxodonta"}))
+	s.True(s.cache.KeyExists(s.ctx, key))
+
+	all, err := s.cache.GetRedisHashValue(s.ctx, key)
+	s.Require().NoError(err)
+	s.Equal(map[string]string{"name": "elephant", "family": "mammal", "scname": "Loxodonta"}, all)
+
+	one, err := s.cache.GetRedisHashValue(s.ctx, key, "family")
+	s.Require().NoError(err)
+	s.Equal(map[string]string{"family": "mammal"}, one)
+
+	_, err = s.cache.GetRedisHashValue(s.ctx, key, "habitat")
+	s.ErrorIs(err, rd.Nil)
+
+	none, err := s.cache.GetRedisHashValue(s.ctx, s.key("missing"))
+	s.Require().NoError(err)
+	s.Empty(none)
+
+	s.Require().NoError(s.cache.DeleteRedisHash(s.ctx, key, "family", "scname"))
+	all, err = s.cache.GetRedisHashValue(s.ctx, key)
+	s.Require().NoError(err)
+	s.Equal(map[string]string{"name": "elephant"}, all)
+
+	s.Require().NoError(s.cache.DeleteRedisHash(s.ctx, key, "name"))
+	s.False(s.cache.KeyExists(s.ctx, key), "removing the last field removes the hash")
+	s.Require().NoError(s.cache.DeleteRedisHash(s.ctx, key, "name"))
+}
+
+func (s *cacheSuite) TestHashIncrement() {
+	key := s.key("hash")
+	count, err := s.cache.HIncrBy(s.ctx, key, "17", 1)
+	s.Require().NoError(err)
+	s.Equal(int64(1), count, "a missing hash counts from 0")
+
+	count, err = s.cache.HIncrBy(s.ctx, key, "17", 2)
+	s.Require().NoError(err)
+	s.Equal(int64(3), count)
+	all, err := s.cache.GetRedisHashValue(s.ctx, key)
+	s.Require().NoError(err)
+	s.Equal(map[string]string{"17": "3"}, all)
+
+	s.Require().NoError(s.cache.SetRedisHash(s.ctx, key, map[string]string{"name": "elephant"}))
+	_, err = s.cache.HIncrBy(s.ctx, key, "name", 1)
+	s.Error(err)
+
+	s.Require().NoError(s.cache.SetValue(s.ctx, s.key("string"), "value", 60000, false))
+	_, err = s.cache.HIncrBy(s.ctx, s.key("string"), "17", 1)
+	s.Error(err)
+}
+
+func (s *cacheSuite) TestHashIncrementConcurrent() {
+	key := s.key("hash")
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.cache.HIncrBy(s.ctx, key, "17", 1)
+			s.NoError(err)
+		}()
+	}
+	wg.Wait()
+
+	one, err := s.cache.GetRedisHashValue(s.ctx, key, "17")
+	s.Require().NoError(err)
+	s.Equal("50", one["17"])
+}
+
+func (s *cacheSuite) TestIncrWindow() {
+	key := s.key("attempts")
+	count, err := s.cache.IncrWindow(s.ctx, key, time.Second)
+	s.Require().NoError(err)
+	s.Equal(int64(1), count, "a missing counter counts from 0")
+	count, err = s.cache.IncrWindow(s.ctx, key, time.Minute)
+	s.Require().NoError(err)
+	s.Equal(int64(2), count)
+	s.LessOrEqual(s.cache.GetTTL(s.ctx, key), 1000, "the window starts with the first increment")
+
+	time.Sleep(1100 * time.Millisecond)
+	count, err = s.cache.IncrWindow(s.ctx, key, time.Second)
+	s.Require().NoError(err)
+	s.Equal(int64(1), count, "a new window counts from 0 again")
+
+	s.Require().NoError(s.cache.SetRedisHash(s.ctx, s.key("hash"), map[string]string{"name": "urea"}))
+	_, err = s.cache.IncrWindow(s.ctx, s.key("hash"), time.Second)
+	s.Error(err)
+}
+
+func (s *cacheSuite) TestHashArguments() {
+	s.Error(s.cache.SetRedisHash(s.ctx, s.key("hash"), nil))
+	s.Error(s.cache.SetRedisHash(s.ctx, " ", map[string]string{"a": "b"}))
+	_, err := s.cache.GetRedisHashValue(s.ctx, "")
+	s.Error(err)
+	s.Error(s.cache.DeleteRedisHash(s.ctx, ""))
+	s.Error(s.cache.DeleteRedisHash(s.ctx, s.key("hash")), "deleting needs a field")
+}
+
+func (s *cacheSuite) TestWrongType() {
+	s.Require().NoError(s.cache.SetValue(s.ctx, s.key("string"), "value", 60000, false))
+	s.Error(s.cache.SetRedisHash(s.ctx, s.key("string"), map[string]string{"a": "b"}))
+	_, err := s.cache.GetRedisHashValue(s.ctx, s.key("string"))
+	s.Error(err)
+
+	s.Require().NoError(s.cache.SetRedisHash(s.ctx, s.key("hash"), map[string]string{"a": "b"}))
+	var value string
+	err = s.cache.GetValue(s.ctx, s.key("hash"), &value)
+	s.Error(err)
+	s.False(IsCacheMiss(err))
+}
+
+func (s *cacheSuite) TestTiered() {
+	stored := crop{Name: "cotton", Season: "kharif"}
+	s.Require().NoError(s.cache.SetTiered(s.ctx, s.key("tiered"), stored, time.Minute))
+
+	got := crop{}
+	s.Require().NoError(s.cache.GetTiered(s.ctx, s.key("tiered"), &got))
+	s.Equal(stored, got)
+	s.True(IsCacheMiss(s.cache.GetTiered(s.ctx, s.key("missing"), &got)))
+}
+
+func (s *cacheSuite) TestLock() {
+	key := s.key("lock")
+	locked, err := s.cache.TryLock(s.ctx, key, "replica-a", time.Second)
+	s.Require().NoError(err)
+	s.True(locked)
+
+	locked, err = s.cache.TryLock(s.ctx, key, "replica-b", time.Second)
+	s.Require().NoError(err)
+	s.False(locked, "the lock is held")
+
+	s.Require().NoError(s.cache.Unlock(s.ctx, key, "replica-b"))
+	s.True(s.cache.KeyExists(s.ctx, key), "only the holder unlocks")
+	s.Require().NoError(s.cache.Unlock(s.ctx, key, "replica-a"))
+	s.False(s.cache.KeyExists(s.ctx, key))
+
+	locked, err = s.cache.TryLock(s.ctx, key, "replica-b", 200*time.Millisecond)
+	s.Require().NoError(err)
+	s.True(locked)
+	time.Sleep(300 * time.Millisecond)
+	locked, err = s.cache.TryLock(s.ctx, key, "replica-a", time.Second)
+	s.Require().NoError(err)
+	s.True(locked, "an expired lock is free")
+}
+
+func (s *cacheSuite) TestPing() {
+	s.NoError(s.cache.Ping(s.ctx))
+}
@@ -2,9 +2,12 @@ package repo
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"kisaanSathi/pkg/config"
 	elog "kisaanSathi/pkg/logger"
 	e "kisaanSathi/pkg/network"
+	"strings"
 
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
@@ -13,41 +16,152 @@ import (
 	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
 
-	// "gorm.io/gorm/schema"
-
 	"time"
 )
 
 type dbLogger struct{}
 
-func PostgreSqlConnect() (*gorm.DB, error) {
-	elog.Log().Info("Connecting to PostgreSQL database")
+// defaults of repo.databases.postgres
+const (
+	defaultSSLMode        = "prefer"
+	defaultSearchPath     = "kisan"
+	defaultConnectTimeout = 5 * time.Second
+	defaultMaxOpenConns   = 20
+	defaultMaxIdleConns   = 5
+	defaultConnLifetime   = 30 * time.Minute
+	defaultConnIdleTime   = 5 * time.Minute
+	redactedPassword      = "xxxxx"
+)
+
+// PostgresConfig is config.App().Repo.Databases.Postgres, with the connection methods
+type PostgresConfig config.PostgresConfig
+
+type (
+	PoolConfig    = config.PoolConfig
+	ReplicaConfig = config.ReplicaConfig
+)
+
+// LoadPostgresConfig reads repo.databases.postgres and fills in the defaults of zero values
+func LoadPostgresConfig() PostgresConfig {
+	cfg := PostgresConfig(config.App().Repo.Databases.Postgres)
+	cfg.setDefaults()
+	return cfg
+}
+
+func (p *PostgresConfig) setDefaults() {
+	if p.SSLMode == "" {
+		p.SSLMode = defaultSSLMode
+	}
+	if p.SearchPath == "" {
+		p.SearchPath = defaultSearchPath
+	}
+	if p.ConnectTimeout <= 0 {
+		p.ConnectTimeout = defaultConnectTimeout
+	}
+	if p.Pool.MaxOpen <= 0 {
+		p.Pool.MaxOpen = defaultMaxOpenConns
+	}
+	if p.Pool.MaxIdle <= 0 {
+		p.Pool.MaxIdle = defaultMaxIdleConns
+	}
+	if p.Pool.MaxIdle > p.Pool.MaxOpen {
+		p.Pool.MaxIdle = p.Pool.MaxOpen
+	}
+	if p.Pool.MaxLifetime <= 0 {
+		p.Pool.MaxLifetime = defaultConnLifetime
+	}
+	if p.Pool.MaxIdleTime <= 0 {
+		p.Pool.MaxIdleTime = defaultConnIdleTime
+	}
+}
+
+// DSN is the keyword/value connection string of the server at host:port
+func (p PostgresConfig) DSN(host, port string) string {
+	return p.dsn(host, port, p.Password)
+}
+
+// RedactedDSN is DSN with the password masked, the only form that is logged
+func (p PostgresConfig) RedactedDSN(host, port string) string {
+	password := ""
+	if p.Password != "" {
+		password = redactedPassword
+	}
+	return p.dsn(host, port, password)
+}
 
-	c := config.GetConfig()
+func (p PostgresConfig) dsn(host, port, password string) string {
+	params := [][2]string{
+		{"host", host},
+		{"port", port},
+		{"user", p.User},
+		{"password", password},
+		{"dbname", p.DB},
+		{"sslmode", p.SSLMode},
+		{"search_path", p.SearchPath},
+	}
+	if seconds := int(p.ConnectTimeout / time.Second); seconds > 0 {
+		params = append(params, [2]string{"connect_timeout", fmt.Sprint(seconds)})
+	}
+	parts := make([]string, 0, len(params))
+	for _, param := range params {
+		if param[1] == "" {
+			continue
+		}
+		parts = append(parts, param[0]+"="+dsnValue(param[1]))
+	}
+	return strings.Join(parts, " ")
+}
 
-	dsn := "host=" + c.GetString("repo.databases.postgres.host") +
-		" user=" + c.GetString("repo.databases.postgres.user") +
-		" password=" + c.GetString("repo.databases.postgres.password") +
-		" dbname=" + c.GetString("repo.databases.postgres.db") +
-		" port=" + c.GetString("repo.databases.postgres.port") 
+// dsnValue quotes values with spaces, quotes or backslashes as libpq expects
+func dsnValue(value string) string {
+	if !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + replacer.Replace(value) + "'"
+}
 
-	// Connect to database
-	elog.Log().Info("PostgreSQL connection string", zap.String("connStr", dsn))
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+func gormConfig() *gorm.Config {
+	return &gorm.Config{
 		NamingStrategy: schema.NamingStrategy{
 			SingularTable: true,
 		},
 		Logger: customLogger(),
-	})
+	}
+}
+
+// PostgreSqlConnect connects the primary postgres server of repo.databases.postgres
+func PostgreSqlConnect() (*gorm.DB, error) {
+	cfg := LoadPostgresConfig()
+	return openPostgres(cfg, cfg.Host, cfg.Port)
+}
+
+// openPostgres connects the server at host:port and applies the pool limits
+func openPostgres(cfg PostgresConfig, host, port string) (*gorm.DB, error) {
+	elog.Log().Info("Connecting to PostgreSQL database", zap.String("connStr", cfg.RedactedDSN(host, port)))
+
+	db, err := gorm.Open(postgres.Open(cfg.DSN(host, port)), gormConfig())
 	if err != nil {
-		elog.Log().Error("failed to connect postgreSQL connection", zap.Error(err), zap.String("connStr", dsn))
+		elog.Log().Error("failed to connect postgreSQL connection", zap.Error(err), zap.String("connStr", cfg.RedactedDSN(host, port)))
 		return nil, e.ApiErrors.PostgresDBConnError
-
 	}
-	elog.Log().Info("postgre Database Connected")
+	sqlDB, err := db.DB()
+	if err != nil {
+		elog.Log().Error("failed to get postgreSQL pool", zap.Error(err))
+		return nil, e.ApiErrors.PostgresDBConnError
+	}
+	setPool(sqlDB, cfg.Pool)
+	elog.Log().Info("postgre Database Connected", zap.String("host", host), zap.Int("maxOpen", cfg.Pool.MaxOpen), zap.Int("maxIdle", cfg.Pool.MaxIdle))
 	return db, nil
 }
 
+func setPool(db *sql.DB, pool PoolConfig) {
+	db.SetMaxOpenConns(pool.MaxOpen)
+	db.SetMaxIdleConns(pool.MaxIdle)
+	db.SetConnMaxLifetime(pool.MaxLifetime)
+	db.SetConnMaxIdleTime(pool.MaxIdleTime)
+}
+
 func customLogger() logger.Interface {
 	return dbLogger{}
 }
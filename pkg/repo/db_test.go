@@ -0,0 +1,47 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresConfig_Defaults(t *testing.T) {
+	cfg := PostgresConfig{Pool: PoolConfig{MaxOpen: 4, MaxIdle: 10}}
+	cfg.setDefaults()
+
+	assert.Equal(t, "prefer", cfg.SSLMode)
+	assert.Equal(t, "kisan", cfg.SearchPath)
+	assert.Equal(t, 5*time.Second, cfg.ConnectTimeout)
+	assert.Equal(t, 4, cfg.Pool.MaxOpen)
+	assert.Equal(t, 4, cfg.Pool.MaxIdle, "idle connections are capped at the open ones")
+	assert.Equal(t, 30*time.Minute, cfg.Pool.MaxLifetime)
+	assert.Equal(t, 5*time.Minute, cfg.Pool.MaxIdleTime)
+}
+
+func TestPostgresConfig_DSN(t *testing.T) {
+	cfg := PostgresConfig{User: "kisan", Password: "it's s3cret", DB: "kisaansathi", SSLMode: "require", SearchPath: "kisan", ConnectTimeout: 3 * time.Second}
+
+	assert.Equal(t, `host=db.internal port=5432 user=kisan password='it\'s s3cret' dbname=kisaansathi sslmode=require search_path=kisan connect_timeout=3`,
+		cfg.DSN("db.internal", "5432"))
+}
+
+func TestPostgresConfig_RedactedDSN(t *testing.T) {
+	cfg := PostgresConfig{User: "kisan", Password: "s3cret", DB: "kisaansathi", SSLMode: "disable", SearchPath: "kisan"}
+
+	redacted := cfg.RedactedDSN("127.0.0.1", "5432")
+	assert.NotContains(t, redacted, "s3cret")
+	assert.Equal(t, "host=127.0.0.1 port=5432 user=kisan password=xxxxx dbname=kisaansathi sslmode=disable search_path=kisan", redacted)
+
+	cfg.Password = ""
+	assert.NotContains(t, cfg.RedactedDSN("127.0.0.1", "5432"), "password")
+}
+
+func TestDatabases_ReaderFallsBackToPrimary(t *testing.T) {
+	databases := Databases{}
+	assert.Nil(t, databases.Reader())
+	assert.Empty(t, databases.Connections())
+	assert.Error(t, databases.Ping(context.Background()))
+}
@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"math/rand"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// DefaultJitter shortens each expiry by up to a tenth so keys set together do not expire together
+	DefaultJitter = 0.1
+	// minTTL is the shortest expiry the cache keeps, shorter ones fall back to its default of an hour
+	minTTL = time.Second
+)
+
+// loads de-duplicates concurrent loads of a key within the instance
+var loads singleflight.Group
+
+// LoadOptions tune GetOrLoad
+type LoadOptions struct {
+	TTL time.Duration
+	// NotFound is the error the loader returns for a missing value. It is cached for NegativeTTL
+	// and returned as is by later calls; a zero NegativeTTL or nil NotFound caches no misses.
+	NotFound    error
+	NegativeTTL time.Duration
+	// Jitter is the fraction of the ttl an expiry may be shortened by, DefaultJitter when 0,
+	// negative for none
+	Jitter float64
+}
+
+// entry is how GetOrLoad keeps a value. OK is set on every entry it writes, so that a nil or zero
+// Value is still a hit; Missing marks a cached NotFound.
+type entry[T any] struct {
+	Value   T    `msgpack:"v"`
+	Missing bool `msgpack:"m"`
+	OK      bool `msgpack:"ok"`
+}
+
+// GetOrLoad returns the value cached at key, calling load on a miss and caching what it returns.
+// Reads go through the in-process tier of repo.redis.local before redis. Concurrent misses of the
+// same key within the instance share one load. A cache that fails is logged and bypassed, and the
+// cache may be nil to always load.
+//
+//	eg. usage
+//	normals, err := repo.GetOrLoad(ctx, cache, "climate:v2:18.5:73.9", repo.LoadOptions{TTL: 24 * time.Hour},
+//		func(ctx context.Context) (*ClimateNormals, error) { return provider.Normals(ctx, 18.5, 73.9) })
+func GetOrLoad[T any](ctx context.Context, cache RedisInterface, key string, opts LoadOptions, load func(context.Context) (T, error)) (T, error) {
+	if cache == nil {
+		return load(ctx)
+	}
+	cached := entry[T]{}
+	if err := cache.GetTiered(ctx, key, &cached); err == nil {
+		if cached.OK && cached.Missing {
+			return cached.Value, opts.NotFound
+		}
+		if cached.OK {
+			return cached.Value, nil
+		}
+		// a value cached before the key was read through GetOrLoad decodes to an entry without OK,
+		// it is dropped from both tiers as the in-process tier may not replace it, and loaded again
+		if err := cache.DeleteKey(ctx, key); err != nil {
+			logger.Log(ctx).Warn("cache delete failed", zap.String("key", key), zap.Error(err))
+		}
+	} else if !IsCacheMiss(err) {
+		logger.Log(ctx).Warn("cache read failed, loading", zap.String("key", key), zap.Error(err))
+	}
+
+	result, err, _ := loads.Do(key, func() (interface{}, error) {
+		// the load is shared, a caller that goes away must not fail it for the others
+		value, err := load(context.WithoutCancel(ctx))
+		switch {
+		case err == nil:
+			store(ctx, cache, key, entry[T]{Value: value, OK: true}, jitter(opts.TTL, opts.Jitter))
+		case opts.NotFound != nil && opts.NegativeTTL > 0 && errors.Is(err, opts.NotFound):
+			store(ctx, cache, key, entry[T]{Missing: true, OK: true}, jitter(opts.NegativeTTL, opts.Jitter))
+		}
+		return value, err
+	})
+	value, ok := result.(T)
+	if !ok && result != nil {
+		// the key is shared with a load of another type, which is a bug of the caller
+		var zero T
+		return zero, fmt.Errorf("cache key [%s] holds %T, not %T", key, result, zero)
+	}
+	return value, err
+}
+
+func store[T any](ctx context.Context, cache RedisInterface, key string, value entry[T], ttl time.Duration) {
+	if err := cache.SetTiered(ctx, key, value, ttl); err != nil {
+		logger.Log(ctx).Warn("cache write failed", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// jitter shortens ttl by a random part of up to fraction of it, never below a second
+func jitter(ttl time.Duration, fraction float64) time.Duration {
+	if fraction == 0 {
+		fraction = DefaultJitter
+	}
+	if fraction > 0 && fraction < 1 && ttl > minTTL {
+		ttl -= time.Duration(rand.Float64() * fraction * float64(ttl))
+	}
+	if ttl < minTTL {
+		ttl = minTTL
+	}
+	return ttl
+}
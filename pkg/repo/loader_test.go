@@ -0,0 +1,198 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	rdc "github.com/go-redis/cache/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+type profile struct {
+	Name  string
+	Crops []string
+}
+
+var errNoProfile = errors.New("profile not found")
+
+// localCache is a redisStruct holding only the in-process tier
+func localCache() *redisStruct {
+	local := rdc.NewTinyLFU(100, time.Minute)
+	return &redisStruct{Cache: rdc.New(&rdc.Options{LocalCache: local}), Local: local}
+}
+
+func TestGetOrLoad_CachesValue(t *testing.T) {
+	cache := localCache()
+	var loads int
+	load := func(context.Context) (*profile, error) {
+		loads++
+		return &profile{Name: "Ramesh", Crops: []string{"wheat", "mustard"}}, nil
+	}
+
+	first, err := GetOrLoad(context.Background(), cache, "profile:1", LoadOptions{TTL: time.Hour}, load)
+	assert.NoError(t, err)
+	second, err := GetOrLoad(context.Background(), cache, "profile:1", LoadOptions{TTL: time.Hour}, load)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, loads)
+	assert.Equal(t, first, second)
+	assert.Equal(t, []string{"wheat", "mustard"}, second.Crops)
+}
+
+func TestGetOrLoad_NegativeCaching(t *testing.T) {
+	cache := localCache()
+	var loads int
+	load := func(context.Context) (*profile, error) {
+		loads++
+		return nil, errNoProfile
+	}
+	opts := LoadOptions{TTL: time.Hour, NotFound: errNoProfile, NegativeTTL: time.Minute}
+
+	for i := 0; i < 3; i++ {
+		value, err := GetOrLoad(context.Background(), cache, "profile:404", opts, load)
+		assert.ErrorIs(t, err, errNoProfile)
+		assert.Nil(t, value)
+	}
+	assert.Equal(t, 1, loads, "the miss is cached")
+}
+
+func TestGetOrLoad_ReloadsBareValues(t *testing.T) {
+	cache := localCache()
+	// written by SetTiered before the key was read through GetOrLoad
+	assert.NoError(t, cache.SetTiered(context.Background(), "profile:2", &profile{Name: "Sunita"}, time.Hour))
+	var loads int
+	load := func(context.Context) (*profile, error) {
+		loads++
+		return &profile{Name: "Sunita", Crops: []string{"cotton"}}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		value, err := GetOrLoad(context.Background(), cache, "profile:2", LoadOptions{TTL: time.Hour}, load)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"cotton"}, value.Crops)
+	}
+	assert.Equal(t, 1, loads, "the bare value is replaced by the loaded entry")
+
+	// a struct type has no nil to tell the bare value by
+	assert.NoError(t, cache.SetTiered(context.Background(), "profile:3", profile{Name: "Sunita"}, time.Hour))
+	loadStruct := func(context.Context) (profile, error) {
+		loads++
+		return profile{Name: "Sunita", Crops: []string{"cotton"}}, nil
+	}
+	value, err := GetOrLoad(context.Background(), cache, "profile:3", LoadOptions{TTL: time.Hour}, loadStruct)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cotton"}, value.Crops)
+	assert.Equal(t, 2, loads)
+}
+
+func TestGetOrLoad_CachesNilAndZeroValues(t *testing.T) {
+	cache := localCache()
+	var loads int
+	loadNil := func(context.Context) (*profile, error) {
+		loads++
+		return nil, nil
+	}
+	for i := 0; i < 2; i++ {
+		value, err := GetOrLoad(context.Background(), cache, "profile:none", LoadOptions{TTL: time.Hour}, loadNil)
+		assert.NoError(t, err)
+		assert.Nil(t, value)
+	}
+	assert.Equal(t, 1, loads, "a nil pointer is a value")
+
+	loads = 0
+	loadStruct := func(context.Context) (profile, error) {
+		loads++
+		return profile{}, nil
+	}
+	for i := 0; i < 2; i++ {
+		_, err := GetOrLoad(context.Background(), cache, "profile:empty", LoadOptions{TTL: time.Hour}, loadStruct)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, loads, "a zero struct is a value")
+
+	loads = 0
+	loadCount := func(context.Context) (int, error) {
+		loads++
+		return 0, nil
+	}
+	for i := 0; i < 2; i++ {
+		count, err := GetOrLoad(context.Background(), cache, "listings:count", LoadOptions{TTL: time.Hour}, loadCount)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	}
+	assert.Equal(t, 1, loads, "a zero scalar is a value")
+}
+
+func TestGetOrLoad_ErrorsAreNotCached(t *testing.T) {
+	cache := localCache()
+	var loads int
+	load := func(context.Context) (int, error) {
+		loads++
+		if loads == 1 {
+			return 0, errors.New("provider timed out")
+		}
+		return 42, nil
+	}
+
+	_, err := GetOrLoad(context.Background(), cache, "answer", LoadOptions{TTL: time.Hour, NotFound: errNoProfile, NegativeTTL: time.Minute}, load)
+	assert.EqualError(t, err, "provider timed out")
+	value, err := GetOrLoad(context.Background(), cache, "answer", LoadOptions{TTL: time.Hour}, load)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, value)
+	assert.Equal(t, 2, loads)
+}
+
+func TestGetOrLoad_SharesConcurrentLoads(t *testing.T) {
+	cache := localCache()
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (string, error) {
+		loads.Add(1)
+		<-release
+		return "kharif", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = GetOrLoad(context.Background(), cache, "season", LoadOptions{TTL: time.Hour}, load)
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+	for _, result := range results {
+		assert.Equal(t, "kharif", result)
+	}
+}
+
+func TestGetOrLoad_WithoutCache(t *testing.T) {
+	var loads int
+	load := func(context.Context) (int, error) {
+		loads++
+		return loads, nil
+	}
+	first, _ := GetOrLoad[int](context.Background(), nil, "n", LoadOptions{TTL: time.Hour}, load)
+	second, _ := GetOrLoad[int](context.Background(), nil, "n", LoadOptions{TTL: time.Hour}, load)
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 2, second)
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		ttl := jitter(time.Hour, 0)
+		assert.LessOrEqual(t, ttl, time.Hour)
+		assert.Greater(t, ttl, 54*time.Minute)
+	}
+	assert.Equal(t, time.Hour, jitter(time.Hour, -1))
+	assert.Equal(t, time.Second, jitter(200*time.Millisecond, 0), "expiries below a second are raised to one")
+}
@@ -0,0 +1,346 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	rdc "github.com/go-redis/cache/v8"
+	rd "github.com/go-redis/redis/v8"
+)
+
+const (
+	// RedisModeMemory keeps the cache in the process, for tests and local runs without redis
+	RedisModeMemory = "memory"
+	RedisModeRedis  = "redis"
+)
+
+// the ttl rules of go-redis/cache: no ttl is an hour, as is a ttl below a second, and a negative
+// ttl is not written
+const (
+	defaultItemTTL = time.Hour
+	sweepInterval  = time.Minute
+)
+
+var (
+	errWrongType   = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	errHDelNoField = errors.New("ERR wrong number of arguments for 'hdel' command")
+	errNotInteger  = errors.New("ERR hash value is not an integer")
+	errNotCounter  = errors.New("ERR value is not an integer or out of range")
+)
+
+type memoryEntry struct {
+	value    []byte
+	hash     map[string]string
+	expireAt time.Time
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
+// memoryCache is a RedisInterface kept in the process. Values are encoded as go-redis/cache
+// encodes them and keys expire as they do in redis, so the two are interchangeable; the cache is
+// not shared between instances.
+type memoryCache struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	codec     *rdc.Cache
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+var _ RedisInterface = (*memoryCache)(nil)
+
+func NewMemoryCache() RedisInterface {
+	return newMemoryCache(time.Now)
+}
+
+func newMemoryCache(now func() time.Time) *memoryCache {
+	return &memoryCache{
+		entries: make(map[string]*memoryEntry),
+		codec:   rdc.New(&rdc.Options{}),
+		now:     now,
+	}
+}
+
+// itemTTL is the expiry go-redis/cache gives an item, 0 when it is not written at all
+func itemTTL(ttl time.Duration) time.Duration {
+	switch {
+	case ttl < 0:
+		return 0
+	case ttl < time.Second:
+		return defaultItemTTL
+	}
+	return ttl
+}
+
+// get returns the live entry of key, dropping it when it has expired. Callers hold mu.
+func (m *memoryCache) get(key string) *memoryEntry {
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil
+	}
+	if entry.expired(m.now()) {
+		delete(m.entries, key)
+		return nil
+	}
+	return entry
+}
+
+// sweep drops the expired entries nobody reads any more. Callers hold mu.
+func (m *memoryCache) sweep() {
+	now := m.now()
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+	for key, entry := range m.entries {
+		if entry.expired(now) {
+			delete(m.entries, key)
+		}
+	}
+}
+
+func (m *memoryCache) set(key string, value interface{}, ttl time.Duration, writeIfNotSet bool) error {
+	b, err := m.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if ttl = itemTTL(ttl); ttl == 0 {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweep()
+	if writeIfNotSet && m.get(key) != nil {
+		return nil
+	}
+	m.entries[key] = &memoryEntry{value: b, expireAt: m.now().Add(ttl)}
+	return nil
+}
+
+func (m *memoryCache) getValue(key string, value interface{}) error {
+	m.mu.Lock()
+	entry := m.get(key)
+	m.mu.Unlock()
+	if entry == nil {
+		return rdc.ErrCacheMiss
+	}
+	if entry.hash != nil {
+		return errWrongType
+	}
+	return m.codec.Unmarshal(entry.value, value)
+}
+
+func (m *memoryCache) SetValue(ctx context.Context, key string, value interface{}, timeout int, writeIfNotSet bool) error {
+	return m.set(key, value, time.Duration(timeout)*time.Millisecond, writeIfNotSet)
+}
+
+func (m *memoryCache) GetValue(ctx context.Context, key string, value interface{}) error {
+	return m.getValue(key, value)
+}
+
+func (m *memoryCache) DeleteKey(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
+
+func (m *memoryCache) GetTTL(ctx context.Context, key string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry := m.get(key)
+	switch {
+	case entry == nil:
+		return ttlMissing
+	case entry.expireAt.IsZero():
+		return ttlPersistent
+	}
+	return int(entry.expireAt.Sub(m.now()).Milliseconds())
+}
+
+func (m *memoryCache) KeyExists(ctx context.Context, key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.get(key) != nil
+}
+
+func (m *memoryCache) SetRedisHash(ctx context.Context, key string, kvpairs map[string]string) error {
+	if kvpairs == nil {
+		return fmt.Errorf("nothing to hash")
+	}
+	if strings.TrimSpace(key) == "" {
+		return fmt.Errorf("key cannot be blank")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweep()
+	entry := m.get(key)
+	if entry != nil && entry.hash == nil {
+		return errWrongType
+	}
+	if len(kvpairs) == 0 {
+		return nil
+	}
+	if entry == nil {
+		entry = &memoryEntry{hash: make(map[string]string, len(kvpairs))}
+		m.entries[key] = entry
+	}
+	for k, v := range kvpairs {
+		entry.hash[k] = v
+	}
+	return nil
+}
+
+func (m *memoryCache) GetRedisHashValue(ctx context.Context, key string, args ...string) (map[string]string, error) {
+	if strings.TrimSpace(key) == "" {
+		return nil, fmt.Errorf("key cannot be blank")
+	}
+	field := ""
+	if len(args) > 0 {
+		field = args[0]
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry := m.get(key)
+	if entry != nil && entry.hash == nil {
+		return nil, errWrongType
+	}
+	if strings.TrimSpace(field) == "" {
+		rmap := make(map[string]string)
+		if entry != nil {
+			for k, v := range entry.hash {
+				rmap[k] = v
+			}
+		}
+		return rmap, nil
+	}
+	if entry == nil {
+		return nil, rd.Nil
+	}
+	rval, ok := entry.hash[field]
+	if !ok {
+		return nil, rd.Nil
+	}
+	return map[string]string{field: rval}, nil
+}
+
+func (m *memoryCache) DeleteRedisHash(ctx context.Context, key string, fields ...string) error {
+	if strings.TrimSpace(key) == "" {
+		return fmt.Errorf("key cannot be blank")
+	}
+	if len(fields) == 0 {
+		return errHDelNoField
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry := m.get(key)
+	if entry == nil {
+		return nil
+	}
+	if entry.hash == nil {
+		return errWrongType
+	}
+	for _, field := range fields {
+		delete(entry.hash, field)
+	}
+	if len(entry.hash) == 0 {
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+func (m *memoryCache) HIncrBy(ctx context.Context, key string, field string, incr int64) (int64, error) {
+	if strings.TrimSpace(key) == "" {
+		return 0, fmt.Errorf("key cannot be blank")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweep()
+	entry := m.get(key)
+	if entry != nil && entry.hash == nil {
+		return 0, errWrongType
+	}
+	if entry == nil {
+		entry = &memoryEntry{hash: make(map[string]string, 1)}
+		m.entries[key] = entry
+	}
+	var value int64
+	if current, ok := entry.hash[field]; ok {
+		var err error
+		if value, err = strconv.ParseInt(current, 10, 64); err != nil {
+			return 0, errNotInteger
+		}
+	}
+	value += incr
+	entry.hash[field] = strconv.FormatInt(value, 10)
+	return value, nil
+}
+
+func (m *memoryCache) IncrWindow(ctx context.Context, key string, window time.Duration) (int64, error) {
+	if strings.TrimSpace(key) == "" {
+		return 0, fmt.Errorf("key cannot be blank")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweep()
+	entry := m.get(key)
+	if entry != nil && entry.hash != nil {
+		return 0, errWrongType
+	}
+	var count int64
+	if entry == nil {
+		entry = &memoryEntry{expireAt: m.now().Add(window)}
+		m.entries[key] = entry
+	} else {
+		var err error
+		if count, err = strconv.ParseInt(string(entry.value), 10, 64); err != nil {
+			return 0, errNotCounter
+		}
+	}
+	count++
+	entry.value = []byte(strconv.FormatInt(count, 10))
+	return count, nil
+}
+
+func (m *memoryCache) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (m *memoryCache) GetTiered(ctx context.Context, key string, value interface{}) error {
+	return m.getValue(key, value)
+}
+
+func (m *memoryCache) SetTiered(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return m.set(key, value, ttl, false)
+}
+
+func (m *memoryCache) TryLock(ctx context.Context, key string, token string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweep()
+	if m.get(key) != nil {
+		return false, nil
+	}
+	entry := &memoryEntry{value: []byte(token)}
+	if ttl > 0 {
+		entry.expireAt = m.now().Add(ttl)
+	}
+	m.entries[key] = entry
+	return true, nil
+}
+
+func (m *memoryCache) Unlock(ctx context.Context, key string, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if entry := m.get(key); entry != nil && entry.hash == nil && string(entry.value) == token {
+		delete(m.entries, key)
+	}
+	return nil
+}
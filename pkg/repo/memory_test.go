@@ -0,0 +1,22 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCache_SweepsExpiredKeys(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	cache := newMemoryCache(func() time.Time { return now })
+	ctx := context.Background()
+
+	assert.NoError(t, cache.SetValue(ctx, "otp:9876543210", "123456", 60000, false))
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, cache.SetValue(ctx, "otp:9123456780", "654321", 60000, false))
+
+	assert.Len(t, cache.entries, 1, "the expired otp is dropped on a later write")
+	assert.Equal(t, 60000, cache.GetTTL(ctx, "otp:9123456780"))
+}
@@ -0,0 +1,41 @@
+-- Sample users (farmers, advisors, scientists, buyers)
+INSERT INTO kisan.users (name, phone, role, language, soil_type, district, lat, lng) VALUES
+('Ravi Yadav', '9876543210', 'farmer', 'Hindi', 'Loamy', 'Barabanki', 26.9371, 81.1895),
+('Suman Verma', '9123456789', 'advisor', 'Hindi', NULL, 'Gorakhpur', 26.7606, 83.3732),
+('Dr. Patel', '9988776655', 'scientist', 'English', NULL, 'Lucknow', 26.8467, 80.9462),
+('Mohan Traders', '9811122233', 'buyer', 'Hindi', NULL, 'Lucknow', 26.8606, 80.9158)
+ON CONFLICT (phone) DO NOTHING;
+
+-- Sample posts
+INSERT INTO kisan.posts (user_id, caption, media_url, crop_tag, likes)
+SELECT u.id, p.caption, p.media_url, p.crop_tag, p.likes
+FROM kisan.users u, (VALUES
+    ('My wheat crop after organic fertilizer use!', 'https://example.com/img/wheat1.jpg', 'wheat', 12),
+    ('Need help identifying this pest on brinjal', 'https://example.com/img/brinjal_bug.jpg', 'brinjal', 5)
+) AS p (caption, media_url, crop_tag, likes)
+WHERE u.phone = '9876543210';
+
+-- Sample mandi prices
+INSERT INTO kisan.mandi_prices (crop, region, price) VALUES
+('Wheat', 'Barabanki', 2250),
+('Rice', 'Lucknow', 1850),
+('Potato', 'Agra', 800);
+
+-- Sample services
+INSERT INTO kisan.services (name, type, contact, address, lat, lng) VALUES
+('Krishi Mitra Vet Center', 'vet', '7523999912', 'Barabanki Road', 26.9368, 81.1900),
+('Soil Testing Lab – DeHaat', 'soil', '7412589630', 'Gorakhpur Sector 3', 26.7610, 83.3735);
+
+-- Sample govt schemes
+INSERT INTO kisan.govt_schemes (title, description, eligibility, tags, pdf_url, open_date, close_date, eligible_roles) VALUES
+('PM-Kisan Yojana', 'Rs. 6000/year direct to farmers bank accounts', 'All small & marginal farmers', ARRAY['income', 'direct-benefit'], 'https://example.gov/pm-kisan.pdf', '2025-04-01', '2025-07-15', ARRAY['farmer']),
+('Fasal Bima Yojana', 'Insurance cover for crop damage due to climate risks', 'All registered farmers', ARRAY['insurance', 'climate'], 'https://example.gov/fasal-bima.pdf', '2025-06-01', '2025-07-31', ARRAY['farmer']);
+
+-- Sample notifications
+INSERT INTO kisan.notifications (user_id, message, type)
+SELECT u.id, n.message, n.type
+FROM kisan.users u, (VALUES
+    ('Wheat price has increased to ₹2250 in Barabanki', 'price'),
+    ('PM-Kisan scheme deadline extended to July 15', 'scheme')
+) AS n (message, type)
+WHERE u.phone = '9876543210';
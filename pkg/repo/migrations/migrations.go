@@ -0,0 +1,393 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"kisaanSathi/pkg/logger"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+//go:embed sql/*.sql
+var migrationFiles embed.FS
+
+//go:embed fixtures/*.sql
+var fixtureFiles embed.FS
+
+// lockKey is the postgres advisory lock held while migrating so only one instance changes the schema at a time
+const lockKey int64 = 7_040_151_041
+
+// the bookkeeping tables live in public so dropping schema kisan on the way down does not take them along
+const (
+	createMigrationsTable = `CREATE TABLE IF NOT EXISTS public.schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT now()
+	)`
+	createFixturesTable = `CREATE TABLE IF NOT EXISTS public.schema_fixtures (
+		name TEXT PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT now()
+	)`
+)
+
+var (
+	ErrBadFileName = errors.New("migration file name is not <version>_<name>.<up|down>.sql")
+	// ErrNoDown is returned when rolling back a migration that has no down file
+	ErrNoDown = errors.New("migration cannot be rolled back")
+	// ErrUnknownVersion is returned when the database has a migration this binary does not know
+	ErrUnknownVersion = errors.New("database has a migration unknown to this build")
+)
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is a numbered schema change, Down undoes Up and may be empty when it cannot be undone
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status tells whether a known migration is applied
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+}
+
+// Fixture is a file of sample data, applied once and never rolled back
+type Fixture struct {
+	Name string
+	SQL  string
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	fixtures   []Fixture
+}
+
+// New reads the migrations and fixtures embedded in the binary
+func New(db *sql.DB) (*Migrator, error) {
+	return newMigrator(db, migrationFiles, fixtureFiles)
+}
+
+func newMigrator(db *sql.DB, migrationFS fs.FS, fixtureFS fs.FS) (*Migrator, error) {
+	migrations, err := Load(migrationFS, "sql")
+	if err != nil {
+		return nil, err
+	}
+	fixtures, err := LoadFixtures(fixtureFS, "fixtures")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations, fixtures: fixtures}, nil
+}
+
+// Load reads the <version>_<name>.up.sql and .down.sql files of dir, ordered by version
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%w [%s]", ErrBadFileName, entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		body, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migration := byVersion[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(body)
+		} else {
+			migration.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// LoadFixtures reads the sql files of dir, ordered by name
+func LoadFixtures(fsys fs.FS, dir string) ([]Fixture, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	fixtures := make([]Fixture, 0, len(entries))
+	for _, entry := range entries {
+		body, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, Fixture{Name: entry.Name(), SQL: string(body)})
+	}
+	return fixtures, nil
+}
+
+// Latest is the newest version known to this build
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies the pending migrations in order, each in its own transaction, and returns them
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			start := time.Now()
+			err := inTx(ctx, conn, migration.Up,
+				`INSERT INTO public.schema_migrations (version, name) VALUES ($1, $2)`, migration.Version, migration.Name)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			logger.Log(ctx).Info("migration applied", zap.Int64("version", migration.Version), zap.String("name", migration.Name), zap.Duration("took", time.Since(start)))
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down rolls back the last steps applied migrations, newest first, and returns them
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	known := map[int64]Migration{}
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		versions := make([]int64, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		for i := 0; i < steps && i < len(versions); i++ {
+			migration, ok := known[versions[i]]
+			if !ok {
+				return fmt.Errorf("%w [%d]", ErrUnknownVersion, versions[i])
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("%w [%d_%s]", ErrNoDown, migration.Version, migration.Name)
+			}
+			err := inTx(ctx, conn, migration.Down, `DELETE FROM public.schema_migrations WHERE version = $1`, migration.Version)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			logger.Log(ctx).Info("migration rolled back", zap.Int64("version", migration.Version), zap.String("name", migration.Name))
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Version is the newest applied migration, 0 when none is
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, `SELECT to_regclass('public.schema_migrations') IS NOT NULL`).Scan(&exists); err != nil || !exists {
+		return 0, err
+	}
+	var version int64
+	err := m.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM public.schema_migrations`).Scan(&version)
+	return version, err
+}
+
+// Pending lists the known migrations which are not applied, gaps below the newest applied version
+// included. Unlike Status it does not wait for the migration lock, the readiness probe calls it.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, `SELECT to_regclass('public.schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, err
+	}
+	applied := map[int64]bool{}
+	if exists {
+		rows, err := m.db.QueryContext(ctx, `SELECT version FROM public.schema_migrations`)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var version int64
+			if err := rows.Scan(&version); err != nil {
+				return nil, err
+			}
+			applied[version] = true
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if !applied[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Status lists every known migration with when it was applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if at, ok := applied[migration.Version]; ok {
+				status.AppliedAt = &at
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// Seed applies the fixtures not applied yet and returns their names. It expects the schema to be up to date.
+func (m *Migrator) Seed(ctx context.Context) ([]string, error) {
+	var done []string
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		if _, err := conn.ExecContext(ctx, createFixturesTable); err != nil {
+			return err
+		}
+		rows, err := conn.QueryContext(ctx, `SELECT name FROM public.schema_fixtures`)
+		if err != nil {
+			return err
+		}
+		applied := map[string]bool{}
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				rows.Close()
+				return err
+			}
+			applied[name] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, fixture := range m.fixtures {
+			if applied[fixture.Name] {
+				continue
+			}
+			if err := inTx(ctx, conn, fixture.SQL, `INSERT INTO public.schema_fixtures (name) VALUES ($1)`, fixture.Name); err != nil {
+				return fmt.Errorf("fixture %s: %w", fixture.Name, err)
+			}
+			logger.Log(ctx).Info("fixture applied", zap.String("name", fixture.Name))
+			done = append(done, fixture.Name)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// withLock runs fn on one connection holding the advisory lock, waiting for another instance to finish first.
+// The lock belongs to the session, so every statement has to go through conn.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("taking the migration lock: %w", err)
+	}
+	defer func() {
+		// unlock even when ctx is done, the connection goes back to the pool
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey); err != nil {
+			logger.Log(ctx).Error("failed to release the migration lock", zap.Error(err))
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, createMigrationsTable); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// appliedVersions maps the applied versions to when they were applied
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM public.schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+// inTx runs the script and the bookkeeping statement in one transaction. Postgres DDL is
+// transactional, so a failed migration leaves neither its changes nor its row behind.
+func inTx(ctx context.Context, conn *sql.Conn, script string, record string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,187 @@
+package migrations
+
+import (
+	"context"
+	"kisaanSathi/pkg/logger"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testFiles = fstest.MapFS{
+	"sql/0001_core.up.sql":     {Data: []byte("CREATE TABLE kisan.users (id SERIAL)")},
+	"sql/0001_core.down.sql":   {Data: []byte("DROP TABLE kisan.users")},
+	"sql/0002_farms.up.sql":    {Data: []byte("CREATE TABLE kisan.farms (id SERIAL)")},
+	"sql/0002_farms.down.sql":  {Data: []byte("DROP TABLE kisan.farms")},
+	"sql/0003_listings.up.sql": {Data: []byte("CREATE TABLE kisan.listings (id SERIAL)")},
+	"fixtures/0001_sample.sql": {Data: []byte("INSERT INTO kisan.users DEFAULT VALUES")},
+	"fixtures/0002_farms.sql":  {Data: []byte("INSERT INTO kisan.farms DEFAULT VALUES")},
+}
+
+func newTestMigrator(t *testing.T) (*Migrator, sqlmock.Sqlmock) {
+	logger.LoggerInit("", -1)
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	})
+	migrator, err := newMigrator(db, testFiles, testFiles)
+	require.NoError(t, err)
+	return migrator, mock
+}
+
+func expectLock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS public.schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestLoad_OrdersAndPairsFiles(t *testing.T) {
+	migrations, err := Load(testFiles, "sql")
+
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 3)
+	assert.Equal(t, Migration{Version: 2, Name: "farms", Up: "CREATE TABLE kisan.farms (id SERIAL)", Down: "DROP TABLE kisan.farms"}, migrations[1])
+	assert.Empty(t, migrations[2].Down)
+
+	_, err = Load(fstest.MapFS{"sql/1_users.sql": {}}, "sql")
+	assert.ErrorIs(t, err, ErrBadFileName)
+	_, err = Load(fstest.MapFS{"sql/0001_users.down.sql": {Data: []byte("DROP TABLE kisan.users")}}, "sql")
+	assert.Error(t, err)
+}
+
+func TestEmbedded_AreComplete(t *testing.T) {
+	migrations, err := Load(migrationFiles, "sql")
+	assert.NoError(t, err)
+	for i, migration := range migrations {
+		assert.Equal(t, int64(i+1), migration.Version, "versions have no gaps")
+		assert.NotEmpty(t, migration.Down, "%d_%s has a down file", migration.Version, migration.Name)
+	}
+	fixtures, err := LoadFixtures(fixtureFiles, "fixtures")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, fixtures)
+}
+
+func TestUp_AppliesPendingInOrder(t *testing.T) {
+	migrator, mock := newTestMigrator(t)
+	expectLock(mock)
+	mock.ExpectQuery(`SELECT version, applied_at FROM public.schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+	for _, migration := range migrator.migrations[1:] {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(migration.Up)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO public.schema_migrations`).WithArgs(migration.Version, migration.Name).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+	expectUnlock(mock)
+
+	done, err := migrator.Up(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Len(t, done, 2)
+	assert.Equal(t, int64(3), migrator.Latest())
+}
+
+func TestUp_StopsAtAFailedMigration(t *testing.T) {
+	migrator, mock := newTestMigrator(t)
+	expectLock(mock)
+	mock.ExpectQuery(`SELECT version, applied_at FROM public.schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE kisan.users`).WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+	expectUnlock(mock)
+
+	done, err := migrator.Up(context.TODO())
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Empty(t, done)
+}
+
+func TestDown_RollsBackNewestFirst(t *testing.T) {
+	migrator, mock := newTestMigrator(t)
+	expectLock(mock)
+	mock.ExpectQuery(`SELECT version, applied_at FROM public.schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()).AddRow(2, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec(`DROP TABLE kisan.farms`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM public.schema_migrations`).WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectUnlock(mock)
+
+	done, err := migrator.Down(context.TODO(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "farms", done[0].Name)
+}
+
+func TestDown_NeedsADownFile(t *testing.T) {
+	migrator, mock := newTestMigrator(t)
+	expectLock(mock)
+	mock.ExpectQuery(`SELECT version, applied_at FROM public.schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(3, time.Now()))
+	expectUnlock(mock)
+
+	_, err := migrator.Down(context.TODO(), 1)
+
+	assert.ErrorIs(t, err, ErrNoDown)
+}
+
+func TestSeed_SkipsAppliedFixtures(t *testing.T) {
+	migrator, mock := newTestMigrator(t)
+	expectLock(mock)
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS public.schema_fixtures`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT name FROM public.schema_fixtures`).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("0001_sample.sql"))
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO kisan.farms DEFAULT VALUES`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO public.schema_fixtures`).WithArgs("0002_farms.sql").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectUnlock(mock)
+
+	done, err := migrator.Seed(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0002_farms.sql"}, done)
+}
+
+func TestVersion_WithoutTable(t *testing.T) {
+	migrator, mock := newTestMigrator(t)
+	mock.ExpectQuery(`SELECT to_regclass`).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	version, err := migrator.Version(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), version)
+}
+
+func TestPending_CountsGaps(t *testing.T) {
+	migrator, mock := newTestMigrator(t)
+	mock.ExpectQuery(`SELECT to_regclass`).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`SELECT version FROM public.schema_migrations`).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1).AddRow(3))
+
+	pending, err := migrator.Pending(context.TODO())
+
+	assert.NoError(t, err)
+	require.Len(t, pending, 1, "the newest applied version is 3 but 2 is missing")
+	assert.Equal(t, int64(2), pending[0].Version)
+}
+
+func TestPending_WithoutTable(t *testing.T) {
+	migrator, mock := newTestMigrator(t)
+	mock.ExpectQuery(`SELECT to_regclass`).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	pending, err := migrator.Pending(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Len(t, pending, 3)
+}
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS kisan.notifications;
+DROP TABLE IF EXISTS kisan.govt_schemes;
+DROP TABLE IF EXISTS kisan.services;
+DROP TABLE IF EXISTS kisan.mandi_prices;
+DROP TABLE IF EXISTS kisan.posts;
+DROP TABLE IF EXISTS kisan.users;
+DROP SCHEMA IF EXISTS kisan;
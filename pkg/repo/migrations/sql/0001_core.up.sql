@@ -0,0 +1,71 @@
+-- Core tables: users, posts, mandi prices, services, govt schemes and notifications
+-- SCHEMA CREATION
+CREATE SCHEMA IF NOT EXISTS kisan;
+
+-- USERS TABLE
+CREATE TABLE IF NOT EXISTS kisan.users (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100),
+    phone VARCHAR(20) UNIQUE,
+    role VARCHAR(20) CHECK (role IN ('farmer', 'advisor', 'scientist', 'buyer')),
+    language VARCHAR(20),
+    soil_type VARCHAR(50),
+    district VARCHAR(100),
+    lat DOUBLE PRECISION,
+    lng DOUBLE PRECISION,
+    created_at TIMESTAMP DEFAULT now()
+);
+
+-- POSTS TABLE
+CREATE TABLE IF NOT EXISTS kisan.posts (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES kisan.users(id),
+    caption TEXT,
+    media_url TEXT,
+    crop_tag VARCHAR(50),
+    likes INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT now()
+);
+
+-- MANDI PRICES TABLE
+CREATE TABLE IF NOT EXISTS kisan.mandi_prices (
+    id SERIAL PRIMARY KEY,
+    crop VARCHAR(100),
+    region VARCHAR(100),
+    price INTEGER,
+    recorded_on DATE DEFAULT CURRENT_DATE
+);
+
+-- SERVICES TABLE
+CREATE TABLE IF NOT EXISTS kisan.services (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100),
+    type VARCHAR(50),
+    contact VARCHAR(50),
+    address TEXT,
+    lat DOUBLE PRECISION,
+    lng DOUBLE PRECISION
+);
+
+-- GOVT SCHEMES TABLE
+CREATE TABLE IF NOT EXISTS kisan.govt_schemes (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(200),
+    description TEXT,
+    eligibility TEXT,
+    tags TEXT[],
+    pdf_url TEXT,
+    created_at TIMESTAMP DEFAULT now()
+);
+
+-- NOTIFICATIONS TABLE
+CREATE TABLE IF NOT EXISTS kisan.notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES kisan.users(id),
+    message TEXT,
+    type VARCHAR(50),
+    read BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_idx ON kisan.notifications (user_id, id DESC);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS kisan.scheme_reminders;
+DROP TABLE IF EXISTS kisan.scheme_bookmarks;
+ALTER TABLE kisan.govt_schemes DROP COLUMN IF EXISTS eligible_districts;
+ALTER TABLE kisan.govt_schemes DROP COLUMN IF EXISTS eligible_roles;
+ALTER TABLE kisan.govt_schemes DROP COLUMN IF EXISTS close_date;
+ALTER TABLE kisan.govt_schemes DROP COLUMN IF EXISTS open_date;
@@ -0,0 +1,23 @@
+-- SCHEME APPLICATION WINDOW AND TARGETING
+-- eligible_roles NULL means the scheme is not broadcast; only bookmarking users get reminders
+ALTER TABLE kisan.govt_schemes ADD COLUMN IF NOT EXISTS open_date DATE;
+ALTER TABLE kisan.govt_schemes ADD COLUMN IF NOT EXISTS close_date DATE;
+ALTER TABLE kisan.govt_schemes ADD COLUMN IF NOT EXISTS eligible_roles TEXT[];
+ALTER TABLE kisan.govt_schemes ADD COLUMN IF NOT EXISTS eligible_districts TEXT[];
+
+-- SCHEME BOOKMARKS TABLE
+CREATE TABLE IF NOT EXISTS kisan.scheme_bookmarks (
+    user_id INTEGER REFERENCES kisan.users(id) ON DELETE CASCADE,
+    scheme_id INTEGER REFERENCES kisan.govt_schemes(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT now(),
+    PRIMARY KEY (user_id, scheme_id)
+);
+
+-- SCHEME REMINDERS TABLE (one row per user, scheme and T-n offset so reminders are sent once)
+CREATE TABLE IF NOT EXISTS kisan.scheme_reminders (
+    scheme_id INTEGER REFERENCES kisan.govt_schemes(id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES kisan.users(id) ON DELETE CASCADE,
+    days_before INTEGER,
+    sent_at TIMESTAMP DEFAULT now(),
+    PRIMARY KEY (scheme_id, user_id, days_before)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS kisan.notification_preferences;
+DROP TABLE IF EXISTS kisan.notification_deliveries;
+DROP TABLE IF EXISTS kisan.device_tokens;
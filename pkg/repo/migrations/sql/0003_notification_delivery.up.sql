@@ -0,0 +1,44 @@
+-- DEVICE TOKENS TABLE (push registration per user and session)
+CREATE TABLE IF NOT EXISTS kisan.device_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES kisan.users(id) ON DELETE CASCADE,
+    session_id VARCHAR(64),
+    token TEXT UNIQUE NOT NULL,
+    platform VARCHAR(20) CHECK (platform IN ('android', 'ios', 'web')),
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS device_tokens_user_id_idx ON kisan.device_tokens (user_id);
+
+-- NOTIFICATION DELIVERIES TABLE (delivery status of a notification per channel)
+CREATE TABLE IF NOT EXISTS kisan.notification_deliveries (
+    notification_id INTEGER REFERENCES kisan.notifications(id) ON DELETE CASCADE,
+    channel VARCHAR(20),
+    status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'delivered', 'failed', 'skipped')),
+    attempts INTEGER DEFAULT 0,
+    last_error TEXT,
+    provider_ref TEXT,
+    next_attempt_at TIMESTAMP DEFAULT now(),
+    delivered_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT now(),
+    PRIMARY KEY (notification_id, channel)
+);
+CREATE INDEX IF NOT EXISTS notification_deliveries_due_idx ON kisan.notification_deliveries (channel, next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS notification_deliveries_provider_ref_idx ON kisan.notification_deliveries (provider_ref) WHERE provider_ref IS NOT NULL;
+
+-- Channel preferences, users without a row get every channel
+CREATE TABLE IF NOT EXISTS kisan.notification_preferences (
+    user_id INTEGER PRIMARY KEY REFERENCES kisan.users(id) ON DELETE CASCADE,
+    channels TEXT[] NOT NULL DEFAULT '{in_app,push,sms}',
+    updated_at TIMESTAMP DEFAULT now()
+);
+
+-- Notification types (the type column of kisan.notifications), quiet hours in the user's
+-- local time and a daily cap on push and sms messages; daily_cap 0 means unlimited
+ALTER TABLE kisan.notification_preferences ADD COLUMN IF NOT EXISTS types TEXT[] NOT NULL DEFAULT '{price,scheme,weather,general}';
+ALTER TABLE kisan.notification_preferences ALTER COLUMN types SET DEFAULT '{price,scheme,weather,crop,general}';
+ALTER TABLE kisan.notification_preferences ADD COLUMN IF NOT EXISTS quiet_start TIME;
+ALTER TABLE kisan.notification_preferences ADD COLUMN IF NOT EXISTS quiet_end TIME;
+ALTER TABLE kisan.notification_preferences ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'Asia/Kolkata';
+ALTER TABLE kisan.notification_preferences ADD COLUMN IF NOT EXISTS daily_cap INTEGER NOT NULL DEFAULT 0 CHECK (daily_cap >= 0);
+CREATE INDEX IF NOT EXISTS notification_deliveries_sent_idx ON kisan.notification_deliveries (delivered_at) WHERE status IN ('sent', 'delivered');
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS kisan.soil_tests;
+DROP TABLE IF EXISTS kisan.ledger_sales;
+DROP TABLE IF EXISTS kisan.ledger_expenses;
+DROP TABLE IF EXISTS kisan.crop_task_reminders;
+DROP TABLE IF EXISTS kisan.crop_tasks;
+DROP TABLE IF EXISTS kisan.crop_calendar_templates;
+DROP TABLE IF EXISTS kisan.crop_cycles;
+DROP TABLE IF EXISTS kisan.farms;
@@ -0,0 +1,142 @@
+-- FARMS TABLE
+-- A farmer's plots; boundary is a GeoJSON polygon and lat/lng its centroid
+CREATE TABLE IF NOT EXISTS kisan.farms (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES kisan.users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    area_acres NUMERIC(10, 2) NOT NULL CHECK (area_acres > 0),
+    irrigation_type VARCHAR(30),
+    soil_type VARCHAR(50),
+    boundary JSONB,
+    lat DOUBLE PRECISION,
+    lng DOUBLE PRECISION,
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS farms_user_id_idx ON kisan.farms (user_id);
+
+-- CROP CYCLES TABLE
+-- One sowing of a crop on a farm
+CREATE TABLE IF NOT EXISTS kisan.crop_cycles (
+    id SERIAL PRIMARY KEY,
+    farm_id INTEGER NOT NULL REFERENCES kisan.farms(id) ON DELETE CASCADE,
+    crop VARCHAR(50) NOT NULL,
+    variety VARCHAR(50),
+    season VARCHAR(10) NOT NULL CHECK (season IN ('rabi', 'kharif', 'zaid')),
+    sowing_date DATE NOT NULL,
+    expected_harvest_date DATE,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'harvested', 'abandoned')),
+    created_at TIMESTAMP DEFAULT now()
+);
+ALTER TABLE kisan.crop_cycles ADD COLUMN IF NOT EXISTS yield_quintals NUMERIC(10,2) CHECK (yield_quintals > 0);
+CREATE INDEX IF NOT EXISTS crop_cycles_farm_id_idx ON kisan.crop_cycles (farm_id);
+
+-- CROP CALENDAR TEMPLATES TABLE
+-- Tasks of a crop and season, day_offset days after sowing
+CREATE TABLE IF NOT EXISTS kisan.crop_calendar_templates (
+    id SERIAL PRIMARY KEY,
+    crop VARCHAR(50) NOT NULL,
+    season VARCHAR(10) NOT NULL CHECK (season IN ('rabi', 'kharif', 'zaid')),
+    task_type VARCHAR(20) NOT NULL CHECK (task_type IN ('irrigation', 'fertilizer', 'spraying', 'weeding', 'harvest')),
+    title VARCHAR(200) NOT NULL,
+    description TEXT,
+    day_offset INTEGER NOT NULL CHECK (day_offset >= 0),
+    UNIQUE (crop, season, task_type, day_offset)
+);
+
+-- CROP TASKS TABLE
+-- The schedule generated from the template when a crop cycle starts
+CREATE TABLE IF NOT EXISTS kisan.crop_tasks (
+    id SERIAL PRIMARY KEY,
+    crop_cycle_id INTEGER NOT NULL REFERENCES kisan.crop_cycles(id) ON DELETE CASCADE,
+    task_type VARCHAR(20) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    description TEXT,
+    due_date DATE NOT NULL,
+    completed_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS crop_tasks_due_idx ON kisan.crop_tasks (due_date) WHERE completed_at IS NULL;
+CREATE INDEX IF NOT EXISTS crop_tasks_cycle_idx ON kisan.crop_tasks (crop_cycle_id);
+
+-- CROP TASK REMINDERS TABLE
+-- One row per reminder sent so the worker never reminds twice for the same offset
+CREATE TABLE IF NOT EXISTS kisan.crop_task_reminders (
+    task_id INTEGER REFERENCES kisan.crop_tasks(id) ON DELETE CASCADE,
+    days_before INTEGER,
+    sent_at TIMESTAMP DEFAULT now(),
+    PRIMARY KEY (task_id, days_before)
+);
+
+-- LEDGER EXPENSES TABLE
+CREATE TABLE IF NOT EXISTS kisan.ledger_expenses (
+    id SERIAL PRIMARY KEY,
+    crop_cycle_id INTEGER NOT NULL REFERENCES kisan.crop_cycles(id) ON DELETE CASCADE,
+    category VARCHAR(20) NOT NULL CHECK (category IN ('seed', 'fertilizer', 'pesticide', 'labour', 'diesel', 'irrigation', 'machinery', 'rent', 'other')),
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    spent_on DATE NOT NULL,
+    note VARCHAR(200),
+    created_at TIMESTAMP DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS ledger_expenses_cycle_idx ON kisan.ledger_expenses (crop_cycle_id);
+
+-- LEDGER SALES TABLE
+-- mandi_price keeps the price of the linked kisan.mandi_prices row as it was on the day of the sale
+CREATE TABLE IF NOT EXISTS kisan.ledger_sales (
+    id SERIAL PRIMARY KEY,
+    crop_cycle_id INTEGER NOT NULL REFERENCES kisan.crop_cycles(id) ON DELETE CASCADE,
+    quantity_quintals NUMERIC(10,2) NOT NULL CHECK (quantity_quintals > 0),
+    price_per_quintal NUMERIC(10,2) NOT NULL CHECK (price_per_quintal > 0),
+    buyer VARCHAR(100),
+    sold_on DATE NOT NULL,
+    mandi_price_id INTEGER REFERENCES kisan.mandi_prices(id) ON DELETE SET NULL,
+    mandi_price NUMERIC(10,2),
+    created_at TIMESTAMP DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS ledger_sales_cycle_idx ON kisan.ledger_sales (crop_cycle_id);
+
+-- SOIL TESTS TABLE
+-- Soil Health Card values: n, p, k in kg/ha, oc in percent, micronutrients in ppm
+CREATE TABLE IF NOT EXISTS kisan.soil_tests (
+    id SERIAL PRIMARY KEY,
+    farm_id INTEGER NOT NULL REFERENCES kisan.farms(id) ON DELETE CASCADE,
+    test_date DATE NOT NULL,
+    lab VARCHAR(100) NOT NULL,
+    n NUMERIC(8,2) NOT NULL DEFAULT 0 CHECK (n >= 0),
+    p NUMERIC(8,2) NOT NULL DEFAULT 0 CHECK (p >= 0),
+    k NUMERIC(8,2) NOT NULL DEFAULT 0 CHECK (k >= 0),
+    ph NUMERIC(4,2) NOT NULL CHECK (ph > 0 AND ph <= 14),
+    oc NUMERIC(4,2) NOT NULL DEFAULT 0 CHECK (oc >= 0),
+    micronutrients JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS soil_tests_farm_idx ON kisan.soil_tests (farm_id, test_date DESC);
+
+-- Crop calendar templates (reference data)
+INSERT INTO kisan.crop_calendar_templates (crop, season, task_type, title, description, day_offset) VALUES
+('Wheat', 'rabi', 'fertilizer', 'Basal dose of DAP and potash', 'Apply full phosphorus, potash and a third of the nitrogen at sowing', 0),
+('Wheat', 'rabi', 'irrigation', 'First irrigation (crown root initiation)', 'The most critical irrigation, do not skip', 21),
+('Wheat', 'rabi', 'fertilizer', 'First urea top dressing', 'Apply a third of the nitrogen after the first irrigation', 25),
+('Wheat', 'rabi', 'weeding', 'Weed control', 'Spray herbicide or hand weed between rows', 30),
+('Wheat', 'rabi', 'irrigation', 'Second irrigation (tillering)', NULL, 45),
+('Wheat', 'rabi', 'fertilizer', 'Second urea top dressing', 'Apply the remaining nitrogen', 50),
+('Wheat', 'rabi', 'irrigation', 'Third irrigation (jointing)', NULL, 65),
+('Wheat', 'rabi', 'spraying', 'Check for rust and aphids', 'Spray only if yellow rust or aphids are seen', 70),
+('Wheat', 'rabi', 'irrigation', 'Fourth irrigation (flowering)', NULL, 85),
+('Wheat', 'rabi', 'irrigation', 'Fifth irrigation (milking)', NULL, 105),
+('Wheat', 'rabi', 'harvest', 'Harvest', 'Harvest when grains are hard and straw turns golden', 125),
+('Paddy', 'kharif', 'fertilizer', 'Basal dose at transplanting', 'Apply phosphorus, potash, zinc and half of the nitrogen', 0),
+('Paddy', 'kharif', 'irrigation', 'Keep 5 cm standing water', 'Maintain standing water for the first weeks after transplanting', 7),
+('Paddy', 'kharif', 'weeding', 'Weed control', NULL, 20),
+('Paddy', 'kharif', 'fertilizer', 'First urea top dressing (tillering)', NULL, 25),
+('Paddy', 'kharif', 'spraying', 'Check for stem borer and leaf folder', 'Spray only above the economic threshold', 40),
+('Paddy', 'kharif', 'fertilizer', 'Second urea top dressing (panicle initiation)', NULL, 50),
+('Paddy', 'kharif', 'irrigation', 'Irrigate at flowering', 'Do not let the field dry during flowering', 75),
+('Paddy', 'kharif', 'harvest', 'Harvest', 'Harvest when 80% of the grains turn golden', 115),
+('Mustard', 'rabi', 'fertilizer', 'Basal dose with sulphur', NULL, 0),
+('Mustard', 'rabi', 'weeding', 'Thinning and weeding', 'Keep 10-15 cm between plants', 20),
+('Mustard', 'rabi', 'irrigation', 'First irrigation', NULL, 30),
+('Mustard', 'rabi', 'fertilizer', 'Urea top dressing', NULL, 32),
+('Mustard', 'rabi', 'spraying', 'Check for aphids', 'Spray only if aphids cover the shoots', 55),
+('Mustard', 'rabi', 'irrigation', 'Second irrigation (pod filling)', NULL, 70),
+('Mustard', 'rabi', 'harvest', 'Harvest', 'Harvest when 75% of the pods turn yellow', 125)
+ON CONFLICT (crop, season, task_type, day_offset) DO NOTHING;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS kisan.crop_profiles;
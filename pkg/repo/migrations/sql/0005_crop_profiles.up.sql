@@ -0,0 +1,28 @@
+-- CROP PROFILES TABLE
+-- Conditions a crop does well in: rainfall is the season total in mm, temperature the season mean in °C
+CREATE TABLE IF NOT EXISTS kisan.crop_profiles (
+    crop VARCHAR(50) PRIMARY KEY,
+    seasons TEXT[] NOT NULL,
+    soil_types TEXT[] NOT NULL,
+    ph_min NUMERIC(3,1) NOT NULL,
+    ph_max NUMERIC(3,1) NOT NULL CHECK (ph_max >= ph_min),
+    rainfall_min INTEGER NOT NULL,
+    rainfall_max INTEGER NOT NULL CHECK (rainfall_max >= rainfall_min),
+    temp_min NUMERIC(4,1) NOT NULL,
+    temp_max NUMERIC(4,1) NOT NULL CHECK (temp_max >= temp_min)
+);
+
+-- Crop profiles (reference data)
+INSERT INTO kisan.crop_profiles (crop, seasons, soil_types, ph_min, ph_max, rainfall_min, rainfall_max, temp_min, temp_max) VALUES
+('Wheat', '{rabi}', '{alluvial,loamy,clay,black}', 6.0, 7.5, 100, 500, 12, 25),
+('Mustard', '{rabi}', '{alluvial,loamy,sandy,arid}', 6.0, 8.0, 50, 400, 10, 25),
+('Chickpea', '{rabi}', '{loamy,black,sandy,alluvial}', 6.0, 8.0, 50, 400, 15, 28),
+('Potato', '{rabi}', '{loamy,sandy,alluvial,silty}', 5.2, 6.5, 100, 500, 12, 24),
+('Paddy', '{kharif}', '{clay,alluvial,loamy}', 5.5, 7.0, 1000, 2500, 22, 35),
+('Maize', '{kharif,zaid}', '{loamy,alluvial,red,black}', 5.8, 7.5, 500, 1000, 21, 30),
+('Cotton', '{kharif}', '{black,alluvial,loamy}', 6.0, 8.0, 500, 1000, 21, 32),
+('Soybean', '{kharif}', '{black,loamy,clay}', 6.0, 7.5, 600, 1000, 20, 30),
+('Bajra', '{kharif}', '{sandy,arid,loamy,red}', 6.5, 8.5, 300, 600, 25, 35),
+('Moong', '{zaid,kharif}', '{loamy,sandy,alluvial}', 6.2, 7.5, 250, 700, 25, 35),
+('Watermelon', '{zaid}', '{sandy,loamy,alluvial}', 6.0, 7.5, 0, 300, 22, 35)
+ON CONFLICT (crop) DO NOTHING;
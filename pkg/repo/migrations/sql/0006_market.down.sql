@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS kisan.offer_messages;
+DROP TABLE IF EXISTS kisan.listing_offers;
+DROP TABLE IF EXISTS kisan.listings;
+-- buyers already registered are kept, the restored check only applies to new rows
+ALTER TABLE kisan.users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE kisan.users ADD CONSTRAINT users_role_check CHECK (role IN ('farmer', 'advisor', 'scientist')) NOT VALID;
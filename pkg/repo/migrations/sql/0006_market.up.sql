@@ -0,0 +1,54 @@
+-- BUYER ROLE
+-- Traders and aggregators who buy listed produce
+ALTER TABLE kisan.users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE kisan.users ADD CONSTRAINT users_role_check CHECK (role IN ('farmer', 'advisor', 'scientist', 'buyer'));
+
+-- LISTINGS TABLE
+-- Produce a farmer offers for sale; prices are per quintal and lat/lng is the pickup point
+CREATE TABLE IF NOT EXISTS kisan.listings (
+    id SERIAL PRIMARY KEY,
+    seller_id INTEGER NOT NULL REFERENCES kisan.users(id) ON DELETE CASCADE,
+    commodity VARCHAR(100) NOT NULL,
+    quantity_quintals NUMERIC(10,2) NOT NULL CHECK (quantity_quintals > 0),
+    price_per_quintal NUMERIC(10,2) NOT NULL CHECK (price_per_quintal > 0),
+    grade VARCHAR(5) NOT NULL CHECK (grade IN ('A', 'B', 'C', 'FAQ')),
+    pickup_address VARCHAR(200) NOT NULL,
+    lat DOUBLE PRECISION NOT NULL,
+    lng DOUBLE PRECISION NOT NULL,
+    photos TEXT[] NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'negotiating', 'sold', 'expired')),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS listings_seller_idx ON kisan.listings (seller_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS listings_open_idx ON kisan.listings (lower(commodity), expires_at) WHERE status IN ('active', 'negotiating');
+
+-- LISTING OFFERS TABLE
+-- A buyer has at most one pending offer per listing
+CREATE TABLE IF NOT EXISTS kisan.listing_offers (
+    id SERIAL PRIMARY KEY,
+    listing_id INTEGER NOT NULL REFERENCES kisan.listings(id) ON DELETE CASCADE,
+    buyer_id INTEGER NOT NULL REFERENCES kisan.users(id) ON DELETE CASCADE,
+    price_per_quintal NUMERIC(10,2) NOT NULL CHECK (price_per_quintal > 0),
+    quantity_quintals NUMERIC(10,2) NOT NULL CHECK (quantity_quintals > 0),
+    message VARCHAR(500),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'rejected')),
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS listing_offers_pending_idx ON kisan.listing_offers (listing_id, buyer_id) WHERE status = 'pending';
+ALTER TABLE kisan.listing_offers ADD COLUMN IF NOT EXISTS awaiting VARCHAR(10) NOT NULL DEFAULT 'seller' CHECK (awaiting IN ('seller', 'buyer'));
+
+-- Negotiation threads of offers
+CREATE TABLE IF NOT EXISTS kisan.offer_messages (
+    id SERIAL PRIMARY KEY,
+    offer_id INTEGER NOT NULL REFERENCES kisan.listing_offers(id) ON DELETE CASCADE,
+    sender_id INTEGER NOT NULL REFERENCES kisan.users(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('offer', 'counter', 'accept', 'reject', 'text')),
+    price_per_quintal NUMERIC(10,2),
+    quantity_quintals NUMERIC(10,2),
+    body TEXT,
+    created_at TIMESTAMP DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS offer_messages_offer_idx ON kisan.offer_messages (offer_id, id);
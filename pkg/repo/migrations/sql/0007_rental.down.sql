@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS kisan.equipment_bookings;
+DROP TABLE IF EXISTS kisan.equipment_slots;
+DROP TABLE IF EXISTS kisan.equipment;
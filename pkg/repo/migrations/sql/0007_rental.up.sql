@@ -0,0 +1,49 @@
+-- EQUIPMENT TABLE
+-- Machines and labour crews rented out from lat/lng by the hour or day
+CREATE TABLE IF NOT EXISTS kisan.equipment (
+    id SERIAL PRIMARY KEY,
+    owner_id INTEGER NOT NULL REFERENCES kisan.users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('tractor', 'harvester', 'sprayer', 'rotavator', 'thresher', 'seed_drill', 'labour')),
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(500),
+    hourly_rate NUMERIC(10,2) CHECK (hourly_rate > 0),
+    daily_rate NUMERIC(10,2) CHECK (daily_rate > 0),
+    address VARCHAR(200) NOT NULL,
+    lat DOUBLE PRECISION NOT NULL,
+    lng DOUBLE PRECISION NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now(),
+    CHECK (hourly_rate IS NOT NULL OR daily_rate IS NOT NULL)
+);
+CREATE INDEX IF NOT EXISTS equipment_owner_idx ON kisan.equipment (owner_id);
+CREATE INDEX IF NOT EXISTS equipment_active_idx ON kisan.equipment (kind, lat) WHERE active;
+
+-- EQUIPMENT SLOTS TABLE
+-- Windows in which the owner offers the equipment, times are UTC
+CREATE TABLE IF NOT EXISTS kisan.equipment_slots (
+    id SERIAL PRIMARY KEY,
+    equipment_id INTEGER NOT NULL REFERENCES kisan.equipment(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL CHECK (ends_at > starts_at)
+);
+CREATE INDEX IF NOT EXISTS equipment_slots_idx ON kisan.equipment_slots (equipment_id, starts_at);
+
+-- EQUIPMENT BOOKINGS TABLE
+-- Pending and confirmed bookings hold their slot; overlaps are checked with the equipment row locked
+CREATE TABLE IF NOT EXISTS kisan.equipment_bookings (
+    id SERIAL PRIMARY KEY,
+    equipment_id INTEGER NOT NULL REFERENCES kisan.equipment(id) ON DELETE CASCADE,
+    farmer_id INTEGER NOT NULL REFERENCES kisan.users(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL CHECK (ends_at > starts_at),
+    unit VARCHAR(5) NOT NULL CHECK (unit IN ('hour', 'day')),
+    rate NUMERIC(10,2) NOT NULL,
+    amount NUMERIC(12,2) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'confirmed', 'rejected', 'cancelled', 'completed')),
+    note VARCHAR(500),
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS equipment_bookings_held_idx ON kisan.equipment_bookings (equipment_id, starts_at) WHERE status IN ('pending', 'confirmed');
+CREATE INDEX IF NOT EXISTS equipment_bookings_farmer_idx ON kisan.equipment_bookings (farmer_id, starts_at DESC);
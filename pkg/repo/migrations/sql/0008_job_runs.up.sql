@@ -0,0 +1,15 @@
+-- JOB RUNS TABLE
+-- History of the runs of the scheduled jobs of pkg/jobs, one row per run across its attempts
+CREATE TABLE IF NOT EXISTS kisan.job_runs (
+    id BIGSERIAL PRIMARY KEY,
+    job VARCHAR(100) NOT NULL,
+    triggered_by VARCHAR(20) NOT NULL CHECK (triggered_by IN ('schedule', 'manual')),
+    instance VARCHAR(200) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    scheduled_at TIMESTAMP,
+    started_at TIMESTAMP NOT NULL DEFAULT now(),
+    finished_at TIMESTAMP,
+    error TEXT
+);
+CREATE INDEX IF NOT EXISTS job_runs_job_idx ON kisan.job_runs (job, started_at DESC);
@@ -0,0 +1,22 @@
+-- TASKS TABLE
+-- Background tasks of pkg/queue; workers claim due rows with FOR UPDATE SKIP LOCKED and lease them
+-- until locked_until. Finished tasks are deleted, tasks out of attempts are kept as dead letters.
+CREATE TABLE IF NOT EXISTS kisan.tasks (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    priority SMALLINT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5 CHECK (max_attempts > 0),
+    run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_until TIMESTAMPTZ,
+    locked_by VARCHAR(200),
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS tasks_due_idx ON kisan.tasks (kind, priority DESC, run_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS tasks_leased_idx ON kisan.tasks (locked_until) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS tasks_dead_idx ON kisan.tasks (kind, finished_at DESC) WHERE status = 'dead';
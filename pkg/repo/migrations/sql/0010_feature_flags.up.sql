@@ -0,0 +1,11 @@
+-- FEATURE FLAGS TABLE
+-- Overrides of the flags defined under flags.definitions; a row replaces the flag of its name and
+-- is picked up by the running servers within flags.pollinterval. Rules are a JSON array of
+-- {"roles": [...], "districts": [...], "percentage": 0-100}.
+CREATE TABLE IF NOT EXISTS kisan.feature_flags (
+    name VARCHAR(100) PRIMARY KEY,
+    description TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    rules JSONB NOT NULL DEFAULT '[]' CHECK (jsonb_typeof(rules) = 'array'),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1 @@
+ALTER TABLE kisan.notification_preferences ALTER COLUMN types SET DEFAULT '{price,scheme,weather,crop,general}';
@@ -0,0 +1,3 @@
+-- Market offers and rental bookings notify with their own types; users who never saved their
+-- notification types receive them too
+ALTER TABLE kisan.notification_preferences ALTER COLUMN types SET DEFAULT '{price,scheme,weather,crop,general,market,rental}';
@@ -2,14 +2,24 @@ package repo
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/config"
 	"kisaanSathi/pkg/logger"
+	"net"
 
 	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
 type Databases struct {
 	PgDB *gorm.DB
+	// PgReadDB shares the pool of PgDB and sends reads outside transactions to the replicas,
+	// it is nil without replicas
+	PgReadDB *gorm.DB
+	replicas []*gorm.DB
 }
 
 type DataObject struct {
@@ -17,24 +27,114 @@ type DataObject struct {
 	Cache     RedisInterface
 }
 
+// Reader is the connection of stores that only read and can live with replication lag
+func (d Databases) Reader() *gorm.DB {
+	if d.PgReadDB != nil {
+		return d.PgReadDB
+	}
+	return d.PgDB
+}
+
+// Connections returns every connection opened, to be closed on shutdown. PgReadDB is left out
+// as it closes with PgDB.
+func (d Databases) Connections() []*gorm.DB {
+	var connections []*gorm.DB
+	if d.PgDB != nil {
+		connections = append(connections, d.PgDB)
+	}
+	return append(connections, d.replicas...)
+}
+
+// Ping checks that the primary and every replica answer, the readiness check of postgres
+func (d Databases) Ping(c context.Context) error {
+	if d.PgDB == nil {
+		return errors.New("postgres is not connected")
+	}
+	var errs []error
+	for i, database := range d.Connections() {
+		sqlDB, err := database.DB()
+		if err == nil {
+			err = sqlDB.PingContext(c)
+		}
+		if err != nil {
+			name := "primary"
+			if i > 0 {
+				name = fmt.Sprintf("replica %d", i)
+			}
+			errs = append(errs, fmt.Errorf("postgres %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func NewRepoObject(c context.Context) (DataObject, error) {
 	logger.Log(c).Info("Creating new repository object")
 	temp := DataObject{}
-    var (
-		readEbatestOracleDB *gorm.DB
-	)
-	// readEbatestOracleDB, err := PostgreSqlConnect()
-	// if err != nil {
-	// 	logger.Log(c).Error("Failed to get oracle connection", zap.Error(err))
-	// 	return temp, err
-	// }
-	temp.Databases.PgDB = readEbatestOracleDB
+	databases, err := connectPostgres(c)
+	if err != nil {
+		logger.Log(c).Error("Failed to get postgres connection", zap.Error(err))
+		return temp, err
+	}
+	temp.Databases = databases
+	if config.App().Repo.Redis.Mode == RedisModeMemory {
+		logger.Log(c).Warn("repo.redis.mode is memory, the cache is not shared between instances")
+		temp.Cache = NewMemoryCache()
+		return temp, nil
+	}
 	redisObj, err := GetRedisObject(c)
 	if err != nil {
-		logger.Log(c).Error("Failed to get redis connection", zap.Error(err))
+		logger.Log(c).Error("Failed to get redis connection, set repo.redis.mode to memory to run without redis", zap.Error(err))
 		return temp, err
 	}
 	temp.Cache = redisObj
 
 	return temp, nil
 }
+
+// connectPostgres connects the primary and the replicas of repo.databases.postgres. The stores
+// need the primary, so a missing host is an error; a replica that cannot be reached is left out
+// and its reads go to the primary.
+func connectPostgres(c context.Context) (Databases, error) {
+	databases := Databases{}
+	cfg := LoadPostgresConfig()
+	if cfg.Host == "" {
+		// config.Load already rejects it
+		return databases, errors.New("repo.databases.postgres.host is not set")
+	}
+	primary, err := openPostgres(cfg, cfg.Host, cfg.Port)
+	if err != nil {
+		return databases, err
+	}
+	databases.PgDB = primary
+
+	var dialectors []gorm.Dialector
+	for _, replica := range cfg.Replicas {
+		db, err := openPostgres(cfg, replica.Host, replica.Port)
+		if err != nil {
+			logger.Log(c).Error("replica left out, its reads go to the primary", zap.String("replica", net.JoinHostPort(replica.Host, replica.Port)), zap.Error(err))
+			continue
+		}
+		sqlDB, _ := db.DB()
+		databases.replicas = append(databases.replicas, db)
+		dialectors = append(dialectors, postgres.New(postgres.Config{Conn: sqlDB}))
+	}
+	if len(dialectors) == 0 {
+		return databases, nil
+	}
+
+	primaryDB, _ := primary.DB()
+	reader, err := gorm.Open(postgres.New(postgres.Config{Conn: primaryDB}), gormConfig())
+	if err == nil {
+		err = reader.Use(dbresolver.Register(dbresolver.Config{
+			Replicas: dialectors,
+			Policy:   dbresolver.RandomPolicy{},
+		}))
+	}
+	if err != nil {
+		logger.Log(c).Error("failed to route reads to the replicas, reads go to the primary", zap.Error(err))
+		return databases, nil
+	}
+	databases.PgReadDB = reader
+	logger.Log(c).Info("postgres reads routed to replicas", zap.Int("replicas", len(dialectors)))
+	return databases, nil
+}
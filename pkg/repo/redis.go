@@ -0,0 +1,206 @@
+package repo
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/logger"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	rdc "github.com/go-redis/cache/v8"
+	rd "github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// modes of repo.redis.mode besides RedisModeRedis and RedisModeMemory
+const (
+	// RedisModeSentinel follows the master named repo.redis.mastername through the sentinels at repo.redis.addrs
+	RedisModeSentinel = "sentinel"
+	// RedisModeCluster spreads keys over the cluster seeded by repo.redis.addrs
+	RedisModeCluster = "cluster"
+)
+
+const defaultLocalTTL = time.Minute
+
+var (
+	ErrRedisMasterName = errors.New("repo.redis.mastername is required in sentinel mode")
+	ErrRedisClusterDB  = errors.New("redis cluster only has db 0")
+)
+
+// RedisConfig is config.App().Repo.Redis, with the client options
+type RedisConfig config.RedisConfig
+
+type (
+	RedisTLS  = config.RedisTLS
+	RedisPool = config.RedisPool
+	LocalTier = config.LocalTier
+)
+
+// LoadRedisConfig reads repo.redis
+func LoadRedisConfig() RedisConfig {
+	cfg := RedisConfig(config.App().Repo.Redis)
+	if cfg.Mode == "" {
+		cfg.Mode = RedisModeRedis
+	}
+	return cfg
+}
+
+// addrs are the servers to dial, host and port unless Addrs is set
+func (r RedisConfig) addrs() []string {
+	if len(r.Addrs) > 0 {
+		return r.Addrs
+	}
+	return []string{net.JoinHostPort(r.Host, strconv.Itoa(r.Port))}
+}
+
+// Options builds the client options of the configured mode
+func (r RedisConfig) Options() (*rd.UniversalOptions, error) {
+	switch r.Mode {
+	case RedisModeRedis:
+	case RedisModeSentinel:
+		if r.MasterName == "" {
+			return nil, ErrRedisMasterName
+		}
+	case RedisModeCluster:
+		if r.DB != 0 {
+			return nil, ErrRedisClusterDB
+		}
+	default:
+		return nil, fmt.Errorf("unknown repo.redis.mode [%s]", r.Mode)
+	}
+
+	opts := &rd.UniversalOptions{
+		Addrs:            r.addrs(),
+		DB:               r.DB,
+		Username:         r.Username,
+		Password:         r.Password,
+		SentinelUsername: r.SentinelUsername,
+		SentinelPassword: r.SentinelPassword,
+		MasterName:       r.MasterName,
+		DialTimeout:      r.DialTimeout,
+		ReadTimeout:      r.ReadTimeout,
+		WriteTimeout:     r.WriteTimeout,
+		PoolSize:         r.Pool.Size,
+		MinIdleConns:     r.Pool.MinIdle,
+		MaxConnAge:       r.Pool.MaxConnAge,
+		IdleTimeout:      r.Pool.IdleTimeout,
+		PoolTimeout:      r.Pool.Timeout,
+	}
+	if r.TLS.Enabled {
+		tlsConfig, err := newTLSConfig(r.TLS)
+		if err != nil {
+			return nil, err
+		}
+		opts.TLSConfig = tlsConfig
+	}
+	return opts, nil
+}
+
+func newTLSConfig(t RedisTLS) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if t.CAFile == "" {
+		return tlsConfig, nil
+	}
+	pem, err := os.ReadFile(t.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repo.redis.tls.cafile: %w", err)
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("invalid repo.redis.tls.cafile: no certificates in %s", t.CAFile)
+	}
+	tlsConfig.RootCAs = roots
+	return tlsConfig, nil
+}
+
+// newRedisClient opens the client of the configured mode
+func newRedisClient(r RedisConfig) (rd.UniversalClient, error) {
+	opts, err := r.Options()
+	if err != nil {
+		return nil, err
+	}
+	switch r.Mode {
+	case RedisModeSentinel:
+		return rd.NewFailoverClient(opts.Failover()), nil
+	case RedisModeCluster:
+		return rd.NewClusterClient(opts.Cluster()), nil
+	}
+	return rd.NewClient(opts.Simple()), nil
+}
+
+var (
+	redisMu  sync.Mutex
+	redisObj *redisStruct
+)
+
+// GetRedisObject returns the redis cache of repo.redis, connecting on the first call. Later calls
+// share the client; a client that fails its ping is closed and the next call tries again.
+func GetRedisObject(ctx context.Context) (RedisInterface, error) {
+	redisMu.Lock()
+	defer redisMu.Unlock()
+	if redisObj != nil {
+		return redisObj, nil
+	}
+
+	cfg := LoadRedisConfig()
+	redisClient, err := newRedisClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	//check connection
+	pong, err := redisClient.Ping(ctx).Result()
+	if err != nil {
+		if closeErr := redisClient.Close(); closeErr != nil {
+			logger.Log(ctx).Warn("failed to close redis client", zap.Error(closeErr))
+		}
+		return nil, err
+	}
+	logger.Log(ctx).Info("redis connected: ", zap.String("result", pong), zap.String("mode", cfg.Mode),
+		zap.Strings("addrs", cfg.addrs()), zap.Int("db", cfg.DB), zap.Bool("tls", cfg.TLS.Enabled))
+
+	var local rdc.LocalCache
+	if cfg.Local.Size > 0 {
+		ttl := cfg.Local.TTL
+		if ttl <= 0 {
+			ttl = defaultLocalTTL
+		}
+		local = rdc.NewTinyLFU(cfg.Local.Size, ttl)
+	}
+	redisObj = &redisStruct{
+		Client: redisClient,
+		Cache: rdc.New(&rdc.Options{
+			Redis:      redisClient,
+			LocalCache: local,
+		}),
+		Local: local,
+	}
+	return redisObj, nil
+}
+
+// CloseRedis closes the shared client, a later GetRedisObject connects again
+func CloseRedis(ctx context.Context) error {
+	logger.Log(ctx).Info("Closing redis connection START")
+	defer logger.Log(ctx).Info("Closing redis connection END")
+	redisMu.Lock()
+	defer redisMu.Unlock()
+	if redisObj == nil {
+		return nil
+	}
+	err := redisObj.Client.Close()
+	redisObj = nil
+	return err
+}
@@ -0,0 +1,59 @@
+package repo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisConfig_Options(t *testing.T) {
+	cfg := RedisConfig{Mode: RedisModeRedis, Host: "10.0.0.5", Port: 6380, Username: "kisan", Password: "s3cret", DB: 3,
+		Pool: RedisPool{Size: 20, MinIdle: 2, Timeout: 4 * time.Second}}
+
+	opts, err := cfg.Options()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.5:6380"}, opts.Addrs)
+	assert.Equal(t, "kisan", opts.Username)
+	assert.Equal(t, "s3cret", opts.Password)
+	assert.Equal(t, 3, opts.DB)
+	assert.Equal(t, 20, opts.PoolSize)
+	assert.Equal(t, 2, opts.MinIdleConns)
+	assert.Equal(t, 4*time.Second, opts.PoolTimeout)
+	assert.Nil(t, opts.TLSConfig)
+}
+
+func TestRedisConfig_Modes(t *testing.T) {
+	sentinels := []string{"10.0.0.7:26379", "10.0.0.8:26379"}
+
+	_, err := RedisConfig{Mode: RedisModeSentinel, Addrs: sentinels}.Options()
+	assert.ErrorIs(t, err, ErrRedisMasterName)
+
+	opts, err := RedisConfig{Mode: RedisModeSentinel, Addrs: sentinels, MasterName: "kisan", DB: 1}.Options()
+	assert.NoError(t, err)
+	assert.Equal(t, sentinels, opts.Failover().SentinelAddrs)
+	assert.Equal(t, "kisan", opts.Failover().MasterName)
+
+	_, err = RedisConfig{Mode: RedisModeCluster, Addrs: sentinels, DB: 1}.Options()
+	assert.ErrorIs(t, err, ErrRedisClusterDB)
+
+	_, err = RedisConfig{Mode: "keydb"}.Options()
+	assert.EqualError(t, err, "unknown repo.redis.mode [keydb]")
+}
+
+func TestRedisConfig_TLS(t *testing.T) {
+	opts, err := RedisConfig{Mode: RedisModeRedis, Host: "cache.internal", Port: 6379, TLS: RedisTLS{Enabled: true, ServerName: "cache.internal"}}.Options()
+	assert.NoError(t, err)
+	assert.Equal(t, "cache.internal", opts.TLSConfig.ServerName)
+	assert.False(t, opts.TLSConfig.InsecureSkipVerify)
+
+	empty := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(empty, []byte("not a certificate"), 0o600))
+	_, err = RedisConfig{Mode: RedisModeRedis, TLS: RedisTLS{Enabled: true, CAFile: empty}}.Options()
+	assert.ErrorContains(t, err, "no certificates")
+
+	_, err = RedisConfig{Mode: RedisModeRedis, TLS: RedisTLS{Enabled: true, CAFile: filepath.Join(t.TempDir(), "missing.pem")}}.Options()
+	assert.ErrorContains(t, err, "invalid repo.redis.tls.cafile")
+}
@@ -0,0 +1,48 @@
+package repo
+
+import (
+	"context"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+type afterCommitKey struct{}
+
+type afterCommitHooks struct {
+	mu    sync.Mutex
+	hooks []func()
+}
+
+// Transaction runs fn in a transaction of db like gorm's Transaction. The functions passed to
+// AfterCommit with its tx run once the transaction commits, and not at all when it rolls back.
+func Transaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	hooks := &afterCommitHooks{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return fn(tx.WithContext(context.WithValue(tx.Statement.Context, afterCommitKey{}, hooks)))
+	})
+	if err != nil {
+		return err
+	}
+	hooks.mu.Lock()
+	committed := hooks.hooks
+	hooks.mu.Unlock()
+	for _, hook := range committed {
+		hook()
+	}
+	return nil
+}
+
+// AfterCommit defers hook until the transaction of tx commits. It runs hook at once when tx is
+// nil or was not started by Transaction, as there is no commit to wait for.
+func AfterCommit(tx *gorm.DB, hook func()) {
+	if tx != nil && tx.Statement != nil && tx.Statement.Context != nil {
+		if hooks, ok := tx.Statement.Context.Value(afterCommitKey{}).(*afterCommitHooks); ok {
+			hooks.mu.Lock()
+			hooks.hooks = append(hooks.hooks, hook)
+			hooks.mu.Unlock()
+			return
+		}
+	}
+	hook()
+}
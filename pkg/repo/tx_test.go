@@ -0,0 +1,54 @@
+package repo
+
+import (
+	"errors"
+	"kisaanSathi/pkg/utils"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestTransaction_AfterCommit(t *testing.T) {
+	_, gormDB, mock := utils.NewMockDB()
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE kisan.notifications`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	var ran []string
+	err := Transaction(gormDB, func(tx *gorm.DB) error {
+		AfterCommit(tx, func() { ran = append(ran, "invalidate") })
+		if err := tx.Exec(`UPDATE kisan.notifications SET read = true`).Error; err != nil {
+			return err
+		}
+		assert.Empty(t, ran, "hooks wait for the commit")
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"invalidate"}, ran)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransaction_Rollback(t *testing.T) {
+	_, gormDB, mock := utils.NewMockDB()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	ran := false
+	err := Transaction(gormDB, func(tx *gorm.DB) error {
+		AfterCommit(tx, func() { ran = true })
+		return errors.New("listing changed")
+	})
+
+	assert.Error(t, err)
+	assert.False(t, ran, "hooks of a rolled back transaction never run")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAfterCommit_WithoutTransaction(t *testing.T) {
+	ran := false
+	AfterCommit(nil, func() { ran = true })
+	assert.True(t, ran)
+}
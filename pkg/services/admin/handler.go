@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"errors"
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/flags"
+	"kisaanSathi/pkg/jobs"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/network"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RequireAdmin lets through requests carrying admin.token in X-Admin-Token. The admin endpoints
+// are closed while no token is configured.
+func (h *adminHandler) RequireAdmin(c *gin.Context) {
+	token := config.App().Admin.Token
+	if token == "" {
+		err := errors.New("admin endpoints are disabled, admin.token is not configured")
+		c.JSON(http.StatusForbidden, network.FailureResponse(network.ApiErrors.Forbidden.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Admin-Token")), []byte(token)) != 1 {
+		err := errors.New("invalid admin token")
+		c.JSON(http.StatusUnauthorized, network.FailureResponse(network.ApiErrors.Unauthorized.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// ListJobs lists the background jobs with their schedule, next run and latest run
+func (h *adminHandler) ListJobs(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	statuses, err := h.scheduler.Jobs(c)
+	if err != nil {
+		logger.Log(c).Error("Failed to list jobs", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.GetDBError.WithErrorDescription(err.Error())))
+		return
+	}
+	c.JSON(http.StatusOK, network.SuccessResponse(statuses))
+}
+
+// ListFlags lists the feature flags in effect with their rules and where they are defined
+func (h *adminHandler) ListFlags(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	c.JSON(http.StatusOK, network.SuccessResponse(flags.Default().List()))
+}
+
+// ListJobRuns lists the recorded runs of a job, latest first
+func (h *adminHandler) ListJobRuns(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	var request jobs.RunsRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		return
+	}
+	if request.Limit == 0 {
+		request.Limit = jobs.DefaultRunsPageSize
+	}
+	runs, err := h.scheduler.Runs(c, c.Param("name"), request.Limit)
+	if err != nil {
+		h.jobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, network.SuccessResponse(runs))
+}
+
+// TriggerJob starts a run of the job now, it answers 202 with the run while it is still running
+func (h *adminHandler) TriggerJob(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	// the run outlives the request, and gin reuses c once it is answered
+	run, err := h.scheduler.Trigger(c.Copy(), c.Param("name"))
+	if err != nil {
+		h.jobError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, network.SuccessResponse(run))
+}
+
+func (h *adminHandler) jobError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, network.FailureResponse(network.ApiErrors.NoDataFound.WithErrorDescription(err.Error())))
+	case errors.Is(err, jobs.ErrJobRunning):
+		c.JSON(http.StatusConflict, network.FailureResponse(network.ApiErrors.Conflict.WithErrorDescription(err.Error())))
+	default:
+		logger.Log(c).Error("Job request failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.InternalServerError.WithErrorDescription(err.Error())))
+	}
+}
@@ -0,0 +1,24 @@
+package admin
+
+import (
+	"kisaanSathi/pkg/jobs"
+
+	"github.com/gin-gonic/gin"
+)
+
+type adminHandler struct {
+	scheduler *jobs.Scheduler
+}
+
+type AdminHandler interface {
+	RequireAdmin(c *gin.Context)
+	ListJobs(c *gin.Context)
+	ListJobRuns(c *gin.Context)
+	TriggerJob(c *gin.Context)
+	ListFlags(c *gin.Context)
+}
+
+// NewAdminHandler serves the operator endpoints, callers authenticate with admin.token
+func NewAdminHandler(scheduler *jobs.Scheduler) AdminHandler {
+	return &adminHandler{scheduler: scheduler}
+}
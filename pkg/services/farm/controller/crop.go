@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	commonUtils "kisaanSathi/pkg/services/common/utils"
+	"kisaanSathi/pkg/services/farm/models"
+	notificationModels "kisaanSathi/pkg/services/notification/models"
+	"kisaanSathi/pkg/utils"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const taskHarvest = "harvest"
+
+var ErrHarvestBeforeSowing = errors.New("expected harvest date must be after the sowing date")
+
+func (s *controller) ListCropCycles(ctx context.Context, userID int64, farmID int64) ([]models.CropCycle, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	if _, err := s.farmStore.GetFarm(ctx, userID, farmID); err != nil {
+		return nil, err
+	}
+	cycles, err := s.farmStore.ListCropCycles(ctx, userID, farmID)
+	if err != nil {
+		return nil, err
+	}
+	if cycles == nil {
+		cycles = []models.CropCycle{}
+	}
+	return cycles, nil
+}
+
+// CreateCropCycle starts a crop cycle and schedules its tasks from the calendar template of the
+// crop and season. Without an expected harvest date the template's harvest task is used.
+func (s *controller) CreateCropCycle(ctx context.Context, userID int64, farmID int64, request *models.CropCycleRequest) (*models.CropCycle, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	sowingDate, err := parseDate(request.SowingDate)
+	if err != nil {
+		return nil, err
+	}
+	cycle := &models.CropCycle{
+		FarmID:     farmID,
+		Crop:       request.Crop,
+		Variety:    request.Variety,
+		Season:     request.Season,
+		SowingDate: sowingDate,
+	}
+	if request.ExpectedHarvestDate != "" {
+		harvestDate, err := parseDate(request.ExpectedHarvestDate)
+		if err != nil {
+			return nil, err
+		}
+		if !harvestDate.After(sowingDate) {
+			return nil, ErrHarvestBeforeSowing
+		}
+		cycle.ExpectedHarvestDate = &harvestDate
+	}
+
+	templates, err := s.farmStore.ListTemplates(ctx, request.Crop, request.Season)
+	if err != nil {
+		return nil, err
+	}
+	tasks := schedule(templates, sowingDate)
+	if cycle.ExpectedHarvestDate == nil {
+		for _, task := range tasks {
+			if task.TaskType == taskHarvest {
+				harvestDate := task.DueDate
+				cycle.ExpectedHarvestDate = &harvestDate
+			}
+		}
+	}
+	if len(templates) == 0 {
+		logger.Log(ctx).Info("no calendar template for crop", zap.String("crop", request.Crop), zap.String("season", request.Season))
+	}
+
+	if err := s.farmStore.CreateCropCycle(ctx, userID, cycle, tasks); err != nil {
+		return nil, err
+	}
+	return cycle, nil
+}
+
+func (s *controller) UpdateCropCycle(ctx context.Context, userID int64, farmID int64, cycleID int64, request *models.UpdateCropCycleRequest) error {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	updates := request.Updates()
+	if len(updates) == 0 {
+		return nil
+	}
+	return s.farmStore.UpdateCropCycle(ctx, userID, farmID, cycleID, updates)
+}
+
+// GetCalendar returns the open tasks of the farm due in the next request.Days days, overdue ones first
+func (s *controller) GetCalendar(ctx context.Context, userID int64, farmID int64, request *models.CalendarRequest) (*models.Calendar, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	if _, err := s.farmStore.GetFarm(ctx, userID, farmID); err != nil {
+		return nil, err
+	}
+	days := request.Days
+	if days <= 0 {
+		days = models.DefaultCalendarDays
+	}
+	today := today(time.Now())
+	until := utils.GetDateSomeTimeAgo(today, 0, 0, -days, 0)
+
+	tasks, err := s.farmStore.ListOpenTasks(ctx, userID, farmID, until)
+	if err != nil {
+		return nil, err
+	}
+	calendar := &models.Calendar{FarmID: farmID, From: today, To: until, Tasks: []models.CropTask{}}
+	for _, task := range tasks {
+		task.DueDate = dateOf(task.DueDate)
+		task.Overdue = task.DueDate.Before(today)
+		calendar.Tasks = append(calendar.Tasks, task)
+	}
+	return calendar, nil
+}
+
+func (s *controller) CompleteTask(ctx context.Context, userID int64, farmID int64, taskID int64) error {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+	return s.farmStore.CompleteTask(ctx, userID, farmID, taskID)
+}
+
+func (s *controller) SendTaskReminders(ctx context.Context, now time.Time) (int, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	reminders, err := s.farmStore.GetDueTaskReminders(ctx, today(now), models.TaskReminderOffsets)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, reminder := range reminders {
+		ok, err := s.farmStore.RecordTaskReminder(ctx, reminder, func(tx *gorm.DB) error {
+			_, err := s.notifier.NotifyTx(ctx, tx, reminder.UserID, notificationModels.TypeCrop, taskReminderMessage(reminder))
+			return err
+		})
+		if err != nil {
+			// keep going, the failed reminder is picked up again on the next run
+			logger.Log(ctx).Error("failed to send task reminder", zap.Int64("taskId", reminder.TaskID), zap.Error(err))
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	logger.Log(ctx).Info("crop task reminders sent", zap.Int("due", len(reminders)), zap.Int("sent", sent))
+	return sent, nil
+}
+
+// schedule turns the calendar template into tasks due DayOffset days after sowing
+func schedule(templates []models.CalendarTemplate, sowingDate time.Time) []models.CropTask {
+	tasks := make([]models.CropTask, 0, len(templates))
+	for _, template := range templates {
+		tasks = append(tasks, models.CropTask{
+			Crop:        template.Crop,
+			TaskType:    template.TaskType,
+			Title:       template.Title,
+			Description: template.Description,
+			// a negative "time ago" is a date in the future
+			DueDate: utils.GetDateSomeTimeAgo(sowingDate, 0, 0, -template.DayOffset, 0),
+		})
+	}
+	return tasks
+}
+
+func taskReminderMessage(reminder models.DueTaskReminder) string {
+	if reminder.DaysBefore == 0 {
+		return fmt.Sprintf("Today: %s for %s on %s", reminder.Title, reminder.Crop, reminder.FarmName)
+	}
+	return fmt.Sprintf("Tomorrow: %s for %s on %s", reminder.Title, reminder.Crop, reminder.FarmName)
+}
+
+// parseDate reads a DD/MM/YYYY request date as a calendar date at UTC midnight
+func parseDate(value string) (time.Time, error) {
+	parsed, err := commonUtils.Date(value).Date()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return dateOf(parsed.UTC()), nil
+}
+
+// today is the current calendar date, kept at UTC midnight like the DATE columns are scanned
+func today(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
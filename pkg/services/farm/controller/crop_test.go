@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"kisaanSathi/pkg/services/farm/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule(t *testing.T) {
+	sowingDate, err := parseDate("15/11/2025")
+	assert.NoError(t, err)
+
+	tasks := schedule([]models.CalendarTemplate{
+		{Crop: "Wheat", TaskType: "fertilizer", Title: "Basal dose", DayOffset: 0},
+		{Crop: "Wheat", TaskType: "irrigation", Title: "First irrigation", DayOffset: 21},
+		{Crop: "Wheat", TaskType: "harvest", Title: "Harvest", DayOffset: 125},
+	}, sowingDate)
+
+	assert.Len(t, tasks, 3)
+	assert.Equal(t, time.Date(2025, 11, 15, 0, 0, 0, 0, time.UTC), tasks[0].DueDate)
+	assert.Equal(t, time.Date(2025, 12, 6, 0, 0, 0, 0, time.UTC), tasks[1].DueDate)
+	assert.Equal(t, time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC), tasks[2].DueDate)
+	assert.Equal(t, "irrigation", tasks[1].TaskType)
+}
+
+func TestTaskReminderMessage(t *testing.T) {
+	reminder := models.DueTaskReminder{Title: "First irrigation", Crop: "Wheat", FarmName: "Nahar wala khet", DaysBefore: 1}
+	assert.Equal(t, "Tomorrow: First irrigation for Wheat on Nahar wala khet", taskReminderMessage(reminder))
+
+	reminder.DaysBefore = 0
+	assert.Equal(t, "Today: First irrigation for Wheat on Nahar wala khet", taskReminderMessage(reminder))
+}
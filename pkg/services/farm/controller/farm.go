@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/queue"
+	"kisaanSathi/pkg/services/farm/models"
+	recommendationModels "kisaanSathi/pkg/services/recommendation/models"
+
+	"gorm.io/gorm"
+)
+
+func (s *controller) ListFarms(ctx context.Context, userID int64) ([]models.Farm, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	farms, err := s.farmStore.ListFarms(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if farms == nil {
+		farms = []models.Farm{}
+	}
+	return farms, nil
+}
+
+func (s *controller) GetFarm(ctx context.Context, userID int64, farmID int64) (*models.Farm, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+	return s.farmStore.GetFarm(ctx, userID, farmID)
+}
+
+func (s *controller) CreateFarm(ctx context.Context, userID int64, request *models.FarmRequest) (*models.Farm, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	farm := &models.Farm{
+		UserID:         userID,
+		Name:           request.Name,
+		AreaAcres:      request.AreaAcres,
+		IrrigationType: request.IrrigationType,
+		SoilType:       request.SoilType,
+		Boundary:       request.Boundary,
+	}
+	if lat, lng, ok := request.Boundary.Centroid(); ok {
+		farm.Lat, farm.Lng = &lat, &lng
+	}
+	// the climate normals of the farm are loaded ahead of its first crop recommendation
+	err := s.farmStore.CreateFarm(ctx, farm, func(tx *gorm.DB) error {
+		if farm.Lat == nil || farm.Lng == nil {
+			return nil
+		}
+		_, err := queue.Enqueue(ctx, tx, recommendationModels.WarmClimate, recommendationModels.ClimateTask{Lat: *farm.Lat, Lng: *farm.Lng},
+			queue.Options{Priority: queue.PriorityLow})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return farm, nil
+}
+
+func (s *controller) UpdateFarm(ctx context.Context, userID int64, farmID int64, request *models.UpdateFarmRequest) (*models.Farm, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	updates := request.Updates()
+	if len(updates) == 0 {
+		return s.farmStore.GetFarm(ctx, userID, farmID)
+	}
+	return s.farmStore.UpdateFarm(ctx, userID, farmID, updates)
+}
+
+func (s *controller) DeleteFarm(ctx context.Context, userID int64, farmID int64) error {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+	return s.farmStore.DeleteFarm(ctx, userID, farmID)
+}
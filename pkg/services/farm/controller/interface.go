@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+	"kisaanSathi/pkg/services/farm/db"
+	"kisaanSathi/pkg/services/farm/models"
+	notification "kisaanSathi/pkg/services/notification/controller"
+	"time"
+)
+
+type controller struct {
+	farmStore  db.Store
+	notifier   notification.NotificationService
+	fertilizer models.FertilizerTable
+}
+
+type FarmController interface {
+	ListFarms(ctx context.Context, userID int64) ([]models.Farm, error)
+	GetFarm(ctx context.Context, userID int64, farmID int64) (*models.Farm, error)
+	CreateFarm(ctx context.Context, userID int64, request *models.FarmRequest) (*models.Farm, error)
+	UpdateFarm(ctx context.Context, userID int64, farmID int64, request *models.UpdateFarmRequest) (*models.Farm, error)
+	DeleteFarm(ctx context.Context, userID int64, farmID int64) error
+	ListCropCycles(ctx context.Context, userID int64, farmID int64) ([]models.CropCycle, error)
+	CreateCropCycle(ctx context.Context, userID int64, farmID int64, request *models.CropCycleRequest) (*models.CropCycle, error)
+	UpdateCropCycle(ctx context.Context, userID int64, farmID int64, cycleID int64, request *models.UpdateCropCycleRequest) error
+	GetCalendar(ctx context.Context, userID int64, farmID int64, request *models.CalendarRequest) (*models.Calendar, error)
+	CompleteTask(ctx context.Context, userID int64, farmID int64, taskID int64) error
+	// SendTaskReminders writes crop notifications for open tasks due on one of
+	// models.TaskReminderOffsets days after now. It is safe to call repeatedly.
+	SendTaskReminders(ctx context.Context, now time.Time) (int, error)
+	GetLedger(ctx context.Context, userID int64, farmID int64, cycleID int64) (*models.Ledger, error)
+	AddExpense(ctx context.Context, userID int64, farmID int64, cycleID int64, request *models.ExpenseRequest) (*models.Expense, error)
+	AddSale(ctx context.Context, userID int64, farmID int64, cycleID int64, request *models.SaleRequest) (*models.Sale, error)
+	DeleteExpense(ctx context.Context, userID int64, farmID int64, cycleID int64, expenseID int64) error
+	DeleteSale(ctx context.Context, userID int64, farmID int64, cycleID int64, saleID int64) error
+	CycleReport(ctx context.Context, userID int64, farmID int64, cycleID int64) (*models.Report, error)
+	SeasonReport(ctx context.Context, userID int64, request *models.SeasonReportRequest) (*models.Report, error)
+	ListSoilTests(ctx context.Context, userID int64, farmID int64) ([]models.SoilTest, error)
+	ImportSoilTests(ctx context.Context, userID int64, farmID int64, requests []models.SoilTestRequest) (*models.SoilTestUpload, error)
+	FertilizerPlan(ctx context.Context, userID int64, farmID int64, request *models.FertilizerRequest) (*models.FertilizerPlan, error)
+}
+
+// NewFarmController takes the fertilizer recommendation table keyed by lower case crop name,
+// models.DefaultFertilizerTable is used when it is empty
+func NewFarmController(farmStore db.Store, notifier notification.NotificationService, fertilizer models.FertilizerTable) FarmController {
+	if len(fertilizer) == 0 {
+		fertilizer = models.DefaultFertilizerTable
+	}
+	return &controller{
+		farmStore:  farmStore,
+		notifier:   notifier,
+		fertilizer: fertilizer,
+	}
+}
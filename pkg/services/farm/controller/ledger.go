@@ -0,0 +1,286 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/farm/db"
+	"kisaanSathi/pkg/services/farm/models"
+	"kisaanSathi/pkg/utils"
+	"time"
+)
+
+var ErrNoSalePrice = errors.New("pricePerQuintal is required when no mandi price is used")
+
+func (s *controller) GetLedger(ctx context.Context, userID int64, farmID int64, cycleID int64) (*models.Ledger, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	info, err := s.farmStore.GetCycleInfo(ctx, userID, farmID, cycleID)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := s.farmStore.ListExpenses(ctx, []int64{cycleID})
+	if err != nil {
+		return nil, err
+	}
+	sales, err := s.farmStore.ListSales(ctx, []int64{cycleID})
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := &models.Ledger{
+		CropCycleID: cycleID,
+		Expenses:    []models.Expense{},
+		Sales:       []models.Sale{},
+		Summary:     cycleTotal(info.YieldQuintals, expenses, sales),
+	}
+	for _, expense := range expenses {
+		expense.SpentOn = dateOf(expense.SpentOn)
+		ledger.Expenses = append(ledger.Expenses, expense)
+	}
+	for _, sale := range sales {
+		sale.SoldOn = dateOf(sale.SoldOn)
+		sale.Amount = utils.Round(sale.Amount, 2)
+		ledger.Sales = append(ledger.Sales, sale)
+	}
+	return ledger, nil
+}
+
+func (s *controller) AddExpense(ctx context.Context, userID int64, farmID int64, cycleID int64, request *models.ExpenseRequest) (*models.Expense, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	if _, err := s.farmStore.GetCycleInfo(ctx, userID, farmID, cycleID); err != nil {
+		return nil, err
+	}
+	spentOn, err := parseDate(request.SpentOn)
+	if err != nil {
+		return nil, err
+	}
+	expense := &models.Expense{
+		CropCycleID: cycleID,
+		Category:    request.Category,
+		Amount:      utils.Round(request.Amount, 2),
+		SpentOn:     spentOn,
+		Note:        request.Note,
+	}
+	if err := s.farmStore.AddExpense(ctx, expense); err != nil {
+		return nil, err
+	}
+	return expense, nil
+}
+
+// AddSale records a sale of the crop cycle's produce. With request.UseMandiPrice the sale is linked
+// to the mandi price of the crop on the day it was sold, which is the sale price unless one is given.
+func (s *controller) AddSale(ctx context.Context, userID int64, farmID int64, cycleID int64, request *models.SaleRequest) (*models.Sale, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	info, err := s.farmStore.GetCycleInfo(ctx, userID, farmID, cycleID)
+	if err != nil {
+		return nil, err
+	}
+	soldOn, err := parseDate(request.SoldOn)
+	if err != nil {
+		return nil, err
+	}
+	sale := &models.Sale{
+		CropCycleID:      cycleID,
+		QuantityQuintals: utils.Round(request.QuantityQuintals, 2),
+		PricePerQuintal:  utils.Round(request.PricePerQuintal, 2),
+		Buyer:            request.Buyer,
+		SoldOn:           soldOn,
+	}
+	if request.UseMandiPrice {
+		price, err := s.farmStore.FindMandiPrice(ctx, userID, info.Crop, request.Market, soldOn)
+		if err != nil {
+			return nil, err
+		}
+		sale.MandiPriceID = &price.ID
+		sale.MandiPrice = &price.Price
+		if sale.PricePerQuintal == 0 {
+			sale.PricePerQuintal = price.Price
+		}
+	}
+	if sale.PricePerQuintal <= 0 {
+		return nil, ErrNoSalePrice
+	}
+	sale.Amount = utils.Round(sale.QuantityQuintals*sale.PricePerQuintal, 2)
+
+	if err := s.farmStore.AddSale(ctx, sale); err != nil {
+		return nil, err
+	}
+	return sale, nil
+}
+
+func (s *controller) DeleteExpense(ctx context.Context, userID int64, farmID int64, cycleID int64, expenseID int64) error {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	if _, err := s.farmStore.GetCycleInfo(ctx, userID, farmID, cycleID); err != nil {
+		return err
+	}
+	return s.farmStore.DeleteEntry(ctx, cycleID, expenseID, false)
+}
+
+func (s *controller) DeleteSale(ctx context.Context, userID int64, farmID int64, cycleID int64, saleID int64) error {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	if _, err := s.farmStore.GetCycleInfo(ctx, userID, farmID, cycleID); err != nil {
+		return err
+	}
+	return s.farmStore.DeleteEntry(ctx, cycleID, saleID, true)
+}
+
+func (s *controller) CycleReport(ctx context.Context, userID int64, farmID int64, cycleID int64) (*models.Report, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	info, err := s.farmStore.GetCycleInfo(ctx, userID, farmID, cycleID)
+	if err != nil {
+		return nil, err
+	}
+	title := fmt.Sprintf("%s (%s %d) - %s", info.Crop, info.Season, info.SowingDate.Year(), info.FarmName)
+	return s.report(ctx, title, []models.CycleInfo{*info})
+}
+
+// SeasonReport is the P&L of every crop cycle of the user sown in the season of the year
+func (s *controller) SeasonReport(ctx context.Context, userID int64, request *models.SeasonReportRequest) (*models.Report, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	cycles, err := s.farmStore.ListSeasonCycles(ctx, userID, request.Season, request.Year)
+	if err != nil {
+		return nil, err
+	}
+	if len(cycles) == 0 {
+		return nil, db.ErrCropCycleNotFound
+	}
+	return s.report(ctx, fmt.Sprintf("%s %d", request.Season, request.Year), cycles)
+}
+
+func (s *controller) report(ctx context.Context, title string, cycles []models.CycleInfo) (*models.Report, error) {
+	ids := make([]int64, 0, len(cycles))
+	for _, cycle := range cycles {
+		ids = append(ids, cycle.CropCycleID)
+	}
+	expenses, err := s.farmStore.ListExpenses(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	sales, err := s.farmStore.ListSales(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return buildReport(title, time.Now(), cycles, expenses, sales), nil
+}
+
+func buildReport(title string, now time.Time, cycles []models.CycleInfo, expenses []models.Expense, sales []models.Sale) *models.Report {
+	expensesOf := make(map[int64][]models.Expense)
+	for _, expense := range expenses {
+		expensesOf[expense.CropCycleID] = append(expensesOf[expense.CropCycleID], expense)
+	}
+	salesOf := make(map[int64][]models.Sale)
+	for _, sale := range sales {
+		salesOf[sale.CropCycleID] = append(salesOf[sale.CropCycleID], sale)
+	}
+
+	report := &models.Report{Title: title, GeneratedAt: now, Cycles: make([]models.CycleReport, 0, len(cycles))}
+	var totals []models.CycleTotal
+	for _, cycle := range cycles {
+		cycle.SowingDate = dateOf(cycle.SowingDate)
+		total := cycleTotal(cycle.YieldQuintals, expensesOf[cycle.CropCycleID], salesOf[cycle.CropCycleID])
+		report.Cycles = append(report.Cycles, models.CycleReport{CycleInfo: cycle, Total: total})
+		totals = append(totals, total)
+	}
+	report.Total = sumTotals(totals)
+	return report
+}
+
+// cycleTotal is the P&L of one crop cycle. Yield defaults to the quantity sold when it was not recorded.
+func cycleTotal(yield *float64, expenses []models.Expense, sales []models.Sale) models.CycleTotal {
+	var total models.CycleTotal
+	byCategory := make(map[string]float64)
+	for _, expense := range expenses {
+		byCategory[expense.Category] += expense.Amount
+		total.TotalExpenses += expense.Amount
+	}
+	for _, sale := range sales {
+		total.Revenue += sale.QuantityQuintals * sale.PricePerQuintal
+		total.QuantitySold += sale.QuantityQuintals
+	}
+	if yield != nil {
+		total.YieldQuintals = *yield
+	}
+	total.Expenses = categoryTotals(byCategory)
+	return finishTotal(total)
+}
+
+// sumTotals adds up crop cycle totals and recomputes the per quintal figures over the sums
+func sumTotals(totals []models.CycleTotal) models.CycleTotal {
+	var sum models.CycleTotal
+	byCategory := make(map[string]float64)
+	for _, total := range totals {
+		for _, expense := range total.Expenses {
+			byCategory[expense.Category] += expense.Amount
+		}
+		sum.TotalExpenses += total.TotalExpenses
+		sum.Revenue += total.Revenue
+		sum.QuantitySold += total.QuantitySold
+		sum.YieldQuintals += total.YieldQuintals
+	}
+	sum.Expenses = categoryTotals(byCategory)
+	return finishTotal(sum)
+}
+
+// finishTotal derives profit, cost per quintal and break-even figures and rounds all amounts.
+//
+// The break-even price is what the unsold produce has to fetch per quintal to cover the expenses
+// not yet recovered; once everything is sold it is the cost per quintal. The break-even quantity
+// is how many quintals have to be sold at the average price to cover the expenses.
+func finishTotal(total models.CycleTotal) models.CycleTotal {
+	if total.YieldQuintals < total.QuantitySold {
+		total.YieldQuintals = total.QuantitySold
+	}
+	total.Profit = utils.Round(total.Revenue-total.TotalExpenses, 2)
+
+	if total.QuantitySold > 0 {
+		total.AveragePrice = rounded(total.Revenue / total.QuantitySold)
+		if total.TotalExpenses > 0 {
+			total.BreakEvenQuintals = rounded(total.TotalExpenses / (total.Revenue / total.QuantitySold))
+		}
+	}
+	if total.YieldQuintals > 0 && total.TotalExpenses > 0 {
+		total.CostPerQuintal = rounded(total.TotalExpenses / total.YieldQuintals)
+		if unsold := total.YieldQuintals - total.QuantitySold; unsold > 0 {
+			total.BreakEvenPrice = rounded(max(0, total.TotalExpenses-total.Revenue) / unsold)
+		} else {
+			total.BreakEvenPrice = total.CostPerQuintal
+		}
+	}
+
+	total.TotalExpenses = utils.Round(total.TotalExpenses, 2)
+	total.Revenue = utils.Round(total.Revenue, 2)
+	total.QuantitySold = utils.Round(total.QuantitySold, 2)
+	total.YieldQuintals = utils.Round(total.YieldQuintals, 2)
+	return total
+}
+
+// categoryTotals lists the expenses per category in the order of models.ExpenseCategories
+func categoryTotals(byCategory map[string]float64) []models.CategoryTotal {
+	totals := []models.CategoryTotal{}
+	for _, category := range models.ExpenseCategories {
+		if amount, ok := byCategory[category]; ok {
+			totals = append(totals, models.CategoryTotal{Category: category, Amount: utils.Round(amount, 2)})
+		}
+	}
+	return totals
+}
+
+func rounded(value float64) *float64 {
+	value = utils.Round(value, 2)
+	return &value
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"kisaanSathi/pkg/services/farm/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCycleTotal(t *testing.T) {
+	yield := 20.0
+	total := cycleTotal(&yield, []models.Expense{
+		{Category: "labour", Amount: 2500.333},
+		{Category: "seed", Amount: 1200.5},
+		{Category: "fertilizer", Amount: 3000},
+	}, []models.Sale{
+		{QuantityQuintals: 10, PricePerQuintal: 2275},
+		{QuantityQuintals: 5, PricePerQuintal: 2300},
+	})
+
+	assert.Equal(t, []models.CategoryTotal{
+		{Category: "seed", Amount: 1200.5},
+		{Category: "fertilizer", Amount: 3000},
+		{Category: "labour", Amount: 2500.33},
+	}, total.Expenses)
+	assert.Equal(t, 6700.83, total.TotalExpenses)
+	assert.Equal(t, 34250.0, total.Revenue)
+	assert.Equal(t, 27549.17, total.Profit)
+	assert.Equal(t, 15.0, total.QuantitySold)
+	assert.Equal(t, 2283.33, *total.AveragePrice)
+	assert.Equal(t, 335.04, *total.CostPerQuintal)
+	// the expenses are recovered already, the 5 unsold quintals break even at any price
+	assert.Equal(t, 0.0, *total.BreakEvenPrice)
+	assert.Equal(t, 2.93, *total.BreakEvenQuintals)
+}
+
+func TestCycleTotal_NothingSold(t *testing.T) {
+	total := cycleTotal(nil, []models.Expense{{Category: "seed", Amount: 1500}}, nil)
+
+	assert.Equal(t, -1500.0, total.Profit)
+	assert.Nil(t, total.AveragePrice)
+	assert.Nil(t, total.CostPerQuintal)
+	assert.Nil(t, total.BreakEvenPrice)
+
+	yield := 12.0
+	total = cycleTotal(&yield, []models.Expense{{Category: "seed", Amount: 1500}}, nil)
+	assert.Equal(t, 125.0, *total.CostPerQuintal)
+	assert.Equal(t, 125.0, *total.BreakEvenPrice)
+}
+
+func TestBuildReport_SeasonTotal(t *testing.T) {
+	yield := 20.0
+	report := buildReport("rabi 2025", time.Now(), []models.CycleInfo{
+		{CropCycleID: 1, Crop: "Wheat", YieldQuintals: &yield},
+		{CropCycleID: 2, Crop: "Potato"},
+	}, []models.Expense{
+		{CropCycleID: 1, Category: "seed", Amount: 2000},
+		{CropCycleID: 1, Category: "labour", Amount: 4000},
+		{CropCycleID: 2, Category: "diesel", Amount: 5000},
+	}, []models.Sale{
+		{CropCycleID: 1, QuantityQuintals: 15, PricePerQuintal: 2000},
+		{CropCycleID: 2, QuantityQuintals: 10, PricePerQuintal: 400},
+	})
+
+	assert.Len(t, report.Cycles, 2)
+	// without a recorded yield the quantity sold is the yield
+	assert.Equal(t, 10.0, report.Cycles[1].Total.YieldQuintals)
+	assert.Equal(t, 500.0, *report.Cycles[1].Total.BreakEvenPrice)
+
+	assert.Equal(t, 11000.0, report.Total.TotalExpenses)
+	assert.Equal(t, 34000.0, report.Total.Revenue)
+	assert.Equal(t, 23000.0, report.Total.Profit)
+	assert.Equal(t, 30.0, report.Total.YieldQuintals)
+	assert.Equal(t, 366.67, *report.Total.CostPerQuintal)
+	assert.Equal(t, []string{"seed", "labour", "diesel"}, []string{
+		report.Total.Expenses[0].Category, report.Total.Expenses[1].Category, report.Total.Expenses[2].Category})
+
+	data, contentType, err := ExportReport(report, models.FormatCSV)
+	assert.NoError(t, err)
+	assert.Equal(t, "text/csv; charset=utf-8", contentType)
+	assert.Contains(t, string(data), "TOTAL,,,,,30.00,25.00,1360.00,34000.00,11000.00,23000.00,366.67,0.00,8.09,2000.00")
+
+	data, contentType, err = ExportReport(report, models.FormatPDF)
+	assert.NoError(t, err)
+	assert.Equal(t, "application/pdf", contentType)
+	assert.Equal(t, "%PDF", string(data[:4]))
+}
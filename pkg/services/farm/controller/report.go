@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"kisaanSathi/pkg/services/farm/models"
+	"strconv"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// ExportReport renders the report as CSV or PDF and returns the content type
+func ExportReport(report *models.Report, format string) ([]byte, string, error) {
+	switch format {
+	case models.FormatCSV:
+		data, err := reportCSV(report)
+		return data, "text/csv; charset=utf-8", err
+	case models.FormatPDF:
+		data, err := reportPDF(report)
+		return data, "application/pdf", err
+	}
+	return nil, "", fmt.Errorf("unsupported report format [%s]", format)
+}
+
+// reportCSV writes one row per crop cycle and a total row, with a column per expense category
+func reportCSV(report *models.Report) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	header := []string{"crop_cycle_id", "farm", "crop", "season", "sowing_date", "yield_quintals", "quantity_sold",
+		"average_price", "revenue", "total_expenses", "profit", "cost_per_quintal", "break_even_price", "break_even_quintals"}
+	for _, category := range models.ExpenseCategories {
+		header = append(header, "expense_"+category)
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+	for _, cycle := range report.Cycles {
+		row := []string{strconv.FormatInt(cycle.CropCycleID, 10), cycle.FarmName, cycle.Crop, cycle.Season, cycle.SowingDate.Format("2006-01-02")}
+		if err := writer.Write(append(row, totalColumns(cycle.Total)...)); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Write(append([]string{"TOTAL", "", "", "", ""}, totalColumns(report.Total)...)); err != nil {
+		return nil, err
+	}
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
+
+func totalColumns(total models.CycleTotal) []string {
+	columns := []string{amount(total.YieldQuintals), amount(total.QuantitySold), optional(total.AveragePrice), amount(total.Revenue),
+		amount(total.TotalExpenses), amount(total.Profit), optional(total.CostPerQuintal), optional(total.BreakEvenPrice), optional(total.BreakEvenQuintals)}
+	byCategory := make(map[string]float64)
+	for _, expense := range total.Expenses {
+		byCategory[expense.Category] = expense.Amount
+	}
+	for _, category := range models.ExpenseCategories {
+		columns = append(columns, amount(byCategory[category]))
+	}
+	return columns
+}
+
+// reportPDF lays the report out on A4. The core fonts have no rupee sign or Devanagari glyphs,
+// so amounts are prefixed with "Rs." and text is translated to cp1252.
+func reportPDF(report *models.Report) ([]byte, error) {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle(tr(report.Title), false)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, tr("Profit & Loss: "+report.Title), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(0, 6, "Generated on "+report.GeneratedAt.Format("02/01/2006 15:04"), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	headers := []string{"Crop", "Farm", "Sown", "Yield (qtl)", "Sold (qtl)", "Revenue", "Expenses", "Profit", "Cost/qtl", "Break-even/qtl"}
+	widths := []float64{30, 45, 22, 22, 22, 28, 28, 28, 24, 28}
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(230, 240, 225)
+	for i, header := range headers {
+		pdf.CellFormat(widths[i], 7, header, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 9)
+	for _, cycle := range report.Cycles {
+		row := []string{tr(cycle.Crop), tr(cycle.FarmName), cycle.SowingDate.Format("02/01/2006")}
+		pdfRow(pdf, widths, append(row, pdfColumns(cycle.Total)...))
+	}
+	pdf.SetFont("Helvetica", "B", 9)
+	pdfRow(pdf, widths, append([]string{"Total", "", ""}, pdfColumns(report.Total)...))
+
+	pdf.Ln(6)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 8, "Expenses by category", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	for _, expense := range report.Total.Expenses {
+		pdf.CellFormat(45, 6, expense.Category, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(35, 6, rupees(expense.Amount), "1", 1, "R", false, 0, "")
+	}
+
+	var buffer bytes.Buffer
+	if err := pdf.Output(&buffer); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func pdfRow(pdf *fpdf.Fpdf, widths []float64, columns []string) {
+	for i, column := range columns {
+		align := "R"
+		if i < 3 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], 6, column, "1", 0, align, false, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+func pdfColumns(total models.CycleTotal) []string {
+	costPerQuintal, breakEven := "-", "-"
+	if total.CostPerQuintal != nil {
+		costPerQuintal = rupees(*total.CostPerQuintal)
+	}
+	if total.BreakEvenPrice != nil {
+		breakEven = rupees(*total.BreakEvenPrice)
+	}
+	return []string{amount(total.YieldQuintals), amount(total.QuantitySold), rupees(total.Revenue),
+		rupees(total.TotalExpenses), rupees(total.Profit), costPerQuintal, breakEven}
+}
+
+func amount(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+func optional(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return amount(*value)
+}
+
+func rupees(value float64) string {
+	return "Rs. " + amount(value)
+}
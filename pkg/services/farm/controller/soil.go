@@ -0,0 +1,209 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/farm/models"
+	"kisaanSathi/pkg/utils"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	hectaresPerAcre = 0.404686
+	// nutrient content of the straight fertilizers
+	ureaN              = 0.46
+	dapN               = 0.18
+	dapP2O5            = 0.46
+	mopK2O             = 0.60
+	ureaBag            = 45.0
+	dapBag             = 50.0
+	mopBag             = 50.0
+	soilTestValidYears = 3
+)
+
+var ErrUnknownCrop = errors.New("no fertilizer recommendation for crop")
+
+func (s *controller) ListSoilTests(ctx context.Context, userID int64, farmID int64) ([]models.SoilTest, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	if _, err := s.farmStore.GetFarm(ctx, userID, farmID); err != nil {
+		return nil, err
+	}
+	tests, err := s.farmStore.ListSoilTests(ctx, userID, farmID)
+	if err != nil {
+		return nil, err
+	}
+	if tests == nil {
+		tests = []models.SoilTest{}
+	}
+	for i := range tests {
+		tests[i].TestDate = dateOf(tests[i].TestDate)
+	}
+	return tests, nil
+}
+
+// ImportSoilTests stores soil tests entered by hand or uploaded from Soil Health Cards. The
+// requests are validated already; a test dated in the future rejects the whole batch.
+func (s *controller) ImportSoilTests(ctx context.Context, userID int64, farmID int64, requests []models.SoilTestRequest) (*models.SoilTestUpload, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	today := today(time.Now())
+	tests := make([]models.SoilTest, 0, len(requests))
+	var rowErrors []models.RowError
+	for i, request := range requests {
+		testDate, err := parseDate(request.TestDate)
+		if err != nil {
+			rowErrors = append(rowErrors, models.RowError{Row: i + 1, Error: err.Error()})
+			continue
+		}
+		if testDate.After(today) {
+			rowErrors = append(rowErrors, models.RowError{Row: i + 1, Error: "testDate is in the future"})
+			continue
+		}
+		test := models.SoilTest{
+			TestDate: testDate,
+			Lab:      strings.TrimSpace(request.Lab),
+			N:        utils.Round(request.N, 2),
+			P:        utils.Round(request.P, 2),
+			K:        utils.Round(request.K, 2),
+			PH:       utils.Round(request.PH, 2),
+			OC:       utils.Round(request.OC, 2),
+		}
+		if request.Micronutrients != nil {
+			test.Micronutrients = *request.Micronutrients
+		}
+		tests = append(tests, test)
+	}
+	if len(rowErrors) > 0 {
+		return nil, &models.UploadError{Rows: rowErrors}
+	}
+
+	if err := s.farmStore.CreateSoilTests(ctx, userID, farmID, tests); err != nil {
+		return nil, err
+	}
+	return &models.SoilTestUpload{Imported: len(tests), SoilTests: tests}, nil
+}
+
+// FertilizerPlan computes the urea, DAP and MOP doses for a target yield from the farm's soil test
+func (s *controller) FertilizerPlan(ctx context.Context, userID int64, farmID int64, request *models.FertilizerRequest) (*models.FertilizerPlan, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	farm, err := s.farmStore.GetFarm(ctx, userID, farmID)
+	if err != nil {
+		return nil, err
+	}
+	recommendation, ok := s.fertilizer[strings.ToLower(strings.TrimSpace(request.Crop))]
+	if !ok {
+		return nil, fmt.Errorf("%w [%s], supported crops are %s", ErrUnknownCrop, request.Crop, strings.Join(s.supportedCrops(), ", "))
+	}
+	test, err := s.farmStore.GetSoilTest(ctx, userID, farmID, request.SoilTestID)
+	if err != nil {
+		return nil, err
+	}
+	test.TestDate = dateOf(test.TestDate)
+
+	area := request.AreaAcres
+	if area == 0 {
+		area = farm.AreaAcres
+	}
+	plan := fertilizerPlan(recommendation, test, request.TargetYield, area, time.Now())
+	plan.Crop = request.Crop
+	return plan, nil
+}
+
+func (s *controller) supportedCrops() []string {
+	crops := make([]string, 0, len(s.fertilizer))
+	for crop := range s.fertilizer {
+		crops = append(crops, crop)
+	}
+	sort.Strings(crops)
+	return crops
+}
+
+// fertilizerPlan applies the targeted yield equations and converts the nutrients to products.
+// DAP covers all of the P2O5 and part of the N, urea the rest of the N and MOP the K2O.
+func fertilizerPlan(recommendation models.CropRecommendation, test *models.SoilTest, targetYield float64, areaAcres float64, now time.Time) *models.FertilizerPlan {
+	plan := &models.FertilizerPlan{TargetYield: targetYield, AreaAcres: areaAcres, SoilTest: test}
+
+	n, capped := dose(recommendation.N, targetYield, test.N)
+	if capped {
+		plan.Notes = append(plan.Notes, fmt.Sprintf("N dose capped at the maximum of %.0f kg/ha", recommendation.N.Max))
+	}
+	p2o5, capped := dose(recommendation.P, targetYield, test.P)
+	if capped {
+		plan.Notes = append(plan.Notes, fmt.Sprintf("P2O5 dose capped at the maximum of %.0f kg/ha", recommendation.P.Max))
+	}
+	k2o, capped := dose(recommendation.K, targetYield, test.K)
+	if capped {
+		plan.Notes = append(plan.Notes, fmt.Sprintf("K2O dose capped at the maximum of %.0f kg/ha", recommendation.K.Max))
+	}
+	plan.Nutrients = models.Nutrients{N: utils.Round(n, 2), P2O5: utils.Round(p2o5, 2), K2O: utils.Round(k2o, 2)}
+
+	dap := p2o5 / dapP2O5
+	urea := math.Max(0, n-dap*dapN) / ureaN
+	mop := k2o / mopK2O
+	hectares := areaAcres * hectaresPerAcre
+	plan.Products = []models.ProductDose{
+		productDose("Urea", urea, hectares, ureaBag),
+		productDose("DAP", dap, hectares, dapBag),
+		productDose("MOP", mop, hectares, mopBag),
+	}
+	plan.Notes = append(plan.Notes, soilNotes(test, now)...)
+	return plan
+}
+
+// dose evaluates a targeted yield equation, reporting whether it was capped at the maximum
+func dose(equation models.NutrientEquation, targetYield float64, soil float64) (float64, bool) {
+	value := math.Max(0, equation.Yield*targetYield-equation.Soil*soil)
+	if equation.Max > 0 && value > equation.Max {
+		return equation.Max, true
+	}
+	return value, false
+}
+
+func productDose(product string, kgPerHectare float64, hectares float64, bagKg float64) models.ProductDose {
+	kg := kgPerHectare * hectares
+	return models.ProductDose{
+		Product:      product,
+		KgPerHectare: utils.Round(kgPerHectare, 2),
+		Kg:           utils.Round(kg, 2),
+		Bags:         utils.Round(kg/bagKg, 1),
+		BagKg:        bagKg,
+	}
+}
+
+// soilNotes are the amendments the Soil Health Card values call for besides NPK
+func soilNotes(test *models.SoilTest, now time.Time) []string {
+	var notes []string
+	switch {
+	case test.PH < 5.5:
+		notes = append(notes, fmt.Sprintf("Soil is acidic (pH %.1f), apply lime as advised by the lab", test.PH))
+	case test.PH > 8.5:
+		notes = append(notes, fmt.Sprintf("Soil is alkaline (pH %.1f), apply gypsum as advised by the lab", test.PH))
+	}
+	if test.OC < 0.5 {
+		notes = append(notes, fmt.Sprintf("Organic carbon is low (%.2f%%), add 10 t/ha of FYM or compost", test.OC))
+	}
+	micro := test.Micronutrients
+	if micro.Zn != nil && *micro.Zn < 0.6 {
+		notes = append(notes, "Zinc is deficient, apply 25 kg/ha of zinc sulphate")
+	}
+	if micro.S != nil && *micro.S < 10 {
+		notes = append(notes, "Sulphur is deficient, apply 20 kg/ha of sulphur through gypsum or bentonite sulphur")
+	}
+	if micro.B != nil && *micro.B < 0.5 {
+		notes = append(notes, "Boron is deficient, apply 10 kg/ha of borax")
+	}
+	if test.TestDate.AddDate(soilTestValidYears, 0, 0).Before(now) {
+		notes = append(notes, fmt.Sprintf("The soil test is older than %d years, get the soil tested again", soilTestValidYears))
+	}
+	return notes
+}
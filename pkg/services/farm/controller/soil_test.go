@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"kisaanSathi/pkg/services/farm/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFertilizerPlan(t *testing.T) {
+	now := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	test := &models.SoilTest{TestDate: time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC), N: 280, P: 20, K: 250, PH: 7.2, OC: 0.62}
+
+	plan := fertilizerPlan(models.DefaultFertilizerTable["wheat"], test, 50, 2.5, now)
+
+	assert.Equal(t, models.Nutrients{N: 73.9, P2O5: 83.8, K2O: 78.5}, plan.Nutrients)
+	assert.Equal(t, []models.ProductDose{
+		{Product: "Urea", KgPerHectare: 89.37, Kg: 90.41, Bags: 2, BagKg: 45},
+		{Product: "DAP", KgPerHectare: 182.17, Kg: 184.31, Bags: 3.7, BagKg: 50},
+		{Product: "MOP", KgPerHectare: 130.83, Kg: 132.37, Bags: 2.6, BagKg: 50},
+	}, plan.Products)
+	assert.Empty(t, plan.Notes)
+}
+
+func TestFertilizerPlan_PoorSoil(t *testing.T) {
+	now := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	zinc := 0.4
+	test := &models.SoilTest{
+		TestDate:       time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+		N:              0,
+		P:              0,
+		K:              400,
+		PH:             8.8,
+		OC:             0.3,
+		Micronutrients: models.Micronutrients{Zn: &zinc},
+	}
+
+	plan := fertilizerPlan(models.DefaultFertilizerTable["wheat"], test, 60, 1, now)
+
+	// N and P2O5 hit the maximum dose, K is plentiful in the soil
+	assert.Equal(t, models.Nutrients{N: 200, P2O5: 100, K2O: 72.2}, plan.Nutrients)
+	assert.Equal(t, []string{
+		"N dose capped at the maximum of 200 kg/ha",
+		"P2O5 dose capped at the maximum of 100 kg/ha",
+		"Soil is alkaline (pH 8.8), apply gypsum as advised by the lab",
+		"Organic carbon is low (0.30%), add 10 t/ha of FYM or compost",
+		"Zinc is deficient, apply 25 kg/ha of zinc sulphate",
+		"The soil test is older than 3 years, get the soil tested again",
+	}, plan.Notes)
+}
@@ -0,0 +1,219 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/farm/models"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const cropCycleColumns = `c.id, c.farm_id, c.crop, COALESCE(c.variety, '') AS variety, c.season, c.sowing_date,
+		c.expected_harvest_date, c.yield_quintals, c.status, c.created_at`
+
+var (
+	ErrCropCycleNotFound = errors.New("crop cycle not found")
+	ErrTaskNotFound      = errors.New("task not found")
+)
+
+func (g *farmStore) ListCropCycles(c context.Context, userID int64, farmID int64) ([]models.CropCycle, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var cycles []models.CropCycle
+	query := `SELECT ` + cropCycleColumns + `
+		FROM kisan.crop_cycles c
+		JOIN kisan.farms f ON f.id = c.farm_id
+		WHERE c.farm_id = ? AND f.user_id = ?
+		ORDER BY c.sowing_date DESC, c.id DESC`
+	err := g.store.WithContext(c).Raw(query, farmID, userID).Scan(&cycles).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return cycles, nil
+}
+
+// ListTemplates returns the calendar template of a crop and season ordered by day offset
+func (g *farmStore) ListTemplates(c context.Context, crop string, season string) ([]models.CalendarTemplate, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var templates []models.CalendarTemplate
+	query := `SELECT id, crop, season, task_type, title, COALESCE(description, '') AS description, day_offset
+		FROM kisan.crop_calendar_templates
+		WHERE lower(crop) = lower(?) AND season = ?
+		ORDER BY day_offset, id`
+	err := g.store.WithContext(c).Raw(query, strings.TrimSpace(crop), season).Scan(&templates).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return templates, nil
+}
+
+// CreateCropCycle inserts the cycle and its task schedule in one transaction
+func (g *farmStore) CreateCropCycle(c context.Context, userID int64, cycle *models.CropCycle, tasks []models.CropTask) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	err := repo.Transaction(g.store.WithContext(c), func(tx *gorm.DB) error {
+		var owned int64
+		if err := tx.Raw(`SELECT COUNT(*) FROM kisan.farms WHERE id = ? AND user_id = ?`, cycle.FarmID, userID).Row().Scan(&owned); err != nil {
+			return err
+		}
+		if owned == 0 {
+			return ErrFarmNotFound
+		}
+
+		err := tx.Raw(`INSERT INTO kisan.crop_cycles (farm_id, crop, variety, season, sowing_date, expected_harvest_date)
+			VALUES (?, ?, NULLIF(?, ''), ?, ?::date, ?::date)
+			RETURNING id, status, created_at`,
+			cycle.FarmID, cycle.Crop, cycle.Variety, cycle.Season, cycle.SowingDate.Format("2006-01-02"), formatDate(cycle.ExpectedHarvestDate)).
+			Row().Scan(&cycle.ID, &cycle.Status, &cycle.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		for i := range tasks {
+			tasks[i].CropCycleID = cycle.ID
+			err := tx.Raw(`INSERT INTO kisan.crop_tasks (crop_cycle_id, task_type, title, description, due_date)
+				VALUES (?, ?, ?, NULLIF(?, ''), ?::date) RETURNING id`,
+				cycle.ID, tasks[i].TaskType, tasks[i].Title, tasks[i].Description, tasks[i].DueDate.Format("2006-01-02")).
+				Row().Scan(&tasks[i].ID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrFarmNotFound) {
+		logger.Log(c).Error("Error creating crop cycle", zap.Error(err))
+	}
+	return err
+}
+
+func (g *farmStore) UpdateCropCycle(c context.Context, userID int64, farmID int64, cycleID int64, updates map[string]interface{}) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	result := g.store.WithContext(c).Table("kisan.crop_cycles").
+		Where("id = ? AND farm_id = ? AND farm_id IN (SELECT id FROM kisan.farms WHERE user_id = ?)", cycleID, farmID, userID).
+		Updates(updates)
+	if result.Error != nil {
+		logger.Log(c).Error("Error updating crop cycle", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCropCycleNotFound
+	}
+	return nil
+}
+
+// ListOpenTasks returns the incomplete tasks of the farm's active crop cycles due on or before until
+func (g *farmStore) ListOpenTasks(c context.Context, userID int64, farmID int64, until time.Time) ([]models.CropTask, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var tasks []models.CropTask
+	query := `SELECT t.id, t.crop_cycle_id, c.crop, t.task_type, t.title, COALESCE(t.description, '') AS description,
+			t.due_date, t.completed_at
+		FROM kisan.crop_tasks t
+		JOIN kisan.crop_cycles c ON c.id = t.crop_cycle_id
+		JOIN kisan.farms f ON f.id = c.farm_id
+		WHERE c.farm_id = ? AND f.user_id = ? AND c.status = 'active'
+		AND t.completed_at IS NULL AND t.due_date <= ?::date
+		ORDER BY t.due_date, t.id`
+	err := g.store.WithContext(c).Raw(query, farmID, userID, until.Format("2006-01-02")).Scan(&tasks).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (g *farmStore) CompleteTask(c context.Context, userID int64, farmID int64, taskID int64) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	result := g.store.WithContext(c).Exec(`UPDATE kisan.crop_tasks t SET completed_at = COALESCE(t.completed_at, now())
+		FROM kisan.crop_cycles c, kisan.farms f
+		WHERE c.id = t.crop_cycle_id AND f.id = c.farm_id AND t.id = ? AND c.farm_id = ? AND f.user_id = ?`, taskID, farmID, userID)
+	if result.Error != nil {
+		logger.Log(c).Error("Error completing task", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+// GetDueTaskReminders returns the open tasks of active crop cycles due offset days after day
+// which have not been reminded for that offset yet
+func (g *farmStore) GetDueTaskReminders(c context.Context, day time.Time, offsets []int) ([]models.DueTaskReminder, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var reminders []models.DueTaskReminder
+	query := `SELECT t.id AS task_id, f.user_id, f.name AS farm_name, c.crop, t.title, t.due_date, d.days_before
+		FROM kisan.crop_tasks t
+		CROSS JOIN unnest(?::int[]) AS d(days_before)
+		JOIN kisan.crop_cycles c ON c.id = t.crop_cycle_id
+		JOIN kisan.farms f ON f.id = c.farm_id
+		WHERE c.status = 'active' AND t.completed_at IS NULL
+		AND t.due_date = ?::date + d.days_before
+		AND NOT EXISTS (
+			SELECT 1 FROM kisan.crop_task_reminders r
+			WHERE r.task_id = t.id AND r.days_before = d.days_before
+		)`
+
+	err := g.store.WithContext(c).Raw(query, repo.IntArray(offsets), day.Format("2006-01-02")).Scan(&reminders).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return reminders, nil
+}
+
+// RecordTaskReminder marks the reminder as sent and calls notify with the same transaction.
+// It returns false without calling notify if the reminder was already sent.
+func (g *farmStore) RecordTaskReminder(c context.Context, reminder models.DueTaskReminder, notify func(tx *gorm.DB) error) (bool, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	sent := false
+	err := repo.Transaction(g.store.WithContext(c), func(tx *gorm.DB) error {
+		result := tx.Exec(`INSERT INTO kisan.crop_task_reminders (task_id, days_before) VALUES (?, ?)
+			ON CONFLICT (task_id, days_before) DO NOTHING`, reminder.TaskID, reminder.DaysBefore)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := notify(tx); err != nil {
+			return err
+		}
+		sent = true
+		return nil
+	})
+	if err != nil {
+		logger.Log(c).Error("Task reminder - ", zap.String("Error", err.Error()))
+		return false, err
+	}
+	return sent, nil
+}
+
+func formatDate(date *time.Time) *string {
+	if date == nil {
+		return nil
+	}
+	formatted := date.Format("2006-01-02")
+	return &formatted
+}
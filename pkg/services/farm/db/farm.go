@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/farm/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const farmColumns = `id, user_id, name, area_acres, irrigation_type, soil_type, boundary, lat, lng, created_at, updated_at`
+
+// ErrFarmNotFound is also returned for farms of other users
+var ErrFarmNotFound = errors.New("farm not found")
+
+func (g *farmStore) ListFarms(c context.Context, userID int64) ([]models.Farm, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var farms []models.Farm
+	err := g.store.WithContext(c).Raw(`SELECT `+farmColumns+` FROM kisan.farms WHERE user_id = ? ORDER BY id`, userID).
+		Scan(&farms).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return farms, nil
+}
+
+func (g *farmStore) GetFarm(c context.Context, userID int64, farmID int64) (*models.Farm, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var farms []models.Farm
+	err := g.store.WithContext(c).Raw(`SELECT `+farmColumns+` FROM kisan.farms WHERE id = ? AND user_id = ?`, farmID, userID).
+		Scan(&farms).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	if len(farms) == 0 {
+		return nil, ErrFarmNotFound
+	}
+	return &farms[0], nil
+}
+
+func (g *farmStore) CreateFarm(c context.Context, farm *models.Farm, enqueue func(tx *gorm.DB) error) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	err := repo.Transaction(g.store.WithContext(c), func(tx *gorm.DB) error {
+		err := tx.Raw(`INSERT INTO kisan.farms (user_id, name, area_acres, irrigation_type, soil_type, boundary, lat, lng)
+			VALUES (?, ?, ?, ?, ?, ?::jsonb, ?, ?)
+			RETURNING id, created_at, updated_at`,
+			farm.UserID, farm.Name, farm.AreaAcres, farm.IrrigationType, farm.SoilType, farm.Boundary, farm.Lat, farm.Lng).
+			Row().Scan(&farm.ID, &farm.CreatedAt, &farm.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		return enqueue(tx)
+	})
+	if err != nil {
+		logger.Log(c).Error("Error inserting farm", zap.Error(err))
+	}
+	return err
+}
+
+func (g *farmStore) UpdateFarm(c context.Context, userID int64, farmID int64, updates map[string]interface{}) (*models.Farm, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	result := g.store.WithContext(c).Table("kisan.farms").Where("id = ? AND user_id = ?", farmID, userID).
+		Updates(withUpdatedAt(updates))
+	if result.Error != nil {
+		logger.Log(c).Error("Error updating farm", zap.Error(result.Error))
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrFarmNotFound
+	}
+	return g.GetFarm(c, userID, farmID)
+}
+
+func (g *farmStore) DeleteFarm(c context.Context, userID int64, farmID int64) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	result := g.store.WithContext(c).Exec(`DELETE FROM kisan.farms WHERE id = ? AND user_id = ?`, farmID, userID)
+	if result.Error != nil {
+		logger.Log(c).Error("Error deleting farm", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrFarmNotFound
+	}
+	return nil
+}
+
+func withUpdatedAt(updates map[string]interface{}) map[string]interface{} {
+	withTimestamp := make(map[string]interface{}, len(updates)+1)
+	for column, value := range updates {
+		withTimestamp[column] = value
+	}
+	withTimestamp["updated_at"] = gorm.Expr("now()")
+	return withTimestamp
+}
@@ -0,0 +1,192 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/queue"
+	"kisaanSathi/pkg/services/farm/models"
+	"kisaanSathi/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type FarmSuite struct {
+	suite.Suite
+	ctx       context.Context
+	sqlDB     *sql.DB
+	gormDB    *gorm.DB
+	sqlMock   sqlmock.Sqlmock
+	farmStore Store
+}
+
+func TestFarmSuite(t *testing.T) {
+	suite.Run(t, new(FarmSuite))
+}
+
+func (suite *FarmSuite) SetupSuite() {
+	logger.LoggerInit("", -1)
+
+	suite.ctx = context.TODO()
+	suite.sqlDB, suite.gormDB, suite.sqlMock = utils.NewMockDB()
+	suite.farmStore = NewDBObject(suite.gormDB)
+}
+
+func (suite *FarmSuite) TearDownTest() {
+	suite.NoError(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *FarmSuite) TestGetFarm_ScansBoundary() {
+	// Mocking and Setting Expected Result
+	rows := sqlmock.NewRows([]string{"id", "user_id", "name", "area_acres", "irrigation_type", "soil_type", "boundary", "lat", "lng", "created_at", "updated_at"}).
+		AddRow(3, 1, "Nahar wala khet", 2.5, "canal", "Loamy",
+			`{"type":"Polygon","coordinates":[[[81.18,26.93],[81.19,26.93],[81.19,26.94],[81.18,26.93]]]}`,
+			26.933, 81.186, time.Now(), time.Now())
+	suite.sqlMock.ExpectQuery("^SELECT (.+) FROM kisan.farms WHERE id = (.+) AND user_id = (.+)$").
+		WithArgs(3, 1).
+		WillReturnRows(rows)
+
+	// Triggering Function
+	farm, err := suite.farmStore.GetFarm(suite.ctx, 1, 3)
+
+	// Validations
+	suite.NoError(err)
+	suite.Equal("Nahar wala khet", farm.Name)
+	suite.Equal("Polygon", farm.Boundary.Type)
+	suite.Len(farm.Boundary.Coordinates[0], 4)
+}
+
+func (suite *FarmSuite) TestGetFarm_OtherUsersFarm() {
+	// Mocking and Setting Expected Result
+	suite.sqlMock.ExpectQuery("^SELECT (.+) FROM kisan.farms WHERE id = (.+) AND user_id = (.+)$").
+		WithArgs(3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	// Triggering Function
+	farm, err := suite.farmStore.GetFarm(suite.ctx, 2, 3)
+
+	// Validations
+	suite.ErrorIs(err, ErrFarmNotFound)
+	suite.Nil(farm)
+}
+
+func (suite *FarmSuite) TestDeleteFarm_NotFound() {
+	// Mocking and Setting Expected Result
+	suite.sqlMock.ExpectExec("^DELETE FROM kisan.farms WHERE id = (.+) AND user_id = (.+)$").
+		WithArgs(3, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Triggering Function
+	err := suite.farmStore.DeleteFarm(suite.ctx, 2, 3)
+
+	// Validations
+	suite.ErrorIs(err, ErrFarmNotFound)
+}
+
+func (suite *FarmSuite) TestCentroid() {
+	polygon := &models.GeoPolygon{Type: "Polygon", Coordinates: [][][]float64{{{80, 20}, {82, 20}, {82, 22}, {80, 22}, {80, 20}}}}
+
+	lat, lng, ok := polygon.Centroid()
+
+	suite.True(ok)
+	suite.InDelta(21.0, lat, 1e-9)
+	suite.InDelta(81.0, lng, 1e-9)
+}
+
+func (suite *FarmSuite) TestRecordTaskReminder_AlreadySent() {
+	// Mocking and Setting Expected Result
+	suite.sqlMock.ExpectBegin()
+	suite.sqlMock.
+		ExpectExec("^INSERT INTO kisan.crop_task_reminders (.+) ON CONFLICT (.+) DO NOTHING$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlMock.ExpectCommit()
+
+	// Triggering Function
+	notified := false
+	sent, err := suite.farmStore.RecordTaskReminder(suite.ctx, models.DueTaskReminder{TaskID: 9, DaysBefore: 1}, func(tx *gorm.DB) error {
+		notified = true
+		return nil
+	})
+
+	// Validations
+	suite.NoError(err)
+	suite.False(sent)
+	suite.False(notified)
+}
+
+func (suite *FarmSuite) TestGetSoilTest_ScansMicronutrients() {
+	// Mocking and Setting Expected Result
+	rows := sqlmock.NewRows([]string{"id", "farm_id", "test_date", "lab", "n", "p", "k", "ph", "oc", "micronutrients", "created_at"}).
+		AddRow(5, 3, time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC), "KVK Barabanki", 280, 20, 250, 7.2, 0.62, `{"zn":0.4,"s":12}`, time.Now())
+	suite.sqlMock.ExpectQuery("^SELECT (.+) FROM kisan.soil_tests s JOIN kisan.farms f (.+) LIMIT 1$").
+		WithArgs(3, 1, 0, 0).
+		WillReturnRows(rows)
+
+	// Triggering Function
+	test, err := suite.farmStore.GetSoilTest(suite.ctx, 1, 3, 0)
+
+	// Validations
+	suite.NoError(err)
+	suite.Equal("KVK Barabanki", test.Lab)
+	suite.Equal(0.4, *test.Micronutrients.Zn)
+	suite.Nil(test.Micronutrients.B)
+}
+
+func (suite *FarmSuite) TestCreateSoilTests_OtherUsersFarm() {
+	// Mocking and Setting Expected Result
+	suite.sqlMock.ExpectBegin()
+	suite.sqlMock.ExpectQuery("^SELECT COUNT(.+) FROM kisan.farms WHERE id = (.+) AND user_id = (.+)$").
+		WithArgs(3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.sqlMock.ExpectRollback()
+
+	// Triggering Function
+	err := suite.farmStore.CreateSoilTests(suite.ctx, 2, 3, []models.SoilTest{{Lab: "KVK Barabanki", PH: 7.2}})
+
+	// Validations
+	suite.ErrorIs(err, ErrFarmNotFound)
+}
+
+func (suite *FarmSuite) TestCreateFarm_EnqueuesInTheSameTransaction() {
+	// Mocking and Setting Expected Result
+	suite.sqlMock.ExpectBegin()
+	suite.sqlMock.ExpectQuery("^INSERT INTO kisan.farms (.+) RETURNING id, created_at, updated_at$").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(9, time.Now(), time.Now()))
+	suite.sqlMock.ExpectQuery("^INSERT INTO kisan.tasks (.+) RETURNING id, status, created_at$").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at"}).AddRow(4, "pending", time.Now()))
+	suite.sqlMock.ExpectCommit()
+
+	// Triggering Function
+	farm := &models.Farm{UserID: 1, Name: "Nahar wala khet"}
+	err := suite.farmStore.CreateFarm(suite.ctx, farm, func(tx *gorm.DB) error {
+		_, err := queue.Enqueue(suite.ctx, tx, queue.Kind[map[string]int64]("test.farm-created"), map[string]int64{"farmId": farm.ID}, queue.Options{})
+		return err
+	})
+
+	// Validations
+	suite.NoError(err)
+	suite.Equal(int64(9), farm.ID)
+}
+
+func (suite *FarmSuite) TestCreateFarm_RollsBackWhenEnqueueFails() {
+	// Mocking and Setting Expected Result
+	suite.sqlMock.ExpectBegin()
+	suite.sqlMock.ExpectQuery("^INSERT INTO kisan.farms (.+)$").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(9, time.Now(), time.Now()))
+	suite.sqlMock.ExpectQuery("^INSERT INTO kisan.tasks (.+)$").
+		WillReturnError(sql.ErrConnDone)
+	suite.sqlMock.ExpectRollback()
+
+	// Triggering Function
+	err := suite.farmStore.CreateFarm(suite.ctx, &models.Farm{UserID: 1, Name: "Nahar wala khet"}, func(tx *gorm.DB) error {
+		_, err := queue.Enqueue(suite.ctx, tx, queue.Kind[string]("test.farm-created"), "", queue.Options{})
+		return err
+	})
+
+	// Validations
+	suite.ErrorIs(err, sql.ErrConnDone)
+}
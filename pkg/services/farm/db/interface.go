@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"kisaanSathi/pkg/services/farm/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type farmStore struct {
+	store *gorm.DB
+}
+
+type FarmStore interface {
+	ListFarms(ctx context.Context, userID int64) ([]models.Farm, error)
+	GetFarm(ctx context.Context, userID int64, farmID int64) (*models.Farm, error)
+	// CreateFarm inserts the farm and calls enqueue with the same transaction
+	CreateFarm(ctx context.Context, farm *models.Farm, enqueue func(tx *gorm.DB) error) error
+	UpdateFarm(ctx context.Context, userID int64, farmID int64, updates map[string]interface{}) (*models.Farm, error)
+	DeleteFarm(ctx context.Context, userID int64, farmID int64) error
+}
+
+type CropStore interface {
+	ListCropCycles(ctx context.Context, userID int64, farmID int64) ([]models.CropCycle, error)
+	ListTemplates(ctx context.Context, crop string, season string) ([]models.CalendarTemplate, error)
+	CreateCropCycle(ctx context.Context, userID int64, cycle *models.CropCycle, tasks []models.CropTask) error
+	UpdateCropCycle(ctx context.Context, userID int64, farmID int64, cycleID int64, updates map[string]interface{}) error
+	ListOpenTasks(ctx context.Context, userID int64, farmID int64, until time.Time) ([]models.CropTask, error)
+	CompleteTask(ctx context.Context, userID int64, farmID int64, taskID int64) error
+	GetDueTaskReminders(ctx context.Context, day time.Time, offsets []int) ([]models.DueTaskReminder, error)
+	RecordTaskReminder(ctx context.Context, reminder models.DueTaskReminder, notify func(tx *gorm.DB) error) (bool, error)
+}
+
+type LedgerStore interface {
+	GetCycleInfo(ctx context.Context, userID int64, farmID int64, cycleID int64) (*models.CycleInfo, error)
+	ListSeasonCycles(ctx context.Context, userID int64, season string, year int) ([]models.CycleInfo, error)
+	ListExpenses(ctx context.Context, cycleIDs []int64) ([]models.Expense, error)
+	ListSales(ctx context.Context, cycleIDs []int64) ([]models.Sale, error)
+	AddExpense(ctx context.Context, expense *models.Expense) error
+	AddSale(ctx context.Context, sale *models.Sale) error
+	DeleteEntry(ctx context.Context, cycleID int64, entryID int64, sale bool) error
+	FindMandiPrice(ctx context.Context, userID int64, crop string, market string, day time.Time) (*models.MandiPrice, error)
+}
+
+type SoilStore interface {
+	ListSoilTests(ctx context.Context, userID int64, farmID int64) ([]models.SoilTest, error)
+	GetSoilTest(ctx context.Context, userID int64, farmID int64, testID int64) (*models.SoilTest, error)
+	CreateSoilTests(ctx context.Context, userID int64, farmID int64, tests []models.SoilTest) error
+}
+
+type Store interface {
+	FarmStore
+	CropStore
+	LedgerStore
+	SoilStore
+}
+
+func NewDBObject(store *gorm.DB) Store {
+	return &farmStore{store: store}
+}
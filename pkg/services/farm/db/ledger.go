@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/farm/models"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const cycleInfoColumns = `c.id, c.farm_id, f.name AS farm_name, c.crop, c.season, c.sowing_date, c.yield_quintals`
+
+var (
+	ErrEntryNotFound      = errors.New("ledger entry not found")
+	ErrMandiPriceNotFound = errors.New("no mandi price recorded for the crop on that day")
+)
+
+func (g *farmStore) GetCycleInfo(c context.Context, userID int64, farmID int64, cycleID int64) (*models.CycleInfo, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var cycles []models.CycleInfo
+	query := `SELECT ` + cycleInfoColumns + `
+		FROM kisan.crop_cycles c
+		JOIN kisan.farms f ON f.id = c.farm_id
+		WHERE c.id = ? AND c.farm_id = ? AND f.user_id = ?`
+	err := g.store.WithContext(c).Raw(query, cycleID, farmID, userID).Scan(&cycles).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	if len(cycles) == 0 {
+		return nil, ErrCropCycleNotFound
+	}
+	return &cycles[0], nil
+}
+
+// ListSeasonCycles returns the user's crop cycles of a season sown in the given year
+func (g *farmStore) ListSeasonCycles(c context.Context, userID int64, season string, year int) ([]models.CycleInfo, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var cycles []models.CycleInfo
+	query := `SELECT ` + cycleInfoColumns + `
+		FROM kisan.crop_cycles c
+		JOIN kisan.farms f ON f.id = c.farm_id
+		WHERE f.user_id = ? AND c.season = ? AND EXTRACT(YEAR FROM c.sowing_date) = ? AND c.status <> 'abandoned'
+		ORDER BY c.sowing_date, c.id`
+	err := g.store.WithContext(c).Raw(query, userID, season, year).Scan(&cycles).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return cycles, nil
+}
+
+func (g *farmStore) ListExpenses(c context.Context, cycleIDs []int64) ([]models.Expense, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var expenses []models.Expense
+	if len(cycleIDs) == 0 {
+		return expenses, nil
+	}
+	err := g.store.WithContext(c).Raw(`SELECT id, crop_cycle_id, category, amount, spent_on, COALESCE(note, '') AS note
+		FROM kisan.ledger_expenses WHERE crop_cycle_id IN ? ORDER BY spent_on, id`, cycleIDs).Scan(&expenses).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return expenses, nil
+}
+
+func (g *farmStore) ListSales(c context.Context, cycleIDs []int64) ([]models.Sale, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var sales []models.Sale
+	if len(cycleIDs) == 0 {
+		return sales, nil
+	}
+	err := g.store.WithContext(c).Raw(`SELECT id, crop_cycle_id, quantity_quintals, price_per_quintal,
+			quantity_quintals * price_per_quintal AS amount, COALESCE(buyer, '') AS buyer, sold_on, mandi_price_id, mandi_price
+		FROM kisan.ledger_sales WHERE crop_cycle_id IN ? ORDER BY sold_on, id`, cycleIDs).Scan(&sales).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return sales, nil
+}
+
+func (g *farmStore) AddExpense(c context.Context, expense *models.Expense) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	err := g.store.WithContext(c).Raw(`INSERT INTO kisan.ledger_expenses (crop_cycle_id, category, amount, spent_on, note)
+		VALUES (?, ?, ?, ?::date, NULLIF(?, '')) RETURNING id`,
+		expense.CropCycleID, expense.Category, expense.Amount, expense.SpentOn.Format("2006-01-02"), expense.Note).
+		Row().Scan(&expense.ID)
+	if err != nil {
+		logger.Log(c).Error("Error inserting expense", zap.Error(err))
+	}
+	return err
+}
+
+func (g *farmStore) AddSale(c context.Context, sale *models.Sale) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	err := g.store.WithContext(c).Raw(`INSERT INTO kisan.ledger_sales (crop_cycle_id, quantity_quintals, price_per_quintal, buyer, sold_on, mandi_price_id, mandi_price)
+		VALUES (?, ?, ?, NULLIF(?, ''), ?::date, ?, ?) RETURNING id`,
+		sale.CropCycleID, sale.QuantityQuintals, sale.PricePerQuintal, sale.Buyer, sale.SoldOn.Format("2006-01-02"), sale.MandiPriceID, sale.MandiPrice).
+		Row().Scan(&sale.ID)
+	if err != nil {
+		logger.Log(c).Error("Error inserting sale", zap.Error(err))
+	}
+	return err
+}
+
+// DeleteEntry removes an expense or sale of the crop cycle; table is ledger_expenses or ledger_sales
+func (g *farmStore) DeleteEntry(c context.Context, cycleID int64, entryID int64, sale bool) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	table := "kisan.ledger_expenses"
+	if sale {
+		table = "kisan.ledger_sales"
+	}
+	result := g.store.WithContext(c).Exec(`DELETE FROM `+table+` WHERE id = ? AND crop_cycle_id = ?`, entryID, cycleID)
+	if result.Error != nil {
+		logger.Log(c).Error("Error deleting ledger entry", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEntryNotFound
+	}
+	return nil
+}
+
+// FindMandiPrice returns the crop's mandi price recorded on day. Without a market the price of the
+// user's district is preferred over other markets.
+func (g *farmStore) FindMandiPrice(c context.Context, userID int64, crop string, market string, day time.Time) (*models.MandiPrice, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var prices []models.MandiPrice
+	query := `SELECT id, crop, region, price, recorded_on
+		FROM kisan.mandi_prices
+		WHERE lower(crop) = lower(?) AND recorded_on = ?::date AND (? = '' OR lower(region) = lower(?))
+		ORDER BY lower(region) = lower(COALESCE((SELECT district FROM kisan.users WHERE id = ?), '')) DESC, id DESC
+		LIMIT 1`
+	err := g.store.WithContext(c).Raw(query, crop, day.Format("2006-01-02"), market, market, userID).Scan(&prices).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	if len(prices) == 0 {
+		return nil, ErrMandiPriceNotFound
+	}
+	return &prices[0], nil
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/farm/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const soilTestColumns = `s.id, s.farm_id, s.test_date, s.lab, s.n, s.p, s.k, s.ph, s.oc, s.micronutrients, s.created_at`
+
+var ErrSoilTestNotFound = errors.New("soil test not found")
+
+// ListSoilTests returns the soil tests of the farm, latest first
+func (g *farmStore) ListSoilTests(c context.Context, userID int64, farmID int64) ([]models.SoilTest, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var tests []models.SoilTest
+	err := g.store.WithContext(c).Raw(`SELECT `+soilTestColumns+`
+		FROM kisan.soil_tests s
+		JOIN kisan.farms f ON f.id = s.farm_id
+		WHERE s.farm_id = ? AND f.user_id = ?
+		ORDER BY s.test_date DESC, s.id DESC`, farmID, userID).Scan(&tests).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return tests, nil
+}
+
+// GetSoilTest returns a soil test of the farm, or the latest one when testID is 0
+func (g *farmStore) GetSoilTest(c context.Context, userID int64, farmID int64, testID int64) (*models.SoilTest, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var tests []models.SoilTest
+	err := g.store.WithContext(c).Raw(`SELECT `+soilTestColumns+`
+		FROM kisan.soil_tests s
+		JOIN kisan.farms f ON f.id = s.farm_id
+		WHERE s.farm_id = ? AND f.user_id = ? AND (? = 0 OR s.id = ?)
+		ORDER BY s.test_date DESC, s.id DESC
+		LIMIT 1`, farmID, userID, testID, testID).Scan(&tests).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	if len(tests) == 0 {
+		return nil, ErrSoilTestNotFound
+	}
+	return &tests[0], nil
+}
+
+// CreateSoilTests inserts all tests of an upload or none of them
+func (g *farmStore) CreateSoilTests(c context.Context, userID int64, farmID int64, tests []models.SoilTest) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	err := repo.Transaction(g.store.WithContext(c), func(tx *gorm.DB) error {
+		var owned int64
+		if err := tx.Raw(`SELECT COUNT(*) FROM kisan.farms WHERE id = ? AND user_id = ?`, farmID, userID).Row().Scan(&owned); err != nil {
+			return err
+		}
+		if owned == 0 {
+			return ErrFarmNotFound
+		}
+
+		for i := range tests {
+			tests[i].FarmID = farmID
+			err := tx.Raw(`INSERT INTO kisan.soil_tests (farm_id, test_date, lab, n, p, k, ph, oc, micronutrients)
+				VALUES (?, ?::date, ?, ?, ?, ?, ?, ?, ?::jsonb)
+				RETURNING id, created_at`,
+				farmID, tests[i].TestDate.Format("2006-01-02"), tests[i].Lab, tests[i].N, tests[i].P, tests[i].K,
+				tests[i].PH, tests[i].OC, tests[i].Micronutrients).
+				Row().Scan(&tests[i].ID, &tests[i].CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrFarmNotFound) {
+		logger.Log(c).Error("Error creating soil tests", zap.Error(err))
+	}
+	return err
+}
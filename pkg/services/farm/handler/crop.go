@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/network"
+	"kisaanSathi/pkg/services/farm/controller"
+	"kisaanSathi/pkg/services/farm/db"
+	"kisaanSathi/pkg/services/farm/models"
+	"kisaanSathi/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func (f *handler) ListCropCycles(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, ok := farmParams(c)
+	if !ok {
+		return
+	}
+
+	data, err := f.controller.ListCropCycles(c, userID, farmID)
+	if err != nil {
+		farmError(c, err, network.ApiErrors.GetDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) CreateCropCycle(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, ok := farmParams(c)
+	if !ok {
+		return
+	}
+	var request models.CropCycleRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.CreateCropCycle(c, userID, farmID, &request)
+	if err != nil {
+		if errors.Is(err, controller.ErrHarvestBeforeSowing) {
+			c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+			c.Abort()
+			return
+		}
+		farmError(c, err, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, network.SuccessResponse(data))
+}
+
+func (f *handler) UpdateCropCycle(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, ok := farmParams(c)
+	if !ok {
+		return
+	}
+	cycleID, err := utils.GetInt64Param(c, "cycleId")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, nil))
+		c.Abort()
+		return
+	}
+	var request models.UpdateCropCycleRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	if err := f.controller.UpdateCropCycle(c, userID, farmID, cycleID, &request); err != nil {
+		farmError(c, err, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse("crop cycle updated"))
+}
+
+func (f *handler) GetFarmCalendar(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, ok := farmParams(c)
+	if !ok {
+		return
+	}
+	var request models.CalendarRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.GetCalendar(c, userID, farmID, &request)
+	if err != nil {
+		farmError(c, err, network.ApiErrors.GetDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) CompleteFarmTask(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, ok := farmParams(c)
+	if !ok {
+		return
+	}
+	taskID, err := utils.GetInt64Param(c, "taskId")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, nil))
+		c.Abort()
+		return
+	}
+
+	if err := f.controller.CompleteTask(c, userID, farmID, taskID); err != nil {
+		farmError(c, err, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse("task completed"))
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, db.ErrFarmNotFound) || errors.Is(err, db.ErrCropCycleNotFound) || errors.Is(err, db.ErrTaskNotFound) ||
+		errors.Is(err, db.ErrEntryNotFound)
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/network"
+	"kisaanSathi/pkg/services/farm/models"
+	"kisaanSathi/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func (f *handler) ListFarms(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+
+	data, err := f.controller.ListFarms(c, userID)
+	if err != nil {
+		farmError(c, err, network.ApiErrors.GetDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) GetFarm(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, ok := farmParams(c)
+	if !ok {
+		return
+	}
+
+	data, err := f.controller.GetFarm(c, userID, farmID)
+	if err != nil {
+		farmError(c, err, network.ApiErrors.GetDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) CreateFarm(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	var request models.FarmRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.CreateFarm(c, userID, &request)
+	if err != nil {
+		farmError(c, err, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, network.SuccessResponse(data))
+}
+
+func (f *handler) UpdateFarm(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, ok := farmParams(c)
+	if !ok {
+		return
+	}
+	var request models.UpdateFarmRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.UpdateFarm(c, userID, farmID, &request)
+	if err != nil {
+		farmError(c, err, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) DeleteFarm(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, ok := farmParams(c)
+	if !ok {
+		return
+	}
+
+	if err := f.controller.DeleteFarm(c, userID, farmID); err != nil {
+		farmError(c, err, network.ApiErrors.DelDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse("farm deleted"))
+}
+
+func requireUser(c *gin.Context) (int64, bool) {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, network.FailureResponse(network.ApiErrors.Unauthorized.WithErrorDescription(err.Error())))
+		c.Abort()
+		return 0, false
+	}
+	return userID, true
+}
+
+func farmParams(c *gin.Context) (int64, int64, bool) {
+	userID, ok := requireUser(c)
+	if !ok {
+		return 0, 0, false
+	}
+	farmID, err := utils.GetInt64Param(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, nil))
+		c.Abort()
+		return 0, 0, false
+	}
+	return userID, farmID, true
+}
+
+func farmError(c *gin.Context, err error, dbError *network.Error) {
+	logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+	if isNotFound(err) {
+		c.JSON(http.StatusNotFound, network.FailureResponse(network.ApiErrors.NoDataFound.WithErrorDescription(err.Error())))
+	} else {
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(dbError.WithErrorDescription(err.Error())))
+	}
+	c.Abort()
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/farm/controller"
+	"kisaanSathi/pkg/services/farm/db"
+	"kisaanSathi/pkg/services/farm/models"
+	notification "kisaanSathi/pkg/services/notification/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+type handler struct {
+	controller controller.FarmController
+}
+
+type FarmHandler interface {
+	ListFarms(c *gin.Context)
+	GetFarm(c *gin.Context)
+	CreateFarm(c *gin.Context)
+	UpdateFarm(c *gin.Context)
+	DeleteFarm(c *gin.Context)
+	ListCropCycles(c *gin.Context)
+	CreateCropCycle(c *gin.Context)
+	UpdateCropCycle(c *gin.Context)
+	GetFarmCalendar(c *gin.Context)
+	CompleteFarmTask(c *gin.Context)
+	GetLedger(c *gin.Context)
+	AddExpense(c *gin.Context)
+	AddSale(c *gin.Context)
+	DeleteExpense(c *gin.Context)
+	DeleteSale(c *gin.Context)
+	GetCycleReport(c *gin.Context)
+	GetSeasonReport(c *gin.Context)
+	ListSoilTests(c *gin.Context)
+	AddSoilTest(c *gin.Context)
+	UploadSoilTests(c *gin.Context)
+	GetFertilizerPlan(c *gin.Context)
+}
+
+func NewFarmHandler(controller controller.FarmController) FarmHandler {
+	return &handler{
+		controller: controller,
+	}
+}
+
+func FarmController(repo repo.DataObject) controller.FarmController {
+	store := db.NewDBObject(repo.Databases.PgDB)
+	return controller.NewFarmController(store, notification.NotificationService(repo), fertilizerTable())
+}
+
+// fertilizerTable reads the recommendation table from farm.fertilizer.crops
+func fertilizerTable() models.FertilizerTable {
+	crops := config.App().Farm.Fertilizer.Crops
+	if len(crops) == 0 {
+		return nil
+	}
+	table := make(models.FertilizerTable, len(crops))
+	for name, crop := range crops {
+		table[name] = models.CropRecommendation{
+			N: models.NutrientEquation(crop.N),
+			P: models.NutrientEquation(crop.P),
+			K: models.NutrientEquation(crop.K),
+		}
+	}
+	return table
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/network"
+	"kisaanSathi/pkg/services/farm/controller"
+	"kisaanSathi/pkg/services/farm/db"
+	"kisaanSathi/pkg/services/farm/models"
+	"kisaanSathi/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func (f *handler) GetLedger(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, cycleID, ok := cycleParams(c)
+	if !ok {
+		return
+	}
+
+	data, err := f.controller.GetLedger(c, userID, farmID, cycleID)
+	if err != nil {
+		farmError(c, err, network.ApiErrors.GetDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) AddExpense(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, cycleID, ok := cycleParams(c)
+	if !ok {
+		return
+	}
+	var request models.ExpenseRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.AddExpense(c, userID, farmID, cycleID, &request)
+	if err != nil {
+		farmError(c, err, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, network.SuccessResponse(data))
+}
+
+func (f *handler) AddSale(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, cycleID, ok := cycleParams(c)
+	if !ok {
+		return
+	}
+	var request models.SaleRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.AddSale(c, userID, farmID, cycleID, &request)
+	if err != nil {
+		if errors.Is(err, db.ErrMandiPriceNotFound) || errors.Is(err, controller.ErrNoSalePrice) {
+			c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+			c.Abort()
+			return
+		}
+		farmError(c, err, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, network.SuccessResponse(data))
+}
+
+func (f *handler) DeleteExpense(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, cycleID, ok := cycleParams(c)
+	if !ok {
+		return
+	}
+	entryID, err := utils.GetInt64Param(c, "entryId")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, nil))
+		c.Abort()
+		return
+	}
+
+	if err := f.controller.DeleteExpense(c, userID, farmID, cycleID, entryID); err != nil {
+		farmError(c, err, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse("expense deleted"))
+}
+
+func (f *handler) DeleteSale(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, cycleID, ok := cycleParams(c)
+	if !ok {
+		return
+	}
+	entryID, err := utils.GetInt64Param(c, "entryId")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, nil))
+		c.Abort()
+		return
+	}
+
+	if err := f.controller.DeleteSale(c, userID, farmID, cycleID, entryID); err != nil {
+		farmError(c, err, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse("sale deleted"))
+}
+
+func (f *handler) GetCycleReport(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, cycleID, ok := cycleParams(c)
+	if !ok {
+		return
+	}
+	var request models.ReportRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.CycleReport(c, userID, farmID, cycleID)
+	if err != nil {
+		farmError(c, err, network.ApiErrors.GetDBError)
+		return
+	}
+
+	writeReport(c, data, request.Format, fmt.Sprintf("crop-cycle-%d", cycleID))
+}
+
+func (f *handler) GetSeasonReport(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	var request models.SeasonReportRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.SeasonReport(c, userID, &request)
+	if err != nil {
+		farmError(c, err, network.ApiErrors.GetDBError)
+		return
+	}
+
+	writeReport(c, data, request.Format, fmt.Sprintf("%s-%d", request.Season, request.Year))
+}
+
+// writeReport responds with the report as JSON or as a CSV/PDF attachment named after name
+func writeReport(c *gin.Context, report *models.Report, format string, name string) {
+	if format == "" || format == models.FormatJSON {
+		c.JSON(http.StatusOK, network.SuccessResponse(report))
+		return
+	}
+	data, contentType, err := controller.ExportReport(report, format)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.InternalServerError.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="ledger-%s.%s"`, name, format))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func cycleParams(c *gin.Context) (int64, int64, int64, bool) {
+	userID, farmID, ok := farmParams(c)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	cycleID, err := utils.GetInt64Param(c, "cycleId")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, nil))
+		c.Abort()
+		return 0, 0, 0, false
+	}
+	return userID, farmID, cycleID, true
+}
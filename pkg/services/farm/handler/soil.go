@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/network"
+	"kisaanSathi/pkg/services/farm/controller"
+	"kisaanSathi/pkg/services/farm/models"
+	"kisaanSathi/pkg/utils/validations"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.uber.org/zap"
+)
+
+// maxUploadBytes limits a Soil Health Card upload
+const maxUploadBytes = 1 << 20
+
+func (f *handler) ListSoilTests(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, ok := farmParams(c)
+	if !ok {
+		return
+	}
+
+	data, err := f.controller.ListSoilTests(c, userID, farmID)
+	if err != nil {
+		farmError(c, err, network.ApiErrors.GetDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) AddSoilTest(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, ok := farmParams(c)
+	if !ok {
+		return
+	}
+	var request models.SoilTestRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.ImportSoilTests(c, userID, farmID, []models.SoilTestRequest{request})
+	if err != nil {
+		soilError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, network.SuccessResponse(data.SoilTests[0]))
+}
+
+// UploadSoilTests imports Soil Health Card data as CSV or as a JSON array, either as the request
+// body or as the multipart form file "file". CSV columns are named like the JSON fields, e.g.
+// test_date,lab,n,p,k,ph,oc,zn,s with dates as DD/MM/YYYY.
+func (f *handler) UploadSoilTests(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, ok := farmParams(c)
+	if !ok {
+		return
+	}
+	requests, err := readSoilTests(c)
+	if err != nil {
+		logger.Log(c).Error("Invalid upload", zap.Error(err))
+		soilError(c, err)
+		return
+	}
+
+	data, err := f.controller.ImportSoilTests(c, userID, farmID, requests)
+	if err != nil {
+		soilError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, network.SuccessResponse(data))
+}
+
+func (f *handler) GetFertilizerPlan(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, farmID, ok := farmParams(c)
+	if !ok {
+		return
+	}
+	var request models.FertilizerRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.FertilizerPlan(c, userID, farmID, &request)
+	if err != nil {
+		if errors.Is(err, controller.ErrUnknownCrop) {
+			c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+			c.Abort()
+			return
+		}
+		farmError(c, err, network.ApiErrors.GetDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+// soilError answers an invalid upload with the rejected rows as data
+func soilError(c *gin.Context, err error) {
+	var uploadErr *models.UploadError
+	if errors.As(err, &uploadErr) {
+		response := network.BadRequestResponse(err, nil)
+		response.Data = uploadErr.Rows
+		c.JSON(http.StatusBadRequest, response)
+		c.Abort()
+		return
+	}
+	if errors.Is(err, errInvalidUpload) {
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, nil))
+		c.Abort()
+		return
+	}
+	farmError(c, err, network.ApiErrors.AddDBError)
+}
+
+var errInvalidUpload = errors.New("invalid soil test upload")
+
+func readSoilTests(c *gin.Context) ([]models.SoilTestRequest, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadBytes)
+
+	var body io.Reader = c.Request.Body
+	format := models.FormatJSON
+	if c.ContentType() == "text/csv" {
+		format = models.FormatCSV
+	}
+	if c.ContentType() == binding.MIMEMultipartPOSTForm {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errInvalidUpload, err.Error())
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		body = file
+		if strings.EqualFold(filepath.Ext(header.Filename), ".csv") {
+			format = models.FormatCSV
+		}
+	}
+
+	var (
+		requests []models.SoilTestRequest
+		err      error
+	)
+	if format == models.FormatCSV {
+		requests, err = parseSoilTestCSV(body)
+	} else if err = json.NewDecoder(body).Decode(&requests); err != nil {
+		err = fmt.Errorf("%w: %s", errInvalidUpload, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 || len(requests) > models.MaxSoilTestRows {
+		return nil, fmt.Errorf("%w: between 1 and %d soil tests are allowed", errInvalidUpload, models.MaxSoilTestRows)
+	}
+
+	var rowErrors []models.RowError
+	for i := range requests {
+		if err := binding.Validator.ValidateStruct(&requests[i]); err != nil {
+			rowErrors = append(rowErrors, models.RowError{Row: i + 1, Error: validations.GetCustomErrorMessages(err, requests[i])})
+		}
+	}
+	if len(rowErrors) > 0 {
+		return nil, &models.UploadError{Rows: rowErrors}
+	}
+	return requests, nil
+}
+
+// parseSoilTestCSV reads rows by header name; headers are matched ignoring case, spaces and underscores
+func parseSoilTestCSV(body io.Reader) ([]models.SoilTestRequest, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidUpload, err.Error())
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: empty csv", errInvalidUpload)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.NewReplacer("_", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(name)))] = i
+	}
+	for _, required := range []string{"testdate", "lab", "ph"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: csv column %s is missing", errInvalidUpload, required)
+		}
+	}
+
+	var requests []models.SoilTestRequest
+	var rowErrors []models.RowError
+	for i, record := range records[1:] {
+		cell := func(name string) string {
+			if index, ok := columns[name]; ok && index < len(record) {
+				return strings.TrimSpace(record[index])
+			}
+			return ""
+		}
+		var parseErr error
+		number := func(name string) *float64 {
+			value := cell(name)
+			if value == "" || parseErr != nil {
+				return nil
+			}
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				parseErr = fmt.Errorf("%s is not a number", name)
+				return nil
+			}
+			return &parsed
+		}
+		valueOf := func(value *float64) float64 {
+			if value == nil {
+				return 0
+			}
+			return *value
+		}
+
+		request := models.SoilTestRequest{
+			TestDate: cell("testdate"),
+			Lab:      cell("lab"),
+			N:        valueOf(number("n")),
+			P:        valueOf(number("p")),
+			K:        valueOf(number("k")),
+			PH:       valueOf(number("ph")),
+			OC:       valueOf(number("oc")),
+		}
+		micro := models.Micronutrients{S: number("s"), Zn: number("zn"), Fe: number("fe"), Mn: number("mn"), Cu: number("cu"), B: number("b")}
+		if micro != (models.Micronutrients{}) {
+			request.Micronutrients = &micro
+		}
+		if parseErr != nil {
+			rowErrors = append(rowErrors, models.RowError{Row: i + 1, Error: parseErr.Error()})
+			continue
+		}
+		requests = append(requests, request)
+	}
+	if len(rowErrors) > 0 {
+		return nil, &models.UploadError{Rows: rowErrors}
+	}
+	return requests, nil
+}
@@ -0,0 +1,111 @@
+package models
+
+import "time"
+
+// crop seasons
+const (
+	SeasonRabi   = "rabi"
+	SeasonKharif = "kharif"
+	SeasonZaid   = "zaid"
+)
+
+// crop cycle status
+const (
+	CycleActive    = "active"
+	CycleHarvested = "harvested"
+	CycleAbandoned = "abandoned"
+)
+
+const (
+	DefaultCalendarDays = 30
+	MaxCalendarDays     = 180
+)
+
+// TaskReminderOffsets are the days before a task's due date on which the farmer is reminded
+var TaskReminderOffsets = []int{1, 0}
+
+type CropCycle struct {
+	ID                  int64      `json:"id" gorm:"column:id"`
+	FarmID              int64      `json:"farmId" gorm:"column:farm_id"`
+	Crop                string     `json:"crop" gorm:"column:crop"`
+	Variety             string     `json:"variety,omitempty" gorm:"column:variety"`
+	Season              string     `json:"season" gorm:"column:season"`
+	SowingDate          time.Time  `json:"sowingDate" gorm:"column:sowing_date"`
+	ExpectedHarvestDate *time.Time `json:"expectedHarvestDate,omitempty" gorm:"column:expected_harvest_date"`
+	// YieldQuintals is the harvested quantity, recorded when the cycle is harvested
+	YieldQuintals *float64  `json:"yieldQuintals,omitempty" gorm:"column:yield_quintals"`
+	Status        string    `json:"status" gorm:"column:status"`
+	CreatedAt     time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+// CropCycleRequest takes dates as DD/MM/YYYY
+type CropCycleRequest struct {
+	Crop                string `json:"crop" binding:"required,max=50,placename"`
+	Variety             string `json:"variety" binding:"omitempty,max=50"`
+	Season              string `json:"season" binding:"required,oneof=rabi kharif zaid"`
+	SowingDate          string `json:"sowingDate" binding:"required,ddmmyyyy_1"`
+	ExpectedHarvestDate string `json:"expectedHarvestDate" binding:"omitempty,ddmmyyyy_1"`
+}
+
+type UpdateCropCycleRequest struct {
+	Status        string   `json:"status" binding:"omitempty,oneof=active harvested abandoned"`
+	YieldQuintals *float64 `json:"yieldQuintals" binding:"omitempty,gt=0"`
+}
+
+// Updates maps the fields present in the request to kisan.crop_cycles columns
+func (r *UpdateCropCycleRequest) Updates() map[string]interface{} {
+	updates := make(map[string]interface{})
+	if r.Status != "" {
+		updates["status"] = r.Status
+	}
+	if r.YieldQuintals != nil {
+		updates["yield_quintals"] = *r.YieldQuintals
+	}
+	return updates
+}
+
+// CalendarTemplate is one row of kisan.crop_calendar_templates: a task DayOffset days after sowing
+type CalendarTemplate struct {
+	ID          int64  `gorm:"column:id"`
+	Crop        string `gorm:"column:crop"`
+	Season      string `gorm:"column:season"`
+	TaskType    string `gorm:"column:task_type"`
+	Title       string `gorm:"column:title"`
+	Description string `gorm:"column:description"`
+	DayOffset   int    `gorm:"column:day_offset"`
+}
+
+type CropTask struct {
+	ID          int64      `json:"id" gorm:"column:id"`
+	CropCycleID int64      `json:"cropCycleId" gorm:"column:crop_cycle_id"`
+	Crop        string     `json:"crop" gorm:"column:crop"`
+	TaskType    string     `json:"taskType" gorm:"column:task_type"`
+	Title       string     `json:"title" gorm:"column:title"`
+	Description string     `json:"description,omitempty" gorm:"column:description"`
+	DueDate     time.Time  `json:"dueDate" gorm:"column:due_date"`
+	CompletedAt *time.Time `json:"completedAt,omitempty" gorm:"column:completed_at"`
+	Overdue     bool       `json:"overdue" gorm:"-"`
+}
+
+type CalendarRequest struct {
+	// Days ahead of today to include, overdue tasks are always included
+	Days int `form:"days" binding:"omitempty,min=1,max=180"`
+}
+
+type Calendar struct {
+	FarmID int64      `json:"farmId"`
+	From   time.Time  `json:"from"`
+	To     time.Time  `json:"to"`
+	Tasks  []CropTask `json:"tasks"`
+}
+
+// DueTaskReminder is a task due DaysBefore days from today on an active crop cycle
+type DueTaskReminder struct {
+	TaskID     int64     `gorm:"column:task_id"`
+	UserID     int64     `gorm:"column:user_id"`
+	FarmName   string    `gorm:"column:farm_name"`
+	Crop       string    `gorm:"column:crop"`
+	Title      string    `gorm:"column:title"`
+	DueDate    time.Time `gorm:"column:due_date"`
+	DaysBefore int       `gorm:"column:days_before"`
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// GeoPolygon is a GeoJSON polygon; positions are [lng, lat] and the first ring is the plot boundary
+type GeoPolygon struct {
+	Type        string        `json:"type" binding:"required,eq=Polygon"`
+	Coordinates [][][]float64 `json:"coordinates" binding:"required,geopolygon" error:"closed rings of at least four [lng, lat] positions"`
+}
+
+// Value stores the polygon in a JSONB column
+func (p GeoPolygon) Value() (driver.Value, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (p *GeoPolygon) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	default:
+		return fmt.Errorf("cannot scan %T into GeoPolygon", value)
+	}
+}
+
+// Centroid is the mean of the boundary's vertices, close enough for plots of a few acres
+func (p *GeoPolygon) Centroid() (lat float64, lng float64, ok bool) {
+	if p == nil || len(p.Coordinates) == 0 || len(p.Coordinates[0]) < 2 {
+		return 0, 0, false
+	}
+	// the last position repeats the first
+	ring := p.Coordinates[0][:len(p.Coordinates[0])-1]
+	for _, position := range ring {
+		lng += position[0]
+		lat += position[1]
+	}
+	return lat / float64(len(ring)), lng / float64(len(ring)), true
+}
+
+type Farm struct {
+	ID             int64       `json:"id" gorm:"column:id"`
+	UserID         int64       `json:"-" gorm:"column:user_id"`
+	Name           string      `json:"name" gorm:"column:name"`
+	AreaAcres      float64     `json:"areaAcres" gorm:"column:area_acres"`
+	IrrigationType string      `json:"irrigationType" gorm:"column:irrigation_type"`
+	SoilType       string      `json:"soilType" gorm:"column:soil_type"`
+	Boundary       *GeoPolygon `json:"boundary,omitempty" gorm:"column:boundary"`
+	// Lat and Lng are the centroid of the boundary
+	Lat       *float64  `json:"lat,omitempty" gorm:"column:lat"`
+	Lng       *float64  `json:"lng,omitempty" gorm:"column:lng"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+type FarmRequest struct {
+	Name           string      `json:"name" binding:"required,max=100"`
+	AreaAcres      float64     `json:"areaAcres" binding:"required,gt=0,lte=10000"`
+	IrrigationType string      `json:"irrigationType" binding:"required,irrigationtype"`
+	SoilType       string      `json:"soilType" binding:"required,soiltype"`
+	Boundary       *GeoPolygon `json:"boundary" binding:"omitempty"`
+}
+
+// UpdateFarmRequest is a partial update, only the fields present are changed
+type UpdateFarmRequest struct {
+	Name           *string     `json:"name" binding:"omitempty,max=100"`
+	AreaAcres      *float64    `json:"areaAcres" binding:"omitempty,gt=0,lte=10000"`
+	IrrigationType *string     `json:"irrigationType" binding:"omitempty,irrigationtype"`
+	SoilType       *string     `json:"soilType" binding:"omitempty,soiltype"`
+	Boundary       *GeoPolygon `json:"boundary" binding:"omitempty"`
+}
+
+// Updates maps the fields present in the request to kisan.farms columns
+func (r *UpdateFarmRequest) Updates() map[string]interface{} {
+	updates := make(map[string]interface{})
+	if r.Name != nil {
+		updates["name"] = *r.Name
+	}
+	if r.AreaAcres != nil {
+		updates["area_acres"] = *r.AreaAcres
+	}
+	if r.IrrigationType != nil {
+		updates["irrigation_type"] = *r.IrrigationType
+	}
+	if r.SoilType != nil {
+		updates["soil_type"] = *r.SoilType
+	}
+	if r.Boundary != nil {
+		updates["boundary"] = *r.Boundary
+		if lat, lng, ok := r.Boundary.Centroid(); ok {
+			updates["lat"] = lat
+			updates["lng"] = lng
+		}
+	}
+	return updates
+}
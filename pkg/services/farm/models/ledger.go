@@ -0,0 +1,125 @@
+package models
+
+import "time"
+
+// expense categories of the ledger
+var ExpenseCategories = []string{"seed", "fertilizer", "pesticide", "labour", "diesel", "irrigation", "machinery", "rent", "other"}
+
+// report formats
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatPDF  = "pdf"
+)
+
+type Expense struct {
+	ID          int64     `json:"id" gorm:"column:id"`
+	CropCycleID int64     `json:"cropCycleId" gorm:"column:crop_cycle_id"`
+	Category    string    `json:"category" gorm:"column:category"`
+	Amount      float64   `json:"amount" gorm:"column:amount"`
+	SpentOn     time.Time `json:"spentOn" gorm:"column:spent_on"`
+	Note        string    `json:"note,omitempty" gorm:"column:note"`
+}
+
+// ExpenseRequest takes dates as DD/MM/YYYY
+type ExpenseRequest struct {
+	Category string  `json:"category" binding:"required,oneof=seed fertilizer pesticide labour diesel irrigation machinery rent other"`
+	Amount   float64 `json:"amount" binding:"required,gt=0,lte=10000000"`
+	SpentOn  string  `json:"spentOn" binding:"required,ddmmyyyy_1"`
+	Note     string  `json:"note" binding:"omitempty,max=200"`
+}
+
+type Sale struct {
+	ID               int64     `json:"id" gorm:"column:id"`
+	CropCycleID      int64     `json:"cropCycleId" gorm:"column:crop_cycle_id"`
+	QuantityQuintals float64   `json:"quantityQuintals" gorm:"column:quantity_quintals"`
+	PricePerQuintal  float64   `json:"pricePerQuintal" gorm:"column:price_per_quintal"`
+	Amount           float64   `json:"amount" gorm:"column:amount"`
+	Buyer            string    `json:"buyer,omitempty" gorm:"column:buyer"`
+	SoldOn           time.Time `json:"soldOn" gorm:"column:sold_on"`
+	// MandiPriceID links the sale to the mandi price of the day it was sold
+	MandiPriceID *int64   `json:"mandiPriceId,omitempty" gorm:"column:mandi_price_id"`
+	MandiPrice   *float64 `json:"mandiPrice,omitempty" gorm:"column:mandi_price"`
+}
+
+// SaleRequest takes dates as DD/MM/YYYY. With UseMandiPrice the sale is linked to the mandi
+// price of the crop on SoldOn, which is also used as the price when PricePerQuintal is empty.
+type SaleRequest struct {
+	QuantityQuintals float64 `json:"quantityQuintals" binding:"required,gt=0,lte=100000"`
+	PricePerQuintal  float64 `json:"pricePerQuintal" binding:"required_without=UseMandiPrice,omitempty,gt=0,lte=1000000"`
+	Buyer            string  `json:"buyer" binding:"omitempty,max=100"`
+	SoldOn           string  `json:"soldOn" binding:"required,ddmmyyyy_1"`
+	UseMandiPrice    bool    `json:"useMandiPrice"`
+	// Market narrows the mandi price lookup, defaults to the farmer's district
+	Market string `json:"market" binding:"omitempty,max=100"`
+}
+
+// MandiPrice is a row of kisan.mandi_prices, prices are per quintal
+type MandiPrice struct {
+	ID         int64     `gorm:"column:id"`
+	Crop       string    `gorm:"column:crop"`
+	Region     string    `gorm:"column:region"`
+	Price      float64   `gorm:"column:price"`
+	RecordedOn time.Time `gorm:"column:recorded_on"`
+}
+
+type Ledger struct {
+	CropCycleID int64      `json:"cropCycleId"`
+	Expenses    []Expense  `json:"expenses"`
+	Sales       []Sale     `json:"sales"`
+	Summary     CycleTotal `json:"summary"`
+}
+
+type CategoryTotal struct {
+	Category string  `json:"category"`
+	Amount   float64 `json:"amount"`
+}
+
+// CycleTotal is the profit and loss of a crop cycle or, summed up, of a season
+type CycleTotal struct {
+	Expenses          []CategoryTotal `json:"expenses"`
+	TotalExpenses     float64         `json:"totalExpenses"`
+	Revenue           float64         `json:"revenue"`
+	Profit            float64         `json:"profit"`
+	QuantitySold      float64         `json:"quantitySold"`
+	YieldQuintals     float64         `json:"yieldQuintals"`
+	AveragePrice      *float64        `json:"averagePrice,omitempty"`
+	CostPerQuintal    *float64        `json:"costPerQuintal,omitempty"`
+	BreakEvenPrice    *float64        `json:"breakEvenPrice,omitempty"`
+	BreakEvenQuintals *float64        `json:"breakEvenQuintals,omitempty"`
+}
+
+// CycleInfo is a crop cycle with the farm it belongs to, as shown in reports
+type CycleInfo struct {
+	CropCycleID   int64     `json:"cropCycleId" gorm:"column:id"`
+	FarmID        int64     `json:"farmId" gorm:"column:farm_id"`
+	FarmName      string    `json:"farmName" gorm:"column:farm_name"`
+	Crop          string    `json:"crop" gorm:"column:crop"`
+	Season        string    `json:"season" gorm:"column:season"`
+	SowingDate    time.Time `json:"sowingDate" gorm:"column:sowing_date"`
+	YieldQuintals *float64  `json:"-" gorm:"column:yield_quintals"`
+}
+
+type CycleReport struct {
+	CycleInfo
+	Total CycleTotal `json:"total"`
+}
+
+// Report is the P&L of one crop cycle or of all crop cycles of a season
+type Report struct {
+	Title       string        `json:"title"`
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Cycles      []CycleReport `json:"cycles"`
+	Total       CycleTotal    `json:"total"`
+}
+
+type ReportRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=json csv pdf"`
+}
+
+// SeasonReportRequest selects the crop cycles sown in the season of a year, e.g. rabi 2025
+type SeasonReportRequest struct {
+	Season string `form:"season" binding:"required,oneof=rabi kharif zaid"`
+	Year   int    `form:"year" binding:"required,min=2000,max=2100"`
+	Format string `form:"format" binding:"omitempty,oneof=json csv pdf"`
+}
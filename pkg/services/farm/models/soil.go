@@ -0,0 +1,155 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MaxSoilTestRows caps the rows of one Soil Health Card upload
+const MaxSoilTestRows = 500
+
+// Micronutrients are the secondary and micro nutrients of a Soil Health Card in ppm
+type Micronutrients struct {
+	S  *float64 `json:"s,omitempty" binding:"omitempty,gte=0,lte=1000"`
+	Zn *float64 `json:"zn,omitempty" binding:"omitempty,gte=0,lte=1000"`
+	Fe *float64 `json:"fe,omitempty" binding:"omitempty,gte=0,lte=1000"`
+	Mn *float64 `json:"mn,omitempty" binding:"omitempty,gte=0,lte=1000"`
+	Cu *float64 `json:"cu,omitempty" binding:"omitempty,gte=0,lte=1000"`
+	B  *float64 `json:"b,omitempty" binding:"omitempty,gte=0,lte=1000"`
+}
+
+// Value stores the micronutrients in a JSONB column
+func (m Micronutrients) Value() (driver.Value, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (m *Micronutrients) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, m)
+	case string:
+		return json.Unmarshal([]byte(v), m)
+	default:
+		return fmt.Errorf("cannot scan %T into Micronutrients", value)
+	}
+}
+
+// SoilTest is one Soil Health Card of a farm. N, P and K are available nutrients in kg/ha
+// and OC is organic carbon in percent.
+type SoilTest struct {
+	ID             int64          `json:"id" gorm:"column:id"`
+	FarmID         int64          `json:"farmId" gorm:"column:farm_id"`
+	TestDate       time.Time      `json:"testDate" gorm:"column:test_date"`
+	Lab            string         `json:"lab" gorm:"column:lab"`
+	N              float64        `json:"n" gorm:"column:n"`
+	P              float64        `json:"p" gorm:"column:p"`
+	K              float64        `json:"k" gorm:"column:k"`
+	PH             float64        `json:"ph" gorm:"column:ph"`
+	OC             float64        `json:"oc" gorm:"column:oc"`
+	Micronutrients Micronutrients `json:"micronutrients" gorm:"column:micronutrients"`
+	CreatedAt      time.Time      `json:"createdAt" gorm:"column:created_at"`
+}
+
+// SoilTestRequest takes dates as DD/MM/YYYY; it is also one row of a CSV or JSON upload
+type SoilTestRequest struct {
+	TestDate       string          `json:"testDate" binding:"required,ddmmyyyy_1"`
+	Lab            string          `json:"lab" binding:"required,max=100"`
+	N              float64         `json:"n" binding:"gte=0,lte=2000"`
+	P              float64         `json:"p" binding:"gte=0,lte=500"`
+	K              float64         `json:"k" binding:"gte=0,lte=3000"`
+	PH             float64         `json:"ph" binding:"required,gt=0,lte=14"`
+	OC             float64         `json:"oc" binding:"gte=0,lte=10"`
+	Micronutrients *Micronutrients `json:"micronutrients" binding:"omitempty"`
+}
+
+// RowError is a rejected row of an upload, rows are counted from 1 without the CSV header
+type RowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// UploadError rejects a whole upload, listing every invalid row
+type UploadError struct {
+	Rows []RowError
+}
+
+func (e *UploadError) Error() string {
+	return fmt.Sprintf("%d invalid rows in upload, first at row %d: %s", len(e.Rows), e.Rows[0].Row, e.Rows[0].Error)
+}
+
+type SoilTestUpload struct {
+	Imported  int        `json:"imported"`
+	SoilTests []SoilTest `json:"soilTests"`
+}
+
+// NutrientEquation is a targeted yield (STCR) equation giving the fertilizer nutrient in kg/ha:
+//
+//	dose = Yield * target yield (q/ha) - Soil * soil test value (kg/ha)
+//
+// clamped between zero and Max.
+type NutrientEquation struct {
+	Yield float64 `json:"yield" mapstructure:"yield"`
+	Soil  float64 `json:"soil" mapstructure:"soil"`
+	Max   float64 `json:"max" mapstructure:"max"`
+}
+
+// CropRecommendation holds the equations for N, P2O5 and K2O of a crop
+type CropRecommendation struct {
+	N NutrientEquation `json:"n" mapstructure:"n"`
+	P NutrientEquation `json:"p" mapstructure:"p"`
+	K NutrientEquation `json:"k" mapstructure:"k"`
+}
+
+// FertilizerTable is keyed by lower case crop name
+type FertilizerTable map[string]CropRecommendation
+
+// DefaultFertilizerTable is used when farm.fertilizer.crops is not configured
+var DefaultFertilizerTable = FertilizerTable{
+	"wheat":   {N: NutrientEquation{4.39, 0.52, 200}, P: NutrientEquation{3.06, 3.46, 100}, K: NutrientEquation{2.67, 0.22, 80}},
+	"paddy":   {N: NutrientEquation{4.25, 0.45, 180}, P: NutrientEquation{2.89, 3.72, 90}, K: NutrientEquation{2.47, 0.21, 80}},
+	"mustard": {N: NutrientEquation{9.32, 0.74, 120}, P: NutrientEquation{6.76, 3.84, 80}, K: NutrientEquation{3.35, 0.17, 60}},
+	"maize":   {N: NutrientEquation{4.20, 0.40, 200}, P: NutrientEquation{2.50, 3.10, 100}, K: NutrientEquation{2.10, 0.18, 80}},
+}
+
+type FertilizerRequest struct {
+	Crop string `json:"crop" binding:"required,alphaws,max=50"`
+	// TargetYield is in quintals per hectare
+	TargetYield float64 `json:"targetYield" binding:"required,gt=0,lte=200"`
+	// SoilTestID defaults to the latest soil test of the farm
+	SoilTestID int64 `json:"soilTestId" binding:"omitempty,gt=0"`
+	// AreaAcres defaults to the area of the farm
+	AreaAcres float64 `json:"areaAcres" binding:"omitempty,gt=0,lte=10000"`
+}
+
+// Nutrients are kg of N, P2O5 and K2O per hectare
+type Nutrients struct {
+	N    float64 `json:"n"`
+	P2O5 float64 `json:"p2o5"`
+	K2O  float64 `json:"k2o"`
+}
+
+type ProductDose struct {
+	Product      string  `json:"product"`
+	KgPerHectare float64 `json:"kgPerHectare"`
+	Kg           float64 `json:"kg"`
+	Bags         float64 `json:"bags"`
+	BagKg        float64 `json:"bagKg"`
+}
+
+type FertilizerPlan struct {
+	Crop        string        `json:"crop"`
+	TargetYield float64       `json:"targetYield"`
+	AreaAcres   float64       `json:"areaAcres"`
+	SoilTest    *SoilTest     `json:"soilTest"`
+	Nutrients   Nutrients     `json:"nutrients"`
+	Products    []ProductDose `json:"products"`
+	Notes       []string      `json:"notes,omitempty"`
+}
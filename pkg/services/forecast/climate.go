@@ -0,0 +1,134 @@
+package forecast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const defaultArchiveURL = "https://archive-api.open-meteo.com/v1/archive"
+
+// ClimateNormals are the monthly means of a location over the past Years years,
+// index 0 is January. Rainfall is the mean monthly total in mm, Temperature the mean in °C.
+type ClimateNormals struct {
+	Years       int         `json:"years"`
+	Rainfall    [12]float64 `json:"rainfall"`
+	Temperature [12]float64 `json:"temperature"`
+}
+
+// ClimateProvider returns the historical rainfall and temperature of a location
+type ClimateProvider interface {
+	Normals(ctx context.Context, lat float64, lng float64) (*ClimateNormals, error)
+}
+
+type ClimateConfig struct {
+	URL string
+	// Years of history the normals are computed from
+	Years int
+	// Timeout in milliseconds
+	Timeout int64
+}
+
+type openMeteoClimate struct {
+	cfg  ClimateConfig
+	rest utils.RestCaller
+	now  func() time.Time
+}
+
+type archiveResponse struct {
+	Daily struct {
+		Time          []string   `json:"time"`
+		Precipitation []*float64 `json:"precipitation_sum"`
+		Temperature   []*float64 `json:"temperature_2m_mean"`
+	} `json:"daily"`
+	Reason string `json:"reason"`
+}
+
+// NewClimateProvider builds the open-meteo archive client from forecast.climate
+func NewClimateProvider() ClimateProvider {
+	c := config.App().Forecast.Climate
+	return NewOpenMeteoClimate(ClimateConfig{
+		URL:     c.URL,
+		Years:   c.Years,
+		Timeout: c.Timeout,
+	}, utils.GetRestCaller())
+}
+
+func NewOpenMeteoClimate(cfg ClimateConfig, rest utils.RestCaller) ClimateProvider {
+	if cfg.URL == "" {
+		cfg.URL = defaultArchiveURL
+	}
+	if cfg.Years <= 0 {
+		cfg.Years = 5
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10000
+	}
+	return &openMeteoClimate{cfg: cfg, rest: rest, now: time.Now}
+}
+
+// Normals fetches the daily history of the last full years and averages it per calendar month
+func (o *openMeteoClimate) Normals(ctx context.Context, lat float64, lng float64) (*ClimateNormals, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	end := time.Date(o.now().Year()-1, time.December, 31, 0, 0, 0, 0, time.UTC)
+	start := time.Date(end.Year()-o.cfg.Years+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	query := map[string]string{
+		"latitude":   strconv.FormatFloat(lat, 'f', 4, 64),
+		"longitude":  strconv.FormatFloat(lng, 'f', 4, 64),
+		"start_date": start.Format("2006-01-02"),
+		"end_date":   end.Format("2006-01-02"),
+		"daily":      "precipitation_sum,temperature_2m_mean",
+		"timezone":   "auto",
+	}
+	body, status, err := o.rest.InvokeResty(utils.BackgroundGinContext(ctx), http.MethodGet, o.cfg.URL, nil, nil, o.cfg.Timeout, nil, query, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("climate archive call failed: %w", err)
+	}
+	var response archiveResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("invalid climate archive response: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("climate archive failed with status %d: %s", status, response.Reason)
+	}
+	normals := monthlyNormals(response, o.cfg.Years)
+	logger.Log(ctx).Debug("climate normals", zap.Float64("lat", lat), zap.Float64("lng", lng), zap.Int("days", len(response.Daily.Time)))
+	return normals, nil
+}
+
+// monthlyNormals sums the daily rainfall per month and averages it over the years, missing days are skipped
+func monthlyNormals(response archiveResponse, years int) *ClimateNormals {
+	normals := &ClimateNormals{Years: years}
+	var temperatureDays [12]int
+	for i, day := range response.Daily.Time {
+		date, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			continue
+		}
+		month := int(date.Month()) - 1
+		if i < len(response.Daily.Precipitation) && response.Daily.Precipitation[i] != nil {
+			normals.Rainfall[month] += *response.Daily.Precipitation[i]
+		}
+		if i < len(response.Daily.Temperature) && response.Daily.Temperature[i] != nil {
+			normals.Temperature[month] += *response.Daily.Temperature[i]
+			temperatureDays[month]++
+		}
+	}
+	for month := range normals.Rainfall {
+		normals.Rainfall[month] = utils.Round(normals.Rainfall[month]/float64(years), 1)
+		if temperatureDays[month] > 0 {
+			normals.Temperature[month] = utils.Round(normals.Temperature[month]/float64(temperatureDays[month]), 1)
+		}
+	}
+	return normals
+}
@@ -0,0 +1,24 @@
+package forecast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonthlyNormals(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	var response archiveResponse
+	response.Daily.Time = []string{"2023-07-01", "2023-07-02", "2024-07-01", "2024-01-15"}
+	response.Daily.Precipitation = []*float64{value(40), value(20), value(60), nil}
+	response.Daily.Temperature = []*float64{value(30), value(31), nil, value(14.5)}
+
+	normals := monthlyNormals(response, 2)
+
+	assert.Equal(t, 2, normals.Years)
+	// July rained 60 mm in 2023 and 60 mm in 2024
+	assert.Equal(t, 60.0, normals.Rainfall[6])
+	assert.Equal(t, 30.5, normals.Temperature[6])
+	assert.Equal(t, 0.0, normals.Rainfall[0])
+	assert.Equal(t, 14.5, normals.Temperature[0])
+}
@@ -0,0 +1,79 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/repo/migrations"
+	mandi "kisaanSathi/pkg/services/mandi/db"
+	"time"
+)
+
+// DefaultMandiMaxAge is how old the latest mandi price may be before the ingest is reported stale
+const DefaultMandiMaxAge = 48 * time.Hour
+
+var errNotConnected = errors.New("not connected")
+
+func postgresCheck(databases repo.Databases) Check {
+	return Check{Name: "postgres", Probe: func(ctx context.Context) (string, error) {
+		return "", databases.Ping(ctx)
+	}}
+}
+
+func redisCheck(cache repo.RedisInterface) Check {
+	return Check{Name: "redis", Probe: func(ctx context.Context) (string, error) {
+		if cache == nil {
+			return "", errNotConnected
+		}
+		return "", cache.Ping(ctx)
+	}}
+}
+
+// migrationCheck is down while a migration of this build is not applied, and degraded when the
+// database is ahead, as during a rollback of the binary
+func migrationCheck(migrator *migrations.Migrator) Check {
+	return Check{Name: "migrations", Probe: func(ctx context.Context) (string, error) {
+		if migrator == nil {
+			return "", errNotConnected
+		}
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			return "", err
+		}
+		// the versions are not always applied in order, a gap below version is pending too
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return "", err
+		}
+		detail := fmt.Sprintf("version %d of %d", version, migrator.Latest())
+		switch {
+		case len(pending) > 0:
+			return detail, fmt.Errorf("%d pending migrations", len(pending))
+		case version > migrator.Latest():
+			return detail, fmt.Errorf("%w: %v", ErrDegraded, migrations.ErrUnknownVersion)
+		}
+		return detail, nil
+	}}
+}
+
+// mandiCheck reports the price ingest degraded when the latest price is older than maxAge
+func mandiCheck(store mandi.MandiStore, maxAge time.Duration, now func() time.Time) Check {
+	return Check{Name: "mandi-ingest", Probe: func(ctx context.Context) (string, error) {
+		if store == nil {
+			return "", errNotConnected
+		}
+		recordedOn, err := store.LastRecordedOn(ctx)
+		if err != nil {
+			return "", err
+		}
+		if recordedOn == nil {
+			return "", fmt.Errorf("%w: no prices ingested", ErrDegraded)
+		}
+		detail := "last price recorded on " + recordedOn.Format(time.DateOnly)
+		if age := now().Sub(*recordedOn); age > maxAge {
+			return detail, fmt.Errorf("%w: prices are %s old", ErrDegraded, age.Truncate(time.Hour))
+		}
+		return detail, nil
+	}}
+}
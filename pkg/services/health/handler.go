@@ -0,0 +1,26 @@
+package health
+
+import (
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/network"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetLiveness answers as long as the process serves requests, it checks no dependency
+func (h *healthHandler) GetLiveness(c *gin.Context) {
+	c.JSON(http.StatusOK, network.SuccessResponse(gin.H{"status": StatusUp}))
+}
+
+// GetReadiness reports each dependency with its latency, 503 while one of them is down
+func (h *healthHandler) GetReadiness(c *gin.Context) {
+	report := h.checker.Report(c)
+	if !report.Ready() {
+		logger.Log(c).Warn("not ready", zap.Any("report", report))
+		c.JSON(http.StatusServiceUnavailable, network.HttpResponse{Status: "failure", Data: report})
+		return
+	}
+	c.JSON(http.StatusOK, network.SuccessResponse(report))
+}
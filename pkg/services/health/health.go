@@ -0,0 +1,131 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// statuses of a component and of the whole report
+const (
+	StatusUp = "up"
+	// StatusDegraded is reported for a dependency that works with stale data, the service stays ready
+	StatusDegraded = "degraded"
+	StatusDown     = "down"
+)
+
+const (
+	DefaultCacheTTL = 5 * time.Second
+	DefaultTimeout  = 2 * time.Second
+)
+
+// ErrDegraded marks a probe failure that does not take the service out of rotation
+var ErrDegraded = errors.New("degraded")
+
+// Check probes one dependency. The probe returns a short detail on success; an error wrapping
+// ErrDegraded reports the component degraded, any other error reports it down.
+type Check struct {
+	Name  string
+	Probe func(ctx context.Context) (string, error)
+}
+
+type Component struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Detail    string  `json:"detail,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status     string      `json:"status"`
+	CheckedAt  time.Time   `json:"checkedAt"`
+	Components []Component `json:"components"`
+}
+
+// Ready reports whether the service should receive traffic
+func (r *Report) Ready() bool {
+	return r.Status != StatusDown
+}
+
+// Checker runs the checks and keeps the report for ttl, so that frequent probes from several
+// sources do not hammer the dependencies. Callers arriving while a run is in flight wait for it.
+type Checker struct {
+	checks  []Check
+	ttl     time.Duration
+	timeout time.Duration
+	now     func() time.Time
+
+	mu     sync.Mutex
+	report *Report
+}
+
+func NewChecker(ttl time.Duration, timeout time.Duration, checks ...Check) *Checker {
+	if ttl < 0 {
+		ttl = 0
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{checks: checks, ttl: ttl, timeout: timeout, now: time.Now}
+}
+
+// Report returns the cached report, running the checks when it is older than the ttl
+func (h *Checker) Report(ctx context.Context) Report {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.report != nil && h.now().Sub(h.report.CheckedAt) < h.ttl {
+		return *h.report
+	}
+	report := h.run(ctx)
+	h.report = &report
+	return report
+}
+
+// run probes every dependency in parallel, each bounded by the timeout
+func (h *Checker) run(ctx context.Context) Report {
+	report := Report{Status: StatusUp, CheckedAt: h.now(), Components: make([]Component, len(h.checks))}
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			report.Components[i] = h.probe(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, component := range report.Components {
+		switch {
+		case component.Status == StatusDown:
+			report.Status = StatusDown
+		case component.Status == StatusDegraded && report.Status == StatusUp:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+func (h *Checker) probe(ctx context.Context, check Check) Component {
+	// the probe outlives the request that triggered it, its result is shared with later callers
+	probeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := check.Probe(probeCtx)
+	component := Component{
+		Name:      check.Name,
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		Detail:    detail,
+	}
+	if err != nil {
+		component.Status = StatusDown
+		if errors.Is(err, ErrDegraded) {
+			component.Status = StatusDegraded
+		}
+		component.Error = err.Error()
+	}
+	return component
+}
@@ -0,0 +1,151 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/repo/migrations"
+	mandi "kisaanSathi/pkg/services/mandi/db"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func probe(detail string, err error, calls *int) func(context.Context) (string, error) {
+	return func(context.Context) (string, error) {
+		*calls++
+		return detail, err
+	}
+}
+
+func TestReport_Status(t *testing.T) {
+	var calls int
+	up := Check{Name: "postgres", Probe: probe("", nil, &calls)}
+	stale := Check{Name: "mandi-ingest", Probe: probe("", fmt.Errorf("%w: prices are 72h0m0s old", ErrDegraded), &calls)}
+	down := Check{Name: "redis", Probe: probe("", errors.New("connection refused"), &calls)}
+
+	report := NewChecker(0, time.Second, up).Report(context.Background())
+	assert.Equal(t, StatusUp, report.Status)
+	assert.True(t, report.Ready())
+
+	report = NewChecker(0, time.Second, up, stale).Report(context.Background())
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.True(t, report.Ready(), "stale prices keep the service ready")
+	assert.Equal(t, "degraded: prices are 72h0m0s old", report.Components[1].Error)
+
+	report = NewChecker(0, time.Second, up, stale, down).Report(context.Background())
+	assert.Equal(t, StatusDown, report.Status)
+	assert.False(t, report.Ready())
+	assert.Equal(t, []string{"postgres", "mandi-ingest", "redis"}, []string{report.Components[0].Name, report.Components[1].Name, report.Components[2].Name})
+	assert.Equal(t, "connection refused", report.Components[2].Error)
+}
+
+func TestChecker_CachesReport(t *testing.T) {
+	var calls int
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	checker := NewChecker(5*time.Second, time.Second, Check{Name: "postgres", Probe: probe("", nil, &calls)})
+	checker.now = func() time.Time { return now }
+
+	checker.Report(context.Background())
+	now = now.Add(4 * time.Second)
+	checker.Report(context.Background())
+	assert.Equal(t, 1, calls, "report is reused within the ttl")
+
+	now = now.Add(time.Second)
+	checker.Report(context.Background())
+	assert.Equal(t, 2, calls)
+}
+
+func TestChecker_ProbeTimeout(t *testing.T) {
+	slow := Check{Name: "redis", Probe: func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}}
+
+	report := NewChecker(0, 10*time.Millisecond, slow).Report(context.Background())
+	assert.Equal(t, StatusDown, report.Components[0].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Components[0].Error)
+}
+
+type fakeMandiStore struct {
+	mandi.MandiStore
+	recordedOn *time.Time
+}
+
+func (f fakeMandiStore) LastRecordedOn(context.Context) (*time.Time, error) {
+	return f.recordedOn, nil
+}
+
+func TestMandiCheck(t *testing.T) {
+	now := func() time.Time { return time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC) }
+	yesterday := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	lastWeek := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+
+	detail, err := mandiCheck(fakeMandiStore{recordedOn: &yesterday}, 48*time.Hour, now).Probe(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "last price recorded on 2026-10-18", detail)
+
+	detail, err = mandiCheck(fakeMandiStore{recordedOn: &lastWeek}, 48*time.Hour, now).Probe(context.Background())
+	assert.ErrorIs(t, err, ErrDegraded)
+	assert.Equal(t, "degraded: prices are 177h0m0s old", err.Error())
+	assert.Equal(t, "last price recorded on 2026-10-12", detail)
+
+	_, err = mandiCheck(fakeMandiStore{}, 48*time.Hour, now).Probe(context.Background())
+	assert.ErrorIs(t, err, ErrDegraded)
+
+	_, err = mandiCheck(nil, 48*time.Hour, now).Probe(context.Background())
+	assert.ErrorIs(t, err, errNotConnected)
+	assert.NotErrorIs(t, err, ErrDegraded)
+}
+
+func TestMigrationCheck(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	migrator, err := migrations.New(db)
+	require.NoError(t, err)
+	check := migrationCheck(migrator)
+	latest := migrator.Latest()
+
+	expect := func(applied ...int64) {
+		rows := sqlmock.NewRows([]string{"version"})
+		for _, version := range applied {
+			rows.AddRow(version)
+		}
+		mock.ExpectQuery(`SELECT to_regclass`).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery(`SELECT COALESCE\(MAX\(version\), 0\)`).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(applied[len(applied)-1]))
+		mock.ExpectQuery(`SELECT to_regclass`).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery(`SELECT version FROM public.schema_migrations`).WillReturnRows(rows)
+	}
+	all := func(skip int64, extra ...int64) []int64 {
+		var versions []int64
+		for version := int64(1); version <= latest; version++ {
+			if version != skip {
+				versions = append(versions, version)
+			}
+		}
+		return append(versions, extra...)
+	}
+
+	expect(all(0)...)
+	detail, err := check.Probe(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("version %d of %d", latest, latest), detail)
+
+	expect(all(2)...)
+	_, err = check.Probe(context.Background())
+	assert.EqualError(t, err, "1 pending migrations", "a gap is pending although the newest version is applied")
+
+	expect(1)
+	_, err = check.Probe(context.Background())
+	assert.EqualError(t, err, fmt.Sprintf("%d pending migrations", latest-1))
+
+	expect(all(0, latest+1)...)
+	_, err = check.Probe(context.Background())
+	assert.ErrorIs(t, err, ErrDegraded, "a database ahead of the build is degraded")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
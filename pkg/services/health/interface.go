@@ -0,0 +1,49 @@
+package health
+
+import (
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/repo/migrations"
+	mandi "kisaanSathi/pkg/services/mandi/db"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type healthHandler struct {
+	checker *Checker
+}
+
+type HealthHandler interface {
+	GetLiveness(c *gin.Context)
+	GetReadiness(c *gin.Context)
+}
+
+// NewHealthHandler checks postgres, redis, the schema version and the mandi ingest. The ttl,
+// probe timeout and ingest age come from health.cachettl, health.timeout and health.mandi.maxage.
+func NewHealthHandler(repo repo.DataObject) HealthHandler {
+	c := config.App().Health
+
+	var (
+		migrator *migrations.Migrator
+		store    mandi.MandiStore
+	)
+	if repo.Databases.PgDB != nil {
+		store = mandi.NewDBObject(repo.Databases.PgDB)
+		if sqlDB, err := repo.Databases.PgDB.DB(); err == nil {
+			migrator, err = migrations.New(sqlDB)
+			if err != nil {
+				logger.Log().Error("readiness cannot check the schema version", zap.Error(err))
+			}
+		}
+	}
+
+	return &healthHandler{checker: NewChecker(c.CacheTTL, c.Timeout,
+		postgresCheck(repo.Databases),
+		redisCheck(repo.Cache),
+		migrationCheck(migrator),
+		mandiCheck(store, c.Mandi.MaxAge, time.Now),
+	)}
+}
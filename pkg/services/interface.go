@@ -1,11 +1,20 @@
 package services
 
 import (
+	"kisaanSathi/pkg/jobs"
 	"kisaanSathi/pkg/network"
 	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/admin"
+	farm "kisaanSathi/pkg/services/farm/handler"
 	"kisaanSathi/pkg/services/feeds"
 	"kisaanSathi/pkg/services/forecast"
+	"kisaanSathi/pkg/services/health"
 	"kisaanSathi/pkg/services/mandi"
+	market "kisaanSathi/pkg/services/market/handler"
+	notification "kisaanSathi/pkg/services/notification/handler"
+	recommendation "kisaanSathi/pkg/services/recommendation/handler"
+	rental "kisaanSathi/pkg/services/rental/handler"
+	scheme "kisaanSathi/pkg/services/scheme/handler"
 	session "kisaanSathi/pkg/services/session/handler"
 	reg "kisaanSathi/pkg/services/user/handler"
 	"net/http"
@@ -14,29 +23,53 @@ import (
 )
 
 type serviceObject struct {
+	health.HealthHandler
 	session.SessionGroup
 	reg.RegisterHandler
 	feeds.FeedsHandler
 	forecast.ForecastHandler
 	mandi.MandiHandler
+	scheme.SchemeHandler
+	notification.NotificationHandler
+	farm.FarmHandler
+	recommendation.RecommendationHandler
+	market.MarketHandler
+	rental.RentalHandler
+	admin.AdminHandler
 }
 
 type ServiceLayer interface {
 	GetMFHealth(c *gin.Context)
+	health.HealthHandler
 	session.SessionGroup
 	reg.RegisterHandler
 	feeds.FeedsHandler
 	forecast.ForecastHandler
 	mandi.MandiHandler
+	scheme.SchemeHandler
+	notification.NotificationHandler
+	farm.FarmHandler
+	recommendation.RecommendationHandler
+	market.MarketHandler
+	rental.RentalHandler
+	admin.AdminHandler
 }
 
-func NewServiceObject(repo repo.DataObject) ServiceLayer {
+func NewServiceObject(repo repo.DataObject, scheduler *jobs.Scheduler) ServiceLayer {
 	return &serviceObject{
+		health.NewHealthHandler(repo),
 		session.NewSessionGroup(repo),
 		reg.NewRegisterHandler(reg.RegisterController(repo)),
 		feeds.NewFeedsHandler(repo),
 		forecast.NewForecastHandler(repo),
 		mandi.NewMandiHandler(repo),
+		scheme.NewSchemeHandler(scheme.SchemeController(repo)),
+		notification.NewNotificationHandler(notification.NotificationController(repo)),
+		farm.NewFarmHandler(farm.FarmController(repo)),
+		recommendation.NewRecommendationHandler(recommendation.RecommendationController(repo)),
+		market.NewMarketHandler(market.MarketController(repo)),
+		rental.NewRentalHandler(rental.RentalController(repo)),
+		admin.NewAdminHandler(scheduler),
 	}
 }
 
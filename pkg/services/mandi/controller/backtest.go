@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/mandi/forecaster"
+	"kisaanSathi/pkg/services/mandi/models"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultFolds is the number of rolling windows a backtest forecasts
+	DefaultFolds = 4
+	// MaxFolds and MaxHorizon bound the work of one backtest, every fold refits every model
+	MaxFolds   = 8
+	MaxHorizon = 30
+)
+
+// Backtest scores the in-process forecasters by MAPE on the recorded prices of the commodity,
+// so models can be compared before one is picked in mandi.forecast.model
+func (s *controller) Backtest(ctx context.Context, request *models.BacktestRequest) (*models.Backtest, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	backtest := &models.Backtest{Market: request.Market, Horizon: request.Horizon, Folds: request.Folds, GeneratedAt: s.now()}
+	if backtest.Horizon <= 0 {
+		backtest.Horizon = models.DefaultHorizon
+	}
+	if backtest.Folds <= 0 {
+		backtest.Folds = DefaultFolds
+	}
+	backtest.Horizon = min(backtest.Horizon, MaxHorizon)
+	backtest.Folds = min(backtest.Folds, MaxFolds)
+	names := request.Models
+	if len(names) == 0 {
+		names = forecaster.Models
+	}
+	candidates := make([]forecaster.PriceForecaster, 0, len(names))
+	for _, name := range names {
+		f, err := forecaster.New(name, s.cfg.Models)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, f)
+	}
+
+	since := s.now().AddDate(0, 0, -s.cfg.HistoryDays)
+	history, err := s.store.GetPriceHistory(ctx, request.Commodity, request.Market, since)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("%w [%s]", forecaster.ErrNoHistory, request.Commodity)
+	}
+	input := forecaster.Input{Commodity: request.Commodity, Market: request.Market, Horizon: backtest.Horizon, History: history}
+
+	result := models.CommodityBacktest{Commodity: request.Commodity}
+	var best float64
+	for i, f := range candidates {
+		score, err := forecaster.Backtest(ctx, f, input, backtest.Folds)
+		if err != nil {
+			if !errors.Is(err, forecaster.ErrShortHistory) {
+				logger.Log(ctx).Warn("backtest failed", zap.String("commodity", request.Commodity), zap.String("model", names[i]), zap.Error(err))
+			}
+			result.Scores = append(result.Scores, models.ModelScore{Model: names[i], Error: err.Error()})
+			continue
+		}
+		score.Model = names[i]
+		result.Scores = append(result.Scores, *score)
+		if result.Best == "" || score.MAPE < best {
+			result.Best, best = names[i], score.MAPE
+		}
+	}
+	backtest.Commodities = append(backtest.Commodities, result)
+	return backtest, nil
+}
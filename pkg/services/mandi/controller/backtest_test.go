@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"context"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/mandi/forecaster"
+	"kisaanSathi/pkg/services/mandi/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBacktest_ScoresEachModel(t *testing.T) {
+	logger.LoggerInit("", -1)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var history []models.PricePoint
+	for d := 0; d < 60; d++ {
+		// a weekly cycle that the moving average cannot follow
+		history = append(history, models.PricePoint{Date: start.AddDate(0, 0, d), Price: float64(2000 + 50*(d%7))})
+	}
+	store := &fakeStore{history: history}
+	c := NewMandiController(store, nil, nil, forecaster.NewSeasonalNaive(forecaster.AnnualPeriod), ForecastConfig{}).(*controller)
+	c.now = func() time.Time { return start.AddDate(0, 0, 60) }
+
+	backtest, err := c.Backtest(context.TODO(), &models.BacktestRequest{Commodity: "Wheat", Horizon: 7, Folds: 2, Models: []string{forecaster.ModelMovingAverage, forecaster.ModelHoltWinters}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, backtest.Folds)
+	assert.Len(t, backtest.Commodities, 1)
+	scores := backtest.Commodities[0].Scores
+	assert.Equal(t, forecaster.ModelMovingAverage, scores[0].Model)
+	assert.Equal(t, 14, scores[0].Points)
+	assert.Greater(t, scores[0].MAPE, 1.0)
+	assert.Less(t, scores[1].MAPE, 0.5)
+	assert.Equal(t, forecaster.ModelHoltWinters, backtest.Commodities[0].Best)
+}
+
+func TestBacktest_UnknownCommodity(t *testing.T) {
+	logger.LoggerInit("", -1)
+	c := NewMandiController(&fakeStore{}, nil, nil, forecaster.NewSeasonalNaive(forecaster.AnnualPeriod), ForecastConfig{})
+
+	_, err := c.Backtest(context.TODO(), &models.BacktestRequest{Commodity: "Saffron"})
+
+	assert.ErrorIs(t, err, forecaster.ErrNoHistory)
+}
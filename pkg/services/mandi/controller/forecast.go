@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/mandi/forecaster"
+	"kisaanSathi/pkg/services/mandi/models"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Forecast predicts the commodity's price for the next request.Horizon days. Forecasts are cached
+// so that the model service is asked at most once per cache period for the same series.
+func (s *controller) Forecast(ctx context.Context, request *models.ForecastRequest) (*models.Forecast, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	horizon := request.Horizon
+	if horizon <= 0 {
+		horizon = models.DefaultHorizon
+	}
+	key := forecastKey(request.Commodity, request.Market, horizon)
+	if s.cache != nil {
+		cached := &models.Forecast{}
+		if err := s.cache.GetValue(ctx, key, cached); err == nil && len(cached.Points) > 0 {
+			return cached, nil
+		}
+	}
+
+	now := s.now()
+	history, err := s.store.GetPriceHistory(ctx, request.Commodity, request.Market, now.AddDate(0, 0, -s.cfg.HistoryDays))
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("%w [%s]", forecaster.ErrNoHistory, request.Commodity)
+	}
+	input := forecaster.Input{Commodity: request.Commodity, Market: request.Market, Horizon: horizon, History: history}
+
+	var result *forecaster.Result
+	fallback := false
+	if s.primary != nil {
+		result, err = s.primary.Forecast(ctx, input)
+		if err != nil {
+			logger.Log(ctx).Warn("price model service unavailable, using the fallback forecaster", zap.Error(err))
+			fallback = true
+		}
+	}
+	if result == nil {
+		if result, err = s.fallback.Forecast(ctx, input); err != nil {
+			return nil, err
+		}
+	}
+
+	forecast := &models.Forecast{
+		Commodity:   request.Commodity,
+		Market:      request.Market,
+		Horizon:     horizon,
+		Model:       result.Model,
+		Fallback:    fallback,
+		LastPrice:   history[len(history)-1],
+		Points:      result.Points,
+		GeneratedAt: now,
+	}
+	if s.cache != nil {
+		// a fallback forecast is kept briefly so the model service is retried soon
+		ttl := s.cfg.CacheTTL
+		if fallback {
+			ttl = s.cfg.CacheTTL / 6
+		}
+		if err := s.cache.SetValue(ctx, key, forecast, int(ttl.Milliseconds()), false); err != nil {
+			logger.Log(ctx).Warn("failed to cache price forecast", zap.Error(err))
+		}
+	}
+	return forecast, nil
+}
+
+func forecastKey(commodity string, market string, horizon int) string {
+	return fmt.Sprintf("forecast:%s:%s:%d", strings.ToLower(commodity), strings.ToLower(market), horizon)
+}
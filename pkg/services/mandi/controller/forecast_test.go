@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/mandi/forecaster"
+	"kisaanSathi/pkg/services/mandi/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStore struct {
+	history []models.PricePoint
+	since   time.Time
+}
+
+func (f *fakeStore) GetPriceHistory(ctx context.Context, commodity string, market string, since time.Time) ([]models.PricePoint, error) {
+	f.since = since
+	return f.history, nil
+}
+
+func (f *fakeStore) LastRecordedOn(ctx context.Context) (*time.Time, error) {
+	return nil, nil
+}
+
+type failingForecaster struct{}
+
+func (failingForecaster) Forecast(ctx context.Context, input forecaster.Input) (*forecaster.Result, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestForecast_FallsBackWhenModelServiceFails(t *testing.T) {
+	logger.LoggerInit("", -1)
+	now := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	store := &fakeStore{history: []models.PricePoint{
+		{Date: time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC), Price: 2240},
+		{Date: time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC), Price: 2250},
+	}}
+	c := NewMandiController(store, nil, failingForecaster{}, forecaster.NewSeasonalNaive(forecaster.AnnualPeriod), ForecastConfig{HistoryDays: 30}).(*controller)
+	c.now = func() time.Time { return now }
+
+	forecast, err := c.Forecast(context.TODO(), &models.ForecastRequest{Commodity: "Wheat"})
+
+	assert.NoError(t, err)
+	assert.True(t, forecast.Fallback)
+	assert.Equal(t, "naive", forecast.Model)
+	assert.Equal(t, models.DefaultHorizon, forecast.Horizon)
+	assert.Len(t, forecast.Points, models.DefaultHorizon)
+	assert.Equal(t, 2250.0, forecast.LastPrice.Price)
+	assert.Equal(t, now.AddDate(0, 0, -30), store.since)
+}
+
+func TestForecast_NoHistory(t *testing.T) {
+	logger.LoggerInit("", -1)
+	c := NewMandiController(&fakeStore{}, nil, nil, forecaster.NewSeasonalNaive(forecaster.AnnualPeriod), ForecastConfig{})
+
+	_, err := c.Forecast(context.TODO(), &models.ForecastRequest{Commodity: "Saffron", Horizon: 7})
+
+	assert.ErrorIs(t, err, forecaster.ErrNoHistory)
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"context"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/mandi/db"
+	"kisaanSathi/pkg/services/mandi/forecaster"
+	"kisaanSathi/pkg/services/mandi/models"
+	"time"
+)
+
+type ForecastConfig struct {
+	// HistoryDays of prices are handed to the forecaster
+	HistoryDays int
+	// CacheTTL keeps a forecast of a commodity, market and horizon
+	CacheTTL time.Duration
+	// Models tunes the in-process forecasters compared by Backtest
+	Models forecaster.Config
+}
+
+type controller struct {
+	store    db.MandiStore
+	cache    repo.RedisInterface
+	primary  forecaster.PriceForecaster
+	fallback forecaster.PriceForecaster
+	cfg      ForecastConfig
+	now      func() time.Time
+}
+
+type MandiController interface {
+	Forecast(ctx context.Context, request *models.ForecastRequest) (*models.Forecast, error)
+	Backtest(ctx context.Context, request *models.BacktestRequest) (*models.Backtest, error)
+}
+
+// NewMandiController forecasts with primary, usually the model service, and falls back to
+// fallback when primary is nil or fails
+func NewMandiController(store db.MandiStore, cache repo.RedisInterface, primary forecaster.PriceForecaster, fallback forecaster.PriceForecaster, cfg ForecastConfig) MandiController {
+	if cfg.HistoryDays <= 0 {
+		cfg.HistoryDays = 3 * forecaster.AnnualPeriod
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = 6 * time.Hour
+	}
+	return &controller{
+		store:    store,
+		cache:    cache,
+		primary:  primary,
+		fallback: fallback,
+		cfg:      cfg,
+		now:      time.Now,
+	}
+}
@@ -0,0 +1,26 @@
+package db
+
+import (
+	"context"
+	"kisaanSathi/pkg/services/mandi/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type mandiStore struct {
+	store *gorm.DB
+}
+
+type MandiStore interface {
+	// GetPriceHistory returns the daily mean price of the commodity since the given day, oldest first
+	GetPriceHistory(ctx context.Context, commodity string, market string, since time.Time) ([]models.PricePoint, error)
+	// LastRecordedOn returns the day of the latest ingested price, nil when there is none
+	LastRecordedOn(ctx context.Context) (*time.Time, error)
+}
+
+func NewDBObject(db *gorm.DB) MandiStore {
+	return &mandiStore{
+		store: db,
+	}
+}
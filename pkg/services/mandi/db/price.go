@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/mandi/models"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func (g *mandiStore) GetPriceHistory(c context.Context, commodity string, market string, since time.Time) ([]models.PricePoint, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var points []models.PricePoint
+	err := g.store.WithContext(c).Raw(`SELECT recorded_on, AVG(price)::float8 AS price
+		FROM kisan.mandi_prices
+		WHERE lower(crop) = lower(?) AND (? = '' OR lower(region) = lower(?)) AND recorded_on >= ?::date
+		GROUP BY recorded_on
+		ORDER BY recorded_on`, commodity, market, market, since.Format("2006-01-02")).Scan(&points).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return points, nil
+}
+
+func (g *mandiStore) LastRecordedOn(c context.Context) (*time.Time, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var recordedOn *time.Time
+	err := g.store.WithContext(c).Raw(`SELECT MAX(recorded_on) FROM kisan.mandi_prices`).Scan(&recordedOn).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return recordedOn, nil
+}
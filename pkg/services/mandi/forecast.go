@@ -0,0 +1,67 @@
+package mandi
+
+import (
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/network"
+	"kisaanSathi/pkg/services/mandi/forecaster"
+	"kisaanSathi/pkg/services/mandi/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func (m *mandiHandler) GetPriceForecast(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	var request models.ForecastRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := m.controller.Forecast(c, &request)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		if errors.Is(err, forecaster.ErrNoHistory) {
+			c.JSON(http.StatusNotFound, network.FailureResponse(network.ApiErrors.NoDataFound.WithErrorDescription(err.Error())))
+		} else {
+			c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.InternalServerError.WithErrorDescription(err.Error())))
+		}
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (m *mandiHandler) GetForecastBacktest(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	var request models.BacktestRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := m.controller.Backtest(c, &request)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		if errors.Is(err, forecaster.ErrNoHistory) {
+			c.JSON(http.StatusNotFound, network.FailureResponse(network.ApiErrors.NoDataFound.WithErrorDescription(err.Error())))
+		} else {
+			c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.InternalServerError.WithErrorDescription(err.Error())))
+		}
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
@@ -0,0 +1,62 @@
+package forecaster
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/services/mandi/models"
+	"kisaanSathi/pkg/utils"
+	"math"
+	"time"
+)
+
+var ErrShortHistory = errors.New("price history is too short to backtest")
+
+// Backtest evaluates the forecaster on rolling origins: the last folds windows of
+// input.Horizon days are each forecast from the history before them and compared with the
+// prices actually recorded, days without arrivals are not scored
+func Backtest(ctx context.Context, f PriceForecaster, input Input, folds int) (*models.ModelScore, error) {
+	if len(input.History) == 0 {
+		return nil, ErrNoHistory
+	}
+	if folds <= 0 {
+		folds = 1
+	}
+	actual := make(map[time.Time]float64, len(input.History))
+	for _, p := range input.History {
+		actual[dayOf(p.Date)] = p.Price
+	}
+	end := dayOf(input.History[len(input.History)-1].Date)
+
+	score := &models.ModelScore{}
+	var errorSum float64
+	for fold := folds; fold >= 1; fold-- {
+		origin := end.AddDate(0, 0, -fold*input.Horizon)
+		var train []models.PricePoint
+		for _, p := range input.History {
+			if dayOf(p.Date).After(origin) {
+				break
+			}
+			train = append(train, p)
+		}
+		if len(train) < 2 {
+			continue
+		}
+
+		result, err := f.Forecast(ctx, Input{Commodity: input.Commodity, Market: input.Market, Horizon: input.Horizon, History: train})
+		if err != nil {
+			return nil, err
+		}
+		score.Folds++
+		for _, p := range result.Points {
+			if price, ok := actual[dayOf(p.Date)]; ok && price > 0 {
+				errorSum += math.Abs(price-p.Price) / price
+				score.Points++
+			}
+		}
+	}
+	if score.Points == 0 {
+		return nil, ErrShortHistory
+	}
+	score.MAPE = utils.Round(100*errorSum/float64(score.Points), 2)
+	return score, nil
+}
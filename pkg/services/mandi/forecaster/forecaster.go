@@ -0,0 +1,75 @@
+package forecaster
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/services/mandi/models"
+	"kisaanSathi/pkg/utils"
+	"math"
+	"time"
+)
+
+// z scores of the two-sided 80% and 95% prediction intervals
+const (
+	z80 = 1.2816
+	z95 = 1.96
+)
+
+var ErrNoHistory = errors.New("no price history for the commodity")
+
+// Input is a commodity's daily price series to forecast Horizon days past its last day
+type Input struct {
+	Commodity string
+	Market    string
+	Horizon   int
+	History   []models.PricePoint
+}
+
+type Result struct {
+	Model  string
+	Points []models.ForecastPoint
+}
+
+// PriceForecaster predicts mandi prices with prediction intervals
+type PriceForecaster interface {
+	Forecast(ctx context.Context, input Input) (*Result, error)
+}
+
+// daily turns the history into one price per day from the first to the last day,
+// carrying the last known price over days without arrivals
+func daily(history []models.PricePoint) ([]float64, time.Time) {
+	if len(history) == 0 {
+		return nil, time.Time{}
+	}
+	start := dayOf(history[0].Date)
+	end := dayOf(history[len(history)-1].Date)
+	values := make([]float64, int(end.Sub(start).Hours()/24)+1)
+	next := 0
+	for i := range values {
+		day := start.AddDate(0, 0, i)
+		if i > 0 {
+			values[i] = values[i-1]
+		}
+		for next < len(history) && !dayOf(history[next].Date).After(day) {
+			values[i] = history[next].Price
+			next++
+		}
+	}
+	return values, end
+}
+
+// point builds a forecast point with intervals of se standard errors; prices never go below zero
+func point(date time.Time, price float64, se float64) models.ForecastPoint {
+	return models.ForecastPoint{
+		Date:    date,
+		Price:   utils.Round(price, 2),
+		Lower80: utils.Round(math.Max(0, price-z80*se), 2),
+		Upper80: utils.Round(price+z80*se, 2),
+		Lower95: utils.Round(math.Max(0, price-z95*se), 2),
+		Upper95: utils.Round(price+z95*se, 2),
+	}
+}
+
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,181 @@
+package forecaster
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/services/mandi/models"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func day(d int) time.Time {
+	return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, d)
+}
+
+func TestDaily_CarriesPricesOverGaps(t *testing.T) {
+	values, last := daily([]models.PricePoint{{Date: day(0), Price: 2200}, {Date: day(3), Price: 2260}, {Date: day(4), Price: 2250}})
+
+	assert.Equal(t, []float64{2200, 2200, 2200, 2260, 2250}, values)
+	assert.Equal(t, day(4), last)
+}
+
+func TestSeasonalNaive_ShortHistoryIsNaive(t *testing.T) {
+	history := []models.PricePoint{{Date: day(0), Price: 100}, {Date: day(1), Price: 103}, {Date: day(2), Price: 99}}
+
+	result, err := NewSeasonalNaive(AnnualPeriod).Forecast(context.TODO(), Input{Horizon: 4, History: history})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "naive", result.Model)
+	assert.Len(t, result.Points, 4)
+	// sigma of the daily differences 3 and -4 is sqrt(12.5)
+	assert.Equal(t, models.ForecastPoint{Date: day(3), Price: 99, Lower80: 94.47, Upper80: 103.53, Lower95: 92.07, Upper95: 105.93}, result.Points[0])
+	// the interval widens with the square root of the steps ahead
+	assert.Equal(t, 99.0, result.Points[3].Price)
+	assert.Equal(t, 85.14, result.Points[3].Lower95)
+}
+
+func TestSeasonalNaive_RepeatsThePeriod(t *testing.T) {
+	var history []models.PricePoint
+	for d := 0; d < 14; d++ {
+		history = append(history, models.PricePoint{Date: day(d), Price: float64(100 + d%7)})
+	}
+
+	result, err := NewSeasonalNaive(7).Forecast(context.TODO(), Input{Horizon: 9, History: history})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "seasonal-naive", result.Model)
+	prices := make([]float64, 0, len(result.Points))
+	for _, p := range result.Points {
+		prices = append(prices, p.Price)
+		// a perfectly periodic series has no error
+		assert.Equal(t, p.Price, p.Upper95)
+	}
+	assert.Equal(t, []float64{100, 101, 102, 103, 104, 105, 106, 100, 101}, prices)
+}
+
+func TestSeasonalNaive_NoHistory(t *testing.T) {
+	_, err := NewSeasonalNaive(0).Forecast(context.TODO(), Input{Horizon: 3})
+	assert.ErrorIs(t, err, ErrNoHistory)
+}
+
+type fakeRest struct {
+	body   string
+	status int
+	err    error
+	sent   interface{}
+}
+
+func (f *fakeRest) InvokeResty(c *gin.Context, method string, url string, body interface{}, headers map[string]string, timeout int64, auth map[string]string, queryparams map[string]string, pathparams map[string]string, formData map[string]string) ([]byte, int, error) {
+	f.sent = body
+	return []byte(f.body), f.status, f.err
+}
+
+func (f *fakeRest) InvokeHttp(c *gin.Context, method string, url string, body interface{}, headers map[string]string, timeout int64, auth map[string]string, queryparams map[string]string, pathparams map[string]string) ([]byte, int, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
+func TestHTTPForecaster(t *testing.T) {
+	rest := &fakeRest{status: http.StatusOK, body: `{"model":"lstm-v3","points":[{"date":"2025-01-03","price":2270,"lower80":2200,"upper80":2340,"lower95":2160,"upper95":2380}]}`}
+	f, err := NewHTTPForecaster(HTTPConfig{URL: "http://models/forecast"}, rest)
+	assert.NoError(t, err)
+
+	result, err := f.Forecast(context.TODO(), Input{Commodity: "Wheat", Horizon: 1, History: []models.PricePoint{{Date: day(1), Price: 2250}}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "lstm-v3", result.Model)
+	assert.Equal(t, day(2), result.Points[0].Date)
+	assert.Equal(t, []historyPoint{{Date: "2025-01-02", Price: 2250}}, rest.sent.(forecastRequest).History)
+}
+
+func TestHTTPForecaster_ServiceError(t *testing.T) {
+	rest := &fakeRest{status: http.StatusServiceUnavailable, body: `{"error":"model loading"}`}
+	f, _ := NewHTTPForecaster(HTTPConfig{URL: "http://models/forecast"}, rest)
+
+	_, err := f.Forecast(context.TODO(), Input{Commodity: "Wheat", Horizon: 1, History: []models.PricePoint{{Date: day(1), Price: 2250}}})
+
+	assert.EqualError(t, err, "price forecast service failed with status 503: model loading")
+}
+
+func TestMovingAverage_FlatAtTheTrailingMean(t *testing.T) {
+	history := []models.PricePoint{{Date: day(0), Price: 100}, {Date: day(1), Price: 110}, {Date: day(2), Price: 90}, {Date: day(3), Price: 104}}
+
+	result, err := NewMovingAverage(3).Forecast(context.TODO(), Input{Horizon: 4, History: history})
+
+	assert.NoError(t, err)
+	assert.Equal(t, ModelMovingAverage, result.Model)
+	// mean of 110, 90 and 104; the only one-step error is 104 - 100
+	assert.Equal(t, models.ForecastPoint{Date: day(4), Price: 101.33, Lower80: 96.21, Upper80: 106.46, Lower95: 93.49, Upper95: 109.17}, result.Points[0])
+	assert.Equal(t, 101.33, result.Points[3].Price)
+	assert.Greater(t, result.Points[3].Upper95, result.Points[0].Upper95)
+}
+
+func TestHoltWinters_LearnsTheWeeklyCycle(t *testing.T) {
+	var history []models.PricePoint
+	for d := 0; d < 70; d++ {
+		history = append(history, models.PricePoint{Date: day(d), Price: 2000 + float64(d) + []float64{0, 30, 60, 30, 0, -60, -60}[d%7]})
+	}
+
+	result, err := NewHoltWinters(HoltWintersConfig{Damping: 1}).Forecast(context.TODO(), Input{Horizon: 7, History: history})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "holt-winters-weekly", result.Model)
+	for h, p := range result.Points {
+		d := 70 + h
+		assert.InDelta(t, 2000+float64(d)+[]float64{0, 30, 60, 30, 0, -60, -60}[d%7], p.Price, 5)
+	}
+}
+
+func TestHoltWinters_ShortHistoryHasNoSeason(t *testing.T) {
+	history := []models.PricePoint{{Date: day(0), Price: 100}, {Date: day(1), Price: 102}, {Date: day(2), Price: 104}}
+
+	result, err := NewHoltWinters(HoltWintersConfig{}).Forecast(context.TODO(), Input{Horizon: 2, History: history})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "holt", result.Model)
+	assert.Len(t, result.Points, 2)
+}
+
+func TestChain_FallsThroughFailures(t *testing.T) {
+	failing := NewChain()
+	history := []models.PricePoint{{Date: day(0), Price: 100}, {Date: day(1), Price: 103}}
+
+	result, err := NewChain(failing, NewMovingAverage(2)).Forecast(context.TODO(), Input{Horizon: 1, History: history})
+
+	assert.NoError(t, err)
+	assert.Equal(t, ModelMovingAverage, result.Model)
+	_, err = NewChain(NewMovingAverage(2)).Forecast(context.TODO(), Input{Horizon: 1})
+	assert.ErrorIs(t, err, ErrNoHistory)
+}
+
+func TestNew_UnknownModel(t *testing.T) {
+	_, err := New("arima", Config{})
+	assert.ErrorIs(t, err, ErrUnknownModel)
+}
+
+func TestBacktest_RollingOrigins(t *testing.T) {
+	var history []models.PricePoint
+	for d := 0; d < 20; d++ {
+		history = append(history, models.PricePoint{Date: day(d), Price: 100})
+	}
+	// the last window jumps by 10%
+	for d := 20; d < 23; d++ {
+		history = append(history, models.PricePoint{Date: day(d), Price: 110})
+	}
+
+	score, err := Backtest(context.TODO(), NewSeasonalNaive(AnnualPeriod), Input{Horizon: 3, History: history}, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, score.Folds)
+	assert.Equal(t, 6, score.Points)
+	// three of six days are off by 10/110
+	assert.Equal(t, 4.55, score.MAPE)
+}
+
+func TestBacktest_ShortHistory(t *testing.T) {
+	_, err := Backtest(context.TODO(), NewSeasonalNaive(AnnualPeriod), Input{Horizon: 7, History: []models.PricePoint{{Date: day(0), Price: 100}}}, 2)
+	assert.ErrorIs(t, err, ErrShortHistory)
+}
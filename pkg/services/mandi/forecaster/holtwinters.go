@@ -0,0 +1,226 @@
+package forecaster
+
+import (
+	"context"
+	"math"
+)
+
+// WeeklyPeriod is the season length of the weekly arrival cycle of daily mandi prices
+const WeeklyPeriod = 7
+
+// DefaultDamping flattens the trend so long horizons do not run away with a recent slope
+const DefaultDamping = 0.98
+
+// HoltWintersConfig fixes the smoothing parameters; a zero parameter is fitted to the history
+type HoltWintersConfig struct {
+	Alpha float64 `mapstructure:"alpha"` // level
+	Beta  float64 `mapstructure:"beta"`  // trend
+	Gamma float64 `mapstructure:"gamma"` // weekly season
+	Delta float64 `mapstructure:"delta"` // annual season
+	// Damping of the trend, between 0.8 and 1
+	Damping float64 `mapstructure:"damping"`
+}
+
+// grids searched for the parameters that are not fixed
+var (
+	alphaGrid = []float64{0.05, 0.1, 0.2, 0.3, 0.5, 0.7, 0.9}
+	betaGrid  = []float64{0, 0.01, 0.05, 0.1, 0.2}
+	gammaGrid = []float64{0.05, 0.1, 0.2, 0.4}
+	deltaGrid = []float64{0.05, 0.1, 0.3}
+)
+
+// warmup days whose one-step errors are left out while fitting
+const warmup = WeeklyPeriod
+
+type holtWinters struct {
+	cfg HoltWintersConfig
+}
+
+type hwParams struct {
+	alpha, beta, gamma, delta, phi float64
+}
+
+type hwState struct {
+	level, trend float64
+	weekly       []float64
+	annual       []float64
+}
+
+// NewHoltWinters forecasts with additive exponential smoothing of a damped trend, a weekly
+// season and an annual season. The annual season needs two years of history and the weekly
+// one two weeks; shorter series are smoothed without them.
+func NewHoltWinters(cfg HoltWintersConfig) PriceForecaster {
+	if cfg.Damping <= 0 || cfg.Damping > 1 {
+		cfg.Damping = DefaultDamping
+	}
+	return &holtWinters{cfg: cfg}
+}
+
+// Forecast fits the parameters by the sum of squared one-step errors over a grid and projects
+// the last state. The standard error h days ahead is sigma * sqrt(1 + sum of c_j^2) for j < h,
+// with c_j = alpha * (1 + beta * (phi + ... + phi^j)) plus gamma or delta on whole seasons.
+func (hw *holtWinters) Forecast(ctx context.Context, input Input) (*Result, error) {
+	values, last := daily(input.History)
+	if len(values) == 0 {
+		return nil, ErrNoHistory
+	}
+	weekly := len(values) >= 2*WeeklyPeriod
+	annual := len(values) >= 2*AnnualPeriod
+	model := "holt"
+	if annual {
+		model = ModelHoltWinters
+	} else if weekly {
+		model = ModelHoltWinters + "-weekly"
+	}
+
+	params, sse, count := hw.fit(values, weekly, annual)
+	state, _, _ := smooth(values, params, initialState(values, weekly, annual))
+	sigma := 0.0
+	if count > 0 {
+		sigma = math.Sqrt(sse / float64(count))
+	}
+
+	result := &Result{Model: model}
+	n := len(values)
+	damped, variance := 0.0, 1.0
+	for h := 1; h <= input.Horizon; h++ {
+		damped += math.Pow(params.phi, float64(h))
+		t := n - 1 + h
+		price := state.level + damped*state.trend + state.weekly[t%WeeklyPeriod] + state.annual[t%AnnualPeriod]
+		result.Points = append(result.Points, point(last.AddDate(0, 0, h), price, sigma*math.Sqrt(variance)))
+
+		c := params.alpha * (1 + params.beta*damped)
+		if h%WeeklyPeriod == 0 {
+			c += params.gamma
+		}
+		if h%AnnualPeriod == 0 {
+			c += params.delta
+		}
+		variance += c * c
+	}
+	return result, nil
+}
+
+// fit returns the parameters with the least squared one-step error
+func (hw *holtWinters) fit(values []float64, weekly bool, annual bool) (hwParams, float64, int) {
+	alphas := grid(hw.cfg.Alpha, alphaGrid, true)
+	betas := grid(hw.cfg.Beta, betaGrid, true)
+	gammas := grid(hw.cfg.Gamma, gammaGrid, weekly)
+	deltas := grid(hw.cfg.Delta, deltaGrid, annual)
+	initial := initialState(values, weekly, annual)
+
+	best := hwParams{phi: hw.cfg.Damping}
+	bestSSE, bestCount := math.Inf(1), 0
+	for _, alpha := range alphas {
+		for _, beta := range betas {
+			for _, gamma := range gammas {
+				for _, delta := range deltas {
+					params := hwParams{alpha: alpha, beta: beta, gamma: gamma, delta: delta, phi: hw.cfg.Damping}
+					_, sse, count := smooth(values, params, initial)
+					if sse < bestSSE {
+						best, bestSSE, bestCount = params, sse, count
+					}
+				}
+			}
+		}
+	}
+	return best, bestSSE, bestCount
+}
+
+// grid is the fixed value, the search grid or, for a disabled season, zero
+func grid(fixed float64, search []float64, enabled bool) []float64 {
+	switch {
+	case !enabled:
+		return []float64{0}
+	case fixed > 0:
+		return []float64{fixed}
+	default:
+		return search
+	}
+}
+
+// smooth runs the recursions over the series and returns the last state with the sum of
+// squared one-step errors after the warmup. The seasons are ring buffers indexed by day.
+func smooth(values []float64, p hwParams, initial hwState) (hwState, float64, int) {
+	state := hwState{
+		level:  initial.level,
+		trend:  initial.trend,
+		weekly: append([]float64(nil), initial.weekly...),
+		annual: append([]float64(nil), initial.annual...),
+	}
+	var sse float64
+	var count int
+	for t, y := range values {
+		w, a := state.weekly[t%WeeklyPeriod], state.annual[t%AnnualPeriod]
+		if t >= warmup {
+			e := y - (state.level + p.phi*state.trend + w + a)
+			sse += e * e
+			count++
+		}
+		level := p.alpha*(y-w-a) + (1-p.alpha)*(state.level+p.phi*state.trend)
+		state.trend = p.beta*(level-state.level) + (1-p.beta)*p.phi*state.trend
+		state.weekly[t%WeeklyPeriod] = p.gamma*(y-level-a) + (1-p.gamma)*w
+		state.annual[t%AnnualPeriod] = p.delta*(y-level-w) + (1-p.delta)*a
+		state.level = level
+	}
+	return state, sse, count
+}
+
+// initialState decomposes the first season of the series: the level is its mean, the annual
+// season the deviation of the centred weekly mean from it and the weekly season the mean
+// deviation of each weekday from the centred weekly mean
+func initialState(values []float64, weekly bool, annual bool) hwState {
+	state := hwState{weekly: make([]float64, WeeklyPeriod), annual: make([]float64, AnnualPeriod)}
+	window := len(values)
+	switch {
+	case annual:
+		window = AnnualPeriod
+	case weekly:
+		window = 2 * WeeklyPeriod
+	case window > WeeklyPeriod:
+		window = WeeklyPeriod
+	}
+	state.level = mean(values[:window])
+
+	smoothed := make([]float64, window)
+	for i := range smoothed {
+		from, to := i-WeeklyPeriod/2, i+WeeklyPeriod/2+1
+		if from < 0 {
+			from = 0
+		}
+		if to > len(values) {
+			to = len(values)
+		}
+		smoothed[i] = mean(values[from:to])
+	}
+	if annual {
+		for i := range smoothed {
+			state.annual[i] = smoothed[i] - state.level
+		}
+	}
+	if weekly {
+		var sums [WeeklyPeriod]float64
+		var counts [WeeklyPeriod]int
+		for i := range smoothed {
+			sums[i%WeeklyPeriod] += values[i] - smoothed[i]
+			counts[i%WeeklyPeriod]++
+		}
+		var total float64
+		for d := range sums {
+			state.weekly[d] = sums[d] / float64(counts[d])
+			total += state.weekly[d]
+		}
+		for d := range state.weekly {
+			state.weekly[d] -= total / WeeklyPeriod
+		}
+	}
+	return state
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
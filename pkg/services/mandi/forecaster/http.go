@@ -0,0 +1,118 @@
+package forecaster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/mandi/models"
+	"kisaanSathi/pkg/utils"
+	"net/http"
+	"time"
+)
+
+type HTTPConfig struct {
+	URL    string
+	APIKey string
+	// Timeout in milliseconds
+	Timeout int64
+}
+
+type httpForecaster struct {
+	cfg  HTTPConfig
+	rest utils.RestCaller
+}
+
+type historyPoint struct {
+	Date  string  `json:"date"`
+	Price float64 `json:"price"`
+}
+
+type forecastRequest struct {
+	Commodity string         `json:"commodity"`
+	Market    string         `json:"market,omitempty"`
+	Horizon   int            `json:"horizon"`
+	History   []historyPoint `json:"history"`
+}
+
+type forecastResponse struct {
+	Model  string `json:"model"`
+	Points []struct {
+		Date    string  `json:"date"`
+		Price   float64 `json:"price"`
+		Lower80 float64 `json:"lower80"`
+		Upper80 float64 `json:"upper80"`
+		Lower95 float64 `json:"lower95"`
+		Upper95 float64 `json:"upper95"`
+	} `json:"points"`
+	Error string `json:"error"`
+}
+
+// NewModelService builds the client of the python model service from thirdparty.priceforecast,
+// it returns nil when no url is configured so that only the in-process forecaster is used
+func NewModelService() (PriceForecaster, error) {
+	c := config.App().Thirdparty.PriceForecast
+	if c.URL == "" {
+		return nil, nil
+	}
+	return NewHTTPForecaster(HTTPConfig{
+		URL:     c.URL,
+		APIKey:  c.APIKey,
+		Timeout: c.Timeout,
+	}, utils.GetRestCaller())
+}
+
+func NewHTTPForecaster(cfg HTTPConfig, rest utils.RestCaller) (PriceForecaster, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("price forecast service url is required")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 3000
+	}
+	return &httpForecaster{cfg: cfg, rest: rest}, nil
+}
+
+// Forecast posts the history to the model service, which answers with points of the same shape
+func (f *httpForecaster) Forecast(ctx context.Context, input Input) (*Result, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	request := forecastRequest{Commodity: input.Commodity, Market: input.Market, Horizon: input.Horizon}
+	for _, price := range input.History {
+		request.History = append(request.History, historyPoint{Date: price.Date.Format("2006-01-02"), Price: price.Price})
+	}
+	var auth map[string]string
+	if f.cfg.APIKey != "" {
+		auth = map[string]string{"token": f.cfg.APIKey}
+	}
+	body, status, err := f.rest.InvokeResty(utils.BackgroundGinContext(ctx), http.MethodPost, f.cfg.URL, request, nil,
+		f.cfg.Timeout, auth, nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("price forecast service call failed: %w", err)
+	}
+
+	var response forecastResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("invalid price forecast response: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("price forecast service failed with status %d: %s", status, response.Error)
+	}
+	if len(response.Points) == 0 {
+		return nil, errors.New("price forecast service returned no points")
+	}
+
+	result := &Result{Model: response.Model, Points: make([]models.ForecastPoint, 0, len(response.Points))}
+	for _, p := range response.Points {
+		date, err := time.Parse("2006-01-02", p.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date in price forecast response: %w", err)
+		}
+		result.Points = append(result.Points, models.ForecastPoint{
+			Date: date, Price: p.Price, Lower80: p.Lower80, Upper80: p.Upper80, Lower95: p.Lower95, Upper95: p.Upper95,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,59 @@
+package forecaster
+
+import (
+	"context"
+	"math"
+)
+
+// DefaultWindow is the number of days averaged by the moving average forecaster
+const DefaultWindow = 28
+
+type movingAverage struct {
+	window int
+}
+
+// NewMovingAverage forecasts every day ahead as the mean price of the last window days
+func NewMovingAverage(window int) PriceForecaster {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &movingAverage{window: window}
+}
+
+// Forecast predicts a flat line at the trailing mean. The standard error is sigma, the root mean
+// square of the in-sample one-step errors of the mean, widened by sqrt(1 + (h-1)/window) as the
+// days ahead move out of the averaged window.
+func (m *movingAverage) Forecast(ctx context.Context, input Input) (*Result, error) {
+	values, last := daily(input.History)
+	if len(values) == 0 {
+		return nil, ErrNoHistory
+	}
+	window := m.window
+	if window > len(values) {
+		window = len(values)
+	}
+
+	var sum, squares float64
+	var count int
+	for t := 0; t < len(values); t++ {
+		if t >= window {
+			mean := sum / float64(window)
+			squares += (values[t] - mean) * (values[t] - mean)
+			count++
+			sum -= values[t-window]
+		}
+		sum += values[t]
+	}
+	mean := sum / float64(window)
+	sigma := 0.0
+	if count > 0 {
+		sigma = math.Sqrt(squares / float64(count))
+	}
+
+	result := &Result{Model: ModelMovingAverage}
+	for h := 1; h <= input.Horizon; h++ {
+		se := sigma * math.Sqrt(1+float64(h-1)/float64(window))
+		result.Points = append(result.Points, point(last.AddDate(0, 0, h), mean, se))
+	}
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package forecaster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// names of the in-process forecasters
+const (
+	ModelSeasonalNaive = "seasonal-naive"
+	ModelMovingAverage = "moving-average"
+	ModelHoltWinters   = "holt-winters"
+)
+
+// Models lists the in-process forecasters, in the order they are compared by a backtest
+var Models = []string{ModelSeasonalNaive, ModelMovingAverage, ModelHoltWinters}
+
+var ErrUnknownModel = errors.New("unknown forecast model")
+
+// Config selects and tunes the in-process forecaster, read from mandi.forecast
+type Config struct {
+	Model string
+	// Window of the moving average in days
+	Window      int
+	HoltWinters HoltWintersConfig
+}
+
+// New builds the in-process forecaster of the given name
+func New(name string, cfg Config) (PriceForecaster, error) {
+	switch name {
+	case ModelSeasonalNaive:
+		return NewSeasonalNaive(AnnualPeriod), nil
+	case ModelMovingAverage:
+		return NewMovingAverage(cfg.Window), nil
+	case ModelHoltWinters:
+		return NewHoltWinters(cfg.HoltWinters), nil
+	default:
+		return nil, fmt.Errorf("%w [%s]", ErrUnknownModel, name)
+	}
+}
+
+type chain struct {
+	forecasters []PriceForecaster
+}
+
+// NewChain asks the forecasters in order and returns the first forecast, so a model that
+// cannot handle a series degrades to the next one
+func NewChain(forecasters ...PriceForecaster) PriceForecaster {
+	return &chain{forecasters: forecasters}
+}
+
+func (c *chain) Forecast(ctx context.Context, input Input) (*Result, error) {
+	err := ErrNoHistory
+	for _, f := range c.forecasters {
+		var result *Result
+		if result, err = f.Forecast(ctx, input); err == nil && len(result.Points) == input.Horizon {
+			return result, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("forecaster returned %d of %d points", len(result.Points), input.Horizon)
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,57 @@
+package forecaster
+
+import (
+	"context"
+	"math"
+)
+
+// AnnualPeriod is the season length of daily prices with a yearly cycle
+const AnnualPeriod = 365
+
+type seasonalNaive struct {
+	period int
+}
+
+// NewSeasonalNaive forecasts each day as the price one period earlier. It runs in-process and
+// backs the model service; series shorter than a period and a day fall back to the last price.
+func NewSeasonalNaive(period int) PriceForecaster {
+	if period <= 0 {
+		period = AnnualPeriod
+	}
+	return &seasonalNaive{period: period}
+}
+
+// Forecast follows the seasonal naive method: the price h days ahead is the price of the
+// same day k periods back, with a standard error of sigma * sqrt(k) where sigma is the
+// root mean square of the one-period differences of the history.
+func (s *seasonalNaive) Forecast(ctx context.Context, input Input) (*Result, error) {
+	values, last := daily(input.History)
+	if len(values) == 0 {
+		return nil, ErrNoHistory
+	}
+	period, model := s.period, ModelSeasonalNaive
+	if len(values) <= period {
+		period, model = 1, "naive"
+	}
+
+	var sum float64
+	var count int
+	for t := period; t < len(values); t++ {
+		diff := values[t] - values[t-period]
+		sum += diff * diff
+		count++
+	}
+	sigma := 0.0
+	if count > 0 {
+		sigma = math.Sqrt(sum / float64(count))
+	}
+
+	result := &Result{Model: model}
+	n := len(values)
+	for h := 1; h <= input.Horizon; h++ {
+		k := (h-1)/period + 1
+		price := values[n+h-1-period*k]
+		result.Points = append(result.Points, point(last.AddDate(0, 0, h), price, sigma*math.Sqrt(float64(k))))
+	}
+	return result, nil
+}
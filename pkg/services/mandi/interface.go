@@ -1,17 +1,57 @@
 package mandi
 
 import (
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/logger"
 	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/mandi/controller"
+	"kisaanSathi/pkg/services/mandi/db"
+	"kisaanSathi/pkg/services/mandi/forecaster"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 type mandiHandler struct {
+	controller controller.MandiController
 }
 type MandiHandler interface {
 	GetMandiBhav(c *gin.Context)
+	GetPriceForecast(c *gin.Context)
+	GetForecastBacktest(c *gin.Context)
 }
 
 func NewMandiHandler(repo repo.DataObject) MandiHandler {
-	return &mandiHandler{}
+	return &mandiHandler{
+		controller: MandiController(repo),
+	}
+}
+
+func MandiController(repo repo.DataObject) controller.MandiController {
+	c := config.App().Mandi.Forecast
+	primary, err := forecaster.NewModelService()
+	if err != nil {
+		logger.Log().Error("price model service is disabled", zap.Error(err))
+	}
+	models := forecaster.Config{
+		Model:       c.Model,
+		Window:      c.MovingAverage.Window,
+		HoltWinters: forecaster.HoltWintersConfig(c.HoltWinters),
+	}
+	// the configured model answers when the model service cannot, seasonal naive when it fails too
+	fallback := forecaster.NewSeasonalNaive(forecaster.AnnualPeriod)
+	if models.Model != "" && models.Model != forecaster.ModelSeasonalNaive {
+		if selected, err := forecaster.New(models.Model, models); err != nil {
+			logger.Log().Error("invalid mandi.forecast.model, using seasonal naive", zap.Error(err))
+		} else {
+			fallback = forecaster.NewChain(selected, fallback)
+		}
+	}
+
+	store := db.NewDBObject(repo.Databases.Reader())
+	return controller.NewMandiController(store, repo.Cache, primary, fallback, controller.ForecastConfig{
+		HistoryDays: c.HistoryDays,
+		CacheTTL:    c.CacheTTL,
+		Models:      models,
+	})
 }
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// DefaultHorizon is the number of days forecast when the request does not say
+const DefaultHorizon = 14
+
+type ForecastRequest struct {
+	Commodity string `form:"commodity" binding:"required,alphaws,max=100"`
+	// Market is the mandi or district, all markets are averaged when empty
+	Market  string `form:"market" binding:"omitempty,placename,max=100"`
+	Horizon int    `form:"horizon" binding:"omitempty,min=1,max=90"`
+}
+
+// PricePoint is the mean modal price per quintal of a day
+type PricePoint struct {
+	Date  time.Time `json:"date" gorm:"column:recorded_on"`
+	Price float64   `json:"price" gorm:"column:price"`
+}
+
+// ForecastPoint is the predicted price of a day with its 80% and 95% prediction intervals
+type ForecastPoint struct {
+	Date    time.Time `json:"date"`
+	Price   float64   `json:"price"`
+	Lower80 float64   `json:"lower80"`
+	Upper80 float64   `json:"upper80"`
+	Lower95 float64   `json:"lower95"`
+	Upper95 float64   `json:"upper95"`
+}
+
+type Forecast struct {
+	Commodity string `json:"commodity"`
+	Market    string `json:"market,omitempty"`
+	Horizon   int    `json:"horizon"`
+	// Model names the forecaster that produced the points
+	Model string `json:"model"`
+	// Fallback is set when the model service was unavailable and the in-process forecaster answered
+	Fallback    bool            `json:"fallback"`
+	LastPrice   PricePoint      `json:"lastPrice"`
+	Points      []ForecastPoint `json:"points"`
+	GeneratedAt time.Time       `json:"generatedAt"`
+}
+
+// BacktestRequest compares the in-process forecasters on the recorded prices of a commodity
+type BacktestRequest struct {
+	Commodity string `form:"commodity" binding:"required,alphaws,max=100"`
+	Market    string `form:"market" binding:"omitempty,placename,max=100"`
+	Horizon   int    `form:"horizon" binding:"omitempty,min=1,max=30"`
+	// Folds is the number of rolling windows forecast
+	Folds  int      `form:"folds" binding:"omitempty,min=1,max=8"`
+	Models []string `form:"models" binding:"omitempty,dive,oneof=seasonal-naive moving-average holt-winters"`
+}
+
+// ModelScore is the mean absolute percentage error of a forecaster over the backtest windows
+type ModelScore struct {
+	Model  string  `json:"model"`
+	Folds  int     `json:"folds"`
+	Points int     `json:"points"`
+	MAPE   float64 `json:"mape"`
+	Error  string  `json:"error,omitempty"`
+}
+
+type CommodityBacktest struct {
+	Commodity string       `json:"commodity"`
+	Scores    []ModelScore `json:"scores"`
+	// Best is the model with the lowest MAPE
+	Best string `json:"best,omitempty"`
+}
+
+type Backtest struct {
+	Market      string              `json:"market,omitempty"`
+	Horizon     int                 `json:"horizon"`
+	Folds       int                 `json:"folds"`
+	Commodities []CommodityBacktest `json:"commodities"`
+	GeneratedAt time.Time           `json:"generatedAt"`
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/market/models"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// subscriberBuffer is the number of messages a slow stream may fall behind before messages are dropped
+const subscriberBuffer = 32
+
+// Hub fans negotiation messages out to the open streams of the users in the thread. It lives in
+// the process, so a user only gets messages written through the same instance; a reconnecting
+// stream catches up from postgres with Last-Event-ID.
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[int64]map[chan models.Message]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[int64]map[chan models.Message]struct{})}
+}
+
+// Subscribe opens a stream for the user, the returned function closes it
+func (h *Hub) Subscribe(userID int64) (<-chan models.Message, func()) {
+	ch := make(chan models.Message, subscriberBuffer)
+	h.mu.Lock()
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[chan models.Message]struct{})
+	}
+	h.subscribers[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers[userID], ch)
+			if len(h.subscribers[userID]) == 0 {
+				delete(h.subscribers, userID)
+			}
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish sends the message to every open stream of the users without blocking
+func (h *Hub) Publish(message models.Message, userIDs ...int64) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, userID := range userIDs {
+		for ch := range h.subscribers[userID] {
+			select {
+			case ch <- message:
+			default:
+				logger.Log().Warn("negotiation stream is behind, dropping message", zap.Int64("userId", userID), zap.Int64("messageId", message.ID))
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"context"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/market/db"
+	"kisaanSathi/pkg/services/market/models"
+	notification "kisaanSathi/pkg/services/notification/controller"
+	"time"
+)
+
+type controller struct {
+	store       db.Store
+	notifier    notification.NotificationService
+	cache       repo.RedisInterface
+	hub         *Hub
+	listingDays int
+	now         func() time.Time
+}
+
+type MarketController interface {
+	CreateListing(ctx context.Context, userID int64, request *models.ListingRequest) (*models.Listing, error)
+	GetListing(ctx context.Context, userID int64, listingID int64) (*models.ListingDetail, error)
+	ListUserListings(ctx context.Context, userID int64) ([]models.Listing, error)
+	SearchListings(ctx context.Context, userID int64, request *models.SearchRequest) ([]models.Listing, error)
+	UpdateListingStatus(ctx context.Context, userID int64, listingID int64, request *models.StatusRequest) (*models.Listing, error)
+	MakeOffer(ctx context.Context, userID int64, listingID int64, request *models.OfferRequest) (*models.Offer, error)
+	// ExpireListings moves open listings past their expiry to expired. It is safe to call repeatedly.
+	ExpireListings(ctx context.Context, now time.Time) (int, error)
+	NegotiationController
+}
+
+type NegotiationController interface {
+	ListThreads(ctx context.Context, userID int64) ([]models.Thread, error)
+	// GetMessages returns a page of the thread and marks it read for the user
+	GetMessages(ctx context.Context, userID int64, offerID int64, request *models.MessagesRequest) (*models.ThreadMessages, error)
+	SendMessage(ctx context.Context, userID int64, offerID int64, request *models.MessageRequest) (*models.Message, error)
+	Counter(ctx context.Context, userID int64, offerID int64, request *models.CounterRequest) (*models.Message, error)
+	Accept(ctx context.Context, userID int64, offerID int64, request *models.ReplyRequest) (*models.Message, error)
+	Reject(ctx context.Context, userID int64, offerID int64, request *models.ReplyRequest) (*models.Message, error)
+	UnreadCounts(ctx context.Context, userID int64) (*models.UnreadCounts, error)
+	// Subscribe opens a live stream of the messages in the user's threads. With lastEventID the
+	// messages after it are returned first so a reconnecting client misses nothing.
+	Subscribe(ctx context.Context, userID int64, lastEventID int64) ([]models.Message, <-chan models.Message, func(), error)
+}
+
+// NewMarketController keeps listings open for listingDays unless the farmer asks otherwise,
+// models.DefaultListingDays when it is not positive. Negotiation messages are streamed through hub
+// and their unread counts kept in cache.
+func NewMarketController(store db.Store, notifier notification.NotificationService, cache repo.RedisInterface, hub *Hub, listingDays int) MarketController {
+	if listingDays <= 0 {
+		listingDays = models.DefaultListingDays
+	}
+	return &controller{
+		store:       store,
+		notifier:    notifier,
+		cache:       cache,
+		hub:         hub,
+		listingDays: listingDays,
+		now:         time.Now,
+	}
+}
@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/market/db"
+	"kisaanSathi/pkg/services/market/models"
+	notificationModels "kisaanSathi/pkg/services/notification/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrForbidden is returned when the user's role may not sell or buy
+	ErrForbidden = errors.New("user role is not allowed to do this")
+	// ErrNoLocation is returned for a listing without a pickup location when the farmer has none either
+	ErrNoLocation = errors.New("pickup location is required, the user has no location on file")
+	// ErrInvalidTransition is returned when the listing cannot move to the requested status
+	ErrInvalidTransition = errors.New("invalid listing status change")
+)
+
+func (s *controller) CreateListing(ctx context.Context, userID int64, request *models.ListingRequest) (*models.Listing, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	user, err := s.requireRole(ctx, userID, models.RoleFarmer)
+	if err != nil {
+		return nil, err
+	}
+	lat, lng := request.Lat, request.Lng
+	if lat == nil || lng == nil {
+		lat, lng = user.Lat, user.Lng
+	}
+	if lat == nil || lng == nil {
+		return nil, ErrNoLocation
+	}
+	days := request.ExpiresInDays
+	if days <= 0 {
+		days = s.listingDays
+	}
+
+	listing := &models.Listing{
+		SellerID:         userID,
+		SellerName:       user.Name,
+		Commodity:        strings.TrimSpace(request.Commodity),
+		QuantityQuintals: request.QuantityQuintals,
+		PricePerQuintal:  request.PricePerQuintal,
+		Grade:            request.Grade,
+		PickupAddress:    strings.TrimSpace(request.PickupAddress),
+		Lat:              *lat,
+		Lng:              *lng,
+		Photos:           request.Photos,
+		ExpiresAt:        s.now().AddDate(0, 0, days),
+	}
+	if listing.Photos == nil {
+		listing.Photos = []string{}
+	}
+	err = s.store.CreateListing(ctx, listing, func(tx *gorm.DB, listing *models.Listing, buyers []int64) error {
+		return s.notifyTransition(ctx, tx, listing, "", buyers)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listing, nil
+}
+
+// GetListing shows the seller every offer and any other user only their own
+func (s *controller) GetListing(ctx context.Context, userID int64, listingID int64) (*models.ListingDetail, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	listing, err := s.store.GetListing(ctx, listingID)
+	if err != nil {
+		return nil, err
+	}
+	buyerID := userID
+	if listing.SellerID == userID {
+		buyerID = 0
+	}
+	offers, err := s.store.ListOffers(ctx, listingID, buyerID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListingDetail{Listing: *listing, Offers: offers}, nil
+}
+
+func (s *controller) ListUserListings(ctx context.Context, userID int64) ([]models.Listing, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	return s.store.ListUserListings(ctx, userID)
+}
+
+// SearchListings searches around the requested point or the user's own location
+func (s *controller) SearchListings(ctx context.Context, userID int64, request *models.SearchRequest) ([]models.Listing, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	filter := models.SearchFilter{
+		Commodity: strings.TrimSpace(request.Commodity),
+		Grade:     request.Grade,
+		Lat:       request.Lat,
+		Lng:       request.Lng,
+		RadiusKm:  request.RadiusKm,
+		Limit:     request.Limit,
+		Offset:    request.Offset,
+	}
+	if filter.RadiusKm <= 0 {
+		filter.RadiusKm = models.DefaultRadiusKm
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = models.DefaultPageSize
+	}
+	if filter.Lat == nil || filter.Lng == nil {
+		user, err := s.store.GetUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		filter.Lat, filter.Lng = user.Lat, user.Lng
+	}
+	return s.store.SearchListings(ctx, filter)
+}
+
+// UpdateListingStatus lets the seller mark a listing sold, withdraw it as expired or reopen it
+// for offers after a negotiation
+func (s *controller) UpdateListingStatus(ctx context.Context, userID int64, listingID int64, request *models.StatusRequest) (*models.Listing, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	listing, err := s.store.GetListing(ctx, listingID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.SellerID != userID {
+		return nil, db.ErrListingNotFound
+	}
+	if !models.CanMove(listing.Status, request.Status) {
+		return nil, fmt.Errorf("%w from %s to %s", ErrInvalidTransition, listing.Status, request.Status)
+	}
+	return s.transition(ctx, listing, request.Status)
+}
+
+func (s *controller) ExpireListings(ctx context.Context, now time.Time) (int, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	listings, err := s.store.ListExpiredListings(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	for i := range listings {
+		if _, err := s.transition(ctx, &listings[i], models.StatusExpired); err != nil {
+			// a listing sold in the meantime is left alone, anything else is retried on the next run
+			if !errors.Is(err, db.ErrStatusChanged) {
+				logger.Log(ctx).Error("failed to expire listing", zap.Int64("listingId", listings[i].ID), zap.Error(err))
+			}
+			continue
+		}
+		expired++
+	}
+	logger.Log(ctx).Info("listings expired", zap.Int("due", len(listings)), zap.Int("expired", expired))
+	return expired, nil
+}
+
+// transition moves the listing from its current status and notifies the seller and the buyers
+// with pending offers in the same transaction
+func (s *controller) transition(ctx context.Context, listing *models.Listing, status string) (*models.Listing, error) {
+	from := listing.Status
+	return s.store.UpdateListingStatus(ctx, listing.ID, []string{from}, status, func(tx *gorm.DB, updated *models.Listing, buyers []int64) error {
+		return s.notifyTransition(ctx, tx, updated, from, buyers)
+	})
+}
+
+func (s *controller) notifyTransition(ctx context.Context, tx *gorm.DB, listing *models.Listing, from string, buyers []int64) error {
+	if message := sellerMessage(listing, from); message != "" {
+		if _, err := s.notifier.NotifyTx(ctx, tx, listing.SellerID, notificationModels.TypeMarket, message); err != nil {
+			return err
+		}
+	}
+	if message := buyerMessage(listing); message != "" {
+		for _, buyer := range buyers {
+			if _, err := s.notifier.NotifyTx(ctx, tx, buyer, notificationModels.TypeMarket, message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sellerMessage tells the farmer their listing moved to its current status from the given one
+func sellerMessage(listing *models.Listing, from string) string {
+	switch listing.Status {
+	case models.StatusActive:
+		if from == "" {
+			return fmt.Sprintf("Your listing of %s quintals %s at Rs. %s/quintal is live", quantity(listing.QuantityQuintals), listing.Commodity, quantity(listing.PricePerQuintal))
+		}
+		return fmt.Sprintf("Your %s listing is open for offers again", listing.Commodity)
+	case models.StatusNegotiating:
+		return fmt.Sprintf("Buyers are negotiating on your %s listing", listing.Commodity)
+	case models.StatusSold:
+		return fmt.Sprintf("Your %s listing is marked sold", listing.Commodity)
+	case models.StatusExpired:
+		return fmt.Sprintf("Your %s listing has expired", listing.Commodity)
+	}
+	return ""
+}
+
+// buyerMessage tells buyers with pending offers that the listing closed
+func buyerMessage(listing *models.Listing) string {
+	switch listing.Status {
+	case models.StatusSold:
+		return fmt.Sprintf("The %s listing you made an offer on has been sold", listing.Commodity)
+	case models.StatusExpired:
+		return fmt.Sprintf("The %s listing you made an offer on has expired", listing.Commodity)
+	}
+	return ""
+}
+
+func (s *controller) requireRole(ctx context.Context, userID int64, role string) (*models.User, error) {
+	user, err := s.store.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != role {
+		return nil, fmt.Errorf("%w, only a %s can", ErrForbidden, role)
+	}
+	return user, nil
+}
+
+func quantity(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
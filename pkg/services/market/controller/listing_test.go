@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/market/db"
+	"kisaanSathi/pkg/services/market/models"
+	notificationModels "kisaanSathi/pkg/services/notification/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type sent struct {
+	userID  int64
+	message string
+}
+
+type fakeNotifier struct {
+	sent []sent
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, userID int64, notificationType string, message string) (*notificationModels.Notification, error) {
+	return f.NotifyTx(ctx, nil, userID, notificationType, message)
+}
+
+func (f *fakeNotifier) NotifyTx(ctx context.Context, tx *gorm.DB, userID int64, notificationType string, message string) (*notificationModels.Notification, error) {
+	f.sent = append(f.sent, sent{userID: userID, message: message})
+	return &notificationModels.Notification{UserID: userID, Message: message, Type: notificationType}, nil
+}
+
+// fakeStore keeps listings in memory and calls notify like the postgres store
+type fakeStore struct {
+	db.Store
+	users    map[int64]*models.User
+	listings map[int64]*models.Listing
+	buyers   []int64
+	offers   []models.Offer
+	messages []models.Message
+}
+
+func (f *fakeStore) GetUser(ctx context.Context, userID int64) (*models.User, error) {
+	if user, ok := f.users[userID]; ok {
+		return user, nil
+	}
+	return nil, db.ErrUserNotFound
+}
+
+func (f *fakeStore) CreateListing(ctx context.Context, listing *models.Listing, notify db.Notify) error {
+	listing.ID, listing.Status = int64(len(f.listings)+1), models.StatusActive
+	f.listings[listing.ID] = listing
+	return notify(nil, listing, nil)
+}
+
+func (f *fakeStore) GetListing(ctx context.Context, listingID int64) (*models.Listing, error) {
+	if listing, ok := f.listings[listingID]; ok {
+		copied := *listing
+		return &copied, nil
+	}
+	return nil, db.ErrListingNotFound
+}
+
+func (f *fakeStore) UpdateListingStatus(ctx context.Context, listingID int64, from []string, status string, notify db.Notify) (*models.Listing, error) {
+	listing := f.listings[listingID]
+	listing.Status = status
+	return listing, notify(nil, listing, f.buyers)
+}
+
+func (f *fakeStore) CreateOffer(ctx context.Context, offer *models.Offer, notify db.Notify) (*models.Message, error) {
+	before := *f.listings[offer.ListingID]
+	offer.ID, offer.Status, offer.Awaiting = int64(len(f.offers)+1), models.OfferPending, models.SideSeller
+	f.offers = append(f.offers, *offer)
+	f.listings[offer.ListingID].Status = models.StatusNegotiating
+	message := &models.Message{ID: int64(len(f.offers)), OfferID: offer.ID, SenderID: offer.BuyerID, Kind: models.KindOffer}
+	return message, notify(nil, &before, f.buyers)
+}
+
+func newTestController() (*controller, *fakeStore, *fakeNotifier) {
+	logger.LoggerInit("", -1)
+	lat, lng := 26.9371, 81.1895
+	store := &fakeStore{
+		users: map[int64]*models.User{
+			1: {ID: 1, Name: "Ravi Yadav", Role: models.RoleFarmer, Lat: &lat, Lng: &lng},
+			2: {ID: 2, Name: "Suman Verma", Role: "advisor"},
+			4: {ID: 4, Name: "Mohan Traders", Role: models.RoleBuyer},
+		},
+		listings: map[int64]*models.Listing{
+			7: {ID: 7, SellerID: 1, Commodity: "Wheat", QuantityQuintals: 20, PricePerQuintal: 2400, Status: models.StatusActive},
+		},
+	}
+	notifier := &fakeNotifier{}
+	c := NewMarketController(store, notifier, repo.NewMemoryCache(), NewHub(), 0).(*controller)
+	c.now = func() time.Time { return time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC) }
+	return c, store, notifier
+}
+
+func TestCreateListing_UsesTheFarmersLocation(t *testing.T) {
+	c, _, notifier := newTestController()
+
+	listing, err := c.CreateListing(context.TODO(), 1, &models.ListingRequest{Commodity: "Mustard", QuantityQuintals: 12.5, PricePerQuintal: 5650, Grade: "FAQ", PickupAddress: "Village Safdarganj"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 26.9371, listing.Lat)
+	assert.Equal(t, time.Date(2025, 4, 15, 10, 0, 0, 0, time.UTC), listing.ExpiresAt)
+	assert.Equal(t, []sent{{userID: 1, message: "Your listing of 12.5 quintals Mustard at Rs. 5650/quintal is live"}}, notifier.sent)
+}
+
+func TestCreateListing_OnlyFarmers(t *testing.T) {
+	c, _, _ := newTestController()
+
+	_, err := c.CreateListing(context.TODO(), 4, &models.ListingRequest{Commodity: "Mustard", QuantityQuintals: 12.5, PricePerQuintal: 5650, Grade: "A"})
+
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestMakeOffer_StartsNegotiation(t *testing.T) {
+	c, store, notifier := newTestController()
+
+	offer, err := c.MakeOffer(context.TODO(), 4, 7, &models.OfferRequest{PricePerQuintal: 2350, QuantityQuintals: 20})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), offer.BuyerID)
+	assert.Equal(t, models.StatusNegotiating, store.listings[7].Status)
+	assert.Equal(t, []sent{
+		{userID: 1, message: "Buyers are negotiating on your Wheat listing"},
+		{userID: 1, message: "New offer of Rs. 2350/quintal for 20 quintals on your Wheat listing"},
+	}, notifier.sent)
+}
+
+func TestMakeOffer_Rules(t *testing.T) {
+	c, store, _ := newTestController()
+
+	_, err := c.MakeOffer(context.TODO(), 2, 7, &models.OfferRequest{PricePerQuintal: 2350, QuantityQuintals: 5})
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = c.MakeOffer(context.TODO(), 4, 7, &models.OfferRequest{PricePerQuintal: 2350, QuantityQuintals: 25})
+	assert.ErrorIs(t, err, ErrInvalidOffer)
+
+	store.listings[7].SellerID = 4
+	_, err = c.MakeOffer(context.TODO(), 4, 7, &models.OfferRequest{PricePerQuintal: 2350, QuantityQuintals: 5})
+	assert.ErrorIs(t, err, ErrOwnListing)
+}
+
+func TestUpdateListingStatus_SoldNotifiesBuyers(t *testing.T) {
+	c, store, notifier := newTestController()
+	store.listings[7].Status = models.StatusNegotiating
+	store.buyers = []int64{4, 5}
+
+	listing, err := c.UpdateListingStatus(context.TODO(), 1, 7, &models.StatusRequest{Status: models.StatusSold})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusSold, listing.Status)
+	assert.Equal(t, []sent{
+		{userID: 1, message: "Your Wheat listing is marked sold"},
+		{userID: 4, message: "The Wheat listing you made an offer on has been sold"},
+		{userID: 5, message: "The Wheat listing you made an offer on has been sold"},
+	}, notifier.sent)
+}
+
+func TestUpdateListingStatus_Rules(t *testing.T) {
+	c, store, notifier := newTestController()
+
+	_, err := c.UpdateListingStatus(context.TODO(), 4, 7, &models.StatusRequest{Status: models.StatusSold})
+	assert.ErrorIs(t, err, db.ErrListingNotFound)
+
+	store.listings[7].Status = models.StatusExpired
+	_, err = c.UpdateListingStatus(context.TODO(), 1, 7, &models.StatusRequest{Status: models.StatusActive})
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+	assert.Empty(t, notifier.sent)
+}
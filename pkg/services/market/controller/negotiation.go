@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/market/db"
+	"kisaanSathi/pkg/services/market/models"
+	notificationModels "kisaanSathi/pkg/services/notification/models"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func (s *controller) ListThreads(ctx context.Context, userID int64) ([]models.Thread, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	threads, err := s.store.ListThreads(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	unread := s.unreadThreads(ctx, userID)
+	for i := range threads {
+		threads[i].Unread = unread[threads[i].ID]
+	}
+	return threads, nil
+}
+
+func (s *controller) GetMessages(ctx context.Context, userID int64, offerID int64, request *models.MessagesRequest) (*models.ThreadMessages, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	thread, err := s.thread(ctx, userID, offerID)
+	if err != nil {
+		return nil, err
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = models.DefaultMessagePageSize
+	}
+	messages, err := s.store.ListMessages(ctx, offerID, request.BeforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if request.BeforeID == 0 {
+		s.markRead(ctx, userID, offerID)
+	}
+	return &models.ThreadMessages{Thread: *thread, Messages: messages}, nil
+}
+
+func (s *controller) SendMessage(ctx context.Context, userID int64, offerID int64, request *models.MessageRequest) (*models.Message, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	thread, err := s.thread(ctx, userID, offerID)
+	if err != nil {
+		return nil, err
+	}
+	message := &models.Message{OfferID: offerID, SenderID: userID, Kind: models.KindText, Body: strings.TrimSpace(request.Body)}
+	if err := s.store.AddMessage(ctx, message); err != nil {
+		return nil, err
+	}
+	s.deliver(ctx, *message, userID, thread.Counterpart(userID))
+	return message, nil
+}
+
+// Counter puts new terms on the table, it is then the other side's turn to answer
+func (s *controller) Counter(ctx context.Context, userID int64, offerID int64, request *models.CounterRequest) (*models.Message, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	thread, err := s.thread(ctx, userID, offerID)
+	if err != nil {
+		return nil, err
+	}
+	listing, err := s.store.GetListing(ctx, thread.ListingID)
+	if err != nil {
+		return nil, err
+	}
+	if request.QuantityQuintals > listing.QuantityQuintals {
+		return nil, fmt.Errorf("%w of %s quintals", ErrInvalidOffer, quantity(listing.QuantityQuintals))
+	}
+
+	message := &models.Message{
+		OfferID:          offerID,
+		SenderID:         userID,
+		Kind:             models.KindCounter,
+		PricePerQuintal:  &request.PricePerQuintal,
+		QuantityQuintals: &request.QuantityQuintals,
+		Body:             strings.TrimSpace(request.Message),
+	}
+	if err := s.store.Counter(ctx, message, thread.Side(userID)); err != nil {
+		return nil, err
+	}
+	counterpart := thread.Counterpart(userID)
+	if _, err := s.notifier.Notify(ctx, counterpart, notificationModels.TypeMarket, counterMessage(thread, message)); err != nil {
+		logger.Log(ctx).Warn("failed to notify counter offer", zap.Int64("offerId", offerID), zap.Error(err))
+	}
+	s.deliver(ctx, *message, userID, counterpart)
+	return message, nil
+}
+
+// Accept closes the deal on the terms on the table and sells the listing
+func (s *controller) Accept(ctx context.Context, userID int64, offerID int64, request *models.ReplyRequest) (*models.Message, error) {
+	return s.close(ctx, userID, offerID, models.OfferAccepted, request)
+}
+
+// Reject ends the negotiation without a deal, the listing reopens when no other offer is pending
+func (s *controller) Reject(ctx context.Context, userID int64, offerID int64, request *models.ReplyRequest) (*models.Message, error) {
+	return s.close(ctx, userID, offerID, models.OfferRejected, request)
+}
+
+func (s *controller) close(ctx context.Context, userID int64, offerID int64, status string, request *models.ReplyRequest) (*models.Message, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	thread, err := s.thread(ctx, userID, offerID)
+	if err != nil {
+		return nil, err
+	}
+	kind := models.KindAccept
+	if status == models.OfferRejected {
+		kind = models.KindReject
+	}
+	message := &models.Message{OfferID: offerID, SenderID: userID, Kind: kind, Body: strings.TrimSpace(request.Message)}
+	if status == models.OfferAccepted {
+		message.PricePerQuintal, message.QuantityQuintals = &thread.PricePerQuintal, &thread.QuantityQuintals
+	}
+
+	counterpart := thread.Counterpart(userID)
+	_, err = s.store.CloseOffer(ctx, message, thread.Side(userID), status, func(tx *gorm.DB, listing *models.Listing, buyers []int64) error {
+		if _, err := s.notifier.NotifyTx(ctx, tx, counterpart, notificationModels.TypeMarket, closeMessage(thread, status)); err != nil {
+			return err
+		}
+		if listing.Status != thread.ListingStatus {
+			return s.notifyTransition(ctx, tx, listing, thread.ListingStatus, buyers)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.deliver(ctx, *message, userID, counterpart)
+	return message, nil
+}
+
+func (s *controller) UnreadCounts(ctx context.Context, userID int64) (*models.UnreadCounts, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	counts := &models.UnreadCounts{Threads: s.unreadThreads(ctx, userID)}
+	for _, count := range counts.Threads {
+		counts.Total += count
+	}
+	return counts, nil
+}
+
+func (s *controller) Subscribe(ctx context.Context, userID int64, lastEventID int64) ([]models.Message, <-chan models.Message, func(), error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	// subscribe before reading the backlog so nothing written in between is lost
+	live, cancel := s.hub.Subscribe(userID)
+	if lastEventID <= 0 {
+		return nil, live, cancel, nil
+	}
+	backlog, err := s.store.ListMessagesSince(ctx, userID, lastEventID, models.MaxReplayMessages)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, err
+	}
+	return backlog, live, cancel, nil
+}
+
+// thread loads the offer's thread if the user is its buyer or seller
+func (s *controller) thread(ctx context.Context, userID int64, offerID int64) (*models.Thread, error) {
+	thread, err := s.store.GetThread(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if thread.Side(userID) == "" {
+		return nil, db.ErrOfferNotFound
+	}
+	return thread, nil
+}
+
+// deliver streams a stored message to both sides and counts it unread for the recipient
+func (s *controller) deliver(ctx context.Context, message models.Message, senderID int64, recipientID int64) {
+	if s.hub != nil {
+		s.hub.Publish(message, senderID, recipientID)
+	}
+	if s.cache == nil {
+		return
+	}
+	if _, err := s.cache.HIncrBy(ctx, unreadKey(recipientID), strconv.FormatInt(message.OfferID, 10), 1); err != nil {
+		logger.Log(ctx).Warn("failed to update unread count", zap.Int64("userId", recipientID), zap.Error(err))
+	}
+}
+
+func (s *controller) markRead(ctx context.Context, userID int64, offerID int64) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.DeleteRedisHash(ctx, unreadKey(userID), strconv.FormatInt(offerID, 10)); err != nil {
+		logger.Log(ctx).Warn("failed to reset unread count", zap.Int64("userId", userID), zap.Error(err))
+	}
+}
+
+// unreadThreads reads the user's unread counts per offer from the redis hash
+func (s *controller) unreadThreads(ctx context.Context, userID int64) map[int64]int64 {
+	threads := map[int64]int64{}
+	if s.cache == nil {
+		return threads
+	}
+	cached, err := s.cache.GetRedisHashValue(ctx, unreadKey(userID))
+	if err != nil {
+		logger.Log(ctx).Warn("failed to read unread counts", zap.Int64("userId", userID), zap.Error(err))
+		return threads
+	}
+	for field, value := range cached {
+		offerID, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			continue
+		}
+		if count, err := strconv.ParseInt(value, 10, 64); err == nil && count > 0 {
+			threads[offerID] = count
+		}
+	}
+	return threads
+}
+
+func unreadKey(userID int64) string {
+	return fmt.Sprintf("negotiations:unread:%d", userID)
+}
+
+func counterMessage(thread *models.Thread, message *models.Message) string {
+	return fmt.Sprintf("Counter offer of Rs. %s/quintal for %s quintals on the %s listing",
+		quantity(*message.PricePerQuintal), quantity(*message.QuantityQuintals), thread.Commodity)
+}
+
+func closeMessage(thread *models.Thread, status string) string {
+	if status == models.OfferAccepted {
+		return fmt.Sprintf("Deal agreed on the %s listing at Rs. %s/quintal for %s quintals",
+			thread.Commodity, quantity(thread.PricePerQuintal), quantity(thread.QuantityQuintals))
+	}
+	return fmt.Sprintf("The negotiation on the %s listing ended without a deal", thread.Commodity)
+}
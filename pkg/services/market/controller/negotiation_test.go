@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"kisaanSathi/pkg/services/market/db"
+	"kisaanSathi/pkg/services/market/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (f *fakeStore) GetThread(ctx context.Context, offerID int64) (*models.Thread, error) {
+	for _, offer := range f.offers {
+		if offer.ID == offerID {
+			listing := f.listings[offer.ListingID]
+			return &models.Thread{Offer: offer, SellerID: listing.SellerID, Commodity: listing.Commodity, ListingStatus: listing.Status}, nil
+		}
+	}
+	return nil, db.ErrOfferNotFound
+}
+
+func (f *fakeStore) AddMessage(ctx context.Context, message *models.Message) error {
+	message.ID = 100 + int64(len(f.offers))
+	return nil
+}
+
+func (f *fakeStore) Counter(ctx context.Context, message *models.Message, side string) error {
+	offer := &f.offers[message.OfferID-1]
+	if offer.Awaiting != side {
+		return db.ErrNotYourTurn
+	}
+	offer.PricePerQuintal, offer.QuantityQuintals = *message.PricePerQuintal, *message.QuantityQuintals
+	offer.Awaiting = models.SideSeller
+	if side == models.SideSeller {
+		offer.Awaiting = models.SideBuyer
+	}
+	return nil
+}
+
+func (f *fakeStore) CloseOffer(ctx context.Context, message *models.Message, side string, status string, notify db.Notify) (*models.Listing, error) {
+	offer := &f.offers[message.OfferID-1]
+	if status == models.OfferAccepted && offer.Awaiting != side {
+		return nil, db.ErrNotYourTurn
+	}
+	offer.Status = status
+	listing := f.listings[offer.ListingID]
+	if status == models.OfferAccepted {
+		listing.Status = models.StatusSold
+	} else {
+		listing.Status = models.StatusActive
+	}
+	return listing, notify(nil, listing, f.buyers)
+}
+
+func TestNegotiation_TakesTurns(t *testing.T) {
+	c, store, notifier := newTestController()
+	_, err := c.MakeOffer(context.TODO(), 4, 7, &models.OfferRequest{PricePerQuintal: 2300, QuantityQuintals: 20})
+	assert.NoError(t, err)
+	notifier.sent = nil
+
+	// the offer waits on the seller, the buyer cannot counter their own terms
+	_, err = c.Counter(context.TODO(), 4, 1, &models.CounterRequest{PricePerQuintal: 2350, QuantityQuintals: 20})
+	assert.ErrorIs(t, err, db.ErrNotYourTurn)
+
+	message, err := c.Counter(context.TODO(), 1, 1, &models.CounterRequest{PricePerQuintal: 2380, QuantityQuintals: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, models.KindCounter, message.Kind)
+	assert.Equal(t, models.SideBuyer, store.offers[0].Awaiting)
+
+	_, err = c.Accept(context.TODO(), 1, 1, &models.ReplyRequest{})
+	assert.ErrorIs(t, err, db.ErrNotYourTurn)
+
+	message, err = c.Accept(context.TODO(), 4, 1, &models.ReplyRequest{Message: "Pickup on Monday"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2380.0, *message.PricePerQuintal)
+	assert.Equal(t, models.StatusSold, store.listings[7].Status)
+	assert.Equal(t, []sent{
+		{userID: 4, message: "Counter offer of Rs. 2380/quintal for 20 quintals on the Wheat listing"},
+		{userID: 1, message: "Deal agreed on the Wheat listing at Rs. 2380/quintal for 20 quintals"},
+		{userID: 1, message: "Your Wheat listing is marked sold"},
+	}, notifier.sent)
+}
+
+func TestNegotiation_OnlyParties(t *testing.T) {
+	c, _, _ := newTestController()
+	_, err := c.MakeOffer(context.TODO(), 4, 7, &models.OfferRequest{PricePerQuintal: 2300, QuantityQuintals: 20})
+	assert.NoError(t, err)
+
+	_, err = c.SendMessage(context.TODO(), 2, 1, &models.MessageRequest{Body: "hello"})
+	assert.ErrorIs(t, err, db.ErrOfferNotFound)
+}
+
+func TestNegotiation_UnreadAndStream(t *testing.T) {
+	c, store, _ := newTestController()
+	seller, cancel := c.hub.Subscribe(1)
+	defer cancel()
+
+	_, err := c.MakeOffer(context.TODO(), 4, 7, &models.OfferRequest{PricePerQuintal: 2300, QuantityQuintals: 20})
+	assert.NoError(t, err)
+	_, err = c.SendMessage(context.TODO(), 4, 1, &models.MessageRequest{Body: " Can you load it on Monday? "})
+	assert.NoError(t, err)
+
+	assert.Equal(t, models.KindOffer, (<-seller).Kind)
+	assert.Equal(t, "Can you load it on Monday?", (<-seller).Body)
+	counts, err := c.UnreadCounts(context.TODO(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.UnreadCounts{Total: 2, Threads: map[int64]int64{1: 2}}, counts)
+
+	store.messages = []models.Message{{ID: 1, OfferID: 1}}
+	_, err = c.GetMessages(context.TODO(), 1, 1, &models.MessagesRequest{})
+	assert.NoError(t, err)
+	counts, _ = c.UnreadCounts(context.TODO(), 1)
+	assert.Equal(t, int64(0), counts.Total)
+}
+
+func TestHub_DropsForSlowStreams(t *testing.T) {
+	hub := NewHub()
+	stream, cancel := hub.Subscribe(1)
+	for i := 0; i < subscriberBuffer+5; i++ {
+		hub.Publish(models.Message{ID: int64(i)}, 1, 2)
+	}
+	assert.Len(t, stream, subscriberBuffer)
+
+	cancel()
+	cancel()
+	hub.Publish(models.Message{ID: 99}, 1)
+	assert.Empty(t, hub.subscribers)
+}
+
+func (f *fakeStore) ListMessages(ctx context.Context, offerID int64, beforeID int64, limit int) ([]models.Message, error) {
+	return f.messages, nil
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/market/models"
+	notificationModels "kisaanSathi/pkg/services/notification/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrOwnListing is returned for an offer on the buyer's own listing
+	ErrOwnListing = errors.New("cannot make an offer on your own listing")
+	// ErrInvalidOffer is returned for an offer on more than the listed quantity
+	ErrInvalidOffer = errors.New("offer quantity is more than the listed quantity")
+)
+
+// MakeOffer records a buyer's offer. The first offer moves an active listing to negotiating;
+// the seller is told about every offer.
+func (s *controller) MakeOffer(ctx context.Context, userID int64, listingID int64, request *models.OfferRequest) (*models.Offer, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	if _, err := s.requireRole(ctx, userID, models.RoleBuyer); err != nil {
+		return nil, err
+	}
+	listing, err := s.store.GetListing(ctx, listingID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.SellerID == userID {
+		return nil, ErrOwnListing
+	}
+	if request.QuantityQuintals > listing.QuantityQuintals {
+		return nil, fmt.Errorf("%w of %s quintals", ErrInvalidOffer, quantity(listing.QuantityQuintals))
+	}
+
+	offer := &models.Offer{
+		ListingID:        listingID,
+		BuyerID:          userID,
+		PricePerQuintal:  request.PricePerQuintal,
+		QuantityQuintals: request.QuantityQuintals,
+		Message:          strings.TrimSpace(request.Message),
+	}
+	message, err := s.store.CreateOffer(ctx, offer, func(tx *gorm.DB, before *models.Listing, buyers []int64) error {
+		if before.Status == models.StatusActive {
+			negotiating := *before
+			negotiating.Status = models.StatusNegotiating
+			if err := s.notifyTransition(ctx, tx, &negotiating, before.Status, buyers); err != nil {
+				return err
+			}
+		}
+		_, err := s.notifier.NotifyTx(ctx, tx, before.SellerID, notificationModels.TypeMarket, offerMessage(before, offer))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.deliver(ctx, *message, userID, listing.SellerID)
+	return offer, nil
+}
+
+func offerMessage(listing *models.Listing, offer *models.Offer) string {
+	return fmt.Sprintf("New offer of Rs. %s/quintal for %s quintals on your %s listing",
+		quantity(offer.PricePerQuintal), quantity(offer.QuantityQuintals), listing.Commodity)
+}
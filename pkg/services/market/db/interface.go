@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"kisaanSathi/pkg/services/market/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type marketStore struct {
+	store *gorm.DB
+}
+
+// Notify writes the notifications of a listing transition inside its transaction. Buyers are the
+// users with pending offers on the listing.
+type Notify func(tx *gorm.DB, listing *models.Listing, buyers []int64) error
+
+type Store interface {
+	GetUser(ctx context.Context, userID int64) (*models.User, error)
+	// CreateListing inserts the listing and calls notify with the same transaction
+	CreateListing(ctx context.Context, listing *models.Listing, notify Notify) error
+	GetListing(ctx context.Context, listingID int64) (*models.Listing, error)
+	ListUserListings(ctx context.Context, userID int64) ([]models.Listing, error)
+	SearchListings(ctx context.Context, filter models.SearchFilter) ([]models.Listing, error)
+	// UpdateListingStatus moves the listing to status if it is in one of from, pending offers
+	// are rejected when the listing is sold or expires
+	UpdateListingStatus(ctx context.Context, listingID int64, from []string, status string, notify Notify) (*models.Listing, error)
+	// ListExpiredListings returns the open listings past their expiry
+	ListExpiredListings(ctx context.Context, now time.Time) ([]models.Listing, error)
+	// CreateOffer inserts the offer on an open listing with the first message of its thread, moving
+	// an active listing to negotiating. notify gets the listing as it was before the offer.
+	CreateOffer(ctx context.Context, offer *models.Offer, notify Notify) (*models.Message, error)
+	// ListOffers returns the offers on the listing, only those of buyerID unless it is 0
+	ListOffers(ctx context.Context, listingID int64, buyerID int64) ([]models.Offer, error)
+	NegotiationStore
+}
+
+// NegotiationStore keeps the message threads of offers
+type NegotiationStore interface {
+	GetThread(ctx context.Context, offerID int64) (*models.Thread, error)
+	// ListThreads returns the offers the user made or received, latest activity first
+	ListThreads(ctx context.Context, userID int64) ([]models.Thread, error)
+	ListMessages(ctx context.Context, offerID int64, beforeID int64, limit int) ([]models.Message, error)
+	// ListMessagesSince returns the messages after afterID in all threads of the user, oldest first
+	ListMessagesSince(ctx context.Context, userID int64, afterID int64, limit int) ([]models.Message, error)
+	AddMessage(ctx context.Context, message *models.Message) error
+	// Counter replaces the terms of a pending offer awaiting side's answer and hands the turn to the other side
+	Counter(ctx context.Context, message *models.Message, side string) error
+	// CloseOffer accepts or rejects a pending offer; only the side the terms wait on may accept. Accepting
+	// sells the listing and rejects its other offers, rejecting the last pending offer reopens the listing.
+	// notify gets the listing with its new status and the buyers of the offers rejected with it.
+	CloseOffer(ctx context.Context, message *models.Message, side string, status string, notify Notify) (*models.Listing, error)
+}
+
+func NewDBObject(db *gorm.DB) Store {
+	return &marketStore{
+		store: db,
+	}
+}
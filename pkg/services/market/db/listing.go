@@ -0,0 +1,230 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/market/models"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const listingColumns = `l.id, l.seller_id, COALESCE(u.name, '') AS seller_name, l.commodity, l.quantity_quintals,
+		l.price_per_quintal, l.grade, l.pickup_address, l.lat, l.lng, array_to_string(l.photos, ',') AS photos,
+		l.status, l.expires_at, l.created_at, l.updated_at`
+
+// distanceKm is the haversine distance in km of the listing from the point bound to its three parameters (lat, lat, lng)
+const distanceKm = `6371 * 2 * asin(least(1, sqrt(power(sin(radians(l.lat - ?) / 2), 2)
+		+ cos(radians(?)) * cos(radians(l.lat)) * power(sin(radians(l.lng - ?) / 2), 2))))`
+
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrListingNotFound = errors.New("listing not found")
+	// ErrStatusChanged is returned when the listing left the expected states in the meantime
+	ErrStatusChanged = errors.New("listing status has changed")
+)
+
+func (g *marketStore) GetUser(c context.Context, userID int64) (*models.User, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var users []models.User
+	err := g.store.WithContext(c).Raw(`SELECT id, COALESCE(name, '') AS name, COALESCE(role, '') AS role, lat, lng
+		FROM kisan.users WHERE id = ?`, userID).Scan(&users).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, ErrUserNotFound
+	}
+	return &users[0], nil
+}
+
+func (g *marketStore) CreateListing(c context.Context, listing *models.Listing, notify Notify) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	err := repo.Transaction(g.store.WithContext(c), func(tx *gorm.DB) error {
+		err := tx.Raw(`INSERT INTO kisan.listings (seller_id, commodity, quantity_quintals, price_per_quintal, grade,
+				pickup_address, lat, lng, photos, expires_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?::text[], ?)
+			RETURNING id, status, created_at, updated_at`,
+			listing.SellerID, listing.Commodity, listing.QuantityQuintals, listing.PricePerQuintal, listing.Grade,
+			listing.PickupAddress, listing.Lat, listing.Lng, repo.TextArray(listing.Photos), listing.ExpiresAt).
+			Row().Scan(&listing.ID, &listing.Status, &listing.CreatedAt, &listing.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		return notify(tx, listing, nil)
+	})
+	if err != nil {
+		logger.Log(c).Error("Error inserting listing", zap.Error(err))
+	}
+	return err
+}
+
+func (g *marketStore) GetListing(c context.Context, listingID int64) (*models.Listing, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	return getListing(g.store.WithContext(c), listingID, false)
+}
+
+// ListUserListings returns the seller's listings, latest first
+func (g *marketStore) ListUserListings(c context.Context, userID int64) ([]models.Listing, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var listings []models.Listing
+	err := g.store.WithContext(c).Raw(`SELECT `+listingColumns+`
+		FROM kisan.listings l
+		LEFT JOIN kisan.users u ON u.id = l.seller_id
+		WHERE l.seller_id = ?
+		ORDER BY l.created_at DESC, l.id DESC`, userID).Scan(&listings).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return withPhotos(listings), nil
+}
+
+// SearchListings returns open listings, nearest first when the filter has a location and latest first otherwise
+func (g *marketStore) SearchListings(c context.Context, filter models.SearchFilter) ([]models.Listing, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	distance := "NULL::float8"
+	var args []interface{}
+	located := filter.Lat != nil && filter.Lng != nil
+	if located {
+		distance = distanceKm
+		args = append(args, *filter.Lat, *filter.Lat, *filter.Lng)
+	}
+	query := `SELECT * FROM (SELECT ` + listingColumns + `, ` + distance + ` AS distance_km
+			FROM kisan.listings l
+			LEFT JOIN kisan.users u ON u.id = l.seller_id
+			WHERE l.status IN ? AND l.expires_at > now()
+				AND (? = '' OR lower(l.commodity) = lower(?)) AND (? = '' OR l.grade = ?)) listings`
+	args = append(args, models.OpenStatuses, filter.Commodity, filter.Commodity, filter.Grade, filter.Grade)
+	if located {
+		query += ` WHERE distance_km <= ? ORDER BY distance_km, id DESC`
+		args = append(args, filter.RadiusKm)
+	} else {
+		query += ` ORDER BY created_at DESC, id DESC`
+	}
+	query += ` LIMIT ? OFFSET ?`
+	args = append(args, filter.Limit, filter.Offset)
+
+	var listings []models.Listing
+	if err := g.store.WithContext(c).Raw(query, args...).Scan(&listings).Error; err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return withPhotos(listings), nil
+}
+
+func (g *marketStore) UpdateListingStatus(c context.Context, listingID int64, from []string, status string, notify Notify) (*models.Listing, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var listing *models.Listing
+	err := repo.Transaction(g.store.WithContext(c), func(tx *gorm.DB) error {
+		var err error
+		if listing, err = getListing(tx, listingID, true); err != nil {
+			return err
+		}
+		if !contains(from, listing.Status) {
+			return ErrStatusChanged
+		}
+		if err := tx.Exec(`UPDATE kisan.listings SET status = ?, updated_at = now() WHERE id = ?`, status, listingID).Error; err != nil {
+			return err
+		}
+		buyers, err := pendingBuyers(tx, listingID)
+		if err != nil {
+			return err
+		}
+		if status == models.StatusSold || status == models.StatusExpired {
+			err := tx.Exec(`UPDATE kisan.listing_offers SET status = ?, updated_at = now() WHERE listing_id = ? AND status = ?`,
+				models.OfferRejected, listingID, models.OfferPending).Error
+			if err != nil {
+				return err
+			}
+		}
+		listing.Status = status
+		return notify(tx, listing, buyers)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrListingNotFound) && !errors.Is(err, ErrStatusChanged) {
+			logger.Log(c).Error("Error updating listing status", zap.Error(err))
+		}
+		return nil, err
+	}
+	return listing, nil
+}
+
+func (g *marketStore) ListExpiredListings(c context.Context, now time.Time) ([]models.Listing, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var listings []models.Listing
+	err := g.store.WithContext(c).Raw(`SELECT `+listingColumns+`
+		FROM kisan.listings l
+		LEFT JOIN kisan.users u ON u.id = l.seller_id
+		WHERE l.status IN ? AND l.expires_at <= ?
+		ORDER BY l.expires_at, l.id`, models.OpenStatuses, now).Scan(&listings).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return withPhotos(listings), nil
+}
+
+// getListing reads a listing, locking its row for the transaction when lock is set
+func getListing(tx *gorm.DB, listingID int64, lock bool) (*models.Listing, error) {
+	query := `SELECT ` + listingColumns + `
+		FROM kisan.listings l
+		LEFT JOIN kisan.users u ON u.id = l.seller_id
+		WHERE l.id = ?`
+	if lock {
+		query += ` FOR UPDATE OF l`
+	}
+	var listings []models.Listing
+	if err := tx.Raw(query, listingID).Scan(&listings).Error; err != nil {
+		return nil, err
+	}
+	if len(listings) == 0 {
+		return nil, ErrListingNotFound
+	}
+	return &withPhotos(listings)[0], nil
+}
+
+func pendingBuyers(tx *gorm.DB, listingID int64) ([]int64, error) {
+	var buyers []int64
+	err := tx.Raw(`SELECT DISTINCT buyer_id FROM kisan.listing_offers WHERE listing_id = ? AND status = ? ORDER BY buyer_id`,
+		listingID, models.OfferPending).Scan(&buyers).Error
+	return buyers, err
+}
+
+func withPhotos(listings []models.Listing) []models.Listing {
+	for i := range listings {
+		listings[i].Photos = []string{}
+		if listings[i].PhotoList != "" {
+			listings[i].Photos = strings.Split(listings[i].PhotoList, ",")
+		}
+	}
+	return listings
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
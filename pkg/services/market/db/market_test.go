@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/market/models"
+	"kisaanSathi/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type MarketSuite struct {
+	suite.Suite
+	ctx         context.Context
+	sqlDB       *sql.DB
+	gormDB      *gorm.DB
+	sqlMock     sqlmock.Sqlmock
+	marketStore Store
+}
+
+var listingRowColumns = []string{"id", "seller_id", "seller_name", "commodity", "quantity_quintals", "price_per_quintal", "grade",
+	"pickup_address", "lat", "lng", "photos", "status", "expires_at", "created_at", "updated_at"}
+
+func TestMarketSuite(t *testing.T) {
+	suite.Run(t, new(MarketSuite))
+}
+
+func (suite *MarketSuite) SetupSuite() {
+	logger.LoggerInit("", -1)
+
+	suite.ctx = context.TODO()
+	suite.sqlDB, suite.gormDB, suite.sqlMock = utils.NewMockDB()
+	suite.marketStore = NewDBObject(suite.gormDB)
+}
+
+func (suite *MarketSuite) TearDownTest() {
+	suite.NoError(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *MarketSuite) TestGetListing_SplitsPhotos() {
+	// Mocking and Setting Expected Result
+	rows := sqlmock.NewRows(listingRowColumns).
+		AddRow(7, 1, "Ravi Yadav", "Wheat", 20, 2400, "A", "Village Safdarganj", 26.93, 81.18,
+			"https://cdn.example/1.jpg,https://cdn.example/2.jpg", "active", time.Now(), time.Now(), time.Now())
+	suite.sqlMock.ExpectQuery("^SELECT (.+) FROM kisan.listings l LEFT JOIN kisan.users u (.+) WHERE l.id = (.+)$").
+		WithArgs(7).
+		WillReturnRows(rows)
+
+	// Triggering Function
+	listing, err := suite.marketStore.GetListing(suite.ctx, 7)
+
+	// Validations
+	suite.NoError(err)
+	suite.Equal([]string{"https://cdn.example/1.jpg", "https://cdn.example/2.jpg"}, listing.Photos)
+}
+
+func (suite *MarketSuite) TestCreateOffer_ClosedListing() {
+	// Mocking and Setting Expected Result
+	rows := sqlmock.NewRows(listingRowColumns).
+		AddRow(7, 1, "Ravi Yadav", "Wheat", 20, 2400, "A", "Village Safdarganj", 26.93, 81.18, "", "sold", time.Now(), time.Now(), time.Now())
+	suite.sqlMock.ExpectBegin()
+	suite.sqlMock.ExpectQuery("^SELECT (.+) FROM kisan.listings l (.+) FOR UPDATE OF l$").
+		WithArgs(7).
+		WillReturnRows(rows)
+	suite.sqlMock.ExpectRollback()
+
+	// Triggering Function
+	notified := false
+	_, err := suite.marketStore.CreateOffer(suite.ctx, &models.Offer{ListingID: 7, BuyerID: 4, PricePerQuintal: 2350, QuantityQuintals: 5},
+		func(tx *gorm.DB, listing *models.Listing, buyers []int64) error {
+			notified = true
+			return nil
+		})
+
+	// Validations
+	suite.ErrorIs(err, ErrListingClosed)
+	suite.False(notified)
+}
+
+func (suite *MarketSuite) TestCloseOffer_AcceptOnlyOnYourTurn() {
+	// Mocking and Setting Expected Result
+	rows := sqlmock.NewRows([]string{"id", "listing_id", "buyer_id", "buyer_name", "price_per_quintal", "quantity_quintals", "message",
+		"status", "awaiting", "created_at", "updated_at", "seller_id", "commodity", "listing_status"}).
+		AddRow(3, 7, 4, "Mohan Traders", 2380, 20, "", "pending", "buyer", time.Now(), time.Now(), 1, "Wheat", "negotiating")
+	suite.sqlMock.ExpectBegin()
+	suite.sqlMock.ExpectQuery("^SELECT (.+) FROM kisan.listing_offers o (.+) WHERE o.id = (.+) FOR UPDATE OF o, l$").
+		WithArgs(3).
+		WillReturnRows(rows)
+	suite.sqlMock.ExpectRollback()
+
+	// Triggering Function
+	_, err := suite.marketStore.CloseOffer(suite.ctx, &models.Message{OfferID: 3, SenderID: 1, Kind: models.KindAccept},
+		models.SideSeller, models.OfferAccepted, func(tx *gorm.DB, listing *models.Listing, buyers []int64) error { return nil })
+
+	// Validations
+	suite.ErrorIs(err, ErrNotYourTurn)
+}
@@ -0,0 +1,232 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/market/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const threadColumns = `o.id, o.listing_id, o.buyer_id, COALESCE(u.name, '') AS buyer_name, o.price_per_quintal,
+		o.quantity_quintals, COALESCE(o.message, '') AS message, o.status, o.awaiting, o.created_at, o.updated_at,
+		l.seller_id, l.commodity, l.status AS listing_status`
+
+const threadTables = `kisan.listing_offers o
+		JOIN kisan.listings l ON l.id = o.listing_id
+		LEFT JOIN kisan.users u ON u.id = o.buyer_id`
+
+const messageColumns = `m.id, m.offer_id, m.sender_id, m.kind, m.price_per_quintal, m.quantity_quintals,
+		COALESCE(m.body, '') AS body, m.created_at`
+
+var (
+	ErrOfferNotFound = errors.New("offer not found")
+	// ErrOfferClosed is returned when the offer was already accepted or rejected
+	ErrOfferClosed = errors.New("offer is no longer being negotiated")
+	// ErrNotYourTurn is returned when the other side has to answer the latest terms
+	ErrNotYourTurn = errors.New("waiting for the other side to answer")
+)
+
+func (g *marketStore) GetThread(c context.Context, offerID int64) (*models.Thread, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	return getThread(g.store.WithContext(c), offerID, false)
+}
+
+func (g *marketStore) ListThreads(c context.Context, userID int64) ([]models.Thread, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	threads := []models.Thread{}
+	err := g.store.WithContext(c).Raw(`SELECT `+threadColumns+`
+		FROM `+threadTables+`
+		WHERE o.buyer_id = ? OR l.seller_id = ?
+		ORDER BY o.updated_at DESC, o.id DESC
+		LIMIT 100`, userID, userID).Scan(&threads).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return threads, nil
+}
+
+// ListMessages returns a page of the thread, latest first
+func (g *marketStore) ListMessages(c context.Context, offerID int64, beforeID int64, limit int) ([]models.Message, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	messages := []models.Message{}
+	err := g.store.WithContext(c).Raw(`SELECT `+messageColumns+`
+		FROM kisan.offer_messages m
+		WHERE m.offer_id = ? AND (? = 0 OR m.id < ?)
+		ORDER BY m.id DESC
+		LIMIT ?`, offerID, beforeID, beforeID, limit).Scan(&messages).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (g *marketStore) ListMessagesSince(c context.Context, userID int64, afterID int64, limit int) ([]models.Message, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	messages := []models.Message{}
+	err := g.store.WithContext(c).Raw(`SELECT `+messageColumns+`
+		FROM kisan.offer_messages m
+		JOIN kisan.listing_offers o ON o.id = m.offer_id
+		JOIN kisan.listings l ON l.id = o.listing_id
+		WHERE m.id > ? AND (o.buyer_id = ? OR l.seller_id = ?)
+		ORDER BY m.id
+		LIMIT ?`, afterID, userID, userID, limit).Scan(&messages).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (g *marketStore) AddMessage(c context.Context, message *models.Message) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	err := insertMessage(g.store.WithContext(c), message)
+	if err != nil {
+		logger.Log(c).Error("Error inserting message", zap.Error(err))
+	}
+	return err
+}
+
+func (g *marketStore) Counter(c context.Context, message *models.Message, side string) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	err := repo.Transaction(g.store.WithContext(c), func(tx *gorm.DB) error {
+		thread, err := getThread(tx, message.OfferID, true)
+		if err != nil {
+			return err
+		}
+		if thread.Status != models.OfferPending {
+			return ErrOfferClosed
+		}
+		if thread.Awaiting != side {
+			return ErrNotYourTurn
+		}
+		err = tx.Exec(`UPDATE kisan.listing_offers SET price_per_quintal = ?, quantity_quintals = ?, awaiting = ?, updated_at = now()
+			WHERE id = ?`, message.PricePerQuintal, message.QuantityQuintals, otherSide(side), message.OfferID).Error
+		if err != nil {
+			return err
+		}
+		return insertMessage(tx, message)
+	})
+	if err != nil && !isNegotiationError(err) {
+		logger.Log(c).Error("Error countering offer", zap.Error(err))
+	}
+	return err
+}
+
+func (g *marketStore) CloseOffer(c context.Context, message *models.Message, side string, status string, notify Notify) (*models.Listing, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var listing *models.Listing
+	err := repo.Transaction(g.store.WithContext(c), func(tx *gorm.DB) error {
+		thread, err := getThread(tx, message.OfferID, true)
+		if err != nil {
+			return err
+		}
+		if thread.Status != models.OfferPending {
+			return ErrOfferClosed
+		}
+		// either side may walk away, but only the side the terms wait on can accept them
+		if status == models.OfferAccepted && thread.Awaiting != side {
+			return ErrNotYourTurn
+		}
+		err = tx.Exec(`UPDATE kisan.listing_offers SET status = ?, updated_at = now() WHERE id = ?`, status, message.OfferID).Error
+		if err != nil {
+			return err
+		}
+		if err := insertMessage(tx, message); err != nil {
+			return err
+		}
+
+		if listing, err = getListing(tx, thread.ListingID, false); err != nil {
+			return err
+		}
+		buyers, err := pendingBuyers(tx, thread.ListingID)
+		if err != nil {
+			return err
+		}
+		next := listing.Status
+		switch {
+		case status == models.OfferAccepted:
+			next = models.StatusSold
+			err := tx.Exec(`UPDATE kisan.listing_offers SET status = ?, updated_at = now() WHERE listing_id = ? AND status = ?`,
+				models.OfferRejected, thread.ListingID, models.OfferPending).Error
+			if err != nil {
+				return err
+			}
+		case len(buyers) == 0 && listing.Status == models.StatusNegotiating:
+			next = models.StatusActive
+		}
+		if next != listing.Status {
+			if err := tx.Exec(`UPDATE kisan.listings SET status = ?, updated_at = now() WHERE id = ?`, next, listing.ID).Error; err != nil {
+				return err
+			}
+			listing.Status = next
+		}
+		if status != models.OfferAccepted {
+			buyers = nil
+		}
+		return notify(tx, listing, buyers)
+	})
+	if err != nil {
+		if !isNegotiationError(err) {
+			logger.Log(c).Error("Error closing offer", zap.Error(err))
+		}
+		return nil, err
+	}
+	return listing, nil
+}
+
+// getThread reads an offer with its listing, locking both rows for the transaction when lock is set
+func getThread(tx *gorm.DB, offerID int64, lock bool) (*models.Thread, error) {
+	query := `SELECT ` + threadColumns + `
+		FROM ` + threadTables + `
+		WHERE o.id = ?`
+	if lock {
+		query += ` FOR UPDATE OF o, l`
+	}
+	var threads []models.Thread
+	if err := tx.Raw(query, offerID).Scan(&threads).Error; err != nil {
+		return nil, err
+	}
+	if len(threads) == 0 {
+		return nil, ErrOfferNotFound
+	}
+	return &threads[0], nil
+}
+
+func insertMessage(tx *gorm.DB, message *models.Message) error {
+	return tx.Raw(`INSERT INTO kisan.offer_messages (offer_id, sender_id, kind, price_per_quintal, quantity_quintals, body)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id, created_at`,
+		message.OfferID, message.SenderID, message.Kind, message.PricePerQuintal, message.QuantityQuintals, message.Body).
+		Row().Scan(&message.ID, &message.CreatedAt)
+}
+
+func otherSide(side string) string {
+	if side == models.SideSeller {
+		return models.SideBuyer
+	}
+	return models.SideSeller
+}
+
+func isNegotiationError(err error) bool {
+	return errors.Is(err, ErrOfferNotFound) || errors.Is(err, ErrOfferClosed) || errors.Is(err, ErrNotYourTurn)
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/market/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrListingClosed is returned for offers on a sold or expired listing
+	ErrListingClosed = errors.New("listing is no longer open for offers")
+	// ErrOfferPending is returned when the buyer already has an open offer on the listing
+	ErrOfferPending = errors.New("an offer on this listing is already pending")
+)
+
+func (g *marketStore) CreateOffer(c context.Context, offer *models.Offer, notify Notify) (*models.Message, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var listing *models.Listing
+	var message *models.Message
+	err := repo.Transaction(g.store.WithContext(c), func(tx *gorm.DB) error {
+		var err error
+		if listing, err = getListing(tx, offer.ListingID, true); err != nil {
+			return err
+		}
+		if !contains(models.OpenStatuses, listing.Status) {
+			return ErrListingClosed
+		}
+		buyers, err := pendingBuyers(tx, offer.ListingID)
+		if err != nil {
+			return err
+		}
+		for _, buyer := range buyers {
+			if buyer == offer.BuyerID {
+				return ErrOfferPending
+			}
+		}
+
+		err = tx.Raw(`INSERT INTO kisan.listing_offers (listing_id, buyer_id, price_per_quintal, quantity_quintals, message)
+			VALUES (?, ?, ?, ?, ?)
+			RETURNING id, status, awaiting, created_at, updated_at`,
+			offer.ListingID, offer.BuyerID, offer.PricePerQuintal, offer.QuantityQuintals, offer.Message).
+			Row().Scan(&offer.ID, &offer.Status, &offer.Awaiting, &offer.CreatedAt, &offer.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		// the offer opens its negotiation thread
+		message = &models.Message{OfferID: offer.ID, SenderID: offer.BuyerID, Kind: models.KindOffer,
+			PricePerQuintal: &offer.PricePerQuintal, QuantityQuintals: &offer.QuantityQuintals, Body: offer.Message}
+		if err := insertMessage(tx, message); err != nil {
+			return err
+		}
+		if listing.Status == models.StatusActive {
+			err := tx.Exec(`UPDATE kisan.listings SET status = ?, updated_at = now() WHERE id = ?`, models.StatusNegotiating, listing.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+		return notify(tx, listing, buyers)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrListingNotFound) && !errors.Is(err, ErrListingClosed) && !errors.Is(err, ErrOfferPending) {
+			logger.Log(c).Error("Error creating offer", zap.Error(err))
+		}
+		return nil, err
+	}
+	return message, nil
+}
+
+// ListOffers returns the offers on the listing, latest first
+func (g *marketStore) ListOffers(c context.Context, listingID int64, buyerID int64) ([]models.Offer, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	offers := []models.Offer{}
+	err := g.store.WithContext(c).Raw(`SELECT o.id, o.listing_id, o.buyer_id, COALESCE(u.name, '') AS buyer_name,
+			o.price_per_quintal, o.quantity_quintals, COALESCE(o.message, '') AS message, o.status, o.awaiting,
+			o.created_at, o.updated_at
+		FROM kisan.listing_offers o
+		LEFT JOIN kisan.users u ON u.id = o.buyer_id
+		WHERE o.listing_id = ? AND (? = 0 OR o.buyer_id = ?)
+		ORDER BY o.created_at DESC, o.id DESC`, listingID, buyerID, buyerID).Scan(&offers).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return offers, nil
+}
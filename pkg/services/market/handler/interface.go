@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/market/controller"
+	"kisaanSathi/pkg/services/market/db"
+	notification "kisaanSathi/pkg/services/notification/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+type handler struct {
+	controller controller.MarketController
+}
+
+type MarketHandler interface {
+	SearchListings(c *gin.Context)
+	ListMyListings(c *gin.Context)
+	GetListing(c *gin.Context)
+	CreateListing(c *gin.Context)
+	UpdateListingStatus(c *gin.Context)
+	MakeOffer(c *gin.Context)
+	ListOfferThreads(c *gin.Context)
+	GetOfferMessages(c *gin.Context)
+	SendOfferMessage(c *gin.Context)
+	CounterOffer(c *gin.Context)
+	AcceptOffer(c *gin.Context)
+	RejectOffer(c *gin.Context)
+	GetOfferUnreadCounts(c *gin.Context)
+	StreamOfferMessages(c *gin.Context)
+}
+
+// hub is shared by every market controller of the process so all streams see all messages
+var hub = controller.NewHub()
+
+func NewMarketHandler(controller controller.MarketController) MarketHandler {
+	return &handler{
+		controller: controller,
+	}
+}
+
+// MarketController keeps listings open for market.listing.days
+func MarketController(repo repo.DataObject) controller.MarketController {
+	store := db.NewDBObject(repo.Databases.PgDB)
+	return controller.NewMarketController(store, notification.NotificationService(repo), repo.Cache, hub, config.App().Market.Listing.Days)
+}
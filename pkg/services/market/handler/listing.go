@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/network"
+	"kisaanSathi/pkg/services/market/controller"
+	"kisaanSathi/pkg/services/market/db"
+	"kisaanSathi/pkg/services/market/models"
+	"kisaanSathi/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func (f *handler) SearchListings(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	var request models.SearchRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.SearchListings(c, userID, &request)
+	if err != nil {
+		marketError(c, err, request, network.ApiErrors.GetDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) ListMyListings(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+
+	data, err := f.controller.ListUserListings(c, userID)
+	if err != nil {
+		marketError(c, err, nil, network.ApiErrors.GetDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) GetListing(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, listingID, ok := listingParams(c)
+	if !ok {
+		return
+	}
+
+	data, err := f.controller.GetListing(c, userID, listingID)
+	if err != nil {
+		marketError(c, err, nil, network.ApiErrors.GetDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) CreateListing(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	var request models.ListingRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.CreateListing(c, userID, &request)
+	if err != nil {
+		marketError(c, err, request, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, network.SuccessResponse(data))
+}
+
+func (f *handler) UpdateListingStatus(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, listingID, ok := listingParams(c)
+	if !ok {
+		return
+	}
+	var request models.StatusRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.UpdateListingStatus(c, userID, listingID, &request)
+	if err != nil {
+		marketError(c, err, request, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func requireUser(c *gin.Context) (int64, bool) {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, network.FailureResponse(network.ApiErrors.Unauthorized.WithErrorDescription(err.Error())))
+		c.Abort()
+		return 0, false
+	}
+	return userID, true
+}
+
+func listingParams(c *gin.Context) (int64, int64, bool) {
+	userID, ok := requireUser(c)
+	if !ok {
+		return 0, 0, false
+	}
+	listingID, err := utils.GetInt64Param(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, nil))
+		c.Abort()
+		return 0, 0, false
+	}
+	return userID, listingID, true
+}
+
+// marketError maps the marketplace errors to their status, anything else is a dbError
+func marketError(c *gin.Context, err error, request interface{}, dbError *network.Error) {
+	logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+	switch {
+	case errors.Is(err, db.ErrListingNotFound) || errors.Is(err, db.ErrUserNotFound) || errors.Is(err, db.ErrOfferNotFound):
+		c.JSON(http.StatusNotFound, network.FailureResponse(network.ApiErrors.NoDataFound.WithErrorDescription(err.Error())))
+	case errors.Is(err, controller.ErrForbidden):
+		c.JSON(http.StatusForbidden, network.FailureResponse(network.ApiErrors.Forbidden.WithErrorDescription(err.Error())))
+	case errors.Is(err, controller.ErrNoLocation) || errors.Is(err, controller.ErrOwnListing) || errors.Is(err, controller.ErrInvalidOffer):
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+	case errors.Is(err, controller.ErrInvalidTransition) || errors.Is(err, db.ErrStatusChanged) ||
+		errors.Is(err, db.ErrListingClosed) || errors.Is(err, db.ErrOfferPending) ||
+		errors.Is(err, db.ErrOfferClosed) || errors.Is(err, db.ErrNotYourTurn):
+		c.JSON(http.StatusConflict, network.FailureResponse(network.ApiErrors.Conflict.WithErrorDescription(err.Error())))
+	default:
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(dbError.WithErrorDescription(err.Error())))
+	}
+	c.Abort()
+}
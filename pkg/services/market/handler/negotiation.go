@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"io"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/network"
+	"kisaanSathi/pkg/services/market/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// heartbeat keeps idle streams open through proxies
+const heartbeat = 25 * time.Second
+
+func (f *handler) ListOfferThreads(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+
+	data, err := f.controller.ListThreads(c, userID)
+	if err != nil {
+		marketError(c, err, nil, network.ApiErrors.GetDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) GetOfferMessages(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, offerID, ok := listingParams(c)
+	if !ok {
+		return
+	}
+	var request models.MessagesRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.GetMessages(c, userID, offerID, &request)
+	if err != nil {
+		marketError(c, err, request, network.ApiErrors.GetDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) SendOfferMessage(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, offerID, ok := listingParams(c)
+	if !ok {
+		return
+	}
+	var request models.MessageRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.SendMessage(c, userID, offerID, &request)
+	if err != nil {
+		marketError(c, err, request, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, network.SuccessResponse(data))
+}
+
+func (f *handler) CounterOffer(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, offerID, ok := listingParams(c)
+	if !ok {
+		return
+	}
+	var request models.CounterRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.Counter(c, userID, offerID, &request)
+	if err != nil {
+		marketError(c, err, request, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, network.SuccessResponse(data))
+}
+
+func (f *handler) AcceptOffer(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, offerID, request, ok := replyParams(c)
+	if !ok {
+		return
+	}
+
+	data, err := f.controller.Accept(c, userID, offerID, &request)
+	if err != nil {
+		marketError(c, err, request, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) RejectOffer(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, offerID, request, ok := replyParams(c)
+	if !ok {
+		return
+	}
+
+	data, err := f.controller.Reject(c, userID, offerID, &request)
+	if err != nil {
+		marketError(c, err, request, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) GetOfferUnreadCounts(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+
+	data, err := f.controller.UnreadCounts(c, userID)
+	if err != nil {
+		marketError(c, err, nil, network.ApiErrors.GetCacheError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+// StreamOfferMessages is a server-sent event stream of the messages in the user's threads. Each
+// event carries the message id, which the client sends back as Last-Event-ID when it reconnects.
+func (f *handler) StreamOfferMessages(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	lastEventID, _ := strconv.ParseInt(c.GetHeader("Last-Event-ID"), 10, 64)
+
+	backlog, live, cancel, err := f.controller.Subscribe(c, userID, lastEventID)
+	if err != nil {
+		marketError(c, err, nil, network.ApiErrors.GetDBError)
+		return
+	}
+	defer cancel()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	var lastSent int64
+	for _, message := range backlog {
+		c.Render(-1, messageEvent(message))
+		lastSent = message.ID
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case message, open := <-live:
+			if !open {
+				return false
+			}
+			// messages written while the backlog was read arrive twice
+			if message.ID > lastSent {
+				c.Render(-1, messageEvent(message))
+			}
+			return true
+		case <-ticker.C:
+			c.Render(-1, sse.Event{Event: "ping", Data: time.Now().Unix()})
+			return true
+		}
+	})
+}
+
+func messageEvent(message models.Message) sse.Event {
+	return sse.Event{Id: strconv.FormatInt(message.ID, 10), Event: "message", Data: message}
+}
+
+func replyParams(c *gin.Context) (int64, int64, models.ReplyRequest, bool) {
+	var request models.ReplyRequest
+	userID, offerID, ok := listingParams(c)
+	if !ok {
+		return 0, 0, request, false
+	}
+	// the note is optional, so is the body
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			logger.Log(c).Error("Invalid request payload", zap.Error(err))
+			c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+			c.Abort()
+			return 0, 0, request, false
+		}
+	}
+	return userID, offerID, request, true
+}
@@ -0,0 +1,151 @@
+package models
+
+import "time"
+
+// listing states
+const (
+	StatusActive      = "active"
+	StatusNegotiating = "negotiating"
+	StatusSold        = "sold"
+	StatusExpired     = "expired"
+)
+
+// Transitions are the states a listing may move to from each state; sold and expired are final
+var Transitions = map[string][]string{
+	StatusActive:      {StatusNegotiating, StatusSold, StatusExpired},
+	StatusNegotiating: {StatusActive, StatusSold, StatusExpired},
+}
+
+// OpenStatuses are the states in which a listing is shown to buyers and takes offers
+var OpenStatuses = []string{StatusActive, StatusNegotiating}
+
+// offer states
+const (
+	OfferPending  = "pending"
+	OfferAccepted = "accepted"
+	OfferRejected = "rejected"
+)
+
+// roles of kisan.users allowed to sell and to buy
+const (
+	RoleFarmer = "farmer"
+	RoleBuyer  = "buyer"
+)
+
+const (
+	// DefaultListingDays is how long a listing stays open when the farmer does not say
+	DefaultListingDays = 14
+	DefaultRadiusKm    = 50
+	DefaultPageSize    = 20
+)
+
+// Listing is produce a farmer offers for sale, prices are per quintal
+type Listing struct {
+	ID               int64     `json:"id" gorm:"column:id"`
+	SellerID         int64     `json:"sellerId" gorm:"column:seller_id"`
+	SellerName       string    `json:"sellerName,omitempty" gorm:"column:seller_name"`
+	Commodity        string    `json:"commodity" gorm:"column:commodity"`
+	QuantityQuintals float64   `json:"quantityQuintals" gorm:"column:quantity_quintals"`
+	PricePerQuintal  float64   `json:"pricePerQuintal" gorm:"column:price_per_quintal"`
+	Grade            string    `json:"grade" gorm:"column:grade"`
+	PickupAddress    string    `json:"pickupAddress" gorm:"column:pickup_address"`
+	Lat              float64   `json:"lat" gorm:"column:lat"`
+	Lng              float64   `json:"lng" gorm:"column:lng"`
+	PhotoList        string    `json:"-" gorm:"column:photos"`
+	Photos           []string  `json:"photos" gorm:"-"`
+	Status           string    `json:"status" gorm:"column:status"`
+	ExpiresAt        time.Time `json:"expiresAt" gorm:"column:expires_at"`
+	CreatedAt        time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt        time.Time `json:"updatedAt" gorm:"column:updated_at"`
+	// DistanceKm from the buyer, set by searches with a location
+	DistanceKm *float64 `json:"distanceKm,omitempty" gorm:"column:distance_km"`
+}
+
+// ListingRequest places the pickup at lat/lng, or at the farmer's location when they are omitted.
+// Photos are urls of images uploaded beforehand.
+type ListingRequest struct {
+	Commodity        string   `json:"commodity" binding:"required,alphaws,max=100"`
+	QuantityQuintals float64  `json:"quantityQuintals" binding:"required,gt=0,lte=100000"`
+	PricePerQuintal  float64  `json:"pricePerQuintal" binding:"required,gt=0,lte=1000000"`
+	Grade            string   `json:"grade" binding:"required,oneof=A B C FAQ"`
+	PickupAddress    string   `json:"pickupAddress" binding:"required,max=200"`
+	Lat              *float64 `json:"lat" binding:"required_with=Lng,omitempty,latitude"`
+	Lng              *float64 `json:"lng" binding:"required_with=Lat,omitempty,longitude"`
+	Photos           []string `json:"photos" binding:"omitempty,max=6,dive,url,max=500,excludesall=0x2C"`
+	ExpiresInDays    int      `json:"expiresInDays" binding:"omitempty,min=1,max=60"`
+}
+
+// SearchRequest finds open listings within RadiusKm of lat/lng, or of the buyer's location when
+// they are omitted; listings are not filtered by distance when neither is known
+type SearchRequest struct {
+	Commodity string   `form:"commodity" binding:"omitempty,alphaws,max=100"`
+	Grade     string   `form:"grade" binding:"omitempty,oneof=A B C FAQ"`
+	Lat       *float64 `form:"lat" binding:"required_with=Lng,omitempty,latitude"`
+	Lng       *float64 `form:"lng" binding:"required_with=Lat,omitempty,longitude"`
+	RadiusKm  float64  `form:"radiusKm" binding:"omitempty,gt=0,max=500"`
+	Limit     int      `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset    int      `form:"offset" binding:"omitempty,min=0,max=10000"`
+}
+
+// SearchFilter is the resolved form of SearchRequest used by the store
+type SearchFilter struct {
+	Commodity string
+	Grade     string
+	// Lat and Lng are nil when the distance is not known
+	Lat      *float64
+	Lng      *float64
+	RadiusKm float64
+	Limit    int
+	Offset   int
+}
+
+// StatusRequest is the farmer closing or reopening a listing, negotiating is set by offers
+type StatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active sold expired"`
+}
+
+type Offer struct {
+	ID               int64   `json:"id" gorm:"column:id"`
+	ListingID        int64   `json:"listingId" gorm:"column:listing_id"`
+	BuyerID          int64   `json:"buyerId" gorm:"column:buyer_id"`
+	BuyerName        string  `json:"buyerName,omitempty" gorm:"column:buyer_name"`
+	PricePerQuintal  float64 `json:"pricePerQuintal" gorm:"column:price_per_quintal"`
+	QuantityQuintals float64 `json:"quantityQuintals" gorm:"column:quantity_quintals"`
+	Message          string  `json:"message,omitempty" gorm:"column:message"`
+	Status           string  `json:"status" gorm:"column:status"`
+	// Awaiting is the side that has to answer the latest terms, seller or buyer
+	Awaiting  string    `json:"awaiting" gorm:"column:awaiting"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+type OfferRequest struct {
+	PricePerQuintal  float64 `json:"pricePerQuintal" binding:"required,gt=0,lte=1000000"`
+	QuantityQuintals float64 `json:"quantityQuintals" binding:"required,gt=0,lte=100000"`
+	Message          string  `json:"message" binding:"omitempty,max=500"`
+}
+
+// ListingDetail shows the seller every offer and a buyer only their own
+type ListingDetail struct {
+	Listing
+	Offers []Offer `json:"offers"`
+}
+
+// User is the part of kisan.users the marketplace needs
+type User struct {
+	ID   int64    `gorm:"column:id"`
+	Name string   `gorm:"column:name"`
+	Role string   `gorm:"column:role"`
+	Lat  *float64 `gorm:"column:lat"`
+	Lng  *float64 `gorm:"column:lng"`
+}
+
+// CanMove reports whether a listing may go from one state to another
+func CanMove(from string, to string) bool {
+	for _, next := range Transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
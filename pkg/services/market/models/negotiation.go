@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// kinds of messages in the negotiation thread of an offer
+const (
+	KindOffer   = "offer"
+	KindCounter = "counter"
+	KindAccept  = "accept"
+	KindReject  = "reject"
+	KindText    = "text"
+)
+
+// sides of a negotiation, Offer.Awaiting names the side whose answer is due
+const (
+	SideSeller = "seller"
+	SideBuyer  = "buyer"
+)
+
+const (
+	DefaultMessagePageSize = 50
+	// MaxReplayMessages bounds the messages replayed to a stream reconnecting with Last-Event-ID
+	MaxReplayMessages = 200
+)
+
+// Message is an entry of the negotiation thread of an offer. Counter offers carry the new terms.
+type Message struct {
+	ID               int64     `json:"id" gorm:"column:id"`
+	OfferID          int64     `json:"offerId" gorm:"column:offer_id"`
+	SenderID         int64     `json:"senderId" gorm:"column:sender_id"`
+	Kind             string    `json:"kind" gorm:"column:kind"`
+	PricePerQuintal  *float64  `json:"pricePerQuintal,omitempty" gorm:"column:price_per_quintal"`
+	QuantityQuintals *float64  `json:"quantityQuintals,omitempty" gorm:"column:quantity_quintals"`
+	Body             string    `json:"body,omitempty" gorm:"column:body"`
+	CreatedAt        time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+// Thread is an offer as a negotiation between its buyer and the listing's seller
+type Thread struct {
+	Offer
+	SellerID  int64  `json:"sellerId" gorm:"column:seller_id"`
+	Commodity string `json:"commodity" gorm:"column:commodity"`
+	// ListingStatus is the status of the listing the offer is on
+	ListingStatus string `json:"listingStatus" gorm:"column:listing_status"`
+	Unread        int64  `json:"unread" gorm:"-"`
+}
+
+// Side is the side the user takes in the thread, empty when they are not part of it
+func (t *Thread) Side(userID int64) string {
+	switch userID {
+	case t.SellerID:
+		return SideSeller
+	case t.BuyerID:
+		return SideBuyer
+	}
+	return ""
+}
+
+// Counterpart is the other party of the thread
+func (t *Thread) Counterpart(userID int64) int64 {
+	if userID == t.SellerID {
+		return t.BuyerID
+	}
+	return t.SellerID
+}
+
+type MessageRequest struct {
+	Body string `json:"body" binding:"required,max=1000"`
+}
+
+type CounterRequest struct {
+	PricePerQuintal  float64 `json:"pricePerQuintal" binding:"required,gt=0,lte=1000000"`
+	QuantityQuintals float64 `json:"quantityQuintals" binding:"required,gt=0,lte=100000"`
+	Message          string  `json:"message" binding:"omitempty,max=1000"`
+}
+
+// ReplyRequest accepts or rejects the terms on the table with an optional note
+type ReplyRequest struct {
+	Message string `json:"message" binding:"omitempty,max=1000"`
+}
+
+// MessagesRequest pages back through a thread, messages before BeforeID, latest first
+type MessagesRequest struct {
+	BeforeID int64 `form:"before" binding:"omitempty,min=1"`
+	Limit    int   `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type ThreadMessages struct {
+	Thread   Thread    `json:"thread"`
+	Messages []Message `json:"messages"`
+}
+
+// UnreadCounts are the unread negotiation messages of a user per offer
+type UnreadCounts struct {
+	Total   int64           `json:"total"`
+	Threads map[int64]int64 `json:"threads"`
+}
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"context"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/notification/models"
+)
+
+func (s *controller) RegisterDevice(ctx context.Context, userID int64, sessionID string, request *models.RegisterDeviceRequest) (*models.Device, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	device := &models.Device{
+		UserID:    userID,
+		SessionID: sessionID,
+		Token:     request.Token,
+		Platform:  request.Platform,
+	}
+	if err := s.notificationStore.UpsertDevice(ctx, device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (s *controller) UnregisterDevice(ctx context.Context, userID int64, token string) (bool, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+	return s.notificationStore.DeleteDevice(ctx, userID, token)
+}
@@ -0,0 +1,284 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/notification/db"
+	"kisaanSathi/pkg/services/notification/models"
+	"kisaanSathi/pkg/services/notification/push"
+	"kisaanSathi/pkg/services/notification/sms"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type DispatcherConfig struct {
+	// BatchSize is the number of deliveries claimed per round
+	BatchSize int
+	// MaxAttempts after which a delivery is marked failed
+	MaxAttempts int
+	// BaseBackoff is doubled after every failed attempt up to MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Lease is how long a claimed delivery is hidden from other workers
+	Lease time.Duration
+	// SMSFallback reports whether an undelivered push falls back to sms for the user, always when nil
+	SMSFallback func(ctx context.Context, userID int64) bool
+}
+
+type dispatcher struct {
+	store   db.DispatchStore
+	sender  push.PushSender
+	gateway sms.SMSGateway
+	cfg     DispatcherConfig
+	now     func() time.Time
+}
+
+// Dispatcher delivers queued notifications to the users' devices and phones
+type Dispatcher interface {
+	// DeliverPending drains due push and sms deliveries until none are left and returns how many were processed
+	DeliverPending(ctx context.Context) (int, error)
+}
+
+// NewDispatcher delivers pushes with sender and sms with gateway. Either may be nil, the deliveries
+// of its channel are then left pending until it is configured.
+func NewDispatcher(store db.DispatchStore, sender push.PushSender, gateway sms.SMSGateway, cfg DispatcherConfig) Dispatcher {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = 30 * time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = time.Hour
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = 5 * time.Minute
+	}
+	return &dispatcher{
+		store:   store,
+		sender:  sender,
+		gateway: gateway,
+		cfg:     cfg,
+		now:     time.Now,
+	}
+}
+
+func (d *dispatcher) DeliverPending(ctx context.Context) (int, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	// push runs first so the sms fallbacks it queues go out in the same round
+	processed := 0
+	for _, channel := range []string{models.ChannelPush, models.ChannelSMS} {
+		if (channel == models.ChannelPush && d.sender == nil) || (channel == models.ChannelSMS && d.gateway == nil) {
+			continue
+		}
+		count, err := d.drain(ctx, channel)
+		processed += count
+		if err != nil {
+			return processed, err
+		}
+	}
+	return processed, nil
+}
+
+func (d *dispatcher) drain(ctx context.Context, channel string) (int, error) {
+	processed := 0
+	for ctx.Err() == nil {
+		deliveries, err := d.store.ClaimPending(ctx, channel, d.cfg.BatchSize, d.cfg.Lease)
+		if err != nil {
+			return processed, err
+		}
+		for _, delivery := range deliveries {
+			update := d.process(ctx, delivery)
+			if err := d.store.UpdateDelivery(ctx, update); err != nil {
+				// the lease expires and the delivery is retried
+				logger.Log(ctx).Error("failed to record delivery", zap.Int64("notificationId", delivery.NotificationID), zap.Error(err))
+			}
+			processed++
+		}
+		if len(deliveries) < d.cfg.BatchSize {
+			break
+		}
+	}
+	return processed, nil
+}
+
+// process applies the user's preferences before handing the delivery to its channel
+func (d *dispatcher) process(ctx context.Context, delivery models.PendingDelivery) models.DeliveryUpdate {
+	update := models.DeliveryUpdate{NotificationID: delivery.NotificationID, Channel: delivery.Channel}
+
+	preferences, err := d.store.GetPreferences(ctx, delivery.UserID)
+	if err != nil {
+		return d.retry(update, delivery.Attempts, err)
+	}
+	now := d.now()
+	sent, err := sentToday(ctx, d.store, preferences, now)
+	if err != nil {
+		return d.retry(update, delivery.Attempts, err)
+	}
+
+	decision := decide(preferences, delivery.Type, delivery.Channel, sent, now)
+	if !decision.Deliver {
+		update.LastError = decision.Reason
+		if decision.DeferUntil != nil {
+			update.Status = models.DeliveryPending
+			update.NextAttemptAt = *decision.DeferUntil
+			update.Deferred = true
+			return update
+		}
+		update.Status = models.DeliverySkipped
+		if decision.Reason == models.ReasonChannelDisabled && delivery.Channel == models.ChannelPush {
+			d.fallbackToSMS(ctx, delivery, preferences)
+		}
+		return update
+	}
+
+	if delivery.Channel == models.ChannelSMS {
+		return d.deliverSMS(ctx, delivery)
+	}
+	update = d.deliverPush(ctx, delivery)
+	if update.Status == models.DeliverySkipped || update.Status == models.DeliveryFailed {
+		d.fallbackToSMS(ctx, delivery, preferences)
+	}
+	return update
+}
+
+// deliverPush sends the notification to every device of the user. The delivery succeeds
+// when at least one device accepted it; invalid tokens are pruned on the way.
+func (d *dispatcher) deliverPush(ctx context.Context, delivery models.PendingDelivery) models.DeliveryUpdate {
+	update := models.DeliveryUpdate{NotificationID: delivery.NotificationID, Channel: delivery.Channel}
+
+	devices, err := d.store.ListDevices(ctx, delivery.UserID)
+	if err != nil {
+		return d.retry(update, delivery.Attempts, err)
+	}
+	if len(devices) == 0 {
+		update.Status = models.DeliverySkipped
+		update.LastError = "no registered devices"
+		return update
+	}
+
+	title, ok := models.PushTitles[delivery.Type]
+	if !ok {
+		title = models.DefaultPushTitle
+	}
+	sent := 0
+	var lastErr error
+	for _, device := range devices {
+		err := d.sender.Send(ctx, push.Message{
+			Token: device.Token,
+			Title: title,
+			Body:  delivery.Message,
+			Data: map[string]string{
+				"notificationId": strconv.FormatInt(delivery.NotificationID, 10),
+				"type":           delivery.Type,
+			},
+		})
+		switch {
+		case err == nil:
+			sent++
+		case errors.Is(err, push.ErrInvalidToken):
+			logger.Log(ctx).Info("pruning invalid device token", zap.Int64("deviceId", device.ID), zap.Error(err))
+			if err := d.store.DeleteToken(ctx, device.Token); err != nil {
+				logger.Log(ctx).Error("failed to prune device token", zap.Int64("deviceId", device.ID), zap.Error(err))
+			}
+		default:
+			lastErr = err
+		}
+	}
+
+	switch {
+	case sent > 0:
+		update.Status = models.DeliverySent
+	case lastErr != nil:
+		return d.retry(update, delivery.Attempts, lastErr)
+	default:
+		update.Status = models.DeliverySkipped
+		update.LastError = "all device tokens were invalid"
+	}
+	return update
+}
+
+// deliverSMS sends the notification as a templated (Hindi by default) sms to the user's phone
+func (d *dispatcher) deliverSMS(ctx context.Context, delivery models.PendingDelivery) models.DeliveryUpdate {
+	update := models.DeliveryUpdate{NotificationID: delivery.NotificationID, Channel: delivery.Channel}
+
+	contact, err := d.store.GetContact(ctx, delivery.UserID)
+	if err != nil {
+		return d.retry(update, delivery.Attempts, err)
+	}
+	if contact.Phone == "" {
+		update.Status = models.DeliverySkipped
+		update.LastError = "no phone number"
+		return update
+	}
+	text, err := sms.Render(contact.Language, delivery.Type, sms.TemplateData{Message: delivery.Message})
+	if err != nil {
+		update.Status = models.DeliveryFailed
+		update.LastError = err.Error()
+		return update
+	}
+
+	reference, err := d.gateway.Send(ctx, sms.Message{
+		To:        contact.Phone,
+		Text:      text,
+		Reference: strconv.FormatInt(delivery.NotificationID, 10),
+	})
+	switch {
+	case err == nil:
+		update.Status = models.DeliverySent
+		update.ProviderRef = reference
+	case errors.Is(err, sms.ErrInvalidNumber):
+		update.Status = models.DeliveryFailed
+		update.LastError = err.Error()
+	default:
+		return d.retry(update, delivery.Attempts, err)
+	}
+	return update
+}
+
+// fallbackToSMS queues an sms for a push that could not be delivered when the user allows sms
+// and the fallback is rolled out to them
+func (d *dispatcher) fallbackToSMS(ctx context.Context, delivery models.PendingDelivery, preferences *models.Preferences) {
+	if !preferences.Enabled(models.ChannelSMS) {
+		return
+	}
+	if d.cfg.SMSFallback != nil && !d.cfg.SMSFallback(ctx, delivery.UserID) {
+		logger.Log(ctx).Debug("sms fallback is off for the user", zap.Int64("notificationId", delivery.NotificationID), zap.Int64("userId", delivery.UserID))
+		return
+	}
+	if err := d.store.QueueDelivery(ctx, delivery.NotificationID, models.ChannelSMS); err != nil {
+		logger.Log(ctx).Error("failed to queue sms fallback", zap.Int64("notificationId", delivery.NotificationID), zap.Error(err))
+	}
+}
+
+func (d *dispatcher) retry(update models.DeliveryUpdate, attempts int, err error) models.DeliveryUpdate {
+	update.LastError = err.Error()
+	if attempts >= d.cfg.MaxAttempts {
+		update.Status = models.DeliveryFailed
+		update.LastError = fmt.Sprintf("giving up after %d attempts: %s", attempts, err.Error())
+		return update
+	}
+	update.Status = models.DeliveryPending
+	update.NextAttemptAt = d.now().Add(d.backoff(attempts))
+	return update
+}
+
+func (d *dispatcher) backoff(attempts int) time.Duration {
+	backoff := d.cfg.BaseBackoff
+	for i := 1; i < attempts && backoff < d.cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > d.cfg.MaxBackoff {
+		backoff = d.cfg.MaxBackoff
+	}
+	return backoff
+}
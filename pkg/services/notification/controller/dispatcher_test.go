@@ -0,0 +1,351 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/services/notification/models"
+	"kisaanSathi/pkg/services/notification/push"
+	"kisaanSathi/pkg/services/notification/sms"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDeliveryStore struct {
+	pending     []models.PendingDelivery
+	updates     []models.DeliveryUpdate
+	devices     map[int64][]models.Device
+	pruned      []string
+	contacts    map[int64]*models.Contact
+	preferences map[int64]*models.Preferences
+	sentToday   int
+}
+
+func (f *fakeDeliveryStore) ClaimPending(ctx context.Context, channel string, limit int, lease time.Duration) ([]models.PendingDelivery, error) {
+	var claimed, rest []models.PendingDelivery
+	for _, delivery := range f.pending {
+		if delivery.Channel == channel && len(claimed) < limit {
+			claimed = append(claimed, delivery)
+			continue
+		}
+		rest = append(rest, delivery)
+	}
+	f.pending = rest
+	return claimed, nil
+}
+
+func (f *fakeDeliveryStore) QueueDelivery(ctx context.Context, notificationID int64, channel string) error {
+	f.pending = append(f.pending, models.PendingDelivery{NotificationID: notificationID, Channel: channel, Attempts: 1, UserID: 1, Message: "Wheat ₹2250", Type: models.TypePrice})
+	return nil
+}
+
+func (f *fakeDeliveryStore) ApplyDeliveryReport(ctx context.Context, providerRef string, status string, reportError string) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeDeliveryStore) GetPreferences(ctx context.Context, userID int64) (*models.Preferences, error) {
+	if preferences, ok := f.preferences[userID]; ok {
+		return preferences, nil
+	}
+	preferences := models.DefaultPreferences(userID)
+	preferences.Channels = []string{models.ChannelInApp, models.ChannelPush}
+	return preferences, nil
+}
+
+func (f *fakeDeliveryStore) CountSentSince(ctx context.Context, userID int64, since time.Time) (int, error) {
+	return f.sentToday, nil
+}
+
+func (f *fakeDeliveryStore) SavePreferences(ctx context.Context, preferences *models.Preferences) error {
+	return nil
+}
+
+func (f *fakeDeliveryStore) GetContact(ctx context.Context, userID int64) (*models.Contact, error) {
+	if contact, ok := f.contacts[userID]; ok {
+		return contact, nil
+	}
+	return &models.Contact{}, nil
+}
+
+func (f *fakeDeliveryStore) UpdateDelivery(ctx context.Context, update models.DeliveryUpdate) error {
+	f.updates = append(f.updates, update)
+	return nil
+}
+
+func (f *fakeDeliveryStore) UpsertDevice(ctx context.Context, device *models.Device) error {
+	return nil
+}
+
+func (f *fakeDeliveryStore) DeleteDevice(ctx context.Context, userID int64, token string) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeDeliveryStore) DeleteToken(ctx context.Context, token string) error {
+	f.pruned = append(f.pruned, token)
+	return nil
+}
+
+func (f *fakeDeliveryStore) ListDevices(ctx context.Context, userID int64) ([]models.Device, error) {
+	return f.devices[userID], nil
+}
+
+func TestDispatcher_DeliverPending(t *testing.T) {
+	now := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		desc           string
+		attempts       int
+		devices        []models.Device
+		invalidTokens  []string
+		sendErr        error
+		expectedStatus string
+		expectedPruned []string
+		expectedNext   time.Time
+	}{
+		{
+			desc:           "Sent",
+			attempts:       1,
+			devices:        []models.Device{{ID: 1, Token: "good"}},
+			expectedStatus: models.DeliverySent,
+		}, {
+			desc:           "NoDevices",
+			attempts:       1,
+			expectedStatus: models.DeliverySkipped,
+		}, {
+			desc:           "PrunesInvalidTokenAndSendsToOthers",
+			attempts:       1,
+			devices:        []models.Device{{ID: 1, Token: "stale"}, {ID: 2, Token: "good"}},
+			invalidTokens:  []string{"stale"},
+			expectedStatus: models.DeliverySent,
+			expectedPruned: []string{"stale"},
+		}, {
+			desc:           "AllTokensInvalid",
+			attempts:       1,
+			devices:        []models.Device{{ID: 1, Token: "stale"}},
+			invalidTokens:  []string{"stale"},
+			expectedStatus: models.DeliverySkipped,
+			expectedPruned: []string{"stale"},
+		}, {
+			desc:           "RetriesWithBackoff",
+			attempts:       3,
+			devices:        []models.Device{{ID: 1, Token: "good"}},
+			sendErr:        errors.New("UNAVAILABLE"),
+			expectedStatus: models.DeliveryPending,
+			expectedNext:   now.Add(2 * time.Minute),
+		}, {
+			desc:           "FailsAfterMaxAttempts",
+			attempts:       5,
+			devices:        []models.Device{{ID: 1, Token: "good"}},
+			sendErr:        errors.New("UNAVAILABLE"),
+			expectedStatus: models.DeliveryFailed,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.desc, func(t *testing.T) {
+			store := &fakeDeliveryStore{
+				pending: []models.PendingDelivery{{NotificationID: 7, Channel: models.ChannelPush, Attempts: testCase.attempts, UserID: 1, Message: "Wheat ₹2250", Type: models.TypePrice}},
+				devices: map[int64][]models.Device{1: testCase.devices},
+			}
+			sender := push.NewFakeSender()
+			sender.Err = testCase.sendErr
+			for _, token := range testCase.invalidTokens {
+				sender.InvalidTokens[token] = true
+			}
+			d := NewDispatcher(store, sender, sms.NewMockGateway(), DispatcherConfig{MaxAttempts: 5, BaseBackoff: 30 * time.Second}).(*dispatcher)
+			d.now = func() time.Time { return now }
+
+			processed, err := d.DeliverPending(context.TODO())
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, processed)
+			assert.Len(t, store.updates, 1)
+			assert.Equal(t, testCase.expectedStatus, store.updates[0].Status)
+			assert.Equal(t, testCase.expectedPruned, store.pruned)
+			if !testCase.expectedNext.IsZero() {
+				assert.Equal(t, testCase.expectedNext, store.updates[0].NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestDispatcher_DisabledChannelIsLeftPending(t *testing.T) {
+	store := &fakeDeliveryStore{pending: []models.PendingDelivery{
+		{NotificationID: 7, Channel: models.ChannelPush, Attempts: 1, UserID: 1, Message: "Wheat ₹2250", Type: models.TypePrice},
+		{NotificationID: 8, Channel: models.ChannelSMS, Attempts: 1, UserID: 1, Message: "Wheat ₹2250", Type: models.TypePrice},
+	}}
+	d := NewDispatcher(store, nil, sms.NewMockGateway(), DispatcherConfig{})
+
+	processed, err := d.DeliverPending(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, int64(8), store.updates[0].NotificationID)
+	assert.Len(t, store.pending, 1, "the push waits for a sender")
+	assert.Equal(t, models.ChannelPush, store.pending[0].Channel)
+}
+
+func TestDispatcher_SMSFallback(t *testing.T) {
+	now := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		desc            string
+		channels        []string
+		phone           string
+		invalidNumber   bool
+		fallbackOff     bool
+		expectedUpdates []models.DeliveryUpdate
+		expectedSMS     int
+	}{
+		{
+			desc:     "FallsBackWhenNoDevices",
+			channels: []string{models.ChannelPush, models.ChannelSMS},
+			phone:    "9876543210",
+			expectedUpdates: []models.DeliveryUpdate{
+				{NotificationID: 7, Channel: models.ChannelPush, Status: models.DeliverySkipped, LastError: "no registered devices"},
+				{NotificationID: 7, Channel: models.ChannelSMS, Status: models.DeliverySent, ProviderRef: "mock-1"},
+			},
+			expectedSMS: 1,
+		}, {
+			desc:     "SMSDisabled",
+			channels: []string{models.ChannelPush},
+			phone:    "9876543210",
+			expectedUpdates: []models.DeliveryUpdate{
+				{NotificationID: 7, Channel: models.ChannelPush, Status: models.DeliverySkipped, LastError: "no registered devices"},
+			},
+		}, {
+			desc:        "FallbackFlagOff",
+			channels:    []string{models.ChannelPush, models.ChannelSMS},
+			phone:       "9876543210",
+			fallbackOff: true,
+			expectedUpdates: []models.DeliveryUpdate{
+				{NotificationID: 7, Channel: models.ChannelPush, Status: models.DeliverySkipped, LastError: "no registered devices"},
+			},
+		}, {
+			desc:     "NoPhone",
+			channels: []string{models.ChannelPush, models.ChannelSMS},
+			expectedUpdates: []models.DeliveryUpdate{
+				{NotificationID: 7, Channel: models.ChannelPush, Status: models.DeliverySkipped, LastError: "no registered devices"},
+				{NotificationID: 7, Channel: models.ChannelSMS, Status: models.DeliverySkipped, LastError: "no phone number"},
+			},
+		}, {
+			desc:          "InvalidNumber",
+			channels:      []string{models.ChannelPush, models.ChannelSMS},
+			phone:         "123",
+			invalidNumber: true,
+			expectedUpdates: []models.DeliveryUpdate{
+				{NotificationID: 7, Channel: models.ChannelPush, Status: models.DeliverySkipped, LastError: "no registered devices"},
+				{NotificationID: 7, Channel: models.ChannelSMS, Status: models.DeliveryFailed, LastError: "invalid phone number: 123"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.desc, func(t *testing.T) {
+			store := &fakeDeliveryStore{
+				pending:     []models.PendingDelivery{{NotificationID: 7, Channel: models.ChannelPush, Attempts: 1, UserID: 1, Message: "Wheat ₹2250", Type: models.TypePrice}},
+				contacts:    map[int64]*models.Contact{1: {Phone: testCase.phone, Language: "hi"}},
+				preferences: map[int64]*models.Preferences{1: {UserID: 1, Types: models.AllTypes, Channels: testCase.channels}},
+			}
+			gateway := sms.NewMockGateway()
+			if testCase.invalidNumber {
+				gateway.InvalidNumbers[testCase.phone] = true
+			}
+			d := NewDispatcher(store, push.NewFakeSender(), gateway, DispatcherConfig{MaxAttempts: 5, SMSFallback: func(ctx context.Context, userID int64) bool {
+				return !testCase.fallbackOff
+			}}).(*dispatcher)
+			d.now = func() time.Time { return now }
+
+			processed, err := d.DeliverPending(context.TODO())
+
+			assert.NoError(t, err)
+			assert.Equal(t, len(testCase.expectedUpdates), processed)
+			assert.Equal(t, testCase.expectedUpdates, store.updates)
+			assert.Len(t, gateway.Messages(), testCase.expectedSMS)
+		})
+	}
+}
+
+func TestDispatcher_Preferences(t *testing.T) {
+	// 23:30 IST
+	now := time.Date(2025, 7, 1, 18, 0, 0, 0, time.UTC)
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	testCases := []struct {
+		desc           string
+		preferences    models.Preferences
+		sentToday      int
+		expectedStatus string
+		expectedReason string
+		expectedNext   time.Time
+		expectedSMS    bool
+	}{
+		{
+			desc:           "TypeMuted",
+			preferences:    models.Preferences{Types: []string{models.TypeScheme}, Channels: models.DefaultChannels},
+			expectedStatus: models.DeliverySkipped,
+			expectedReason: models.ReasonTypeMuted,
+		}, {
+			desc:           "QuietHoursDefersToLocalMorning",
+			preferences:    models.Preferences{Types: models.AllTypes, Channels: models.DefaultChannels, QuietHours: &models.QuietHours{Start: "22:00", End: "06:00"}, Timezone: "Asia/Kolkata"},
+			expectedStatus: models.DeliveryPending,
+			expectedReason: models.ReasonQuietHours,
+			expectedNext:   time.Date(2025, 7, 2, 6, 0, 0, 0, ist),
+		}, {
+			desc:           "OutsideQuietHours",
+			preferences:    models.Preferences{Types: models.AllTypes, Channels: models.DefaultChannels, QuietHours: &models.QuietHours{Start: "13:00", End: "15:00"}, Timezone: "Asia/Kolkata"},
+			expectedStatus: models.DeliverySent,
+		}, {
+			desc:           "DailyCapDefersToLocalMidnight",
+			preferences:    models.Preferences{Types: models.AllTypes, Channels: models.DefaultChannels, DailyCap: 3, Timezone: "Asia/Kolkata"},
+			sentToday:      3,
+			expectedStatus: models.DeliveryPending,
+			expectedReason: models.ReasonDailyCap,
+			expectedNext:   time.Date(2025, 7, 2, 0, 0, 0, 0, ist),
+		}, {
+			desc:           "DailyCapDefersPastQuietHours",
+			preferences:    models.Preferences{Types: models.AllTypes, Channels: models.DefaultChannels, DailyCap: 3, QuietHours: &models.QuietHours{Start: "23:45", End: "06:00"}, Timezone: "Asia/Kolkata"},
+			sentToday:      3,
+			expectedStatus: models.DeliveryPending,
+			expectedReason: models.ReasonDailyCap,
+			expectedNext:   time.Date(2025, 7, 2, 6, 0, 0, 0, ist),
+		}, {
+			desc:           "UnderDailyCap",
+			preferences:    models.Preferences{Types: models.AllTypes, Channels: models.DefaultChannels, DailyCap: 3},
+			sentToday:      2,
+			expectedStatus: models.DeliverySent,
+		}, {
+			desc:           "PushDisabledFallsBackToSMS",
+			preferences:    models.Preferences{Types: models.AllTypes, Channels: []string{models.ChannelInApp, models.ChannelSMS}},
+			expectedStatus: models.DeliverySkipped,
+			expectedReason: models.ReasonChannelDisabled,
+			expectedSMS:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.desc, func(t *testing.T) {
+			preferences := testCase.preferences
+			preferences.UserID = 1
+			store := &fakeDeliveryStore{
+				pending:     []models.PendingDelivery{{NotificationID: 7, Channel: models.ChannelPush, Attempts: 1, UserID: 1, Message: "Wheat ₹2250", Type: models.TypePrice}},
+				devices:     map[int64][]models.Device{1: {{ID: 1, Token: "good"}}},
+				contacts:    map[int64]*models.Contact{1: {Phone: "9876543210"}},
+				preferences: map[int64]*models.Preferences{1: &preferences},
+				sentToday:   testCase.sentToday,
+			}
+			gateway := sms.NewMockGateway()
+			d := NewDispatcher(store, push.NewFakeSender(), gateway, DispatcherConfig{MaxAttempts: 5}).(*dispatcher)
+			d.now = func() time.Time { return now }
+
+			_, err := d.DeliverPending(context.TODO())
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedStatus, store.updates[0].Status)
+			assert.Equal(t, testCase.expectedReason, store.updates[0].LastError)
+			if !testCase.expectedNext.IsZero() {
+				assert.True(t, testCase.expectedNext.Equal(store.updates[0].NextAttemptAt), "next attempt %s", store.updates[0].NextAttemptAt)
+				assert.True(t, store.updates[0].Deferred)
+			}
+			assert.Equal(t, testCase.expectedSMS, len(gateway.Messages()) == 1)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"context"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/notification/db"
+	"kisaanSathi/pkg/services/notification/models"
+
+	"gorm.io/gorm"
+)
+
+type controller struct {
+	notificationStore db.Store
+	cache             repo.RedisInterface
+}
+
+// NotificationService is the single place through which every module writes notifications
+type NotificationService interface {
+	// Notify writes a notification for the user. The notification is nil when the user muted its type.
+	Notify(ctx context.Context, userID int64, notificationType string, message string) (*models.Notification, error)
+	// NotifyTx writes a notification inside the caller's transaction. The unread count is cleared
+	// once a transaction started by repo.Transaction commits, at once for any other tx.
+	NotifyTx(ctx context.Context, tx *gorm.DB, userID int64, notificationType string, message string) (*models.Notification, error)
+}
+
+type NotificationController interface {
+	NotificationService
+	List(ctx context.Context, userID int64, request *models.ListNotificationsRequest) (*models.NotificationPage, error)
+	MarkRead(ctx context.Context, userID int64, notificationID int64) (bool, error)
+	MarkAllRead(ctx context.Context, userID int64, notificationType string) (int64, error)
+	UnreadCount(ctx context.Context, userID int64) (*models.UnreadCount, error)
+	RegisterDevice(ctx context.Context, userID int64, sessionID string, request *models.RegisterDeviceRequest) (*models.Device, error)
+	UnregisterDevice(ctx context.Context, userID int64, token string) (bool, error)
+	GetPreferences(ctx context.Context, userID int64) (*models.Preferences, error)
+	SavePreferences(ctx context.Context, userID int64, request *models.PreferencesRequest) (*models.Preferences, error)
+	PreviewPreferences(ctx context.Context, userID int64, request *models.PreviewRequest) (*models.Preview, error)
+	ApplyDeliveryReport(ctx context.Context, request *models.DeliveryReportRequest) (bool, error)
+}
+
+func NewNotificationController(notificationStore db.Store, cache repo.RedisInterface) NotificationController {
+	return &controller{
+		notificationStore: notificationStore,
+		cache:             cache,
+	}
+}
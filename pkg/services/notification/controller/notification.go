@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/notification/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// unreadCountTTL bounds how long a cached count can be wrong
+	unreadCountTTL = time.Minute
+	// unreadCountStaleTTL keeps a count from being cached right after it changed, so that a read
+	// that counted before the change commits cannot cache its old count
+	unreadCountStaleTTL = 10 * time.Second
+	// unreadCountStale marks a count that changed, it is never returned
+	unreadCountStale int64 = -1
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+func (s *controller) Notify(ctx context.Context, userID int64, notificationType string, message string) (*models.Notification, error) {
+	return s.NotifyTx(ctx, nil, userID, notificationType, message)
+}
+
+func (s *controller) NotifyTx(ctx context.Context, tx *gorm.DB, userID int64, notificationType string, message string) (*models.Notification, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	if strings.TrimSpace(message) == "" {
+		return nil, errors.New("notification message cannot be blank")
+	}
+	preferences, err := s.notificationStore.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	inbox := decide(preferences, notificationType, models.ChannelInApp, 0, time.Now())
+	if inbox.Reason == models.ReasonTypeMuted {
+		logger.Log(ctx).Debug("notification type muted", zap.Int64("userId", userID), zap.String("type", notificationType))
+		return nil, nil
+	}
+	notification := &models.Notification{
+		UserID:  userID,
+		Message: message,
+		Type:    notificationType,
+		// with the inbox disabled the notification is kept as history without adding to the unread count
+		Read: !inbox.Deliver,
+	}
+	if err := s.notificationStore.Insert(ctx, tx, notification, primaryChannel(preferences)); err != nil {
+		return nil, err
+	}
+	if !notification.Read {
+		// inside a transaction the count is cached again from the old rows until it commits
+		repo.AfterCommit(tx, func() {
+			s.invalidateUnreadCount(context.WithoutCancel(ctx), userID)
+		})
+	}
+	return notification, nil
+}
+
+// primaryChannel is the first channel a notification is delivered on, push falls back to sms later
+func primaryChannel(preferences *models.Preferences) string {
+	switch {
+	case preferences.Enabled(models.ChannelPush):
+		return models.ChannelPush
+	case preferences.Enabled(models.ChannelSMS):
+		return models.ChannelSMS
+	default:
+		return ""
+	}
+}
+
+func (s *controller) List(ctx context.Context, userID int64, request *models.ListNotificationsRequest) (*models.NotificationPage, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	beforeID, err := decodeCursor(request.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = models.DefaultPageSize
+	}
+	if limit > models.MaxPageSize {
+		limit = models.MaxPageSize
+	}
+
+	// fetch one extra row to know whether there is a next page
+	notifications, err := s.notificationStore.List(ctx, userID, models.ListFilter{
+		UnreadOnly: request.Unread,
+		Type:       request.Type,
+		BeforeID:   beforeID,
+		Limit:      limit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.NotificationPage{Notifications: notifications}
+	if len(notifications) > limit {
+		page.Notifications = notifications[:limit]
+		page.NextCursor = encodeCursor(page.Notifications[limit-1].ID)
+	}
+	if page.Notifications == nil {
+		page.Notifications = []models.Notification{}
+	}
+	return page, nil
+}
+
+func (s *controller) MarkRead(ctx context.Context, userID int64, notificationID int64) (bool, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	found, err := s.notificationStore.MarkRead(ctx, userID, notificationID)
+	if err != nil || !found {
+		return false, err
+	}
+	s.invalidateUnreadCount(ctx, userID)
+	return true, nil
+}
+
+func (s *controller) MarkAllRead(ctx context.Context, userID int64, notificationType string) (int64, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	updated, err := s.notificationStore.MarkAllRead(ctx, userID, notificationType)
+	if err != nil {
+		return 0, err
+	}
+	s.invalidateUnreadCount(ctx, userID)
+	return updated, nil
+}
+
+// UnreadCount serves the badge count from redis and falls back to postgres on a miss. The count is
+// cached only when the key is free, so it never replaces the stale mark of a newer change.
+func (s *controller) UnreadCount(ctx context.Context, userID int64) (*models.UnreadCount, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	key := unreadCountKey(userID)
+	var cached int64
+	err := s.cache.GetValue(ctx, key, &cached)
+	if err == nil && cached != unreadCountStale {
+		return &models.UnreadCount{Unread: cached}, nil
+	}
+
+	count, err := s.notificationStore.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if cached != unreadCountStale {
+		if err := s.cache.SetValue(ctx, key, count, int(unreadCountTTL.Milliseconds()), true); err != nil {
+			logger.Log(ctx).Warn("failed to cache unread count", zap.Int64("userId", userID), zap.Error(err))
+		}
+	}
+	return &models.UnreadCount{Unread: count}, nil
+}
+
+// invalidateUnreadCount marks the count stale instead of deleting it, reads in flight then cannot
+// cache the count they made before the change
+func (s *controller) invalidateUnreadCount(ctx context.Context, userID int64) {
+	if err := s.cache.SetValue(ctx, unreadCountKey(userID), unreadCountStale, int(unreadCountStaleTTL.Milliseconds()), false); err != nil {
+		logger.Log(ctx).Warn("failed to invalidate unread count", zap.Int64("userId", userID), zap.Error(err))
+	}
+}
+
+// the v2 keys hold the count as a value, the older ones a hash without expiry
+func unreadCountKey(userID int64) string {
+	return fmt.Sprintf("notifications:unread:v2:%d", userID)
+}
+
+// cursors are opaque to clients, they carry the id of the last notification on the page
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func decodeCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/notification/db"
+	"kisaanSathi/pkg/services/notification/models"
+	"kisaanSathi/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type NotificationSuite struct {
+	suite.Suite
+	ctx        context.Context
+	sqlDB      *sql.DB
+	gormDB     *gorm.DB
+	sqlMock    sqlmock.Sqlmock
+	cache      repo.RedisInterface
+	controller NotificationController
+}
+
+func TestNotificationSuite(t *testing.T) {
+	suite.Run(t, new(NotificationSuite))
+}
+
+func (suite *NotificationSuite) SetupTest() {
+	logger.LoggerInit("", -1)
+
+	suite.ctx = context.TODO()
+	suite.sqlDB, suite.gormDB, suite.sqlMock = utils.NewMockDB()
+	suite.cache = repo.NewMemoryCache()
+	suite.controller = NewNotificationController(db.NewDBObject(suite.gormDB), suite.cache)
+}
+
+func (suite *NotificationSuite) TearDownTest() {
+	suite.NoError(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *NotificationSuite) expectCount(count int64) {
+	suite.sqlMock.
+		ExpectQuery("^SELECT COUNT(.+) FROM kisan.notifications WHERE user_id = (.+) AND read = FALSE$").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+}
+
+func (suite *NotificationSuite) TestUnreadCount_StaleMarkIsNotOverwritten() {
+	// a notification commits while the count is read: the read counted 2, the commit made it 3
+	suite.expectCount(2)
+	suite.controller.(*controller).invalidateUnreadCount(suite.ctx, 1)
+	count, err := suite.controller.UnreadCount(suite.ctx, 1)
+	suite.NoError(err)
+	suite.Equal(int64(2), count.Unread)
+
+	// the old count was not cached, the next read counts again
+	suite.expectCount(3)
+	count, err = suite.controller.UnreadCount(suite.ctx, 1)
+	suite.NoError(err)
+	suite.Equal(int64(3), count.Unread)
+	suite.Greater(suite.cache.GetTTL(suite.ctx, unreadCountKey(1)), 0, "the stale mark expires")
+}
+
+func (suite *NotificationSuite) notificationRows(ids ...int64) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "user_id", "message", "type", "read", "created_at"})
+	for _, id := range ids {
+		rows.AddRow(id, 1, "Wheat ₹2250 at Indore", models.TypePrice, false, time.Date(2025, 3, 9, 8, 0, 0, 0, time.UTC))
+	}
+	return rows
+}
+
+func (suite *NotificationSuite) TestList_UnreadAndTypeFilters() {
+	// Mocking and Setting Expected Result
+	suite.sqlMock.
+		ExpectQuery("^SELECT id, user_id, message, type, read, created_at FROM kisan.notifications WHERE user_id = (.+) AND read = FALSE AND type = (.+) ORDER BY id DESC").
+		WithArgs(1, models.TypePrice).
+		WillReturnRows(suite.notificationRows(9, 7, 4))
+
+	// Triggering Function
+	page, err := suite.controller.List(suite.ctx, 1, &models.ListNotificationsRequest{Unread: true, Type: models.TypePrice, Limit: 2})
+
+	// Validations
+	suite.NoError(err)
+	suite.Len(page.Notifications, 2)
+	suite.Equal(int64(7), page.Notifications[1].ID)
+	suite.Equal(encodeCursor(7), page.NextCursor, "the extra row means there is a next page")
+}
+
+func (suite *NotificationSuite) TestList_Cursor() {
+	// Mocking and Setting Expected Result
+	suite.sqlMock.
+		ExpectQuery("^SELECT (.+) FROM kisan.notifications WHERE user_id = (.+) AND id < (.+) ORDER BY id DESC").
+		WithArgs(1, 7).
+		WillReturnRows(suite.notificationRows(4))
+
+	// Triggering Function
+	page, err := suite.controller.List(suite.ctx, 1, &models.ListNotificationsRequest{Cursor: encodeCursor(7)})
+
+	// Validations
+	suite.NoError(err)
+	suite.Len(page.Notifications, 1)
+	suite.Empty(page.NextCursor, "the last page has no cursor")
+}
+
+func (suite *NotificationSuite) TestList_InvalidCursor() {
+	for _, cursor := range []string{"not base64!", encodeCursor(0), "LTM", "YWJj"} {
+		_, err := suite.controller.List(suite.ctx, 1, &models.ListNotificationsRequest{Cursor: cursor})
+		suite.ErrorIs(err, ErrInvalidCursor, cursor)
+	}
+}
+
+func (suite *NotificationSuite) TestCursorRoundTrip() {
+	id, err := decodeCursor(encodeCursor(1234567))
+	suite.NoError(err)
+	suite.Equal(int64(1234567), id)
+
+	id, err = decodeCursor("")
+	suite.NoError(err)
+	suite.Zero(id, "no cursor is the first page")
+}
+
+func (suite *NotificationSuite) TestMarkRead_OtherUsersNotification() {
+	// Mocking and Setting Expected Result
+	suite.NoError(suite.cache.SetValue(suite.ctx, unreadCountKey(1), int64(4), 60000, false))
+	suite.sqlMock.
+		ExpectExec("^UPDATE kisan.notifications SET read = TRUE WHERE id = (.+) AND user_id = (.+)$").
+		WithArgs(12, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Triggering Function
+	found, err := suite.controller.MarkRead(suite.ctx, 1, 12)
+
+	// Validations
+	suite.NoError(err)
+	suite.False(found, "the handler answers not found")
+	var cached int64
+	suite.NoError(suite.cache.GetValue(suite.ctx, unreadCountKey(1), &cached))
+	suite.Equal(int64(4), cached, "nothing changed, the count is kept")
+}
+
+func (suite *NotificationSuite) TestUnreadCount_MissThenHit() {
+	// Mocking and Setting Expected Result
+	suite.expectCount(5)
+
+	// Triggering Function
+	first, err := suite.controller.UnreadCount(suite.ctx, 1)
+	suite.NoError(err)
+	second, err := suite.controller.UnreadCount(suite.ctx, 1)
+	suite.NoError(err)
+
+	// Validations
+	suite.Equal(int64(5), first.Unread)
+	suite.Equal(int64(5), second.Unread, "the second read is served from the cache")
+	suite.Greater(suite.cache.GetTTL(suite.ctx, unreadCountKey(1)), 0, "the cached count expires")
+}
+
+func (suite *NotificationSuite) TestUnreadCount_MarkReadInvalidates() {
+	// Mocking and Setting Expected Result
+	suite.NoError(suite.cache.SetValue(suite.ctx, unreadCountKey(1), int64(4), 60000, false))
+	suite.sqlMock.
+		ExpectExec("^UPDATE kisan.notifications SET read = TRUE WHERE id = (.+) AND user_id = (.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.expectCount(3)
+
+	// Triggering Function
+	found, err := suite.controller.MarkRead(suite.ctx, 1, 12)
+	suite.NoError(err)
+	suite.True(found)
+	count, err := suite.controller.UnreadCount(suite.ctx, 1)
+
+	// Validations
+	suite.NoError(err)
+	suite.Equal(int64(3), count.Unread)
+}
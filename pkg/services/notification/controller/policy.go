@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"context"
+	"kisaanSathi/pkg/services/notification/db"
+	"kisaanSathi/pkg/services/notification/models"
+	"time"
+)
+
+// decide checks one channel of a notification against the user's preferences. It is the only
+// place preferences are enforced: NotifyTx asks it about the inbox, the dispatcher about push
+// and sms, and the preview endpoint shows its answers.
+func decide(preferences *models.Preferences, notificationType string, channel string, sentToday int, now time.Time) models.Decision {
+	decision := models.Decision{Channel: channel}
+	switch {
+	case !preferences.Receives(notificationType):
+		decision.Reason = models.ReasonTypeMuted
+	case !preferences.Enabled(channel):
+		decision.Reason = models.ReasonChannelDisabled
+	case channel == models.ChannelInApp:
+		// the inbox is silent, quiet hours and the cap only apply to push and sms
+		decision.Deliver = true
+	case preferences.DailyCap > 0 && sentToday >= preferences.DailyCap:
+		// held, not dropped, until the cap resets at the user's next midnight or the quiet hours
+		// that midnight falls in end
+		decision.Reason = models.ReasonDailyCap
+		until := preferences.StartOfNextDay(now)
+		if quietUntil, quiet := preferences.QuietUntil(until); quiet {
+			until = quietUntil
+		}
+		decision.DeferUntil = &until
+	default:
+		if until, quiet := preferences.QuietUntil(now); quiet {
+			decision.Reason = models.ReasonQuietHours
+			decision.DeferUntil = &until
+		} else {
+			decision.Deliver = true
+		}
+	}
+	return decision
+}
+
+// sentToday counts the user's push and sms messages of the local day, only needed with a daily cap
+func sentToday(ctx context.Context, store db.DeliveryStore, preferences *models.Preferences, now time.Time) (int, error) {
+	if preferences.DailyCap == 0 {
+		return 0, nil
+	}
+	return store.CountSentSince(ctx, preferences.UserID, preferences.StartOfDay(now))
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/notification/models"
+	"strings"
+	"time"
+)
+
+func (s *controller) GetPreferences(ctx context.Context, userID int64) (*models.Preferences, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+	return s.notificationStore.GetPreferences(ctx, userID)
+}
+
+func (s *controller) SavePreferences(ctx context.Context, userID int64, request *models.PreferencesRequest) (*models.Preferences, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	preferences := &models.Preferences{
+		UserID:     userID,
+		Types:      unique(request.Types),
+		Channels:   unique(request.Channels),
+		QuietHours: request.QuietHours,
+		Timezone:   request.Timezone,
+		DailyCap:   request.DailyCap,
+	}
+	if request.Types == nil {
+		preferences.Types = append([]string(nil), models.AllTypes...)
+	}
+	if preferences.Timezone == "" {
+		preferences.Timezone = models.DefaultTimezone
+	}
+	if err := s.notificationStore.SavePreferences(ctx, preferences); err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// PreviewPreferences shows, per notification type and channel, what the dispatcher would do
+// with a notification sent at the requested time
+func (s *controller) PreviewPreferences(ctx context.Context, userID int64, request *models.PreviewRequest) (*models.Preview, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	preferences, err := s.notificationStore.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	at := request.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	sent, err := sentToday(ctx, s.notificationStore, preferences, at)
+	if err != nil {
+		return nil, err
+	}
+
+	types := models.AllTypes
+	if request.Type != "" {
+		types = []string{request.Type}
+	}
+	preview := &models.Preview{At: at.In(preferences.Location()), SentToday: sent, DailyCap: preferences.DailyCap}
+	for _, notificationType := range types {
+		typePreview := models.TypePreview{Type: notificationType}
+		for _, channel := range models.DefaultChannels {
+			typePreview.Decisions = append(typePreview.Decisions, decide(preferences, notificationType, channel, sent, at))
+		}
+		preview.Types = append(preview.Types, typePreview)
+	}
+	return preview, nil
+}
+
+// ApplyDeliveryReport maps the gateway's delivery report status to the delivery status
+func (s *controller) ApplyDeliveryReport(ctx context.Context, request *models.DeliveryReportRequest) (bool, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	status := models.DeliveryFailed
+	switch strings.ToUpper(strings.TrimSpace(request.Status)) {
+	case "DELIVRD", "DELIVERED":
+		status = models.DeliveryDelivered
+	case "ACCEPTD", "ACCEPTED", "ENROUTE", "SENT", "SUBMITTED":
+		// intermediate states, the delivery stays sent until a final report arrives
+		return true, nil
+	}
+	return s.notificationStore.ApplyDeliveryReport(ctx, request.MessageID, status, request.Error)
+}
+
+func unique(values []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
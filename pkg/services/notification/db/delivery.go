@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/notification/models"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ClaimPending leases up to limit due deliveries of a channel. The lease pushes
+// next_attempt_at forward so concurrent workers (and other replicas) skip the rows
+// while they are being sent; a crashed worker's rows become due again after the lease.
+func (g *notificationStore) ClaimPending(c context.Context, channel string, limit int, lease time.Duration) ([]models.PendingDelivery, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var deliveries []models.PendingDelivery
+	query := `WITH claimed AS (
+			UPDATE kisan.notification_deliveries d
+			SET next_attempt_at = now() + make_interval(secs => ?), attempts = d.attempts + 1, updated_at = now()
+			WHERE (d.notification_id, d.channel) IN (
+				SELECT notification_id, channel FROM kisan.notification_deliveries
+				WHERE channel = ? AND status = 'pending' AND next_attempt_at <= now()
+				ORDER BY next_attempt_at
+				LIMIT ?
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING d.notification_id, d.channel, d.attempts
+		)
+		SELECT c.notification_id, c.channel, c.attempts, n.user_id, n.message, n.type
+		FROM claimed c JOIN kisan.notifications n ON n.id = c.notification_id`
+
+	err := g.store.WithContext(c).Raw(query, lease.Seconds(), channel, limit).Scan(&deliveries).Error
+	if err != nil {
+		logger.Log(c).Error("Error claiming deliveries", zap.Error(err))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (g *notificationStore) UpdateDelivery(c context.Context, update models.DeliveryUpdate) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	query := `UPDATE kisan.notification_deliveries
+		SET status = ?, last_error = NULLIF(?, ''), provider_ref = COALESCE(NULLIF(?, ''), provider_ref), updated_at = now(),
+			attempts = CASE WHEN ? THEN GREATEST(attempts - 1, 0) ELSE attempts END,
+			next_attempt_at = CASE WHEN ? = 'pending' THEN ?::timestamptz ELSE next_attempt_at END,
+			delivered_at = CASE WHEN ? = 'sent' THEN now() ELSE delivered_at END
+		WHERE notification_id = ? AND channel = ?`
+	err := g.store.WithContext(c).Exec(query, update.Status, update.LastError, update.ProviderRef, update.Deferred, update.Status, update.NextAttemptAt,
+		update.Status, update.NotificationID, update.Channel).Error
+	if err != nil {
+		logger.Log(c).Error("Error updating delivery", zap.Error(err))
+	}
+	return err
+}
+
+// CountSentSince counts the push and sms messages that reached the user since the given time, for the daily cap
+func (g *notificationStore) CountSentSince(c context.Context, userID int64, since time.Time) (int, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var count int
+	err := g.store.WithContext(c).Raw(`SELECT COUNT(*) FROM kisan.notification_deliveries d
+		JOIN kisan.notifications n ON n.id = d.notification_id
+		WHERE n.user_id = ? AND d.channel IN ('push', 'sms') AND d.status IN ('sent', 'delivered') AND d.delivered_at >= ?::timestamptz`,
+		userID, since).Row().Scan(&count)
+	if err != nil {
+		logger.Log(c).Error("Error counting deliveries", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// QueueDelivery queues the notification on another channel, used to fall back from push to sms
+func (g *notificationStore) QueueDelivery(c context.Context, notificationID int64, channel string) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	err := g.store.WithContext(c).Exec(`INSERT INTO kisan.notification_deliveries (notification_id, channel) VALUES (?, ?)
+		ON CONFLICT (notification_id, channel) DO NOTHING`, notificationID, channel).Error
+	if err != nil {
+		logger.Log(c).Error("Error queueing delivery", zap.Error(err))
+	}
+	return err
+}
+
+// ApplyDeliveryReport records the final status reported by the sms gateway for a message id
+func (g *notificationStore) ApplyDeliveryReport(c context.Context, providerRef string, status string, reportError string) (bool, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	result := g.store.WithContext(c).Exec(`UPDATE kisan.notification_deliveries
+		SET status = ?, last_error = NULLIF(?, ''), updated_at = now(),
+			delivered_at = CASE WHEN ? = 'delivered' THEN now() ELSE delivered_at END
+		WHERE channel = 'sms' AND provider_ref = ?`, status, reportError, status, providerRef)
+	if result.Error != nil {
+		logger.Log(c).Error("Error applying delivery report", zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/notification/models"
+
+	"go.uber.org/zap"
+)
+
+// UpsertDevice registers a token for the user, moving it over if it was registered by another user
+func (g *notificationStore) UpsertDevice(c context.Context, device *models.Device) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	err := g.store.WithContext(c).Raw(`INSERT INTO kisan.device_tokens (user_id, session_id, token, platform) VALUES (?, ?, ?, ?)
+		ON CONFLICT (token) DO UPDATE SET user_id = EXCLUDED.user_id, session_id = EXCLUDED.session_id,
+			platform = EXCLUDED.platform, updated_at = now()
+		RETURNING id, updated_at`, device.UserID, device.SessionID, device.Token, device.Platform).
+		Row().Scan(&device.ID, &device.UpdatedAt)
+	if err != nil {
+		logger.Log(c).Error("Error registering device", zap.Error(err))
+	}
+	return err
+}
+
+func (g *notificationStore) DeleteDevice(c context.Context, userID int64, token string) (bool, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	result := g.store.WithContext(c).Exec(`DELETE FROM kisan.device_tokens WHERE user_id = ? AND token = ?`, userID, token)
+	if result.Error != nil {
+		logger.Log(c).Error("Error deleting device", zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteToken prunes a token reported invalid by the push provider
+func (g *notificationStore) DeleteToken(c context.Context, token string) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	err := g.store.WithContext(c).Exec(`DELETE FROM kisan.device_tokens WHERE token = ?`, token).Error
+	if err != nil {
+		logger.Log(c).Error("Error deleting token", zap.Error(err))
+	}
+	return err
+}
+
+func (g *notificationStore) ListDevices(c context.Context, userID int64) ([]models.Device, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var devices []models.Device
+	err := g.store.WithContext(c).Raw(`SELECT id, user_id, session_id, token, platform, updated_at
+		FROM kisan.device_tokens WHERE user_id = ? ORDER BY updated_at DESC`, userID).Scan(&devices).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return devices, nil
+}
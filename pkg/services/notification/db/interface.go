@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"kisaanSathi/pkg/services/notification/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type notificationStore struct {
+	store *gorm.DB
+}
+
+type NotificationStore interface {
+	// Insert writes a notification using tx when given so callers can include it in their transaction
+	// and queues its delivery on channel, no delivery is queued for an empty channel
+	Insert(ctx context.Context, tx *gorm.DB, notification *models.Notification, channel string) error
+	List(ctx context.Context, userID int64, filter models.ListFilter) ([]models.Notification, error)
+	MarkRead(ctx context.Context, userID int64, notificationID int64) (bool, error)
+	MarkAllRead(ctx context.Context, userID int64, notificationType string) (int64, error)
+	CountUnread(ctx context.Context, userID int64) (int64, error)
+}
+
+type DeviceStore interface {
+	UpsertDevice(ctx context.Context, device *models.Device) error
+	DeleteDevice(ctx context.Context, userID int64, token string) (bool, error)
+	DeleteToken(ctx context.Context, token string) error
+	ListDevices(ctx context.Context, userID int64) ([]models.Device, error)
+}
+
+type DeliveryStore interface {
+	ClaimPending(ctx context.Context, channel string, limit int, lease time.Duration) ([]models.PendingDelivery, error)
+	UpdateDelivery(ctx context.Context, update models.DeliveryUpdate) error
+	QueueDelivery(ctx context.Context, notificationID int64, channel string) error
+	CountSentSince(ctx context.Context, userID int64, since time.Time) (int, error)
+	ApplyDeliveryReport(ctx context.Context, providerRef string, status string, reportError string) (bool, error)
+}
+
+type PreferenceStore interface {
+	GetPreferences(ctx context.Context, userID int64) (*models.Preferences, error)
+	SavePreferences(ctx context.Context, preferences *models.Preferences) error
+	GetContact(ctx context.Context, userID int64) (*models.Contact, error)
+}
+
+// DispatchStore is what the delivery worker needs
+type DispatchStore interface {
+	DeviceStore
+	DeliveryStore
+	PreferenceStore
+}
+
+type Store interface {
+	NotificationStore
+	DeviceStore
+	DeliveryStore
+	PreferenceStore
+}
+
+func NewDBObject(store *gorm.DB) Store {
+	return &notificationStore{store: store}
+}
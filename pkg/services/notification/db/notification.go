@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/notification/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func (g *notificationStore) Insert(c context.Context, tx *gorm.DB, notification *models.Notification, channel string) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	if tx == nil {
+		tx = g.store
+	}
+	// the delivery is queued in the same statement so a notification is never left undelivered
+	err := tx.WithContext(c).Raw(`WITH inserted AS (
+			INSERT INTO kisan.notifications (user_id, message, type, read) VALUES (?, ?, ?, ?)
+			RETURNING id, created_at
+		), queued AS (
+			INSERT INTO kisan.notification_deliveries (notification_id, channel)
+			SELECT id, ? FROM inserted WHERE ? <> ''
+		)
+		SELECT id, created_at FROM inserted`, notification.UserID, notification.Message, notification.Type, notification.Read, channel, channel).
+		Row().Scan(&notification.ID, &notification.CreatedAt)
+	if err != nil {
+		logger.Log(c).Error("Error inserting notification", zap.Error(err))
+	}
+	return err
+}
+
+func (g *notificationStore) List(c context.Context, userID int64, filter models.ListFilter) ([]models.Notification, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var notifications []models.Notification
+	query := g.store.WithContext(c).
+		Table("kisan.notifications").
+		Select("id, user_id, message, type, read, created_at").
+		Where("user_id = ?", userID)
+	if filter.UnreadOnly {
+		query = query.Where("read = FALSE")
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.BeforeID > 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+	err := query.Order("id DESC").Limit(filter.Limit).Scan(&notifications).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (g *notificationStore) MarkRead(c context.Context, userID int64, notificationID int64) (bool, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	result := g.store.WithContext(c).Exec(`UPDATE kisan.notifications SET read = TRUE WHERE id = ? AND user_id = ?`, notificationID, userID)
+	if result.Error != nil {
+		logger.Log(c).Error("Error marking notification read", zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (g *notificationStore) MarkAllRead(c context.Context, userID int64, notificationType string) (int64, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	query := `UPDATE kisan.notifications SET read = TRUE WHERE user_id = ? AND read = FALSE`
+	args := []interface{}{userID}
+	if notificationType != "" {
+		query += ` AND type = ?`
+		args = append(args, notificationType)
+	}
+	result := g.store.WithContext(c).Exec(query, args...)
+	if result.Error != nil {
+		logger.Log(c).Error("Error marking notifications read", zap.Error(result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func (g *notificationStore) CountUnread(c context.Context, userID int64) (int64, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var count int64
+	err := g.store.WithContext(c).Raw(`SELECT COUNT(1) FROM kisan.notifications WHERE user_id = ? AND read = FALSE`, userID).Scan(&count).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+	}
+	return count, err
+}
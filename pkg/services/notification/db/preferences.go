@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/notification/models"
+	"kisaanSathi/pkg/utils"
+
+	"go.uber.org/zap"
+)
+
+type preferencesRow struct {
+	Types      string `gorm:"column:types"`
+	Channels   string `gorm:"column:channels"`
+	QuietStart string `gorm:"column:quiet_start"`
+	QuietEnd   string `gorm:"column:quiet_end"`
+	Timezone   string `gorm:"column:timezone"`
+	DailyCap   int    `gorm:"column:daily_cap"`
+}
+
+// GetPreferences returns the saved preferences of the user or the defaults
+func (g *notificationStore) GetPreferences(c context.Context, userID int64) (*models.Preferences, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var row preferencesRow
+	err := g.store.WithContext(c).Raw(`SELECT array_to_string(types, ',') AS types, array_to_string(channels, ',') AS channels,
+			COALESCE(to_char(quiet_start, 'HH24:MI'), '') AS quiet_start, COALESCE(to_char(quiet_end, 'HH24:MI'), '') AS quiet_end,
+			timezone, daily_cap
+		FROM kisan.notification_preferences WHERE user_id = ?`, userID).
+		Row().Scan(&row.Types, &row.Channels, &row.QuietStart, &row.QuietEnd, &row.Timezone, &row.DailyCap)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.DefaultPreferences(userID), nil
+	}
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+
+	preferences := &models.Preferences{
+		UserID:   userID,
+		Types:    utils.GetSliceFromStringBySeparator(row.Types, ","),
+		Channels: utils.GetSliceFromStringBySeparator(row.Channels, ","),
+		Timezone: row.Timezone,
+		DailyCap: row.DailyCap,
+	}
+	if row.QuietStart != "" && row.QuietEnd != "" {
+		preferences.QuietHours = &models.QuietHours{Start: row.QuietStart, End: row.QuietEnd}
+	}
+	return preferences, nil
+}
+
+func (g *notificationStore) SavePreferences(c context.Context, preferences *models.Preferences) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var quietStart, quietEnd *string
+	if preferences.QuietHours != nil {
+		quietStart, quietEnd = &preferences.QuietHours.Start, &preferences.QuietHours.End
+	}
+	err := g.store.WithContext(c).Exec(`INSERT INTO kisan.notification_preferences (user_id, types, channels, quiet_start, quiet_end, timezone, daily_cap)
+		VALUES (?, ?::text[], ?::text[], ?::time, ?::time, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET types = EXCLUDED.types, channels = EXCLUDED.channels,
+			quiet_start = EXCLUDED.quiet_start, quiet_end = EXCLUDED.quiet_end, timezone = EXCLUDED.timezone,
+			daily_cap = EXCLUDED.daily_cap, updated_at = now()`,
+		preferences.UserID, repo.TextArray(preferences.Types), repo.TextArray(preferences.Channels), quietStart, quietEnd,
+		preferences.Timezone, preferences.DailyCap).Error
+	if err != nil {
+		logger.Log(c).Error("Error saving preferences", zap.Error(err))
+	}
+	return err
+}
+
+func (g *notificationStore) GetContact(c context.Context, userID int64) (*models.Contact, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var contact models.Contact
+	err := g.store.WithContext(c).Raw(`SELECT COALESCE(phone, '') AS phone, COALESCE(language, '') AS language
+		FROM kisan.users WHERE id = ?`, userID).Scan(&contact).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return &contact, nil
+}
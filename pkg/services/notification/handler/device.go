@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/network"
+	"kisaanSathi/pkg/services/notification/models"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func (f *handler) RegisterDevice(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	var request models.RegisterDeviceRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.RegisterDevice(c, userID, c.GetHeader("sessionId"), &request)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.AddDBError.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) UnregisterDevice(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	token := strings.TrimSpace(c.Param("token"))
+
+	found, err := f.controller.UnregisterDevice(c, userID, token)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.DelDBError.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, network.FailureResponse(network.ApiErrors.NoDataFound.WithErrorDescription("device not registered")))
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse("device unregistered"))
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/flags"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/notification/controller"
+	"kisaanSathi/pkg/services/notification/db"
+	"kisaanSathi/pkg/services/notification/models"
+	"kisaanSathi/pkg/services/notification/push"
+	"kisaanSathi/pkg/services/notification/sms"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type handler struct {
+	controller controller.NotificationController
+}
+
+type NotificationHandler interface {
+	ListNotifications(c *gin.Context)
+	MarkNotificationRead(c *gin.Context)
+	MarkAllNotificationsRead(c *gin.Context)
+	GetUnreadNotificationCount(c *gin.Context)
+	RegisterDevice(c *gin.Context)
+	UnregisterDevice(c *gin.Context)
+	GetNotificationPreferences(c *gin.Context)
+	SaveNotificationPreferences(c *gin.Context)
+	PreviewNotificationPreferences(c *gin.Context)
+	SMSDeliveryReport(c *gin.Context)
+}
+
+func NewNotificationHandler(controller controller.NotificationController) NotificationHandler {
+	return &handler{
+		controller: controller,
+	}
+}
+
+func NotificationController(repo repo.DataObject) controller.NotificationController {
+	store := db.NewDBObject(repo.Databases.PgDB)
+	return controller.NewNotificationController(store, repo.Cache)
+}
+
+// NotificationService is used by other modules to write notifications
+func NotificationService(repo repo.DataObject) controller.NotificationService {
+	return NotificationController(repo)
+}
+
+// Dispatcher builds the delivery worker with the push sender and sms gateway selected in config.
+// Pushes fall back to sms for the users the sms-fallback flag is on for. A channel without a
+// provider is disabled with a warning, its deliveries wait until it is configured.
+func Dispatcher(repo repo.DataObject) (controller.Dispatcher, error) {
+	sender, err := push.NewPushSender()
+	switch {
+	case errors.Is(err, push.ErrNoProvider):
+		logger.Log().Warn("push delivery is disabled", zap.Error(err))
+	case err != nil:
+		return nil, err
+	}
+	gateway, err := sms.NewSMSGateway()
+	switch {
+	case errors.Is(err, sms.ErrNoProvider):
+		logger.Log().Warn("sms delivery is disabled", zap.Error(err))
+	case err != nil:
+		return nil, err
+	}
+	if sender == nil && gateway == nil {
+		return nil, errors.New("neither notification.push.provider nor notification.sms.provider is set")
+	}
+	c := config.App().Notification.Push
+	store := db.NewDBObject(repo.Databases.PgDB)
+	return controller.NewDispatcher(store, sender, gateway, controller.DispatcherConfig{
+		BatchSize:   c.BatchSize,
+		MaxAttempts: c.MaxAttempts,
+		BaseBackoff: c.Backoff,
+		MaxBackoff:  c.MaxBackoff,
+		Lease:       c.Lease,
+		// the fallback was always on before its flag, it stays on where the flag is not defined
+		SMSFallback: func(ctx context.Context, userID int64) bool {
+			return flags.EnabledForOr(ctx, models.FlagSMSFallback, userID, true)
+		},
+	}), nil
+}
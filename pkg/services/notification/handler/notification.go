@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/network"
+	"kisaanSathi/pkg/services/notification/controller"
+	"kisaanSathi/pkg/services/notification/models"
+	"kisaanSathi/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func (f *handler) ListNotifications(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	var request models.ListNotificationsRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.List(c, userID, &request)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		if errors.Is(err, controller.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		} else {
+			c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.GetDBError.WithErrorDescription(err.Error())))
+		}
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) MarkNotificationRead(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	notificationID, err := utils.GetInt64Param(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, nil))
+		c.Abort()
+		return
+	}
+
+	found, err := f.controller.MarkRead(c, userID, notificationID)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.AddDBError.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, network.FailureResponse(network.ApiErrors.NoDataFound.WithErrorDescription("notification not found")))
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse("notification marked as read"))
+}
+
+func (f *handler) MarkAllNotificationsRead(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	// the body is optional, an empty body marks every type as read
+	var request models.MarkAllReadRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			logger.Log(c).Error("Invalid request payload", zap.Error(err))
+			c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+			c.Abort()
+			return
+		}
+	}
+
+	updated, err := f.controller.MarkAllRead(c, userID, request.Type)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.AddDBError.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(gin.H{"updated": updated}))
+}
+
+func (f *handler) GetUnreadNotificationCount(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+
+	data, err := f.controller.UnreadCount(c, userID)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.GetDBError.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func requireUser(c *gin.Context) (int64, bool) {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, network.FailureResponse(network.ApiErrors.Unauthorized.WithErrorDescription(err.Error())))
+		c.Abort()
+		return 0, false
+	}
+	return userID, true
+}
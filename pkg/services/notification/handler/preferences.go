@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/network"
+	"kisaanSathi/pkg/services/notification/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func (f *handler) GetNotificationPreferences(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+
+	data, err := f.controller.GetPreferences(c, userID)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.GetDBError.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) SaveNotificationPreferences(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	var request models.PreferencesRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.SavePreferences(c, userID, &request)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.AddDBError.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) PreviewNotificationPreferences(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	var request models.PreviewRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.PreviewPreferences(c, userID, &request)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.GetDBError.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+// SMSDeliveryReport is the callback the sms gateway calls with the final status of a message.
+// The gateway authenticates with the shared token configured in notification.sms.dlrtoken.
+func (f *handler) SMSDeliveryReport(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	token := config.App().Notification.SMS.DLRToken
+	if token == "" || subtle.ConstantTimeCompare([]byte(c.GetHeader("X-DLR-Token")), []byte(token)) != 1 {
+		err := errors.New("invalid delivery report token")
+		c.JSON(http.StatusUnauthorized, network.FailureResponse(network.ApiErrors.Unauthorized.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+	var request models.DeliveryReportRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	found, err := f.controller.ApplyDeliveryReport(c, &request)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.AddDBError.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, network.FailureResponse(network.ApiErrors.NoDataFound.WithErrorDescription("unknown message id")))
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse("delivery report recorded"))
+}
@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// delivery channels of a notification, the in-app inbox is kisan.notifications itself
+const (
+	ChannelInApp = "in_app"
+	ChannelPush  = "push"
+	ChannelSMS   = "sms"
+)
+
+// DefaultChannels apply to users who never saved their preferences
+var DefaultChannels = []string{ChannelInApp, ChannelPush, ChannelSMS}
+
+// FlagSMSFallback is the feature flag of the users whose undelivered pushes fall back to sms, the
+// fallback is on for everyone while the flag is not defined
+const FlagSMSFallback = "sms-fallback"
+
+// delivery status stored in kisan.notification_deliveries
+const (
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+	DeliverySkipped = "skipped"
+	// DeliveryDelivered is set from sms delivery reports, sent only means accepted by the gateway
+	DeliveryDelivered = "delivered"
+)
+
+// PushTitles is the push title shown for each notification type
+var PushTitles = map[string]string{
+	TypePrice:   "Mandi price update",
+	TypeScheme:  "Scheme reminder",
+	TypeWeather: "Weather alert",
+	TypeCrop:    "Crop calendar",
+	TypeMarket:  "Market update",
+	TypeRental:  "Equipment rental",
+}
+
+const DefaultPushTitle = "Kisaan Sathi"
+
+type Device struct {
+	ID        int64     `json:"id" gorm:"column:id"`
+	UserID    int64     `json:"-" gorm:"column:user_id"`
+	SessionID string    `json:"sessionId,omitempty" gorm:"column:session_id"`
+	Token     string    `json:"token" gorm:"column:token"`
+	Platform  string    `json:"platform" gorm:"column:platform"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+type RegisterDeviceRequest struct {
+	Token    string `json:"token" binding:"required,max=4096"`
+	Platform string `json:"platform" binding:"required,oneof=android ios web"`
+}
+
+// PendingDelivery is a claimed delivery together with the notification to deliver
+type PendingDelivery struct {
+	NotificationID int64  `gorm:"column:notification_id"`
+	Channel        string `gorm:"column:channel"`
+	Attempts       int    `gorm:"column:attempts"`
+	UserID         int64  `gorm:"column:user_id"`
+	Message        string `gorm:"column:message"`
+	Type           string `gorm:"column:type"`
+}
+
+// DeliveryUpdate is the outcome of one delivery attempt
+type DeliveryUpdate struct {
+	NotificationID int64
+	Channel        string
+	Status         string
+	LastError      string
+	ProviderRef    string
+	// NextAttemptAt is only used for DeliveryPending
+	NextAttemptAt time.Time
+	// Deferred gives back the attempt of a delivery held for quiet hours or the daily cap
+	Deferred bool
+}
+
+// Contact is what the sms channel needs to know about the recipient
+type Contact struct {
+	Phone    string `gorm:"column:phone"`
+	Language string `gorm:"column:language"`
+}
+
+// DeliveryReportRequest is the delivery report (DLR) callback of the sms gateway
+type DeliveryReportRequest struct {
+	MessageID string `json:"message_id" binding:"required"`
+	Status    string `json:"status" binding:"required"`
+	Error     string `json:"error"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// notification types stored in the type column of kisan.notifications
+const (
+	TypePrice   = "price"
+	TypeScheme  = "scheme"
+	TypeWeather = "weather"
+	TypeCrop    = "crop"
+	TypeGeneral = "general"
+	TypeMarket  = "market"
+	TypeRental  = "rental"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+type Notification struct {
+	ID        int64     `json:"id" gorm:"column:id"`
+	UserID    int64     `json:"-" gorm:"column:user_id"`
+	Message   string    `json:"message" gorm:"column:message"`
+	Type      string    `json:"type" gorm:"column:type"`
+	Read      bool      `json:"read" gorm:"column:read"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+type ListNotificationsRequest struct {
+	Unread bool   `form:"unread"`
+	Type   string `form:"type" binding:"omitempty,max=50"`
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// ListFilter is the decoded form of ListNotificationsRequest used by the store
+type ListFilter struct {
+	UnreadOnly bool
+	Type       string
+	BeforeID   int64
+	Limit      int
+}
+
+type NotificationPage struct {
+	Notifications []Notification `json:"notifications"`
+	NextCursor    string         `json:"nextCursor,omitempty"`
+}
+
+type MarkAllReadRequest struct {
+	Type string `json:"type" binding:"omitempty,max=50"`
+}
+
+type UnreadCount struct {
+	Unread int64 `json:"unread"`
+}
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// AllTypes apply to users who never saved which notification types they want
+var AllTypes = []string{TypePrice, TypeScheme, TypeWeather, TypeCrop, TypeGeneral, TypeMarket, TypeRental}
+
+// DefaultTimezone is used for quiet hours and the daily cap when the user did not pick one
+const DefaultTimezone = "Asia/Kolkata"
+
+// reasons a delivery is held back by the user's preferences
+const (
+	ReasonTypeMuted       = "type muted"
+	ReasonChannelDisabled = "channel disabled"
+	ReasonQuietHours      = "quiet hours"
+	ReasonDailyCap        = "daily cap reached"
+)
+
+// QuietHours is a local time window (HH:MM, 24 hour clock) in which nothing is pushed or texted.
+// The window may wrap midnight, e.g. 22:00 to 06:00.
+type QuietHours struct {
+	Start string `json:"start" binding:"required,datetime=15:04"`
+	End   string `json:"end" binding:"required,datetime=15:04"`
+}
+
+type Preferences struct {
+	UserID     int64       `json:"-"`
+	Types      []string    `json:"types"`
+	Channels   []string    `json:"channels"`
+	QuietHours *QuietHours `json:"quietHours,omitempty"`
+	Timezone   string      `json:"timezone"`
+	// DailyCap is the maximum number of push and sms messages per local day, 0 is unlimited.
+	// Messages over the cap wait for the next local day.
+	DailyCap int `json:"dailyCap"`
+}
+
+// PreferencesRequest replaces the user's preferences; types defaults to all types when omitted
+type PreferencesRequest struct {
+	Types      []string    `json:"types" binding:"omitempty,dive,oneof=price scheme weather crop general market rental"`
+	Channels   []string    `json:"channels" binding:"required,dive,oneof=in_app push sms"`
+	QuietHours *QuietHours `json:"quietHours" binding:"omitempty"`
+	Timezone   string      `json:"timezone" binding:"omitempty,timezone"`
+	DailyCap   int         `json:"dailyCap" binding:"min=0,max=100"`
+}
+
+// DefaultPreferences apply to users without a saved row
+func DefaultPreferences(userID int64) *Preferences {
+	return &Preferences{
+		UserID:   userID,
+		Types:    append([]string(nil), AllTypes...),
+		Channels: append([]string(nil), DefaultChannels...),
+		Timezone: DefaultTimezone,
+	}
+}
+
+// PreviewRequest asks what would happen to a notification sent at a given time
+type PreviewRequest struct {
+	Type string `form:"type" binding:"omitempty,oneof=price scheme weather crop general market rental"`
+	// At defaults to now
+	At time.Time `form:"at" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// Decision is the outcome of checking one channel of a notification against the preferences
+type Decision struct {
+	Channel string `json:"channel"`
+	Deliver bool   `json:"deliver"`
+	Reason  string `json:"reason,omitempty"`
+	// DeferUntil is set when the delivery waits for quiet hours to end or the daily cap to reset
+	DeferUntil *time.Time `json:"deferUntil,omitempty"`
+}
+
+type TypePreview struct {
+	Type      string     `json:"type"`
+	Decisions []Decision `json:"decisions"`
+}
+
+type Preview struct {
+	At        time.Time     `json:"at"`
+	SentToday int           `json:"sentToday"`
+	DailyCap  int           `json:"dailyCap"`
+	Types     []TypePreview `json:"types"`
+}
+
+// Enabled reports whether the channel is one of the user's channels
+func (p *Preferences) Enabled(channel string) bool {
+	return contains(p.Channels, channel)
+}
+
+// Receives reports whether the user wants notifications of this type
+func (p *Preferences) Receives(notificationType string) bool {
+	return contains(p.Types, notificationType)
+}
+
+// Location is the user's time zone, falling back to DefaultTimezone
+func (p *Preferences) Location() *time.Location {
+	if p.Timezone != "" {
+		if location, err := time.LoadLocation(p.Timezone); err == nil {
+			return location
+		}
+	}
+	location, err := time.LoadLocation(DefaultTimezone)
+	if err != nil {
+		return time.FixedZone("IST", 5*60*60+30*60)
+	}
+	return location
+}
+
+// StartOfDay is local midnight of the day t falls on, the daily cap resets then
+func (p *Preferences) StartOfDay(t time.Time) time.Time {
+	local := t.In(p.Location())
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+}
+
+// StartOfNextDay is local midnight after t, deliveries held by the daily cap are retried then
+func (p *Preferences) StartOfNextDay(t time.Time) time.Time {
+	start := p.StartOfDay(t)
+	return time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, start.Location())
+}
+
+// QuietUntil returns the end of the quiet hours when t falls inside them
+func (p *Preferences) QuietUntil(t time.Time) (time.Time, bool) {
+	if p.QuietHours == nil {
+		return time.Time{}, false
+	}
+	start, err := minuteOfDay(p.QuietHours.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := minuteOfDay(p.QuietHours.End)
+	if err != nil || start == end {
+		return time.Time{}, false
+	}
+
+	local := t.In(p.Location())
+	now := local.Hour()*60 + local.Minute()
+	var quiet bool
+	if start < end {
+		quiet = now >= start && now < end
+	} else {
+		quiet = now >= start || now < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, local.Location())
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
+
+func minuteOfDay(value string) (int, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day [%s]: %w", value, err)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package push
+
+import (
+	"context"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// FakeSender records messages instead of sending them. It is used for tests and
+// local runs without firebase credentials.
+type FakeSender struct {
+	mu            sync.Mutex
+	Sent          []Message
+	InvalidTokens map[string]bool
+	// Err, when set, is returned for every valid token to simulate provider outages
+	Err error
+}
+
+func NewFakeSender() *FakeSender {
+	return &FakeSender{InvalidTokens: make(map[string]bool)}
+}
+
+func (f *FakeSender) Send(ctx context.Context, message Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.InvalidTokens[message.Token] {
+		return fmt.Errorf("%w: %s", ErrInvalidToken, message.Token)
+	}
+	if f.Err != nil {
+		return f.Err
+	}
+	f.Sent = append(f.Sent, message)
+	logger.Log(ctx).Debug("fake push sent", zap.String("title", message.Title), zap.String("body", message.Body))
+	return nil
+}
+
+// Messages returns a copy of the messages sent so far
+func (f *FakeSender) Messages() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Message(nil), f.Sent...)
+}
@@ -0,0 +1,239 @@
+package push
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/utils"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	fcmScope       = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmSendURL     = "https://fcm.googleapis.com/v1/projects/{project}/messages:send"
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+	// access tokens are refreshed this long before they expire
+	tokenRefreshMargin = time.Minute
+)
+
+type FCMConfig struct {
+	ProjectID       string
+	CredentialsFile string
+	// Timeout of each provider call in milliseconds
+	Timeout int64
+}
+
+// serviceAccount holds the fields of a google service account json key used by the sender
+type serviceAccount struct {
+	ProjectID   string `json:"project_id"`
+	PrivateKey  string `json:"private_key"`
+	ClientEmail string `json:"client_email"`
+	TokenURI    string `json:"token_uri"`
+}
+
+type fcmSender struct {
+	projectID   string
+	clientEmail string
+	tokenURI    string
+	privateKey  *rsa.PrivateKey
+	timeout     int64
+	rest        utils.RestCaller
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+	now         func() time.Time
+}
+
+type fcmErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// NewFCMSender creates a sender for the firebase cloud messaging http v1 api. It
+// authenticates with the service account key in cfg.CredentialsFile.
+func NewFCMSender(cfg FCMConfig, rest utils.RestCaller) (PushSender, error) {
+	raw, err := os.ReadFile(cfg.CredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read fcm credentials: %w", err)
+	}
+	sender, err := newFCMSenderFromJSON(cfg, raw, rest)
+	if err != nil {
+		return nil, err
+	}
+	return sender, nil
+}
+
+func newFCMSenderFromJSON(cfg FCMConfig, raw []byte, rest utils.RestCaller) (*fcmSender, error) {
+	var account serviceAccount
+	if err := json.Unmarshal(raw, &account); err != nil {
+		return nil, fmt.Errorf("invalid fcm credentials: %w", err)
+	}
+	key, err := parsePrivateKey(account.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	sender := &fcmSender{
+		projectID:   cfg.ProjectID,
+		clientEmail: account.ClientEmail,
+		tokenURI:    account.TokenURI,
+		privateKey:  key,
+		timeout:     cfg.Timeout,
+		rest:        rest,
+		now:         time.Now,
+	}
+	if sender.projectID == "" {
+		sender.projectID = account.ProjectID
+	}
+	if sender.tokenURI == "" {
+		sender.tokenURI = googleTokenURL
+	}
+	if sender.timeout <= 0 {
+		sender.timeout = 5000
+	}
+	if sender.projectID == "" || sender.clientEmail == "" {
+		return nil, errors.New("fcm project id and client email are required")
+	}
+	return sender, nil
+}
+
+func (f *fcmSender) Send(ctx context.Context, message Message) error {
+	token, err := f.getAccessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"message": map[string]interface{}{
+			"token": message.Token,
+			"notification": map[string]string{
+				"title": message.Title,
+				"body":  message.Body,
+			},
+			"data": message.Data,
+		},
+	}
+	body, status, err := f.rest.InvokeResty(utils.BackgroundGinContext(ctx), http.MethodPost, fcmSendURL, payload, nil,
+		f.timeout, map[string]string{"token": token}, nil, map[string]string{"project": f.projectID}, nil)
+	if err != nil {
+		return fmt.Errorf("fcm send failed: %w", err)
+	}
+	if status == http.StatusOK {
+		return nil
+	}
+	if status == http.StatusUnauthorized {
+		// force a new access token on the next attempt
+		f.mu.Lock()
+		f.accessToken = ""
+		f.mu.Unlock()
+	}
+	return classifyFCMError(status, body)
+}
+
+// classifyFCMError maps the fcm error response to ErrInvalidToken for tokens
+// which will never succeed, everything else is retryable
+func classifyFCMError(status int, body []byte) error {
+	var response fcmErrorResponse
+	_ = json.Unmarshal(body, &response)
+	errorCode := response.Error.Status
+	for _, detail := range response.Error.Details {
+		if detail.ErrorCode != "" {
+			errorCode = detail.ErrorCode
+		}
+	}
+	switch errorCode {
+	case "UNREGISTERED", "NOT_FOUND", "SENDER_ID_MISMATCH":
+		return fmt.Errorf("%w: %s", ErrInvalidToken, errorCode)
+	case "INVALID_ARGUMENT":
+		if status == http.StatusBadRequest {
+			return fmt.Errorf("%w: %s", ErrInvalidToken, response.Error.Message)
+		}
+	}
+	return fmt.Errorf("fcm send failed with status %d: %s %s", status, errorCode, response.Error.Message)
+}
+
+func (f *fcmSender) getAccessToken(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.accessToken != "" && f.now().Before(f.expiresAt.Add(-tokenRefreshMargin)) {
+		return f.accessToken, nil
+	}
+	assertion, err := f.signAssertion()
+	if err != nil {
+		return "", err
+	}
+	form := map[string]string{
+		"grant_type": "urn:ietf:params:oauth:grant-type:jwt-bearer",
+		"assertion":  assertion,
+	}
+	body, status, err := f.rest.InvokeResty(utils.BackgroundGinContext(ctx), http.MethodPost, f.tokenURI, nil, nil,
+		f.timeout, nil, nil, nil, form)
+	if err != nil {
+		return "", fmt.Errorf("fcm token request failed: %w", err)
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("fcm token request failed with status %d: %s", status, string(body))
+	}
+	var response struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || response.AccessToken == "" {
+		return "", fmt.Errorf("invalid fcm token response: %s", string(body))
+	}
+	f.accessToken = response.AccessToken
+	f.expiresAt = f.now().Add(time.Duration(response.ExpiresIn) * time.Second)
+	return f.accessToken, nil
+}
+
+// signAssertion builds the RS256 signed jwt exchanged for an oauth access token
+func (f *fcmSender) signAssertion() (string, error) {
+	now := f.now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   f.clientEmail,
+		"scope": fcmScope,
+		"aud":   f.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, f.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("unable to sign fcm assertion: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func parsePrivateKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, errors.New("fcm private key is not pem encoded")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if rsaKey, ok := key.(*rsa.PrivateKey); ok {
+			return rsaKey, nil
+		}
+		return nil, errors.New("fcm private key is not an rsa key")
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
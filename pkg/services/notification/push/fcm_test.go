@@ -0,0 +1,140 @@
+package push
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"kisaanSathi/pkg/config"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type restResponse struct {
+	body   string
+	status int
+}
+
+// fakeRestCaller answers token requests and send requests with canned responses
+type fakeRestCaller struct {
+	tokenCalls int
+	sendCalls  []map[string]string
+	send       restResponse
+}
+
+func (f *fakeRestCaller) InvokeResty(c *gin.Context, method string, url string, body interface{}, headers map[string]string, timeout int64, auth map[string]string, queryparams map[string]string, pathparams map[string]string, formData map[string]string) ([]byte, int, error) {
+	if formData != nil {
+		f.tokenCalls++
+		return []byte(`{"access_token":"access-token","expires_in":3600}`), http.StatusOK, nil
+	}
+	f.sendCalls = append(f.sendCalls, map[string]string{"token": auth["token"], "project": pathparams["project"], "url": url})
+	return []byte(f.send.body), f.send.status, nil
+}
+
+func (f *fakeRestCaller) InvokeHttp(c *gin.Context, method string, url string, body interface{}, headers map[string]string, timeout int64, auth map[string]string, queryparams map[string]string, pathparams map[string]string) ([]byte, int, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
+func newTestFCMSender(t *testing.T, rest *fakeRestCaller) *fcmSender {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	credentials, _ := json.Marshal(serviceAccount{
+		ProjectID:   "kisaan-sathi",
+		ClientEmail: "push@kisaan-sathi.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	})
+	sender, err := newFCMSenderFromJSON(FCMConfig{}, credentials, rest)
+	require.NoError(t, err)
+	return sender
+}
+
+func TestFCMSender_SendReusesAccessToken(t *testing.T) {
+	rest := &fakeRestCaller{send: restResponse{body: `{"name":"projects/kisaan-sathi/messages/1"}`, status: http.StatusOK}}
+	sender := newTestFCMSender(t, rest)
+
+	for i := 0; i < 2; i++ {
+		err := sender.Send(context.TODO(), Message{Token: "device-token", Title: "Mandi", Body: "Wheat price up"})
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, rest.tokenCalls)
+	assert.Len(t, rest.sendCalls, 2)
+	assert.Equal(t, "access-token", rest.sendCalls[0]["token"])
+	assert.Equal(t, "kisaan-sathi", rest.sendCalls[0]["project"])
+}
+
+func TestFCMSender_SendClassifiesErrors(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		response     restResponse
+		invalidToken bool
+	}{
+		{
+			desc:         "Unregistered",
+			response:     restResponse{status: http.StatusNotFound, body: `{"error":{"code":404,"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`},
+			invalidToken: true,
+		}, {
+			desc:         "MalformedToken",
+			response:     restResponse{status: http.StatusBadRequest, body: `{"error":{"code":400,"status":"INVALID_ARGUMENT","message":"The registration token is not a valid FCM registration token"}}`},
+			invalidToken: true,
+		}, {
+			desc:         "Unavailable",
+			response:     restResponse{status: http.StatusServiceUnavailable, body: `{"error":{"code":503,"status":"UNAVAILABLE"}}`},
+			invalidToken: false,
+		}, {
+			desc:         "QuotaExceeded",
+			response:     restResponse{status: http.StatusTooManyRequests, body: `{"error":{"code":429,"status":"RESOURCE_EXHAUSTED","details":[{"errorCode":"QUOTA_EXCEEDED"}]}}`},
+			invalidToken: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.desc, func(t *testing.T) {
+			sender := newTestFCMSender(t, &fakeRestCaller{send: testCase.response})
+
+			err := sender.Send(context.TODO(), Message{Token: "device-token"})
+
+			assert.Error(t, err)
+			assert.Equal(t, testCase.invalidToken, errors.Is(err, ErrInvalidToken))
+		})
+	}
+}
+
+func TestFCMSender_SignAssertion(t *testing.T) {
+	sender := newTestFCMSender(t, &fakeRestCaller{})
+
+	assertion, err := sender.signAssertion()
+
+	assert.NoError(t, err)
+	assert.Len(t, strings.Split(assertion, "."), 3)
+}
+
+func TestNewPushSender_Provider(t *testing.T) {
+	dir := t.TempDir()
+	write := func(provider string) {
+		yaml := "repo:\n  databases:\n    postgres:\n      host: 127.0.0.1\nnotification:\n  push:\n    provider: \"" + provider + "\"\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "test.yaml"), []byte(yaml), 0o600))
+		require.NoError(t, config.Load("test", dir))
+	}
+
+	write("")
+	_, err := NewPushSender()
+	assert.ErrorIs(t, err, ErrNoProvider, "an empty provider never falls back to the fake")
+
+	write("fake")
+	sender, err := NewPushSender()
+	assert.NoError(t, err)
+	assert.IsType(t, &FakeSender{}, sender)
+}
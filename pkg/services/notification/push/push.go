@@ -0,0 +1,53 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/utils"
+)
+
+// ErrInvalidToken is returned when the provider reports the device token as
+// unregistered or malformed. Such tokens must be removed and never retried.
+var ErrInvalidToken = errors.New("invalid device token")
+
+// ErrNoProvider is returned while notification.push.provider is not set
+var ErrNoProvider = errors.New("notification.push.provider is not set")
+
+type Message struct {
+	Token string
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+type PushSender interface {
+	// Send delivers one message to one device. It returns ErrInvalidToken (wrapped)
+	// for tokens that must be pruned, any other error is treated as retryable.
+	Send(ctx context.Context, message Message) error
+}
+
+// NewPushSender builds the sender selected by notification.push.provider
+//
+//	fcm  -> firebase cloud messaging http v1 api
+//	fake -> in memory sender which only records messages
+//
+// The fake is only used when it is asked for, an empty provider returns ErrNoProvider.
+func NewPushSender() (PushSender, error) {
+	c := config.App().Notification.Push
+	switch provider := c.Provider; provider {
+	case "fcm":
+		return NewFCMSender(FCMConfig{
+			ProjectID:       c.FCM.ProjectID,
+			CredentialsFile: c.FCM.CredentialsFile,
+			Timeout:         c.FCM.Timeout,
+		}, utils.GetRestCaller())
+	case "fake":
+		return NewFakeSender(), nil
+	case "":
+		return nil, ErrNoProvider
+	default:
+		return nil, fmt.Errorf("unknown push provider [%s]", provider)
+	}
+}
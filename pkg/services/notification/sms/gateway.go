@@ -0,0 +1,138 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/utils"
+	"net/http"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrInvalidNumber is returned when the gateway rejects the destination, such messages are not retried
+var ErrInvalidNumber = errors.New("invalid phone number")
+
+// ErrNoProvider is returned while notification.sms.provider is not set
+var ErrNoProvider = errors.New("notification.sms.provider is not set")
+
+type Message struct {
+	To   string
+	Text string
+	// Reference is echoed back by the gateway in delivery reports
+	Reference string
+}
+
+type SMSGateway interface {
+	// Send submits the message and returns the gateway's message id used by delivery reports
+	Send(ctx context.Context, message Message) (string, error)
+}
+
+type GatewayConfig struct {
+	URL         string
+	APIKey      string
+	SenderID    string
+	CallbackURL string
+	// Timeout of each gateway call in milliseconds
+	Timeout int64
+}
+
+type httpGateway struct {
+	cfg  GatewayConfig
+	rest utils.RestCaller
+}
+
+type sendRequest struct {
+	To          string `json:"to"`
+	From        string `json:"from"`
+	Text        string `json:"text"`
+	Unicode     bool   `json:"unicode"`
+	Parts       int    `json:"parts"`
+	Reference   string `json:"reference,omitempty"`
+	CallbackURL string `json:"callback_url,omitempty"`
+}
+
+// MarshalLogObject keeps the text out of the logs, InvokeResty logs the request body
+// and the text of an otp message is the code itself.
+func (r sendRequest) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("to", r.To)
+	enc.AddString("from", r.From)
+	enc.AddString("text", config.Redacted)
+	enc.AddBool("unicode", r.Unicode)
+	enc.AddInt("parts", r.Parts)
+	enc.AddString("reference", r.Reference)
+	enc.AddString("callback_url", r.CallbackURL)
+	return nil
+}
+
+type sendResponse struct {
+	MessageID string `json:"message_id"`
+	Status    string `json:"status"`
+	Error     string `json:"error"`
+}
+
+// NewSMSGateway builds the gateway selected by notification.sms.provider
+//
+//	http -> json http gateway called through utils.RestCaller
+//	mock -> in memory gateway which only records messages
+//
+// The mock is only used when it is asked for, an empty provider returns ErrNoProvider.
+func NewSMSGateway() (SMSGateway, error) {
+	c := config.App().Notification.SMS
+	switch provider := c.Provider; provider {
+	case "http":
+		return NewHTTPGateway(GatewayConfig{
+			URL:         c.URL,
+			APIKey:      c.APIKey,
+			SenderID:    c.SenderID,
+			CallbackURL: c.CallbackURL,
+			Timeout:     c.Timeout,
+		}, utils.GetRestCaller())
+	case "mock":
+		return NewMockGateway(), nil
+	case "":
+		return nil, ErrNoProvider
+	default:
+		return nil, fmt.Errorf("unknown sms provider [%s]", provider)
+	}
+}
+
+func NewHTTPGateway(cfg GatewayConfig, rest utils.RestCaller) (SMSGateway, error) {
+	if cfg.URL == "" || cfg.APIKey == "" {
+		return nil, errors.New("sms gateway url and api key are required")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5000
+	}
+	return &httpGateway{cfg: cfg, rest: rest}, nil
+}
+
+func (g *httpGateway) Send(ctx context.Context, message Message) (string, error) {
+	request := sendRequest{
+		To:          message.To,
+		From:        g.cfg.SenderID,
+		Text:        message.Text,
+		Unicode:     DetectEncoding(message.Text) == UCS2,
+		Parts:       SegmentCount(message.Text),
+		Reference:   message.Reference,
+		CallbackURL: g.cfg.CallbackURL,
+	}
+	body, status, err := g.rest.InvokeResty(utils.BackgroundGinContext(ctx), http.MethodPost, g.cfg.URL, request, nil,
+		g.cfg.Timeout, map[string]string{"token": g.cfg.APIKey}, nil, nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("sms gateway call failed: %w", err)
+	}
+
+	var response sendResponse
+	_ = json.Unmarshal(body, &response)
+	switch {
+	case status >= 200 && status < 300 && response.MessageID != "":
+		return response.MessageID, nil
+	case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity:
+		return "", fmt.Errorf("%w: %s", ErrInvalidNumber, response.Error)
+	default:
+		return "", fmt.Errorf("sms gateway failed with status %d: %s", status, response.Error)
+	}
+}
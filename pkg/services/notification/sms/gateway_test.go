@@ -0,0 +1,70 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNewSMSGateway_Provider(t *testing.T) {
+	dir := t.TempDir()
+	write := func(provider string) {
+		yaml := "repo:\n  databases:\n    postgres:\n      host: 127.0.0.1\nnotification:\n  sms:\n    provider: \"" + provider + "\"\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "test.yaml"), []byte(yaml), 0o600))
+		require.NoError(t, config.Load("test", dir))
+	}
+
+	write("")
+	_, err := NewSMSGateway()
+	assert.ErrorIs(t, err, ErrNoProvider, "an empty provider never falls back to the mock")
+
+	write("mock")
+	gateway, err := NewSMSGateway()
+	assert.NoError(t, err)
+	assert.IsType(t, &MockGateway{}, gateway)
+}
+
+func TestHTTPGateway_TextIsNotLogged(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.yaml"), []byte("repo:\n  databases:\n    postgres:\n      host: 127.0.0.1\nnotification:\n  sms:\n    provider: http\n"), 0o600))
+	require.NoError(t, config.Load("test", dir))
+
+	var received sendRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		_, _ = w.Write([]byte(`{"message_id":"m-1","status":"accepted"}`))
+	}))
+	defer server.Close()
+
+	core, logs := observer.New(zap.DebugLevel)
+	defer logger.Replace(zap.New(core))()
+
+	gateway, err := NewHTTPGateway(GatewayConfig{URL: server.URL, APIKey: "key", Timeout: 1000}, utils.GetRestCaller())
+	require.NoError(t, err)
+
+	// Triggering Function
+	id, err := gateway.Send(context.Background(), Message{To: "+919800000001", Text: "Your OTP is 482913"})
+
+	// Validations
+	require.NoError(t, err)
+	assert.Equal(t, "m-1", id)
+	assert.Equal(t, "Your OTP is 482913", received.Text, "the gateway still gets the text")
+	require.NotZero(t, logs.Len(), "the request is logged")
+	for _, entry := range logs.All() {
+		assert.NotContains(t, entry.Message, "482913")
+		assert.NotContains(t, fmt.Sprint(entry.ContextMap()), "482913")
+	}
+}
@@ -0,0 +1,45 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// MockGateway records messages instead of sending them. It is used for tests and local runs.
+type MockGateway struct {
+	mu             sync.Mutex
+	Sent           []Message
+	InvalidNumbers map[string]bool
+	// Err, when set, is returned for every valid number to simulate gateway outages
+	Err error
+}
+
+func NewMockGateway() *MockGateway {
+	return &MockGateway{InvalidNumbers: make(map[string]bool)}
+}
+
+func (m *MockGateway) Send(ctx context.Context, message Message) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.InvalidNumbers[message.To] {
+		return "", fmt.Errorf("%w: %s", ErrInvalidNumber, message.To)
+	}
+	if m.Err != nil {
+		return "", m.Err
+	}
+	m.Sent = append(m.Sent, message)
+	logger.Log(ctx).Debug("mock sms sent", zap.String("to", message.To), zap.Int("parts", SegmentCount(message.Text)))
+	return fmt.Sprintf("mock-%d", len(m.Sent)), nil
+}
+
+// Messages returns a copy of the messages sent so far
+func (m *MockGateway) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.Sent...)
+}
@@ -0,0 +1,148 @@
+package sms
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+type Encoding string
+
+const (
+	GSM7 Encoding = "gsm7"
+	UCS2 Encoding = "ucs2"
+)
+
+// segment sizes in septets (GSM-7) or UTF-16 code units (UCS-2). Concatenated
+// messages lose room to the user data header.
+const (
+	gsm7SingleLimit = 160
+	gsm7PartLimit   = 153
+	ucs2SingleLimit = 70
+	ucs2PartLimit   = 67
+
+	virama = '्'
+	zwj    = '‍'
+	zwnj   = '‌'
+)
+
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// characters of the GSM-7 extension table, each costs an escape plus the character
+const gsm7Extended = "^{}\\[~]|€\f"
+
+// DetectEncoding returns GSM7 when every character is in the GSM 03.38 alphabet, UCS2 otherwise
+func DetectEncoding(text string) Encoding {
+	for _, r := range text {
+		if !strings.ContainsRune(gsm7Basic, r) && !strings.ContainsRune(gsm7Extended, r) {
+			return UCS2
+		}
+	}
+	return GSM7
+}
+
+// Split breaks text into the parts a handset will reassemble. Parts never end in the middle
+// of a GSM-7 escape sequence, a UTF-16 surrogate pair or a Devanagari cluster (a consonant
+// with its matras, nukta and virama conjuncts) so Hindi text renders correctly on every part.
+func Split(text string) []string {
+	if text == "" {
+		return nil
+	}
+	encoding := DetectEncoding(text)
+	clusters := splitClusters(text)
+
+	singleLimit, partLimit := gsm7SingleLimit, gsm7PartLimit
+	if encoding == UCS2 {
+		singleLimit, partLimit = ucs2SingleLimit, ucs2PartLimit
+	}
+	total := 0
+	for _, cluster := range clusters {
+		total += units(cluster, encoding)
+	}
+	if total <= singleLimit {
+		return []string{text}
+	}
+
+	var (
+		parts   []string
+		current strings.Builder
+		size    int
+	)
+	for _, cluster := range clusters {
+		clusterSize := units(cluster, encoding)
+		if size+clusterSize > partLimit && size > 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+			size = 0
+		}
+		// a single cluster longer than a part can only be split by code point
+		if clusterSize > partLimit {
+			for _, r := range cluster {
+				runeSize := units(string(r), encoding)
+				if size+runeSize > partLimit {
+					parts = append(parts, current.String())
+					current.Reset()
+					size = 0
+				}
+				current.WriteRune(r)
+				size += runeSize
+			}
+			continue
+		}
+		current.WriteString(cluster)
+		size += clusterSize
+	}
+	if size > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
+
+// SegmentCount returns how many SMS the text is billed as
+func SegmentCount(text string) int {
+	return len(Split(text))
+}
+
+// units returns the size of s in septets for GSM7 or UTF-16 code units for UCS2
+func units(s string, encoding Encoding) int {
+	if encoding == UCS2 {
+		return len(utf16.Encode([]rune(s)))
+	}
+	n := 0
+	for _, r := range s {
+		n++
+		if strings.ContainsRune(gsm7Extended, r) {
+			n++
+		}
+	}
+	return n
+}
+
+// splitClusters groups runes which must stay in the same part: combining marks stay with
+// their base character, and a virama or joiner binds the following consonant
+func splitClusters(text string) []string {
+	var clusters []string
+	var current []rune
+	for _, r := range text {
+		if len(current) > 0 && joinsPrevious(current[len(current)-1], r) {
+			current = append(current, r)
+			continue
+		}
+		if len(current) > 0 {
+			clusters = append(clusters, string(current))
+		}
+		current = []rune{r}
+	}
+	if len(current) > 0 {
+		clusters = append(clusters, string(current))
+	}
+	return clusters
+}
+
+func joinsPrevious(previous rune, r rune) bool {
+	if unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r) || r == zwj || r == zwnj {
+		return true
+	}
+	return previous == virama || previous == zwj
+}
@@ -0,0 +1,71 @@
+package sms
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	assert.Equal(t, GSM7, DetectEncoding("Wheat price is Rs 2250 in Barabanki"))
+	assert.Equal(t, GSM7, DetectEncoding("Price {up} by €5"))
+	assert.Equal(t, UCS2, DetectEncoding("Wheat ₹2250"))
+	assert.Equal(t, UCS2, DetectEncoding("गेहूं का भाव"))
+}
+
+func TestSplit(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		text          string
+		expectedParts int
+	}{
+		{desc: "Empty", text: "", expectedParts: 0},
+		{desc: "GSM7Single", text: strings.Repeat("a", 160), expectedParts: 1},
+		{desc: "GSM7Multipart", text: strings.Repeat("a", 161), expectedParts: 2},
+		{desc: "GSM7ExtendedCountsDouble", text: strings.Repeat("€", 81), expectedParts: 2},
+		{desc: "UCS2Single", text: strings.Repeat("क", 70), expectedParts: 1},
+		{desc: "UCS2Multipart", text: strings.Repeat("क", 71), expectedParts: 2},
+		{desc: "UCS2ThreeParts", text: strings.Repeat("क", 135), expectedParts: 3},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.desc, func(t *testing.T) {
+			parts := Split(testCase.text)
+			assert.Len(t, parts, testCase.expectedParts)
+			assert.Equal(t, testCase.text, strings.Join(parts, ""))
+		})
+	}
+}
+
+func TestSplit_KeepsDevanagariClustersTogether(t *testing.T) {
+	// "क्षि" is a conjunct (क + virama + ष) with a vowel sign, four code points which must stay in one part
+	text := strings.Repeat("ब", 66) + "क्षि" + " गेहूं"
+
+	parts := Split(text)
+
+	assert.Len(t, parts, 2)
+	assert.Equal(t, text, strings.Join(parts, ""))
+	assert.True(t, strings.HasPrefix(parts[1], "क्षि"))
+	for _, part := range parts {
+		assert.True(t, utf8.ValidString(part))
+		assert.LessOrEqual(t, len(utf16.Encode([]rune(part))), ucs2PartLimit)
+	}
+}
+
+func TestRender(t *testing.T) {
+	text, err := Render("Hindi", TemplateOTP, TemplateData{Code: "482913", ValidMinutes: 5})
+	assert.NoError(t, err)
+	assert.Contains(t, text, "482913")
+	assert.Equal(t, UCS2, DetectEncoding(text))
+
+	text, err = Render("English", "unknown-type", TemplateData{Message: "Wheat price up"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Kisaan Sathi: Wheat price up", text)
+
+	text, err = Render("en", "rental", TemplateData{Message: "Tractor booking confirmed"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Kisaan Sathi: Equipment rental - Tractor booking confirmed", text)
+}
@@ -0,0 +1,79 @@
+package sms
+
+import (
+	"strings"
+	"text/template"
+)
+
+const (
+	LanguageHindi   = "hi"
+	LanguageEnglish = "en"
+
+	// TemplateOTP is the template name used for one time passwords
+	TemplateOTP = "otp"
+	// templateDefault is used for notification types without their own template
+	templateDefault = "general"
+)
+
+// templates per language and notification type. Hindi is the default as most
+// feature-phone users read Hindi; messages are sent as Unicode (UCS-2) SMS.
+var templates = map[string]map[string]*template.Template{
+	LanguageHindi: {
+		"price":         parse("किसान साथी: मंडी भाव - {{.Message}}"),
+		"scheme":        parse("किसान साथी: योजना सूचना - {{.Message}}"),
+		"weather":       parse("किसान साथी: मौसम चेतावनी - {{.Message}}"),
+		"crop":          parse("किसान साथी: फसल कार्य - {{.Message}}"),
+		"market":        parse("किसान साथी: बाज़ार - {{.Message}}"),
+		"rental":        parse("किसान साथी: उपकरण किराया - {{.Message}}"),
+		templateDefault: parse("किसान साथी: {{.Message}}"),
+		TemplateOTP:     parse("किसान साथी: आपका OTP {{.Code}} है। यह {{.ValidMinutes}} मिनट तक मान्य है। इसे किसी के साथ साझा न करें।"),
+	},
+	LanguageEnglish: {
+		"price":         parse("Kisaan Sathi: Mandi price - {{.Message}}"),
+		"scheme":        parse("Kisaan Sathi: Scheme update - {{.Message}}"),
+		"weather":       parse("Kisaan Sathi: Weather alert - {{.Message}}"),
+		"crop":          parse("Kisaan Sathi: Crop task - {{.Message}}"),
+		"market":        parse("Kisaan Sathi: Market - {{.Message}}"),
+		"rental":        parse("Kisaan Sathi: Equipment rental - {{.Message}}"),
+		templateDefault: parse("Kisaan Sathi: {{.Message}}"),
+		TemplateOTP:     parse("Kisaan Sathi: Your OTP is {{.Code}}. It is valid for {{.ValidMinutes}} minutes. Do not share it with anyone."),
+	},
+}
+
+type TemplateData struct {
+	Message      string
+	Code         string
+	ValidMinutes int
+}
+
+func parse(text string) *template.Template {
+	return template.Must(template.New("").Parse(text))
+}
+
+// LanguageCode maps the free text language stored on kisan.users to a template language
+func LanguageCode(language string) string {
+	switch strings.ToLower(strings.TrimSpace(language)) {
+	case "en", "english":
+		return LanguageEnglish
+	default:
+		return LanguageHindi
+	}
+}
+
+// Render fills the template for name (a notification type or TemplateOTP) in language,
+// falling back to the general template and to Hindi
+func Render(language string, name string, data TemplateData) (string, error) {
+	byName, ok := templates[LanguageCode(language)]
+	if !ok {
+		byName = templates[LanguageHindi]
+	}
+	tmpl, ok := byName[name]
+	if !ok {
+		tmpl = byName[templateDefault]
+	}
+	var text strings.Builder
+	if err := tmpl.Execute(&text, data); err != nil {
+		return "", err
+	}
+	return text.String(), nil
+}
@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"context"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/forecast"
+	"kisaanSathi/pkg/services/recommendation/db"
+	"kisaanSathi/pkg/services/recommendation/models"
+	"time"
+)
+
+type controller struct {
+	store   db.RecommendationStore
+	climate forecast.ClimateProvider
+	cache   repo.RedisInterface
+	weights models.Weights
+	now     func() time.Time
+}
+
+type RecommendationController interface {
+	// RecommendCrops ranks the known crops by how well they suit the farm in the season
+	RecommendCrops(ctx context.Context, userID int64, request *models.CropRequest) (*models.CropRecommendation, error)
+	// WarmClimate caches the climate normals of the location, the handler of models.WarmClimate
+	WarmClimate(ctx context.Context, task models.ClimateTask) error
+}
+
+// NewRecommendationController normalises the weights to sum to 1, falling back to
+// models.DefaultWeights when none are set
+func NewRecommendationController(store db.RecommendationStore, climate forecast.ClimateProvider, cache repo.RedisInterface, weights models.Weights) RecommendationController {
+	return &controller{
+		store:   store,
+		climate: climate,
+		cache:   cache,
+		weights: normalise(weights),
+		now:     time.Now,
+	}
+}
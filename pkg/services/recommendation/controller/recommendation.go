@@ -0,0 +1,268 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/forecast"
+	"kisaanSathi/pkg/services/recommendation/models"
+	"kisaanSathi/pkg/utils"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// climateTTL keeps the climate normals of a location, they only change once a year
+const climateTTL = 30 * 24 * time.Hour
+
+// seasonMonths are the growing months of a season, January is 1
+var seasonMonths = map[string][]time.Month{
+	"kharif": {time.June, time.July, time.August, time.September, time.October},
+	"rabi":   {time.November, time.December, time.January, time.February, time.March},
+	"zaid":   {time.March, time.April, time.May, time.June},
+}
+
+func (s *controller) RecommendCrops(ctx context.Context, userID int64, request *models.CropRequest) (*models.CropRecommendation, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	now := s.now()
+	site, err := s.store.GetFarmSite(ctx, userID, request.FarmID)
+	if err != nil {
+		return nil, err
+	}
+	season := request.Season
+	if season == "" {
+		season = sowingSeason(now)
+	}
+	profiles, err := s.store.ListCropProfiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	trends, err := s.store.ListPriceTrends(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	trendOf := make(map[string]models.PriceTrend)
+	for _, trend := range trends {
+		trendOf[trend.Crop] = trend
+	}
+
+	recommendation := &models.CropRecommendation{
+		Farm:        *site,
+		Season:      season,
+		Climate:     s.seasonClimate(ctx, site, season),
+		Weights:     s.weights,
+		Crops:       make([]models.CropScore, 0, len(profiles)),
+		GeneratedAt: now,
+	}
+	for _, profile := range profiles {
+		score := scoreCrop(profile, site, season, recommendation.Climate, trendOf[strings.ToLower(profile.Crop)], s.weights)
+		recommendation.Crops = append(recommendation.Crops, score)
+	}
+	sort.SliceStable(recommendation.Crops, func(i, j int) bool {
+		return recommendation.Crops[i].Score > recommendation.Crops[j].Score
+	})
+	for i := range recommendation.Crops {
+		recommendation.Crops[i].Rank = i + 1
+	}
+	return recommendation, nil
+}
+
+// seasonClimate sums up the climate normals over the season's months. The normals are cached per
+// location rounded to about 10 km; without a location or provider the climate is not available.
+func (s *controller) seasonClimate(ctx context.Context, site *models.FarmSite, season string) models.SeasonClimate {
+	if site.Lat == nil || site.Lng == nil || s.climate == nil {
+		return models.SeasonClimate{}
+	}
+	normals, err := s.climateNormals(ctx, *site.Lat, *site.Lng)
+	if err != nil {
+		logger.Log(ctx).Error("climate normals unavailable", zap.Error(err))
+		return models.SeasonClimate{}
+	}
+	return climateOf(normals, season)
+}
+
+func (s *controller) WarmClimate(ctx context.Context, task models.ClimateTask) error {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	if s.climate == nil {
+		return nil
+	}
+	_, err := s.climateNormals(ctx, task.Lat, task.Lng)
+	return err
+}
+
+func (s *controller) climateNormals(ctx context.Context, lat float64, lng float64) (*forecast.ClimateNormals, error) {
+	lat, lng = utils.Round(lat, 1), utils.Round(lng, 1)
+	// v2 keys hold the entries of repo.GetOrLoad, the older keys hold bare normals
+	key := fmt.Sprintf("climate:v2:%.1f:%.1f", lat, lng)
+	return repo.GetOrLoad(ctx, s.cache, key, repo.LoadOptions{TTL: climateTTL}, func(ctx context.Context) (*forecast.ClimateNormals, error) {
+		return s.climate.Normals(ctx, lat, lng)
+	})
+}
+
+func climateOf(normals *forecast.ClimateNormals, season string) models.SeasonClimate {
+	months := seasonMonths[season]
+	climate := models.SeasonClimate{Available: true, Years: normals.Years}
+	for _, month := range months {
+		climate.Rainfall += normals.Rainfall[month-1]
+		climate.Temperature += normals.Temperature[month-1]
+	}
+	climate.Rainfall = utils.Round(climate.Rainfall, 0)
+	climate.Temperature = utils.Round(climate.Temperature/float64(len(months)), 1)
+	return climate
+}
+
+// sowingSeason is the season sown around now: rabi from October, zaid from February and kharif from May
+func sowingSeason(now time.Time) string {
+	switch now.Month() {
+	case time.October, time.November, time.December, time.January:
+		return "rabi"
+	case time.February, time.March, time.April:
+		return "zaid"
+	default:
+		return "kharif"
+	}
+}
+
+// scoreCrop weighs the 0 to 1 component scores into a 0 to 100 suitability score. Components
+// without data score a neutral 0.5 so that missing data neither promotes nor buries a crop.
+func scoreCrop(profile models.CropProfile, site *models.FarmSite, season string, climate models.SeasonClimate, trend models.PriceTrend, weights models.Weights) models.CropScore {
+	score := models.CropScore{Crop: profile.Crop, Components: make(map[string]float64)}
+	add := func(component string, value float64, reasons ...string) {
+		score.Components[component] = utils.Round(value, 2)
+		score.Explanation = append(score.Explanation, reasons...)
+	}
+
+	add(seasonScore(profile, season))
+	add(soilScore(profile, site))
+	add(rainfallScore(profile, site, climate))
+	add(temperatureScore(profile, climate))
+	add(marketScore(trend))
+
+	total := weights.Soil*score.Components[models.ComponentSoil] +
+		weights.Rainfall*score.Components[models.ComponentRainfall] +
+		weights.Temperature*score.Components[models.ComponentTemperature] +
+		weights.Season*score.Components[models.ComponentSeason] +
+		weights.Market*score.Components[models.ComponentMarket]
+	score.Score = utils.Round(total*100, 1)
+	return score
+}
+
+func seasonScore(profile models.CropProfile, season string) (string, float64, string) {
+	if containsFold(profile.Seasons, season) {
+		return models.ComponentSeason, 1, fmt.Sprintf("%s is grown in %s", profile.Crop, season)
+	}
+	return models.ComponentSeason, 0, fmt.Sprintf("%s is not a %s crop", profile.Crop, season)
+}
+
+func soilScore(profile models.CropProfile, site *models.FarmSite) (string, float64, string, string) {
+	typeScore, typeReason := 0.4, fmt.Sprintf("%s soil is not ideal for %s, it prefers %s", title(site.SoilType), profile.Crop, strings.ReplaceAll(profile.SoilTypes, ",", ", "))
+	if containsFold(profile.SoilTypes, site.SoilType) {
+		typeScore, typeReason = 1, fmt.Sprintf("%s soil suits %s", title(site.SoilType), profile.Crop)
+	}
+	if site.PH == nil {
+		return models.ComponentSoil, typeScore, typeReason, "no soil test, pH not considered"
+	}
+
+	ph := *site.PH
+	phScore, phReason := 1.0, fmt.Sprintf("soil pH %.1f is within %.1f-%.1f", ph, profile.PHMin, profile.PHMax)
+	if distance := outside(ph, profile.PHMin, profile.PHMax); distance > 0 {
+		phScore = math.Max(0, 1-distance/1.5)
+		phReason = fmt.Sprintf("soil pH %.1f is outside %.1f-%.1f", ph, profile.PHMin, profile.PHMax)
+	}
+	return models.ComponentSoil, (typeScore + phScore) / 2, typeReason, phReason
+}
+
+func rainfallScore(profile models.CropProfile, site *models.FarmSite, climate models.SeasonClimate) (string, float64, string) {
+	if !climate.Available {
+		return models.ComponentRainfall, 0.5, "no rainfall history for the location"
+	}
+	rain := climate.Rainfall
+	irrigated := site.IrrigationType != "" && !strings.EqualFold(site.IrrigationType, "rainfed")
+	switch {
+	case rain < profile.RainfallMin && irrigated:
+		return models.ComponentRainfall, 0.8, fmt.Sprintf("season rainfall of %.0f mm is below the %.0f mm needed, %s irrigation makes up for it", rain, profile.RainfallMin, site.IrrigationType)
+	case rain < profile.RainfallMin:
+		return models.ComponentRainfall, rain / profile.RainfallMin, fmt.Sprintf("season rainfall of %.0f mm is below the %.0f mm needed on a rainfed plot", rain, profile.RainfallMin)
+	case profile.RainfallMax > 0 && rain > profile.RainfallMax:
+		return models.ComponentRainfall, profile.RainfallMax / rain, fmt.Sprintf("season rainfall of %.0f mm is more than the %.0f mm it tolerates", rain, profile.RainfallMax)
+	}
+	return models.ComponentRainfall, 1, fmt.Sprintf("season rainfall of %.0f mm is within %.0f-%.0f mm", rain, profile.RainfallMin, profile.RainfallMax)
+}
+
+func temperatureScore(profile models.CropProfile, climate models.SeasonClimate) (string, float64, string) {
+	if !climate.Available {
+		return models.ComponentTemperature, 0.5, "no temperature history for the location"
+	}
+	temperature := climate.Temperature
+	if distance := outside(temperature, profile.TempMin, profile.TempMax); distance > 0 {
+		return models.ComponentTemperature, math.Max(0, 1-distance/5),
+			fmt.Sprintf("mean season temperature of %.1f°C is outside %.0f-%.0f°C", temperature, profile.TempMin, profile.TempMax)
+	}
+	return models.ComponentTemperature, 1, fmt.Sprintf("mean season temperature of %.1f°C is within %.0f-%.0f°C", temperature, profile.TempMin, profile.TempMax)
+}
+
+// marketScore maps the price change of the last 30 days onto 0 to 1, a 20% rise or fall saturates it
+func marketScore(trend models.PriceTrend) (string, float64, string) {
+	if trend.Recent == nil {
+		return models.ComponentMarket, 0.5, "no recent mandi prices"
+	}
+	if trend.Before == nil || *trend.Before <= 0 {
+		return models.ComponentMarket, 0.5, fmt.Sprintf("mandi price Rs. %.0f/quintal, not enough history for a trend", *trend.Recent)
+	}
+	change := (*trend.Recent - *trend.Before) / *trend.Before
+	direction := "up"
+	if change < 0 {
+		direction = "down"
+	}
+	return models.ComponentMarket, math.Min(1, math.Max(0, 0.5+change*2.5)),
+		fmt.Sprintf("mandi prices %s %.0f%% over the last 30 days", direction, math.Abs(change)*100)
+}
+
+// outside is how far value lies outside [min, max], 0 when inside
+func outside(value float64, min float64, max float64) float64 {
+	if value < min {
+		return min - value
+	}
+	if value > max {
+		return value - max
+	}
+	return 0
+}
+
+func normalise(weights models.Weights) models.Weights {
+	sum := weights.Soil + weights.Rainfall + weights.Temperature + weights.Season + weights.Market
+	if sum <= 0 {
+		return models.DefaultWeights
+	}
+	return models.Weights{
+		Soil:        weights.Soil / sum,
+		Rainfall:    weights.Rainfall / sum,
+		Temperature: weights.Temperature / sum,
+		Season:      weights.Season / sum,
+		Market:      weights.Market / sum,
+	}
+}
+
+func containsFold(list string, value string) bool {
+	for _, item := range utils.GetSliceFromStringBySeparator(list, ",") {
+		if strings.EqualFold(strings.TrimSpace(item), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func title(value string) string {
+	if value == "" {
+		return "Unknown"
+	}
+	return strings.ToUpper(value[:1]) + strings.ToLower(value[1:])
+}
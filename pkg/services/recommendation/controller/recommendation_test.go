@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/forecast"
+	"kisaanSathi/pkg/services/recommendation/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStore struct {
+	site     models.FarmSite
+	profiles []models.CropProfile
+	trends   []models.PriceTrend
+}
+
+func (f *fakeStore) GetFarmSite(ctx context.Context, userID int64, farmID int64) (*models.FarmSite, error) {
+	site := f.site
+	return &site, nil
+}
+
+func (f *fakeStore) ListCropProfiles(ctx context.Context) ([]models.CropProfile, error) {
+	return f.profiles, nil
+}
+
+func (f *fakeStore) ListPriceTrends(ctx context.Context, now time.Time) ([]models.PriceTrend, error) {
+	return f.trends, nil
+}
+
+type fakeClimate struct {
+	normals *forecast.ClimateNormals
+	err     error
+}
+
+func (f *fakeClimate) Normals(ctx context.Context, lat float64, lng float64) (*forecast.ClimateNormals, error) {
+	return f.normals, f.err
+}
+
+func float(v float64) *float64 { return &v }
+
+func newFixture() (*fakeStore, *fakeClimate) {
+	store := &fakeStore{
+		site: models.FarmSite{FarmID: 3, SoilType: "loamy", IrrigationType: "tubewell", Lat: float(26.93), Lng: float(81.19), PH: float(7.2)},
+		profiles: []models.CropProfile{
+			{Crop: "Paddy", Seasons: "kharif", SoilTypes: "clay,alluvial,loamy", PHMin: 5.5, PHMax: 7.0, RainfallMin: 1000, RainfallMax: 2500, TempMin: 22, TempMax: 35},
+			{Crop: "Potato", Seasons: "rabi", SoilTypes: "loamy,sandy", PHMin: 5.2, PHMax: 6.5, RainfallMin: 100, RainfallMax: 500, TempMin: 12, TempMax: 24},
+			{Crop: "Wheat", Seasons: "rabi", SoilTypes: "alluvial,loamy", PHMin: 6.0, PHMax: 7.5, RainfallMin: 100, RainfallMax: 500, TempMin: 12, TempMax: 25},
+		},
+		trends: []models.PriceTrend{
+			{Crop: "wheat", Recent: float(2300), Before: float(2200)},
+			{Crop: "potato", Recent: float(700), Before: float(1000)},
+		},
+	}
+	normals := &forecast.ClimateNormals{Years: 5}
+	// Nov to Mar: 10 + 5 + 20 + 25 + 15 mm, a mean of 18°C
+	for month, rain := range map[time.Month]float64{time.November: 10, time.December: 5, time.January: 20, time.February: 25, time.March: 15} {
+		normals.Rainfall[month-1] = rain
+		normals.Temperature[month-1] = 18
+	}
+	return store, &fakeClimate{normals: normals}
+}
+
+func TestRecommendCrops_Rabi(t *testing.T) {
+	logger.LoggerInit("", -1)
+	store, climate := newFixture()
+	c := NewRecommendationController(store, climate, nil, models.DefaultWeights).(*controller)
+	c.now = func() time.Time { return time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC) }
+
+	recommendation, err := c.RecommendCrops(context.TODO(), 1, &models.CropRequest{FarmID: 3})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "rabi", recommendation.Season)
+	assert.Equal(t, models.SeasonClimate{Available: true, Rainfall: 75, Temperature: 18, Years: 5}, recommendation.Climate)
+	assert.Equal(t, []string{"Wheat", "Potato", "Paddy"}, []string{
+		recommendation.Crops[0].Crop, recommendation.Crops[1].Crop, recommendation.Crops[2].Crop})
+
+	wheat := recommendation.Crops[0]
+	assert.Equal(t, 1, wheat.Rank)
+	// irrigation makes up for 75 mm of rain, prices are up 4.5%
+	assert.Equal(t, 0.8, wheat.Components[models.ComponentRainfall])
+	assert.Equal(t, 0.61, wheat.Components[models.ComponentMarket])
+	assert.Equal(t, 90.2, wheat.Score)
+	assert.Contains(t, wheat.Explanation, "Loamy soil suits Wheat")
+	assert.Contains(t, wheat.Explanation, "mandi prices up 5% over the last 30 days")
+
+	potato := recommendation.Crops[1]
+	assert.Contains(t, potato.Explanation, "soil pH 7.2 is outside 5.2-6.5")
+	assert.Contains(t, potato.Explanation, "mandi prices down 30% over the last 30 days")
+	assert.Equal(t, 0.0, potato.Components[models.ComponentMarket])
+}
+
+func TestRecommendCrops_NoClimate(t *testing.T) {
+	logger.LoggerInit("", -1)
+	store, climate := newFixture()
+	store.site.PH = nil
+	climate.err = errors.New("archive down")
+	c := NewRecommendationController(store, climate, nil, models.Weights{Soil: 1, Season: 1}).(*controller)
+
+	recommendation, err := c.RecommendCrops(context.TODO(), 1, &models.CropRequest{FarmID: 3, Season: "kharif"})
+
+	assert.NoError(t, err)
+	assert.False(t, recommendation.Climate.Available)
+	assert.Equal(t, models.Weights{Soil: 0.5, Season: 0.5}, recommendation.Weights)
+	paddy := recommendation.Crops[0]
+	assert.Equal(t, "Paddy", paddy.Crop)
+	assert.Equal(t, 100.0, paddy.Score)
+	assert.Contains(t, paddy.Explanation, "no rainfall history for the location")
+	assert.Contains(t, paddy.Explanation, "no soil test, pH not considered")
+}
+
+func TestWarmClimate(t *testing.T) {
+	logger.LoggerInit("", -1)
+	store, climate := newFixture()
+	c := NewRecommendationController(store, climate, repo.NewMemoryCache(), models.DefaultWeights)
+
+	assert.NoError(t, c.WarmClimate(context.TODO(), models.ClimateTask{Lat: 26.91, Lng: 81.21}))
+
+	// the recommendation reads the warmed normals once the archive is down
+	climate.err, climate.normals = errors.New("archive down"), nil
+	recommendation, err := c.RecommendCrops(context.TODO(), 1, &models.CropRequest{FarmID: 3, Season: "rabi"})
+	assert.NoError(t, err)
+	assert.True(t, recommendation.Climate.Available)
+
+	assert.Error(t, c.WarmClimate(context.TODO(), models.ClimateTask{Lat: 12.97, Lng: 77.59}))
+}
+
+func TestRecommendCrops_OldClimateCache(t *testing.T) {
+	logger.LoggerInit("", -1)
+	store, climate := newFixture()
+	cache := repo.NewMemoryCache()
+	// releases before GetOrLoad cached the bare normals
+	for _, key := range []string{"climate:26.9:81.2", "climate:v2:26.9:81.2"} {
+		assert.NoError(t, cache.SetTiered(context.TODO(), key, &forecast.ClimateNormals{Years: 3}, time.Hour))
+	}
+	c := NewRecommendationController(store, climate, cache, models.DefaultWeights)
+
+	recommendation, err := c.RecommendCrops(context.TODO(), 1, &models.CropRequest{FarmID: 3, Season: "rabi"})
+
+	assert.NoError(t, err)
+	assert.True(t, recommendation.Climate.Available)
+	assert.Equal(t, 5, recommendation.Climate.Years, "the normals are loaded again")
+}
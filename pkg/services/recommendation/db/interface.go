@@ -0,0 +1,25 @@
+package db
+
+import (
+	"context"
+	"kisaanSathi/pkg/services/recommendation/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type recommendationStore struct {
+	store *gorm.DB
+}
+
+type RecommendationStore interface {
+	GetFarmSite(ctx context.Context, userID int64, farmID int64) (*models.FarmSite, error)
+	ListCropProfiles(ctx context.Context) ([]models.CropProfile, error)
+	ListPriceTrends(ctx context.Context, now time.Time) ([]models.PriceTrend, error)
+}
+
+func NewDBObject(db *gorm.DB) RecommendationStore {
+	return &recommendationStore{
+		store: db,
+	}
+}
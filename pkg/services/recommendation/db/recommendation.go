@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/recommendation/models"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrFarmNotFound is also returned for farms of other users
+var ErrFarmNotFound = errors.New("farm not found")
+
+// GetFarmSite returns the farm with its latest soil test; without a boundary the user's location is used
+func (g *recommendationStore) GetFarmSite(c context.Context, userID int64, farmID int64) (*models.FarmSite, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var sites []models.FarmSite
+	err := g.store.WithContext(c).Raw(`SELECT f.id, f.name, f.soil_type, f.irrigation_type,
+			COALESCE(f.lat, u.lat) AS lat, COALESCE(f.lng, u.lng) AS lng, s.ph, s.oc
+		FROM kisan.farms f
+		JOIN kisan.users u ON u.id = f.user_id
+		LEFT JOIN LATERAL (
+			SELECT ph, oc FROM kisan.soil_tests WHERE farm_id = f.id ORDER BY test_date DESC, id DESC LIMIT 1
+		) s ON true
+		WHERE f.id = ? AND f.user_id = ?`, farmID, userID).Scan(&sites).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	if len(sites) == 0 {
+		return nil, ErrFarmNotFound
+	}
+	return &sites[0], nil
+}
+
+func (g *recommendationStore) ListCropProfiles(c context.Context) ([]models.CropProfile, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var profiles []models.CropProfile
+	err := g.store.WithContext(c).Raw(`SELECT crop, array_to_string(seasons, ',') AS seasons, array_to_string(soil_types, ',') AS soil_types,
+			ph_min, ph_max, rainfall_min, rainfall_max, temp_min, temp_max
+		FROM kisan.crop_profiles ORDER BY crop`).Scan(&profiles).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// ListPriceTrends returns per crop the mean mandi price of the 30 days before now and of the 90 days before that
+func (g *recommendationStore) ListPriceTrends(c context.Context, now time.Time) ([]models.PriceTrend, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var trends []models.PriceTrend
+	today := now.Format("2006-01-02")
+	err := g.store.WithContext(c).Raw(`SELECT lower(crop) AS crop,
+			AVG(price) FILTER (WHERE recorded_on > ?::date - 30) AS recent,
+			AVG(price) FILTER (WHERE recorded_on <= ?::date - 30) AS before
+		FROM kisan.mandi_prices
+		WHERE recorded_on > ?::date - 120 AND recorded_on <= ?::date
+		GROUP BY lower(crop)`, today, today, today, today).Scan(&trends).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return trends, nil
+}
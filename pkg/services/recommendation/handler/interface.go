@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/forecast"
+	"kisaanSathi/pkg/services/recommendation/controller"
+	"kisaanSathi/pkg/services/recommendation/db"
+	"kisaanSathi/pkg/services/recommendation/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type handler struct {
+	controller controller.RecommendationController
+}
+
+type RecommendationHandler interface {
+	RecommendCrops(c *gin.Context)
+}
+
+func NewRecommendationHandler(controller controller.RecommendationController) RecommendationHandler {
+	return &handler{
+		controller: controller,
+	}
+}
+
+// RecommendationController reads the scoring weights from recommendation.weights
+func RecommendationController(repo repo.DataObject) controller.RecommendationController {
+	weights := models.Weights(config.App().Recommendation.Weights)
+	store := db.NewDBObject(repo.Databases.Reader())
+	return controller.NewRecommendationController(store, forecast.NewClimateProvider(), repo.Cache, weights)
+}
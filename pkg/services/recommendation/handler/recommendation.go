@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/network"
+	"kisaanSathi/pkg/services/recommendation/db"
+	"kisaanSathi/pkg/services/recommendation/models"
+	"kisaanSathi/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func (f *handler) RecommendCrops(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, network.FailureResponse(network.ApiErrors.Unauthorized.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+	var request models.CropRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.RecommendCrops(c, userID, &request)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		if errors.Is(err, db.ErrFarmNotFound) {
+			c.JSON(http.StatusNotFound, network.FailureResponse(network.ApiErrors.NoDataFound.WithErrorDescription(err.Error())))
+		} else {
+			c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.GetDBError.WithErrorDescription(err.Error())))
+		}
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
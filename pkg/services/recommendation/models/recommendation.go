@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// score components, also the keys of recommendation.weights
+const (
+	ComponentSoil        = "soil"
+	ComponentRainfall    = "rainfall"
+	ComponentTemperature = "temperature"
+	ComponentSeason      = "season"
+	ComponentMarket      = "market"
+)
+
+// Weights of the score components, normalised to sum to 1 before scoring
+type Weights struct {
+	Soil        float64 `json:"soil"`
+	Rainfall    float64 `json:"rainfall"`
+	Temperature float64 `json:"temperature"`
+	Season      float64 `json:"season"`
+	Market      float64 `json:"market"`
+}
+
+// DefaultWeights apply when recommendation.weights is not configured
+var DefaultWeights = Weights{Soil: 0.3, Rainfall: 0.2, Temperature: 0.2, Season: 0.15, Market: 0.15}
+
+type CropRequest struct {
+	FarmID int64 `form:"farm_id" binding:"required,gt=0"`
+	// Season defaults to the season of the current month
+	Season string `form:"season" binding:"omitempty,oneof=rabi kharif zaid"`
+}
+
+// CropProfile is the agronomic range a crop does well in. Rainfall is the total over
+// the growing season in mm, temperature the mean over the season in °C.
+type CropProfile struct {
+	Crop        string  `gorm:"column:crop"`
+	Seasons     string  `gorm:"column:seasons"`
+	SoilTypes   string  `gorm:"column:soil_types"`
+	PHMin       float64 `gorm:"column:ph_min"`
+	PHMax       float64 `gorm:"column:ph_max"`
+	RainfallMin float64 `gorm:"column:rainfall_min"`
+	RainfallMax float64 `gorm:"column:rainfall_max"`
+	TempMin     float64 `gorm:"column:temp_min"`
+	TempMax     float64 `gorm:"column:temp_max"`
+}
+
+// FarmSite is what the recommendation knows about the farm; the location falls back to the user's
+type FarmSite struct {
+	FarmID         int64    `json:"farmId" gorm:"column:id"`
+	Name           string   `json:"name" gorm:"column:name"`
+	SoilType       string   `json:"soilType" gorm:"column:soil_type"`
+	IrrigationType string   `json:"irrigationType" gorm:"column:irrigation_type"`
+	Lat            *float64 `json:"lat,omitempty" gorm:"column:lat"`
+	Lng            *float64 `json:"lng,omitempty" gorm:"column:lng"`
+	PH             *float64 `json:"ph,omitempty" gorm:"column:ph"`
+	OC             *float64 `json:"oc,omitempty" gorm:"column:oc"`
+}
+
+// PriceTrend compares the mean mandi price of the last 30 days with the 90 days before
+type PriceTrend struct {
+	Crop   string   `gorm:"column:crop"`
+	Recent *float64 `gorm:"column:recent"`
+	Before *float64 `gorm:"column:before"`
+}
+
+type SeasonClimate struct {
+	Available   bool    `json:"available"`
+	Rainfall    float64 `json:"rainfallMm,omitempty"`
+	Temperature float64 `json:"temperatureC,omitempty"`
+	Years       int     `json:"years,omitempty"`
+}
+
+type CropScore struct {
+	Rank  int     `json:"rank"`
+	Crop  string  `json:"crop"`
+	Score float64 `json:"score"`
+	// Components are the 0 to 1 scores the weighted score is made of
+	Components  map[string]float64 `json:"components"`
+	Explanation []string           `json:"explanation"`
+}
+
+type CropRecommendation struct {
+	Farm        FarmSite      `json:"farm"`
+	Season      string        `json:"season"`
+	Climate     SeasonClimate `json:"climate"`
+	Weights     Weights       `json:"weights"`
+	Crops       []CropScore   `json:"crops"`
+	GeneratedAt time.Time     `json:"generatedAt"`
+}
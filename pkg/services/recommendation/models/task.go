@@ -0,0 +1,12 @@
+package models
+
+import "kisaanSathi/pkg/queue"
+
+// ClimateTask loads the climate normals of a location ahead of its first recommendation
+type ClimateTask struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// WarmClimate is queued when a farm with a location is created
+var WarmClimate = queue.Kind[ClimateTask]("recommendation.warm-climate")
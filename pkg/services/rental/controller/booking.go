@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	notificationModels "kisaanSathi/pkg/services/notification/models"
+	"kisaanSathi/pkg/services/rental/db"
+	"kisaanSathi/pkg/services/rental/models"
+	"kisaanSathi/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrOwnEquipment is returned when the owner books their own equipment
+	ErrOwnEquipment = errors.New("cannot book your own equipment")
+	// ErrNoRate is returned when the equipment is not rented by the requested unit
+	ErrNoRate = errors.New("equipment has no rate for this unit")
+	// ErrInvalidTransition is returned when the user cannot move the booking to the requested status
+	ErrInvalidTransition = errors.New("invalid booking status change")
+)
+
+// Book requests the slot from the owner; the slot is held until the owner rejects or either side cancels
+func (s *controller) Book(ctx context.Context, userID int64, equipmentID int64, request *models.BookingRequest) (*models.Booking, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	equipment, err := s.store.GetEquipment(ctx, equipmentID)
+	if err != nil {
+		return nil, err
+	}
+	if equipment.OwnerID == userID {
+		return nil, ErrOwnEquipment
+	}
+	from, to := request.StartsAt.UTC(), request.EndsAt.UTC()
+	switch {
+	case !from.After(s.now()):
+		return nil, fmt.Errorf("%w, the slot has already started", ErrInvalidSlot)
+	case to.Sub(from) < models.MinBooking:
+		return nil, fmt.Errorf("%w, book at least %s", ErrInvalidSlot, models.MinBooking)
+	case to.Sub(from) > models.MaxBookingDays*24*time.Hour:
+		return nil, fmt.Errorf("%w, book at most %d days", ErrInvalidSlot, models.MaxBookingDays)
+	}
+	unit := request.Unit
+	if unit == "" {
+		unit = defaultUnit(equipment, to.Sub(from))
+	}
+	rate := equipment.Rate(unit)
+	if rate == nil {
+		return nil, fmt.Errorf("%w, it is rented by the %s", ErrNoRate, otherUnit(unit))
+	}
+
+	booking := &models.Booking{
+		EquipmentID: equipmentID,
+		FarmerID:    userID,
+		StartsAt:    from,
+		EndsAt:      to,
+		Unit:        unit,
+		Rate:        *rate,
+		Amount:      utils.Round(float64(units(from, to, unit))**rate, 2),
+		Note:        strings.TrimSpace(request.Note),
+	}
+	err = s.store.CreateBooking(ctx, booking, func(tx *gorm.DB, booking *models.Booking) error {
+		return s.notifyBooking(ctx, tx, booking, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// GetBooking shows the booking to the farmer who made it and the owner of the equipment
+func (s *controller) GetBooking(ctx context.Context, userID int64, bookingID int64) (*models.Booking, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	booking, err := s.store.GetBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.FarmerID != userID && booking.OwnerID != userID {
+		return nil, db.ErrBookingNotFound
+	}
+	return booking, nil
+}
+
+func (s *controller) ListUserBookings(ctx context.Context, userID int64) ([]models.Booking, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	return s.store.ListUserBookings(ctx, userID)
+}
+
+func (s *controller) UpdateBookingStatus(ctx context.Context, userID int64, bookingID int64, request *models.BookingStatusRequest) (*models.Booking, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	booking, err := s.GetBooking(ctx, userID, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	transitions := models.FarmerTransitions
+	if booking.OwnerID == userID {
+		transitions = models.OwnerTransitions
+	}
+	if !models.CanMove(transitions, booking.Status, request.Status) {
+		return nil, fmt.Errorf("%w from %s to %s", ErrInvalidTransition, booking.Status, request.Status)
+	}
+	if request.Status == models.BookingCompleted && booking.EndsAt.After(s.now()) {
+		return nil, fmt.Errorf("%w, the slot has not ended yet", ErrInvalidTransition)
+	}
+	return s.store.UpdateBookingStatus(ctx, bookingID, booking.Status, request.Status, func(tx *gorm.DB, booking *models.Booking) error {
+		return s.notifyBooking(ctx, tx, booking, userID)
+	})
+}
+
+// notifyBooking tells the owner and the farmer about the booking's new status, the side that
+// made the change is told too so both have the lifecycle in their inbox
+func (s *controller) notifyBooking(ctx context.Context, tx *gorm.DB, booking *models.Booking, actorID int64) error {
+	for _, userID := range []int64{booking.OwnerID, booking.FarmerID} {
+		message := bookingMessage(booking, userID == booking.OwnerID, userID == actorID)
+		if _, err := s.notifier.NotifyTx(ctx, tx, userID, notificationModels.TypeRental, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bookingMessage(booking *models.Booking, owner bool, actor bool) string {
+	local := (&notificationModels.Preferences{}).Location()
+	slot := fmt.Sprintf("%s from %s to %s", booking.EquipmentName,
+		booking.StartsAt.In(local).Format(slotFormat), booking.EndsAt.In(local).Format(slotFormat))
+	switch booking.Status {
+	case models.BookingPending:
+		if owner {
+			return fmt.Sprintf("New booking request for %s, Rs. %s", slot, amount(booking.Amount))
+		}
+		return fmt.Sprintf("Your booking request for %s is sent to the owner", slot)
+	case models.BookingConfirmed:
+		if owner {
+			return fmt.Sprintf("You confirmed the booking of %s", slot)
+		}
+		return fmt.Sprintf("Your booking of %s is confirmed, Rs. %s", slot, amount(booking.Amount))
+	case models.BookingRejected:
+		if owner {
+			return fmt.Sprintf("You declined the booking of %s", slot)
+		}
+		return fmt.Sprintf("The owner declined your booking of %s", slot)
+	case models.BookingCancelled:
+		if actor {
+			return fmt.Sprintf("You cancelled the booking of %s", slot)
+		}
+		return fmt.Sprintf("The booking of %s was cancelled", slot)
+	case models.BookingCompleted:
+		if owner {
+			return fmt.Sprintf("The booking of %s is completed", slot)
+		}
+		return fmt.Sprintf("Your booking of %s is completed, Rs. %s is due", slot, amount(booking.Amount))
+	}
+	return ""
+}
+
+// slotFormat shows slot times to the users, in the default notification time zone
+const slotFormat = "02 Jan 15:04"
+
+// defaultUnit charges by the day for slots of a day or more when the equipment has a daily rate
+func defaultUnit(equipment *models.Equipment, length time.Duration) string {
+	if equipment.DailyRate != nil && (length >= 24*time.Hour || equipment.HourlyRate == nil) {
+		return models.UnitDay
+	}
+	return models.UnitHour
+}
+
+func otherUnit(unit string) string {
+	if unit == models.UnitDay {
+		return models.UnitHour
+	}
+	return models.UnitDay
+}
+
+func amount(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
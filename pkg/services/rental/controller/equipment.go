@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/rental/db"
+	"kisaanSathi/pkg/services/rental/models"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoLocation is returned for equipment without a location when the owner has none either,
+	// and for a search without a point when the farmer has no location on file
+	ErrNoLocation = errors.New("location is required, the user has no location on file")
+	// ErrInvalidSlot is returned for a window that is in the past or too short or long
+	ErrInvalidSlot = errors.New("invalid time slot")
+)
+
+func (s *controller) CreateEquipment(ctx context.Context, userID int64, request *models.EquipmentRequest) (*models.Equipment, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	user, err := s.store.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	lat, lng := request.Lat, request.Lng
+	if lat == nil || lng == nil {
+		lat, lng = user.Lat, user.Lng
+	}
+	if lat == nil || lng == nil {
+		return nil, ErrNoLocation
+	}
+
+	equipment := &models.Equipment{
+		OwnerID:     userID,
+		OwnerName:   user.Name,
+		Kind:        request.Kind,
+		Name:        strings.TrimSpace(request.Name),
+		Description: strings.TrimSpace(request.Description),
+		HourlyRate:  request.HourlyRate,
+		DailyRate:   request.DailyRate,
+		Address:     strings.TrimSpace(request.Address),
+		Lat:         *lat,
+		Lng:         *lng,
+	}
+	if err := s.store.CreateEquipment(ctx, equipment); err != nil {
+		return nil, err
+	}
+	return equipment, nil
+}
+
+func (s *controller) GetEquipment(ctx context.Context, equipmentID int64) (*models.Equipment, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	return s.store.GetEquipment(ctx, equipmentID)
+}
+
+func (s *controller) ListOwnerEquipment(ctx context.Context, userID int64) ([]models.Equipment, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	return s.store.ListOwnerEquipment(ctx, userID)
+}
+
+// SetEquipmentActive withdraws or relists the equipment, bookings already made are kept
+func (s *controller) SetEquipmentActive(ctx context.Context, userID int64, equipmentID int64, request *models.ActiveRequest) (*models.Equipment, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	equipment, err := s.ownEquipment(ctx, userID, equipmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.SetEquipmentActive(ctx, equipmentID, *request.Active); err != nil {
+		return nil, err
+	}
+	equipment.Active = *request.Active
+	return equipment, nil
+}
+
+// SearchEquipment searches around the requested point or the farmer's own location
+func (s *controller) SearchEquipment(ctx context.Context, userID int64, request *models.SearchRequest) ([]models.Equipment, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	filter := models.SearchFilter{
+		Kind:     request.Kind,
+		RadiusKm: request.RadiusKm,
+		From:     request.From.UTC(),
+		To:       request.To.UTC(),
+		Limit:    request.Limit,
+		Offset:   request.Offset,
+	}
+	if filter.RadiusKm <= 0 {
+		filter.RadiusKm = models.DefaultRadiusKm
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = models.DefaultPageSize
+	}
+	if !request.From.IsZero() && !filter.To.After(filter.From) {
+		return nil, fmt.Errorf("%w, to must be after from", ErrInvalidSlot)
+	}
+	lat, lng := request.Lat, request.Lng
+	if lat == nil || lng == nil {
+		user, err := s.store.GetUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		lat, lng = user.Lat, user.Lng
+	}
+	if lat == nil || lng == nil {
+		return nil, ErrNoLocation
+	}
+	filter.Lat, filter.Lng = *lat, *lng
+	return s.store.SearchEquipment(ctx, filter)
+}
+
+// AddSlot offers the equipment for booking in the window
+func (s *controller) AddSlot(ctx context.Context, userID int64, equipmentID int64, request *models.SlotRequest) (*models.Slot, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	if _, err := s.ownEquipment(ctx, userID, equipmentID); err != nil {
+		return nil, err
+	}
+	slot := &models.Slot{EquipmentID: equipmentID, StartsAt: request.StartsAt.UTC(), EndsAt: request.EndsAt.UTC()}
+	if !slot.EndsAt.After(s.now()) {
+		return nil, fmt.Errorf("%w, the window has already ended", ErrInvalidSlot)
+	}
+	if err := s.store.AddSlot(ctx, slot); err != nil {
+		return nil, err
+	}
+	return slot, nil
+}
+
+func (s *controller) DeleteSlot(ctx context.Context, userID int64, equipmentID int64, slotID int64) error {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	if _, err := s.ownEquipment(ctx, userID, equipmentID); err != nil {
+		return err
+	}
+	return s.store.DeleteSlot(ctx, equipmentID, slotID)
+}
+
+// GetCalendar shows the owner who holds each slot and anyone else only when it is taken
+func (s *controller) GetCalendar(ctx context.Context, userID int64, equipmentID int64, request *models.CalendarRequest) (*models.Calendar, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	equipment, err := s.store.GetEquipment(ctx, equipmentID)
+	if err != nil {
+		return nil, err
+	}
+	from, to := request.From.UTC(), request.To.UTC()
+	if request.From.IsZero() {
+		from = s.now().UTC()
+	}
+	if request.To.IsZero() {
+		to = from.AddDate(0, 0, models.DefaultCalendarDays)
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w, to must be after from", ErrInvalidSlot)
+	}
+
+	slots, err := s.store.ListSlots(ctx, equipmentID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	booked, err := s.store.ListHeldBookings(ctx, equipmentID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if equipment.OwnerID != userID {
+		for i := range booked {
+			booked[i] = models.Booking{ID: booked[i].ID, EquipmentID: equipmentID, StartsAt: booked[i].StartsAt, EndsAt: booked[i].EndsAt, Status: booked[i].Status}
+		}
+	}
+	return &models.Calendar{Equipment: *equipment, From: from, To: to, Availability: slots, Booked: booked}, nil
+}
+
+// ownEquipment loads the equipment if the user owns it, it does not exist for anyone else
+func (s *controller) ownEquipment(ctx context.Context, userID int64, equipmentID int64) (*models.Equipment, error) {
+	equipment, err := s.store.GetEquipment(ctx, equipmentID)
+	if err != nil {
+		return nil, err
+	}
+	if equipment.OwnerID != userID {
+		return nil, db.ErrEquipmentNotFound
+	}
+	return equipment, nil
+}
+
+// units is the number of started hours or days in the slot
+func units(from time.Time, to time.Time, unit string) int64 {
+	size := time.Hour
+	if unit == models.UnitDay {
+		size = 24 * time.Hour
+	}
+	return int64((to.Sub(from) + size - 1) / size)
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"context"
+	notification "kisaanSathi/pkg/services/notification/controller"
+	"kisaanSathi/pkg/services/rental/db"
+	"kisaanSathi/pkg/services/rental/models"
+	"time"
+)
+
+type controller struct {
+	store    db.Store
+	notifier notification.NotificationService
+	now      func() time.Time
+}
+
+type RentalController interface {
+	CreateEquipment(ctx context.Context, userID int64, request *models.EquipmentRequest) (*models.Equipment, error)
+	GetEquipment(ctx context.Context, equipmentID int64) (*models.Equipment, error)
+	ListOwnerEquipment(ctx context.Context, userID int64) ([]models.Equipment, error)
+	SetEquipmentActive(ctx context.Context, userID int64, equipmentID int64, request *models.ActiveRequest) (*models.Equipment, error)
+	SearchEquipment(ctx context.Context, userID int64, request *models.SearchRequest) ([]models.Equipment, error)
+	AddSlot(ctx context.Context, userID int64, equipmentID int64, request *models.SlotRequest) (*models.Slot, error)
+	DeleteSlot(ctx context.Context, userID int64, equipmentID int64, slotID int64) error
+	// GetCalendar returns the availability windows and held bookings of the equipment
+	GetCalendar(ctx context.Context, userID int64, equipmentID int64, request *models.CalendarRequest) (*models.Calendar, error)
+	BookingController
+}
+
+type BookingController interface {
+	Book(ctx context.Context, userID int64, equipmentID int64, request *models.BookingRequest) (*models.Booking, error)
+	GetBooking(ctx context.Context, userID int64, bookingID int64) (*models.Booking, error)
+	ListUserBookings(ctx context.Context, userID int64) ([]models.Booking, error)
+	// UpdateBookingStatus lets the owner confirm, reject or complete a booking and either side cancel it
+	UpdateBookingStatus(ctx context.Context, userID int64, bookingID int64, request *models.BookingStatusRequest) (*models.Booking, error)
+}
+
+func NewRentalController(store db.Store, notifier notification.NotificationService) RentalController {
+	return &controller{
+		store:    store,
+		notifier: notifier,
+		now:      time.Now,
+	}
+}
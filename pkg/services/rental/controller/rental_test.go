@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"context"
+	"kisaanSathi/pkg/logger"
+	notificationModels "kisaanSathi/pkg/services/notification/models"
+	"kisaanSathi/pkg/services/rental/db"
+	"kisaanSathi/pkg/services/rental/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type sent struct {
+	userID  int64
+	message string
+}
+
+type fakeNotifier struct {
+	sent []sent
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, userID int64, notificationType string, message string) (*notificationModels.Notification, error) {
+	return f.NotifyTx(ctx, nil, userID, notificationType, message)
+}
+
+func (f *fakeNotifier) NotifyTx(ctx context.Context, tx *gorm.DB, userID int64, notificationType string, message string) (*notificationModels.Notification, error) {
+	f.sent = append(f.sent, sent{userID: userID, message: message})
+	return &notificationModels.Notification{UserID: userID, Message: message, Type: notificationType}, nil
+}
+
+// fakeStore keeps equipment and bookings in memory and calls notify like the postgres store
+type fakeStore struct {
+	db.Store
+	users     map[int64]*models.User
+	equipment map[int64]*models.Equipment
+	bookings  map[int64]*models.Booking
+	filter    models.SearchFilter
+}
+
+func (f *fakeStore) GetUser(ctx context.Context, userID int64) (*models.User, error) {
+	if user, ok := f.users[userID]; ok {
+		return user, nil
+	}
+	return nil, db.ErrUserNotFound
+}
+
+func (f *fakeStore) GetEquipment(ctx context.Context, equipmentID int64) (*models.Equipment, error) {
+	if equipment, ok := f.equipment[equipmentID]; ok {
+		copied := *equipment
+		return &copied, nil
+	}
+	return nil, db.ErrEquipmentNotFound
+}
+
+func (f *fakeStore) SearchEquipment(ctx context.Context, filter models.SearchFilter) ([]models.Equipment, error) {
+	f.filter = filter
+	return []models.Equipment{}, nil
+}
+
+func (f *fakeStore) CreateBooking(ctx context.Context, booking *models.Booking, notify db.Notify) error {
+	for _, held := range f.bookings {
+		if held.EquipmentID == booking.EquipmentID && held.StartsAt.Before(booking.EndsAt) && held.EndsAt.After(booking.StartsAt) {
+			return db.ErrSlotTaken
+		}
+	}
+	booking.ID, booking.Status = int64(len(f.bookings)+1), models.BookingPending
+	booking.EquipmentName, booking.OwnerID = f.equipment[booking.EquipmentID].Name, f.equipment[booking.EquipmentID].OwnerID
+	f.bookings[booking.ID] = booking
+	return notify(nil, booking)
+}
+
+func (f *fakeStore) GetBooking(ctx context.Context, bookingID int64) (*models.Booking, error) {
+	if booking, ok := f.bookings[bookingID]; ok {
+		copied := *booking
+		return &copied, nil
+	}
+	return nil, db.ErrBookingNotFound
+}
+
+func (f *fakeStore) UpdateBookingStatus(ctx context.Context, bookingID int64, from string, status string, notify db.Notify) (*models.Booking, error) {
+	booking := f.bookings[bookingID]
+	booking.Status = status
+	return booking, notify(nil, booking)
+}
+
+func newTestController() (*controller, *fakeStore, *fakeNotifier) {
+	logger.LoggerInit("", -1)
+	lat, lng := 26.9371, 81.1895
+	hourly, daily := 900.0, 6000.0
+	store := &fakeStore{
+		users: map[int64]*models.User{
+			1: {ID: 1, Name: "Ravi Yadav", Lat: &lat, Lng: &lng},
+			4: {ID: 4, Name: "Mohan Traders"},
+		},
+		equipment: map[int64]*models.Equipment{
+			3: {ID: 3, OwnerID: 4, Kind: models.KindTractor, Name: "Mahindra 575", HourlyRate: &hourly, DailyRate: &daily, Active: true},
+		},
+		bookings: map[int64]*models.Booking{},
+	}
+	notifier := &fakeNotifier{}
+	c := NewRentalController(store, notifier).(*controller)
+	c.now = func() time.Time { return time.Date(2025, 4, 1, 4, 30, 0, 0, time.UTC) }
+	return c, store, notifier
+}
+
+func TestBook_ChargesStartedUnits(t *testing.T) {
+	c, _, notifier := newTestController()
+	starts := time.Date(2025, 4, 2, 2, 30, 0, 0, time.UTC)
+
+	booking, err := c.Book(context.TODO(), 1, 3, &models.BookingRequest{StartsAt: starts, EndsAt: starts.Add(150 * time.Minute)})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.UnitHour, booking.Unit)
+	assert.Equal(t, 2700.0, booking.Amount)
+	assert.Equal(t, []sent{
+		{userID: 4, message: "New booking request for Mahindra 575 from 02 Apr 08:00 to 02 Apr 10:30, Rs. 2700"},
+		{userID: 1, message: "Your booking request for Mahindra 575 from 02 Apr 08:00 to 02 Apr 10:30 is sent to the owner"},
+	}, notifier.sent)
+
+	booking, err = c.Book(context.TODO(), 1, 3, &models.BookingRequest{StartsAt: starts.AddDate(0, 0, 2), EndsAt: starts.AddDate(0, 0, 3).Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Equal(t, models.UnitDay, booking.Unit)
+	assert.Equal(t, 12000.0, booking.Amount)
+}
+
+func TestBook_Rules(t *testing.T) {
+	c, store, _ := newTestController()
+	starts := time.Date(2025, 4, 2, 2, 30, 0, 0, time.UTC)
+
+	_, err := c.Book(context.TODO(), 4, 3, &models.BookingRequest{StartsAt: starts, EndsAt: starts.Add(2 * time.Hour)})
+	assert.ErrorIs(t, err, ErrOwnEquipment)
+
+	_, err = c.Book(context.TODO(), 1, 3, &models.BookingRequest{StartsAt: c.now().Add(-time.Hour), EndsAt: starts})
+	assert.ErrorIs(t, err, ErrInvalidSlot)
+
+	_, err = c.Book(context.TODO(), 1, 3, &models.BookingRequest{StartsAt: starts, EndsAt: starts.Add(30 * time.Minute)})
+	assert.ErrorIs(t, err, ErrInvalidSlot)
+
+	store.equipment[3].HourlyRate = nil
+	_, err = c.Book(context.TODO(), 1, 3, &models.BookingRequest{StartsAt: starts, EndsAt: starts.Add(2 * time.Hour), Unit: models.UnitHour})
+	assert.ErrorIs(t, err, ErrNoRate)
+
+	_, err = c.Book(context.TODO(), 1, 3, &models.BookingRequest{StartsAt: starts, EndsAt: starts.Add(2 * time.Hour)})
+	assert.NoError(t, err)
+	_, err = c.Book(context.TODO(), 1, 3, &models.BookingRequest{StartsAt: starts.Add(time.Hour), EndsAt: starts.Add(3 * time.Hour)})
+	assert.ErrorIs(t, err, db.ErrSlotTaken)
+}
+
+func TestUpdateBookingStatus_Lifecycle(t *testing.T) {
+	c, store, notifier := newTestController()
+	starts := time.Date(2025, 4, 2, 2, 30, 0, 0, time.UTC)
+	_, err := c.Book(context.TODO(), 1, 3, &models.BookingRequest{StartsAt: starts, EndsAt: starts.Add(2 * time.Hour)})
+	assert.NoError(t, err)
+	notifier.sent = nil
+
+	// only the owner confirms, and nobody but the two sides sees the booking
+	_, err = c.UpdateBookingStatus(context.TODO(), 1, 1, &models.BookingStatusRequest{Status: models.BookingConfirmed})
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+	_, err = c.UpdateBookingStatus(context.TODO(), 2, 1, &models.BookingStatusRequest{Status: models.BookingCancelled})
+	assert.ErrorIs(t, err, db.ErrBookingNotFound)
+
+	booking, err := c.UpdateBookingStatus(context.TODO(), 4, 1, &models.BookingStatusRequest{Status: models.BookingConfirmed})
+	assert.NoError(t, err)
+	assert.Equal(t, models.BookingConfirmed, booking.Status)
+
+	_, err = c.UpdateBookingStatus(context.TODO(), 4, 1, &models.BookingStatusRequest{Status: models.BookingCompleted})
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+
+	_, err = c.UpdateBookingStatus(context.TODO(), 1, 1, &models.BookingStatusRequest{Status: models.BookingCancelled})
+	assert.NoError(t, err)
+	assert.Equal(t, models.BookingCancelled, store.bookings[1].Status)
+	assert.Equal(t, []sent{
+		{userID: 4, message: "You confirmed the booking of Mahindra 575 from 02 Apr 08:00 to 02 Apr 10:00"},
+		{userID: 1, message: "Your booking of Mahindra 575 from 02 Apr 08:00 to 02 Apr 10:00 is confirmed, Rs. 1800"},
+		{userID: 4, message: "The booking of Mahindra 575 from 02 Apr 08:00 to 02 Apr 10:00 was cancelled"},
+		{userID: 1, message: "You cancelled the booking of Mahindra 575 from 02 Apr 08:00 to 02 Apr 10:00"},
+	}, notifier.sent)
+}
+
+func TestSearchEquipment_DefaultsToTheUsersLocation(t *testing.T) {
+	c, store, _ := newTestController()
+
+	_, err := c.SearchEquipment(context.TODO(), 1, &models.SearchRequest{Kind: models.KindTractor})
+	assert.NoError(t, err)
+	assert.Equal(t, models.SearchFilter{Kind: models.KindTractor, Lat: 26.9371, Lng: 81.1895, RadiusKm: models.DefaultRadiusKm, Limit: models.DefaultPageSize}, store.filter)
+
+	_, err = c.SearchEquipment(context.TODO(), 4, &models.SearchRequest{})
+	assert.ErrorIs(t, err, ErrNoLocation)
+}
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/rental/models"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const bookingColumns = `b.id, b.equipment_id, e.name AS equipment_name, e.owner_id, b.farmer_id,
+		COALESCE(u.name, '') AS farmer_name, b.starts_at, b.ends_at, b.unit, b.rate, b.amount, b.status,
+		COALESCE(b.note, '') AS note, b.created_at, b.updated_at`
+
+const bookingTables = `kisan.equipment_bookings b
+		JOIN kisan.equipment e ON e.id = b.equipment_id
+		LEFT JOIN kisan.users u ON u.id = b.farmer_id`
+
+var (
+	ErrBookingNotFound = errors.New("booking not found")
+	// ErrEquipmentInactive is returned when booking equipment the owner has withdrawn
+	ErrEquipmentInactive = errors.New("equipment is not available for rent")
+	// ErrNotAvailable is returned when the slot is not inside one of the owner's availability windows
+	ErrNotAvailable = errors.New("equipment is not offered for the whole slot")
+	// ErrSlotTaken is returned when the slot overlaps another booking holding the equipment
+	ErrSlotTaken = errors.New("equipment is already booked for part of the slot")
+	// ErrStatusChanged is returned when the booking left the expected state in the meantime
+	ErrStatusChanged = errors.New("booking status has changed")
+)
+
+func (g *rentalStore) CreateBooking(c context.Context, booking *models.Booking, notify Notify) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	err := repo.Transaction(g.store.WithContext(c), func(tx *gorm.DB) error {
+		equipment, err := getEquipment(tx, booking.EquipmentID, true)
+		if err != nil {
+			return err
+		}
+		if !equipment.Active {
+			return ErrEquipmentInactive
+		}
+
+		var covering int64
+		err = tx.Raw(`SELECT count(*) FROM kisan.equipment_slots
+			WHERE equipment_id = ? AND starts_at <= ? AND ends_at >= ?`,
+			booking.EquipmentID, booking.StartsAt, booking.EndsAt).Row().Scan(&covering)
+		if err != nil {
+			return err
+		}
+		if covering == 0 {
+			return ErrNotAvailable
+		}
+		var overlapping int64
+		err = tx.Raw(`SELECT count(*) FROM kisan.equipment_bookings
+			WHERE equipment_id = ? AND status IN ? AND starts_at < ? AND ends_at > ?`,
+			booking.EquipmentID, models.HoldingStatuses, booking.EndsAt, booking.StartsAt).Row().Scan(&overlapping)
+		if err != nil {
+			return err
+		}
+		if overlapping > 0 {
+			return ErrSlotTaken
+		}
+
+		err = tx.Raw(`INSERT INTO kisan.equipment_bookings (equipment_id, farmer_id, starts_at, ends_at, unit, rate, amount, note)
+			VALUES (?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''))
+			RETURNING id, status, created_at, updated_at`,
+			booking.EquipmentID, booking.FarmerID, booking.StartsAt, booking.EndsAt, booking.Unit, booking.Rate,
+			booking.Amount, booking.Note).
+			Row().Scan(&booking.ID, &booking.Status, &booking.CreatedAt, &booking.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		booking.EquipmentName, booking.OwnerID = equipment.Name, equipment.OwnerID
+		return notify(tx, booking)
+	})
+	if err != nil && !isBookingError(err) {
+		logger.Log(c).Error("Error inserting booking", zap.Error(err))
+	}
+	return err
+}
+
+func (g *rentalStore) GetBooking(c context.Context, bookingID int64) (*models.Booking, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	return getBooking(g.store.WithContext(c), bookingID, false)
+}
+
+func (g *rentalStore) ListUserBookings(c context.Context, userID int64) ([]models.Booking, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	bookings := []models.Booking{}
+	err := g.store.WithContext(c).Raw(`SELECT `+bookingColumns+`
+		FROM `+bookingTables+`
+		WHERE b.farmer_id = ? OR e.owner_id = ?
+		ORDER BY b.starts_at DESC, b.id DESC
+		LIMIT 100`, userID, userID).Scan(&bookings).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return bookings, nil
+}
+
+func (g *rentalStore) ListHeldBookings(c context.Context, equipmentID int64, from time.Time, to time.Time) ([]models.Booking, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	bookings := []models.Booking{}
+	err := g.store.WithContext(c).Raw(`SELECT `+bookingColumns+`
+		FROM `+bookingTables+`
+		WHERE b.equipment_id = ? AND b.status IN ? AND b.starts_at < ? AND b.ends_at > ?
+		ORDER BY b.starts_at, b.id`, equipmentID, models.HoldingStatuses, to, from).Scan(&bookings).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return bookings, nil
+}
+
+func (g *rentalStore) UpdateBookingStatus(c context.Context, bookingID int64, from string, status string, notify Notify) (*models.Booking, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var booking *models.Booking
+	err := repo.Transaction(g.store.WithContext(c), func(tx *gorm.DB) error {
+		var err error
+		if booking, err = getBooking(tx, bookingID, true); err != nil {
+			return err
+		}
+		if booking.Status != from {
+			return ErrStatusChanged
+		}
+		if err := tx.Exec(`UPDATE kisan.equipment_bookings SET status = ?, updated_at = now() WHERE id = ?`, status, bookingID).Error; err != nil {
+			return err
+		}
+		booking.Status = status
+		return notify(tx, booking)
+	})
+	if err != nil {
+		if !isBookingError(err) {
+			logger.Log(c).Error("Error updating booking status", zap.Error(err))
+		}
+		return nil, err
+	}
+	return booking, nil
+}
+
+// getBooking reads a booking, locking its row for the transaction when lock is set
+func getBooking(tx *gorm.DB, bookingID int64, lock bool) (*models.Booking, error) {
+	query := `SELECT ` + bookingColumns + `
+		FROM ` + bookingTables + `
+		WHERE b.id = ?`
+	if lock {
+		query += ` FOR UPDATE OF b`
+	}
+	var bookings []models.Booking
+	if err := tx.Raw(query, bookingID).Scan(&bookings).Error; err != nil {
+		return nil, err
+	}
+	if len(bookings) == 0 {
+		return nil, ErrBookingNotFound
+	}
+	return &bookings[0], nil
+}
+
+// isBookingError reports whether err is an expected outcome rather than a failure worth logging
+func isBookingError(err error) bool {
+	for _, expected := range []error{ErrEquipmentNotFound, ErrBookingNotFound, ErrEquipmentInactive, ErrNotAvailable, ErrSlotTaken, ErrStatusChanged} {
+		if errors.Is(err, expected) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/rental/models"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const equipmentColumns = `e.id, e.owner_id, COALESCE(u.name, '') AS owner_name, e.kind, e.name,
+		COALESCE(e.description, '') AS description, e.hourly_rate, e.daily_rate, e.address, e.lat, e.lng,
+		e.active, e.created_at, e.updated_at`
+
+// distanceKm is the haversine distance in km of the equipment from the point bound to its three parameters (lat, lat, lng)
+const distanceKm = `6371 * 2 * asin(least(1, sqrt(power(sin(radians(e.lat - ?) / 2), 2)
+		+ cos(radians(?)) * cos(radians(e.lat)) * power(sin(radians(e.lng - ?) / 2), 2))))`
+
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrEquipmentNotFound = errors.New("equipment not found")
+	ErrSlotNotFound      = errors.New("availability slot not found")
+)
+
+func (g *rentalStore) GetUser(c context.Context, userID int64) (*models.User, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var users []models.User
+	err := g.store.WithContext(c).Raw(`SELECT id, COALESCE(name, '') AS name, lat, lng
+		FROM kisan.users WHERE id = ?`, userID).Scan(&users).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, ErrUserNotFound
+	}
+	return &users[0], nil
+}
+
+func (g *rentalStore) CreateEquipment(c context.Context, equipment *models.Equipment) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	err := g.store.WithContext(c).Raw(`INSERT INTO kisan.equipment (owner_id, kind, name, description, hourly_rate,
+			daily_rate, address, lat, lng)
+		VALUES (?, ?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?)
+		RETURNING id, active, created_at, updated_at`,
+		equipment.OwnerID, equipment.Kind, equipment.Name, equipment.Description, equipment.HourlyRate,
+		equipment.DailyRate, equipment.Address, equipment.Lat, equipment.Lng).
+		Row().Scan(&equipment.ID, &equipment.Active, &equipment.CreatedAt, &equipment.UpdatedAt)
+	if err != nil {
+		logger.Log(c).Error("Error inserting equipment", zap.Error(err))
+	}
+	return err
+}
+
+func (g *rentalStore) GetEquipment(c context.Context, equipmentID int64) (*models.Equipment, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	return getEquipment(g.store.WithContext(c), equipmentID, false)
+}
+
+func (g *rentalStore) ListOwnerEquipment(c context.Context, ownerID int64) ([]models.Equipment, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	equipment := []models.Equipment{}
+	err := g.store.WithContext(c).Raw(`SELECT `+equipmentColumns+`
+		FROM kisan.equipment e
+		LEFT JOIN kisan.users u ON u.id = e.owner_id
+		WHERE e.owner_id = ?
+		ORDER BY e.created_at DESC, e.id DESC`, ownerID).Scan(&equipment).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return equipment, nil
+}
+
+func (g *rentalStore) SetEquipmentActive(c context.Context, equipmentID int64, active bool) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	result := g.store.WithContext(c).Exec(`UPDATE kisan.equipment SET active = ?, updated_at = now() WHERE id = ?`, active, equipmentID)
+	if result.Error != nil {
+		logger.Log(c).Error("Error updating equipment", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEquipmentNotFound
+	}
+	return nil
+}
+
+func (g *rentalStore) SearchEquipment(c context.Context, filter models.SearchFilter) ([]models.Equipment, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	query := `SELECT * FROM (SELECT ` + equipmentColumns + `, ` + distanceKm + ` AS distance_km
+			FROM kisan.equipment e
+			LEFT JOIN kisan.users u ON u.id = e.owner_id
+			WHERE e.active AND (? = '' OR e.kind = ?)`
+	args := []interface{}{filter.Lat, filter.Lat, filter.Lng, filter.Kind, filter.Kind}
+	if !filter.From.IsZero() {
+		query += `
+				AND EXISTS (SELECT 1 FROM kisan.equipment_slots s
+					WHERE s.equipment_id = e.id AND s.starts_at <= ? AND s.ends_at >= ?)
+				AND NOT EXISTS (SELECT 1 FROM kisan.equipment_bookings b
+					WHERE b.equipment_id = e.id AND b.status IN ? AND b.starts_at < ? AND b.ends_at > ?)`
+		args = append(args, filter.From, filter.To, models.HoldingStatuses, filter.To, filter.From)
+	}
+	query += `) equipment
+		WHERE distance_km <= ?
+		ORDER BY distance_km, id
+		LIMIT ? OFFSET ?`
+	args = append(args, filter.RadiusKm, filter.Limit, filter.Offset)
+
+	equipment := []models.Equipment{}
+	if err := g.store.WithContext(c).Raw(query, args...).Scan(&equipment).Error; err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return equipment, nil
+}
+
+func (g *rentalStore) AddSlot(c context.Context, slot *models.Slot) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	err := g.store.WithContext(c).Raw(`INSERT INTO kisan.equipment_slots (equipment_id, starts_at, ends_at)
+		VALUES (?, ?, ?) RETURNING id`, slot.EquipmentID, slot.StartsAt, slot.EndsAt).Row().Scan(&slot.ID)
+	if err != nil {
+		logger.Log(c).Error("Error inserting availability slot", zap.Error(err))
+	}
+	return err
+}
+
+// DeleteSlot withdraws an availability window, bookings already made in it are kept
+func (g *rentalStore) DeleteSlot(c context.Context, equipmentID int64, slotID int64) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	result := g.store.WithContext(c).Exec(`DELETE FROM kisan.equipment_slots WHERE id = ? AND equipment_id = ?`, slotID, equipmentID)
+	if result.Error != nil {
+		logger.Log(c).Error("Error deleting availability slot", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSlotNotFound
+	}
+	return nil
+}
+
+func (g *rentalStore) ListSlots(c context.Context, equipmentID int64, from time.Time, to time.Time) ([]models.Slot, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	slots := []models.Slot{}
+	err := g.store.WithContext(c).Raw(`SELECT id, equipment_id, starts_at, ends_at
+		FROM kisan.equipment_slots
+		WHERE equipment_id = ? AND starts_at < ? AND ends_at > ?
+		ORDER BY starts_at, id`, equipmentID, to, from).Scan(&slots).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return slots, nil
+}
+
+// getEquipment reads equipment, locking its row for the transaction when lock is set
+func getEquipment(tx *gorm.DB, equipmentID int64, lock bool) (*models.Equipment, error) {
+	query := `SELECT ` + equipmentColumns + `
+		FROM kisan.equipment e
+		LEFT JOIN kisan.users u ON u.id = e.owner_id
+		WHERE e.id = ?`
+	if lock {
+		query += ` FOR UPDATE OF e`
+	}
+	var equipment []models.Equipment
+	if err := tx.Raw(query, equipmentID).Scan(&equipment).Error; err != nil {
+		return nil, err
+	}
+	if len(equipment) == 0 {
+		return nil, ErrEquipmentNotFound
+	}
+	return &equipment[0], nil
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"kisaanSathi/pkg/services/rental/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type rentalStore struct {
+	store *gorm.DB
+}
+
+// Notify writes the notifications of a booking change inside its transaction
+type Notify func(tx *gorm.DB, booking *models.Booking) error
+
+type Store interface {
+	GetUser(ctx context.Context, userID int64) (*models.User, error)
+	CreateEquipment(ctx context.Context, equipment *models.Equipment) error
+	GetEquipment(ctx context.Context, equipmentID int64) (*models.Equipment, error)
+	ListOwnerEquipment(ctx context.Context, ownerID int64) ([]models.Equipment, error)
+	// SetEquipmentActive withdraws the equipment from search and booking or lists it again
+	SetEquipmentActive(ctx context.Context, equipmentID int64, active bool) error
+	// SearchEquipment returns active equipment within the radius, nearest first
+	SearchEquipment(ctx context.Context, filter models.SearchFilter) ([]models.Equipment, error)
+	AddSlot(ctx context.Context, slot *models.Slot) error
+	DeleteSlot(ctx context.Context, equipmentID int64, slotID int64) error
+	// ListSlots returns the availability windows overlapping from..to, earliest first
+	ListSlots(ctx context.Context, equipmentID int64, from time.Time, to time.Time) ([]models.Slot, error)
+	BookingStore
+}
+
+// BookingStore keeps the bookings of equipment
+type BookingStore interface {
+	// CreateBooking inserts a pending booking if the slot lies in one availability window and
+	// overlaps no booking holding the equipment, with the equipment row locked so concurrent
+	// bookings of the same equipment are checked one after the other
+	CreateBooking(ctx context.Context, booking *models.Booking, notify Notify) error
+	GetBooking(ctx context.Context, bookingID int64) (*models.Booking, error)
+	// ListUserBookings returns the bookings the user made or received as owner, latest slot first
+	ListUserBookings(ctx context.Context, userID int64) ([]models.Booking, error)
+	// ListHeldBookings returns the bookings holding the equipment between from and to, earliest first
+	ListHeldBookings(ctx context.Context, equipmentID int64, from time.Time, to time.Time) ([]models.Booking, error)
+	// UpdateBookingStatus moves the booking to status if it is still in from
+	UpdateBookingStatus(ctx context.Context, bookingID int64, from string, status string, notify Notify) (*models.Booking, error)
+}
+
+func NewDBObject(db *gorm.DB) Store {
+	return &rentalStore{
+		store: db,
+	}
+}
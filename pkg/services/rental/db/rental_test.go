@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/rental/models"
+	"kisaanSathi/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type RentalSuite struct {
+	suite.Suite
+	ctx         context.Context
+	sqlDB       *sql.DB
+	gormDB      *gorm.DB
+	sqlMock     sqlmock.Sqlmock
+	rentalStore Store
+}
+
+var equipmentRowColumns = []string{"id", "owner_id", "owner_name", "kind", "name", "description", "hourly_rate", "daily_rate",
+	"address", "lat", "lng", "active", "created_at", "updated_at"}
+
+func TestRentalSuite(t *testing.T) {
+	suite.Run(t, new(RentalSuite))
+}
+
+func (suite *RentalSuite) SetupSuite() {
+	logger.LoggerInit("", -1)
+
+	suite.ctx = context.TODO()
+	suite.sqlDB, suite.gormDB, suite.sqlMock = utils.NewMockDB()
+	suite.rentalStore = NewDBObject(suite.gormDB)
+}
+
+func (suite *RentalSuite) TearDownTest() {
+	suite.NoError(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RentalSuite) expectLockedEquipment(active bool) {
+	rows := sqlmock.NewRows(equipmentRowColumns).
+		AddRow(3, 4, "Mohan Traders", "tractor", "Mahindra 575", "", 900, 6000, "Village Safdarganj", 26.93, 81.18, active, time.Now(), time.Now())
+	suite.sqlMock.ExpectBegin()
+	suite.sqlMock.ExpectQuery("^SELECT (.+) FROM kisan.equipment e (.+) WHERE e.id = (.+) FOR UPDATE OF e$").
+		WithArgs(3).
+		WillReturnRows(rows)
+}
+
+func (suite *RentalSuite) TestCreateBooking_Inserts() {
+	// Mocking and Setting Expected Result
+	starts := time.Date(2025, 4, 2, 2, 30, 0, 0, time.UTC)
+	booking := &models.Booking{EquipmentID: 3, FarmerID: 1, StartsAt: starts, EndsAt: starts.Add(2 * time.Hour), Unit: models.UnitHour, Rate: 900, Amount: 1800}
+	suite.expectLockedEquipment(true)
+	suite.sqlMock.ExpectQuery("^SELECT count(.+) FROM kisan.equipment_slots (.+)$").
+		WithArgs(3, booking.StartsAt, booking.EndsAt).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	suite.sqlMock.ExpectQuery("^SELECT count(.+) FROM kisan.equipment_bookings (.+) AND status IN (.+)$").
+		WithArgs(3, models.BookingPending, models.BookingConfirmed, booking.EndsAt, booking.StartsAt).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.sqlMock.ExpectQuery("^INSERT INTO kisan.equipment_bookings (.+) RETURNING (.+)$").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at", "updated_at"}).AddRow(11, "pending", time.Now(), time.Now()))
+	suite.sqlMock.ExpectCommit()
+
+	// Triggering Function
+	var notified *models.Booking
+	err := suite.rentalStore.CreateBooking(suite.ctx, booking, func(tx *gorm.DB, booking *models.Booking) error {
+		notified = booking
+		return nil
+	})
+
+	// Validations
+	suite.NoError(err)
+	suite.Equal(int64(11), notified.ID)
+	suite.Equal(int64(4), notified.OwnerID)
+	suite.Equal("Mahindra 575", notified.EquipmentName)
+}
+
+func (suite *RentalSuite) TestCreateBooking_SlotTaken() {
+	// Mocking and Setting Expected Result
+	starts := time.Date(2025, 4, 2, 2, 30, 0, 0, time.UTC)
+	booking := &models.Booking{EquipmentID: 3, FarmerID: 1, StartsAt: starts, EndsAt: starts.Add(2 * time.Hour)}
+	suite.expectLockedEquipment(true)
+	suite.sqlMock.ExpectQuery("^SELECT count(.+) FROM kisan.equipment_slots (.+)$").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	suite.sqlMock.ExpectQuery("^SELECT count(.+) FROM kisan.equipment_bookings (.+)$").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	suite.sqlMock.ExpectRollback()
+
+	// Triggering Function
+	err := suite.rentalStore.CreateBooking(suite.ctx, booking, func(tx *gorm.DB, booking *models.Booking) error { return nil })
+
+	// Validations
+	suite.ErrorIs(err, ErrSlotTaken)
+}
+
+func (suite *RentalSuite) TestCreateBooking_OutsideAvailability() {
+	// Mocking and Setting Expected Result
+	starts := time.Date(2025, 4, 2, 2, 30, 0, 0, time.UTC)
+	booking := &models.Booking{EquipmentID: 3, FarmerID: 1, StartsAt: starts, EndsAt: starts.Add(2 * time.Hour)}
+	suite.expectLockedEquipment(true)
+	suite.sqlMock.ExpectQuery("^SELECT count(.+) FROM kisan.equipment_slots (.+)$").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.sqlMock.ExpectRollback()
+
+	// Triggering Function
+	err := suite.rentalStore.CreateBooking(suite.ctx, booking, func(tx *gorm.DB, booking *models.Booking) error { return nil })
+
+	// Validations
+	suite.ErrorIs(err, ErrNotAvailable)
+}
+
+func (suite *RentalSuite) TestCreateBooking_Inactive() {
+	// Mocking and Setting Expected Result
+	suite.expectLockedEquipment(false)
+	suite.sqlMock.ExpectRollback()
+
+	// Triggering Function
+	err := suite.rentalStore.CreateBooking(suite.ctx, &models.Booking{EquipmentID: 3}, func(tx *gorm.DB, booking *models.Booking) error { return nil })
+
+	// Validations
+	suite.ErrorIs(err, ErrEquipmentInactive)
+}
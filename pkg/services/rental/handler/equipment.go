@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/network"
+	"kisaanSathi/pkg/services/rental/controller"
+	"kisaanSathi/pkg/services/rental/db"
+	"kisaanSathi/pkg/services/rental/models"
+	"kisaanSathi/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func (f *handler) SearchEquipment(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	var request models.SearchRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.SearchEquipment(c, userID, &request)
+	if err != nil {
+		rentalError(c, err, request, network.ApiErrors.GetDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) ListMyEquipment(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+
+	data, err := f.controller.ListOwnerEquipment(c, userID)
+	if err != nil {
+		rentalError(c, err, nil, network.ApiErrors.GetDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) GetEquipment(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	_, equipmentID, ok := idParams(c)
+	if !ok {
+		return
+	}
+
+	data, err := f.controller.GetEquipment(c, equipmentID)
+	if err != nil {
+		rentalError(c, err, nil, network.ApiErrors.GetDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) CreateEquipment(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	var request models.EquipmentRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.CreateEquipment(c, userID, &request)
+	if err != nil {
+		rentalError(c, err, request, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, network.SuccessResponse(data))
+}
+
+func (f *handler) SetEquipmentActive(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, equipmentID, ok := idParams(c)
+	if !ok {
+		return
+	}
+	var request models.ActiveRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.SetEquipmentActive(c, userID, equipmentID, &request)
+	if err != nil {
+		rentalError(c, err, request, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) GetEquipmentCalendar(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, equipmentID, ok := idParams(c)
+	if !ok {
+		return
+	}
+	var request models.CalendarRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.GetCalendar(c, userID, equipmentID, &request)
+	if err != nil {
+		rentalError(c, err, request, network.ApiErrors.GetDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) AddEquipmentSlot(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, equipmentID, ok := idParams(c)
+	if !ok {
+		return
+	}
+	var request models.SlotRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.AddSlot(c, userID, equipmentID, &request)
+	if err != nil {
+		rentalError(c, err, request, network.ApiErrors.AddDBError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, network.SuccessResponse(data))
+}
+
+func (f *handler) DeleteEquipmentSlot(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, equipmentID, ok := idParams(c)
+	if !ok {
+		return
+	}
+	slotID, err := utils.GetInt64Param(c, "slotId")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, nil))
+		c.Abort()
+		return
+	}
+
+	if err := f.controller.DeleteSlot(c, userID, equipmentID, slotID); err != nil {
+		rentalError(c, err, nil, network.ApiErrors.DelDBError)
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse("slot deleted"))
+}
+
+func requireUser(c *gin.Context) (int64, bool) {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, network.FailureResponse(network.ApiErrors.Unauthorized.WithErrorDescription(err.Error())))
+		c.Abort()
+		return 0, false
+	}
+	return userID, true
+}
+
+func idParams(c *gin.Context) (int64, int64, bool) {
+	userID, ok := requireUser(c)
+	if !ok {
+		return 0, 0, false
+	}
+	id, err := utils.GetInt64Param(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, nil))
+		c.Abort()
+		return 0, 0, false
+	}
+	return userID, id, true
+}
+
+// rentalError maps the rental errors to their status, anything else is a dbError
+func rentalError(c *gin.Context, err error, request interface{}, dbError *network.Error) {
+	logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+	switch {
+	case errors.Is(err, db.ErrEquipmentNotFound) || errors.Is(err, db.ErrBookingNotFound) ||
+		errors.Is(err, db.ErrSlotNotFound) || errors.Is(err, db.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, network.FailureResponse(network.ApiErrors.NoDataFound.WithErrorDescription(err.Error())))
+	case errors.Is(err, controller.ErrNoLocation) || errors.Is(err, controller.ErrInvalidSlot) ||
+		errors.Is(err, controller.ErrOwnEquipment) || errors.Is(err, controller.ErrNoRate):
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+	case errors.Is(err, controller.ErrInvalidTransition) || errors.Is(err, db.ErrStatusChanged) ||
+		errors.Is(err, db.ErrEquipmentInactive) || errors.Is(err, db.ErrNotAvailable) || errors.Is(err, db.ErrSlotTaken):
+		c.JSON(http.StatusConflict, network.FailureResponse(network.ApiErrors.Conflict.WithErrorDescription(err.Error())))
+	default:
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(dbError.WithErrorDescription(err.Error())))
+	}
+	c.Abort()
+}
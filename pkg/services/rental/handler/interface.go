@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"kisaanSathi/pkg/repo"
+	notification "kisaanSathi/pkg/services/notification/handler"
+	"kisaanSathi/pkg/services/rental/controller"
+	"kisaanSathi/pkg/services/rental/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+type handler struct {
+	controller controller.RentalController
+}
+
+type RentalHandler interface {
+	SearchEquipment(c *gin.Context)
+	ListMyEquipment(c *gin.Context)
+	GetEquipment(c *gin.Context)
+	CreateEquipment(c *gin.Context)
+	SetEquipmentActive(c *gin.Context)
+	GetEquipmentCalendar(c *gin.Context)
+	AddEquipmentSlot(c *gin.Context)
+	DeleteEquipmentSlot(c *gin.Context)
+	BookEquipment(c *gin.Context)
+	ListMyBookings(c *gin.Context)
+	GetBooking(c *gin.Context)
+	UpdateBookingStatus(c *gin.Context)
+}
+
+func NewRentalHandler(controller controller.RentalController) RentalHandler {
+	return &handler{
+		controller: controller,
+	}
+}
+
+func RentalController(repo repo.DataObject) controller.RentalController {
+	store := db.NewDBObject(repo.Databases.PgDB)
+	return controller.NewRentalController(store, notification.NotificationService(repo))
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// booking states; pending and confirmed bookings hold their slot
+const (
+	BookingPending   = "pending"
+	BookingConfirmed = "confirmed"
+	BookingRejected  = "rejected"
+	BookingCancelled = "cancelled"
+	BookingCompleted = "completed"
+)
+
+// HoldingStatuses are the booking states that keep the slot from other farmers
+var HoldingStatuses = []string{BookingPending, BookingConfirmed}
+
+// OwnerTransitions are the moves the owner of the equipment may make from each state
+var OwnerTransitions = map[string][]string{
+	BookingPending:   {BookingConfirmed, BookingRejected, BookingCancelled},
+	BookingConfirmed: {BookingCompleted, BookingCancelled},
+}
+
+// FarmerTransitions are the moves the farmer who booked may make from each state
+var FarmerTransitions = map[string][]string{
+	BookingPending:   {BookingCancelled},
+	BookingConfirmed: {BookingCancelled},
+}
+
+const (
+	// MinBooking is the shortest slot that can be booked
+	MinBooking = time.Hour
+	// MaxBookingDays is the longest slot that can be booked
+	MaxBookingDays = 30
+)
+
+// Booking is a farmer's hold on a slot of equipment, Amount is Rate times the started units
+type Booking struct {
+	ID            int64     `json:"id" gorm:"column:id"`
+	EquipmentID   int64     `json:"equipmentId" gorm:"column:equipment_id"`
+	EquipmentName string    `json:"equipmentName,omitempty" gorm:"column:equipment_name"`
+	OwnerID       int64     `json:"ownerId,omitempty" gorm:"column:owner_id"`
+	FarmerID      int64     `json:"farmerId,omitempty" gorm:"column:farmer_id"`
+	FarmerName    string    `json:"farmerName,omitempty" gorm:"column:farmer_name"`
+	StartsAt      time.Time `json:"startsAt" gorm:"column:starts_at"`
+	EndsAt        time.Time `json:"endsAt" gorm:"column:ends_at"`
+	Unit          string    `json:"unit,omitempty" gorm:"column:unit"`
+	Rate          float64   `json:"rate,omitempty" gorm:"column:rate"`
+	Amount        float64   `json:"amount,omitempty" gorm:"column:amount"`
+	Status        string    `json:"status" gorm:"column:status"`
+	Note          string    `json:"note,omitempty" gorm:"column:note"`
+	CreatedAt     time.Time `json:"createdAt,omitempty" gorm:"column:created_at"`
+	UpdatedAt     time.Time `json:"updatedAt,omitempty" gorm:"column:updated_at"`
+}
+
+// BookingRequest books the slot from StartsAt to EndsAt. Unit defaults to days for slots of a day
+// or more when the equipment has a daily rate, and to hours otherwise.
+type BookingRequest struct {
+	StartsAt time.Time `json:"startsAt" binding:"required"`
+	EndsAt   time.Time `json:"endsAt" binding:"required,gtfield=StartsAt"`
+	Unit     string    `json:"unit" binding:"omitempty,oneof=hour day"`
+	Note     string    `json:"note" binding:"omitempty,max=500"`
+}
+
+type BookingStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=confirmed rejected cancelled completed"`
+}
+
+// CanMove reports whether the booking may go from one state to another by the given transitions
+func CanMove(transitions map[string][]string, from string, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package models
+
+import "time"
+
+// kinds of equipment; labour crews are listed and booked like machines
+const (
+	KindTractor   = "tractor"
+	KindHarvester = "harvester"
+	KindSprayer   = "sprayer"
+	KindRotavator = "rotavator"
+	KindThresher  = "thresher"
+	KindSeedDrill = "seed_drill"
+	KindLabour    = "labour"
+)
+
+// units a booking is charged by
+const (
+	UnitHour = "hour"
+	UnitDay  = "day"
+)
+
+const (
+	DefaultRadiusKm = 25
+	DefaultPageSize = 20
+	// DefaultCalendarDays is the span of the calendar when the request does not give one
+	DefaultCalendarDays = 30
+)
+
+// Equipment is a machine or labour crew an owner rents out from lat/lng. At least one of the
+// rates is set.
+type Equipment struct {
+	ID          int64     `json:"id" gorm:"column:id"`
+	OwnerID     int64     `json:"ownerId" gorm:"column:owner_id"`
+	OwnerName   string    `json:"ownerName,omitempty" gorm:"column:owner_name"`
+	Kind        string    `json:"kind" gorm:"column:kind"`
+	Name        string    `json:"name" gorm:"column:name"`
+	Description string    `json:"description,omitempty" gorm:"column:description"`
+	HourlyRate  *float64  `json:"hourlyRate,omitempty" gorm:"column:hourly_rate"`
+	DailyRate   *float64  `json:"dailyRate,omitempty" gorm:"column:daily_rate"`
+	Address     string    `json:"address" gorm:"column:address"`
+	Lat         float64   `json:"lat" gorm:"column:lat"`
+	Lng         float64   `json:"lng" gorm:"column:lng"`
+	Active      bool      `json:"active" gorm:"column:active"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt   time.Time `json:"updatedAt" gorm:"column:updated_at"`
+	// DistanceKm from the farmer, set by searches with a location
+	DistanceKm *float64 `json:"distanceKm,omitempty" gorm:"column:distance_km"`
+}
+
+// EquipmentRequest places the equipment at lat/lng, or at the owner's location when they are omitted
+type EquipmentRequest struct {
+	Kind        string   `json:"kind" binding:"required,oneof=tractor harvester sprayer rotavator thresher seed_drill labour"`
+	Name        string   `json:"name" binding:"required,max=100"`
+	Description string   `json:"description" binding:"omitempty,max=500"`
+	HourlyRate  *float64 `json:"hourlyRate" binding:"required_without=DailyRate,omitempty,gt=0,lte=100000"`
+	DailyRate   *float64 `json:"dailyRate" binding:"required_without=HourlyRate,omitempty,gt=0,lte=1000000"`
+	Address     string   `json:"address" binding:"required,max=200"`
+	Lat         *float64 `json:"lat" binding:"required_with=Lng,omitempty,latitude"`
+	Lng         *float64 `json:"lng" binding:"required_with=Lat,omitempty,longitude"`
+}
+
+// ActiveRequest withdraws the equipment from rent or lists it again
+type ActiveRequest struct {
+	Active *bool `json:"active" binding:"required"`
+}
+
+// Slot is a window of time the owner offers the equipment for booking
+type Slot struct {
+	ID          int64     `json:"id" gorm:"column:id"`
+	EquipmentID int64     `json:"equipmentId" gorm:"column:equipment_id"`
+	StartsAt    time.Time `json:"startsAt" gorm:"column:starts_at"`
+	EndsAt      time.Time `json:"endsAt" gorm:"column:ends_at"`
+}
+
+type SlotRequest struct {
+	StartsAt time.Time `json:"startsAt" binding:"required"`
+	EndsAt   time.Time `json:"endsAt" binding:"required,gtfield=StartsAt"`
+}
+
+// CalendarRequest reads the calendar between from and to, the next DefaultCalendarDays by default
+type CalendarRequest struct {
+	From time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// Calendar shows when the equipment is offered and which of that time is taken. Only the owner
+// sees who booked.
+type Calendar struct {
+	Equipment    Equipment `json:"equipment"`
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	Availability []Slot    `json:"availability"`
+	Booked       []Booking `json:"booked"`
+}
+
+// SearchRequest finds active equipment within RadiusKm of lat/lng, or of the farmer's location
+// when they are omitted. With from and to only equipment free for that whole window is returned.
+type SearchRequest struct {
+	Kind     string    `form:"kind" binding:"omitempty,oneof=tractor harvester sprayer rotavator thresher seed_drill labour"`
+	Lat      *float64  `form:"lat" binding:"required_with=Lng,omitempty,latitude"`
+	Lng      *float64  `form:"lng" binding:"required_with=Lat,omitempty,longitude"`
+	RadiusKm float64   `form:"radiusKm" binding:"omitempty,gt=0,max=200"`
+	From     time.Time `form:"from" binding:"required_with=To" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" binding:"required_with=From" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit    int       `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset   int       `form:"offset" binding:"omitempty,min=0,max=10000"`
+}
+
+// SearchFilter is the resolved form of SearchRequest used by the store
+type SearchFilter struct {
+	Kind     string
+	Lat      float64
+	Lng      float64
+	RadiusKm float64
+	// From and To are zero when availability is not filtered
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Offset int
+}
+
+// User is the part of kisan.users the rental module needs
+type User struct {
+	ID   int64    `gorm:"column:id"`
+	Name string   `gorm:"column:name"`
+	Lat  *float64 `gorm:"column:lat"`
+	Lng  *float64 `gorm:"column:lng"`
+}
+
+// Rate is the equipment's rate for the unit, nil when it is not rented by that unit
+func (e *Equipment) Rate(unit string) *float64 {
+	if unit == UnitDay {
+		return e.DailyRate
+	}
+	return e.HourlyRate
+}
@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"context"
+	notification "kisaanSathi/pkg/services/notification/controller"
+	"kisaanSathi/pkg/services/scheme/db"
+	"kisaanSathi/pkg/services/scheme/models"
+	"time"
+)
+
+type controller struct {
+	schemeStore db.SchemeStore
+	notifier    notification.NotificationService
+}
+
+type SchemeController interface {
+	ListSchemes(ctx context.Context, userID int64, request *models.ListSchemesRequest) ([]models.SchemeResponse, error)
+	ListBookmarks(ctx context.Context, userID int64) ([]models.SchemeResponse, error)
+	Bookmark(ctx context.Context, userID int64, schemeID int64) error
+	RemoveBookmark(ctx context.Context, userID int64, schemeID int64) error
+	// SendDeadlineReminders writes scheme notifications for deadlines falling on one of
+	// models.ReminderOffsets days after now. It is safe to call repeatedly.
+	SendDeadlineReminders(ctx context.Context, now time.Time) (int, error)
+}
+
+func NewSchemeController(schemeStore db.SchemeStore, notifier notification.NotificationService) SchemeController {
+	return &controller{
+		schemeStore: schemeStore,
+		notifier:    notifier,
+	}
+}
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	notificationModels "kisaanSathi/pkg/services/notification/models"
+	"kisaanSathi/pkg/services/scheme/models"
+	"kisaanSathi/pkg/utils"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func (s *controller) ListSchemes(ctx context.Context, userID int64, request *models.ListSchemesRequest) ([]models.SchemeResponse, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	today := startOfDay(time.Now())
+	var openOn *time.Time
+	if request.OpenOnly {
+		openOn = &today
+	}
+	schemes, err := s.schemeStore.ListSchemes(ctx, userID, openOn)
+	if err != nil {
+		return nil, err
+	}
+	return toResponses(schemes, today), nil
+}
+
+func (s *controller) ListBookmarks(ctx context.Context, userID int64) ([]models.SchemeResponse, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	schemes, err := s.schemeStore.ListBookmarks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toResponses(schemes, startOfDay(time.Now())), nil
+}
+
+func (s *controller) Bookmark(ctx context.Context, userID int64, schemeID int64) error {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+	return s.schemeStore.AddBookmark(ctx, userID, schemeID)
+}
+
+func (s *controller) RemoveBookmark(ctx context.Context, userID int64, schemeID int64) error {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+	return s.schemeStore.RemoveBookmark(ctx, userID, schemeID)
+}
+
+func (s *controller) SendDeadlineReminders(ctx context.Context, now time.Time) (int, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	reminders, err := s.schemeStore.GetDueReminders(ctx, startOfDay(now), models.ReminderOffsets)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, reminder := range reminders {
+		ok, err := s.schemeStore.RecordReminder(ctx, reminder, func(tx *gorm.DB) error {
+			_, err := s.notifier.NotifyTx(ctx, tx, reminder.UserID, notificationModels.TypeScheme, reminderMessage(reminder))
+			return err
+		})
+		if err != nil {
+			// keep going, the failed reminder is picked up again on the next run
+			logger.Log(ctx).Error("failed to send scheme reminder", zap.Int64("schemeId", reminder.SchemeID), zap.Int64("userId", reminder.UserID), zap.Error(err))
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	logger.Log(ctx).Info("scheme deadline reminders sent", zap.Int("due", len(reminders)), zap.Int("sent", sent))
+	return sent, nil
+}
+
+func reminderMessage(reminder models.DueReminder) string {
+	closeDate := reminder.CloseDate.Format("02 Jan 2006")
+	if reminder.DaysBefore == 1 {
+		return fmt.Sprintf("Last day tomorrow: apply for %s before %s", reminder.Title, closeDate)
+	}
+	return fmt.Sprintf("%s closes in %d days on %s. Apply now", reminder.Title, reminder.DaysBefore, closeDate)
+}
+
+func toResponses(schemes []models.Scheme, today time.Time) []models.SchemeResponse {
+	return utils.Map(schemes, func(scheme models.Scheme) models.SchemeResponse {
+		response := models.SchemeResponse{
+			Scheme: scheme,
+			Tags:   utils.GetSliceFromStringBySeparator(scheme.Tags, ","),
+			IsOpen: isOpen(scheme, today),
+		}
+		if scheme.CloseDate != nil {
+			closeDate := dateIn(*scheme.CloseDate, today.Location())
+			if !closeDate.Before(today) {
+				daysLeft := int(closeDate.Sub(today).Hours() / 24)
+				response.DaysLeft = &daysLeft
+			}
+		}
+		return response
+	})
+}
+
+func isOpen(scheme models.Scheme, today time.Time) bool {
+	if scheme.OpenDate != nil && dateIn(*scheme.OpenDate, today.Location()).After(today) {
+		return false
+	}
+	if scheme.CloseDate != nil && dateIn(*scheme.CloseDate, today.Location()).Before(today) {
+		return false
+	}
+	return true
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// dateIn moves a DATE column value (scanned as UTC midnight) to the same calendar day in loc
+func dateIn(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,26 @@
+package db
+
+import (
+	"context"
+	"kisaanSathi/pkg/services/scheme/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type schemeStore struct {
+	store *gorm.DB
+}
+
+type SchemeStore interface {
+	ListSchemes(ctx context.Context, userID int64, openOn *time.Time) ([]models.Scheme, error)
+	ListBookmarks(ctx context.Context, userID int64) ([]models.Scheme, error)
+	AddBookmark(ctx context.Context, userID int64, schemeID int64) error
+	RemoveBookmark(ctx context.Context, userID int64, schemeID int64) error
+	GetDueReminders(ctx context.Context, day time.Time, offsets []int) ([]models.DueReminder, error)
+	RecordReminder(ctx context.Context, reminder models.DueReminder, notify func(tx *gorm.DB) error) (bool, error)
+}
+
+func NewDBObject(store *gorm.DB) SchemeStore {
+	return &schemeStore{store: store}
+}
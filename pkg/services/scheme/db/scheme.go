@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/scheme/models"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const schemeColumns = `s.id, s.title, s.description, s.eligibility, array_to_string(s.tags, ',') AS tags,
+		s.pdf_url, s.open_date, s.close_date`
+
+var ErrSchemeNotFound = errors.New("scheme not found")
+
+func (g *schemeStore) ListSchemes(c context.Context, userID int64, openOn *time.Time) ([]models.Scheme, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var schemes []models.Scheme
+	query := `SELECT ` + schemeColumns + `,
+			EXISTS (SELECT 1 FROM kisan.scheme_bookmarks b WHERE b.scheme_id = s.id AND b.user_id = ?) AS bookmarked
+		FROM kisan.govt_schemes s`
+	args := []interface{}{userID}
+	if openOn != nil {
+		query += ` WHERE (s.open_date IS NULL OR s.open_date <= ?) AND (s.close_date IS NULL OR s.close_date >= ?)`
+		args = append(args, *openOn, *openOn)
+	}
+	query += ` ORDER BY s.close_date NULLS LAST, s.id`
+
+	err := g.store.WithContext(c).Raw(query, args...).Scan(&schemes).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return schemes, nil
+}
+
+func (g *schemeStore) ListBookmarks(c context.Context, userID int64) ([]models.Scheme, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var schemes []models.Scheme
+	query := `SELECT ` + schemeColumns + `, TRUE AS bookmarked
+		FROM kisan.govt_schemes s
+		JOIN kisan.scheme_bookmarks b ON b.scheme_id = s.id
+		WHERE b.user_id = ?
+		ORDER BY s.close_date NULLS LAST, s.id`
+
+	err := g.store.WithContext(c).Raw(query, userID).Scan(&schemes).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return schemes, nil
+}
+
+func (g *schemeStore) AddBookmark(c context.Context, userID int64, schemeID int64) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	result := g.store.WithContext(c).Exec(`INSERT INTO kisan.scheme_bookmarks (user_id, scheme_id)
+		SELECT ?, s.id FROM kisan.govt_schemes s WHERE s.id = ?
+		ON CONFLICT (user_id, scheme_id) DO NOTHING`, userID, schemeID)
+	if result.Error != nil {
+		logger.Log(c).Error("Error adding bookmark", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		// either already bookmarked or the scheme does not exist
+		var count int64
+		if err := g.store.WithContext(c).Raw(`SELECT COUNT(1) FROM kisan.govt_schemes WHERE id = ?`, schemeID).Scan(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrSchemeNotFound
+		}
+	}
+	return nil
+}
+
+func (g *schemeStore) RemoveBookmark(c context.Context, userID int64, schemeID int64) error {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	err := g.store.WithContext(c).Exec(`DELETE FROM kisan.scheme_bookmarks WHERE user_id = ? AND scheme_id = ?`, userID, schemeID).Error
+	if err != nil {
+		logger.Log(c).Error("Error removing bookmark", zap.Error(err))
+	}
+	return err
+}
+
+// GetDueReminders returns every (scheme, user, offset) combination whose close date is
+// offset days after day and which has not been reminded yet. Users qualify when they
+// bookmarked the scheme or match its eligible roles and districts.
+func (g *schemeStore) GetDueReminders(c context.Context, day time.Time, offsets []int) ([]models.DueReminder, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var reminders []models.DueReminder
+	query := `SELECT s.id AS scheme_id, s.title, s.close_date, u.id AS user_id, d.days_before
+		FROM kisan.govt_schemes s
+		CROSS JOIN unnest(?::int[]) AS d(days_before)
+		JOIN kisan.users u ON (
+			EXISTS (SELECT 1 FROM kisan.scheme_bookmarks b WHERE b.scheme_id = s.id AND b.user_id = u.id)
+			OR (s.eligible_roles IS NOT NULL AND u.role = ANY(s.eligible_roles)
+				AND (s.eligible_districts IS NULL OR u.district = ANY(s.eligible_districts)))
+		)
+		WHERE s.close_date = ?::date + d.days_before
+		AND NOT EXISTS (
+			SELECT 1 FROM kisan.scheme_reminders r
+			WHERE r.scheme_id = s.id AND r.user_id = u.id AND r.days_before = d.days_before
+		)`
+
+	err := g.store.WithContext(c).Raw(query, repo.IntArray(offsets), day.Format("2006-01-02")).Scan(&reminders).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	return reminders, nil
+}
+
+// RecordReminder marks the reminder as sent and calls notify with the same transaction so the
+// notification is written atomically. It returns false without calling notify if the reminder
+// was already sent.
+func (g *schemeStore) RecordReminder(c context.Context, reminder models.DueReminder, notify func(tx *gorm.DB) error) (bool, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	sent := false
+	err := repo.Transaction(g.store.WithContext(c), func(tx *gorm.DB) error {
+		result := tx.Exec(`INSERT INTO kisan.scheme_reminders (scheme_id, user_id, days_before) VALUES (?, ?, ?)
+			ON CONFLICT (scheme_id, user_id, days_before) DO NOTHING`, reminder.SchemeID, reminder.UserID, reminder.DaysBefore)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := notify(tx); err != nil {
+			return err
+		}
+		sent = true
+		return nil
+	})
+	if err != nil {
+		logger.Log(c).Error("Scheme reminder - ", zap.String("Error", err.Error()))
+		return false, err
+	}
+	return sent, nil
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/scheme/models"
+	"kisaanSathi/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type SchemeSuite struct {
+	suite.Suite
+	ctx         context.Context
+	sqlDB       *sql.DB
+	gormDB      *gorm.DB
+	sqlMock     sqlmock.Sqlmock
+	schemeStore SchemeStore
+	reminder    models.DueReminder
+}
+
+func TestSchemeSuite(t *testing.T) {
+	suite.Run(t, new(SchemeSuite))
+}
+
+func (suite *SchemeSuite) SetupSuite() {
+	logger.LoggerInit("", -1)
+
+	suite.ctx = context.TODO()
+	suite.sqlDB, suite.gormDB, suite.sqlMock = utils.NewMockDB()
+	suite.schemeStore = NewDBObject(suite.gormDB)
+	suite.reminder = models.DueReminder{
+		SchemeID:   1,
+		Title:      "PM-Kisan Yojana",
+		CloseDate:  time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC),
+		UserID:     1,
+		DaysBefore: 7,
+	}
+}
+
+func (suite *SchemeSuite) TearDownTest() {
+	suite.NoError(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *SchemeSuite) TestRecordReminder_WritesNotification() {
+	// Mocking and Setting Expected Result
+	suite.sqlMock.ExpectBegin()
+	suite.sqlMock.
+		ExpectExec("^INSERT INTO kisan.scheme_reminders (.+) ON CONFLICT (.+) DO NOTHING$").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlMock.ExpectCommit()
+
+	// Triggering Function
+	notified := false
+	sent, err := suite.schemeStore.RecordReminder(suite.ctx, suite.reminder, func(tx *gorm.DB) error {
+		notified = true
+		return nil
+	})
+
+	// Validations
+	suite.NoError(err)
+	suite.True(sent)
+	suite.True(notified)
+}
+
+func (suite *SchemeSuite) TestRecordReminder_NotifyInTransaction() {
+	// Mocking and Setting Expected Result
+	suite.sqlMock.ExpectBegin()
+	suite.sqlMock.
+		ExpectExec("^INSERT INTO kisan.scheme_reminders (.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlMock.
+		ExpectExec("^INSERT INTO kisan.notifications (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlMock.ExpectCommit()
+
+	// Triggering Function
+	sent, err := suite.schemeStore.RecordReminder(suite.ctx, suite.reminder, func(tx *gorm.DB) error {
+		return tx.Exec(`INSERT INTO kisan.notifications (user_id, message, type) VALUES (?, ?, ?)`, 1, "reminder", "scheme").Error
+	})
+
+	// Validations
+	suite.NoError(err)
+	suite.True(sent)
+}
+
+func (suite *SchemeSuite) TestRecordReminder_AlreadySent() {
+	// Mocking and Setting Expected Result
+	suite.sqlMock.ExpectBegin()
+	suite.sqlMock.
+		ExpectExec("^INSERT INTO kisan.scheme_reminders (.+) ON CONFLICT (.+) DO NOTHING$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlMock.ExpectCommit()
+
+	// Triggering Function
+	sent, err := suite.schemeStore.RecordReminder(suite.ctx, suite.reminder, func(tx *gorm.DB) error {
+		suite.Fail("notify must not be called for a reminder already sent")
+		return nil
+	})
+
+	// Validations
+	suite.NoError(err)
+	suite.False(sent)
+}
+
+func (suite *SchemeSuite) TestRecordReminder_NotifyErrorRollsBack() {
+	// Mocking and Setting Expected Result
+	suite.sqlMock.ExpectBegin()
+	suite.sqlMock.
+		ExpectExec("^INSERT INTO kisan.scheme_reminders (.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlMock.ExpectRollback()
+
+	// Triggering Function
+	sent, err := suite.schemeStore.RecordReminder(suite.ctx, suite.reminder, func(tx *gorm.DB) error {
+		return errors.New("SQL Error")
+	})
+
+	// Validations
+	suite.EqualError(err, "SQL Error")
+	suite.False(sent)
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"kisaanSathi/pkg/repo"
+	notification "kisaanSathi/pkg/services/notification/handler"
+	"kisaanSathi/pkg/services/scheme/controller"
+	"kisaanSathi/pkg/services/scheme/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+type handler struct {
+	controller controller.SchemeController
+}
+
+type SchemeHandler interface {
+	ListSchemes(c *gin.Context)
+	ListSchemeBookmarks(c *gin.Context)
+	BookmarkScheme(c *gin.Context)
+	RemoveSchemeBookmark(c *gin.Context)
+}
+
+func NewSchemeHandler(controller controller.SchemeController) SchemeHandler {
+	return &handler{
+		controller: controller,
+	}
+}
+
+func SchemeController(repo repo.DataObject) controller.SchemeController {
+	store := db.NewDBObject(repo.Databases.PgDB)
+	return controller.NewSchemeController(store, notification.NotificationService(repo))
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/network"
+	"kisaanSathi/pkg/services/scheme/db"
+	"kisaanSathi/pkg/services/scheme/models"
+	"kisaanSathi/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func (f *handler) ListSchemes(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	var request models.ListSchemesRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+	// listing works for anonymous users, bookmarks are only flagged when the user is known
+	userID, _ := utils.GetUserID(c)
+
+	data, err := f.controller.ListSchemes(c, userID, &request)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.GetDBError.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) ListSchemeBookmarks(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, network.FailureResponse(network.ApiErrors.Unauthorized.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.ListBookmarks(c, userID)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.GetDBError.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) BookmarkScheme(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, schemeID, ok := bookmarkParams(c)
+	if !ok {
+		return
+	}
+
+	if err := f.controller.Bookmark(c, userID, schemeID); err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		if errors.Is(err, db.ErrSchemeNotFound) {
+			c.JSON(http.StatusNotFound, network.FailureResponse(network.ApiErrors.NoDataFound.WithErrorDescription(err.Error())))
+		} else {
+			c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.AddDBError.WithErrorDescription(err.Error())))
+		}
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse("scheme bookmarked"))
+}
+
+func (f *handler) RemoveSchemeBookmark(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	userID, schemeID, ok := bookmarkParams(c)
+	if !ok {
+		return
+	}
+
+	if err := f.controller.RemoveBookmark(c, userID, schemeID); err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.DelDBError.WithErrorDescription(err.Error())))
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse("bookmark removed"))
+}
+
+// bookmarkParams reads the user and scheme ids and writes the failure response if either is missing
+func bookmarkParams(c *gin.Context) (int64, int64, bool) {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, network.FailureResponse(network.ApiErrors.Unauthorized.WithErrorDescription(err.Error())))
+		c.Abort()
+		return 0, 0, false
+	}
+	schemeID, err := utils.GetInt64Param(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, nil))
+		c.Abort()
+		return 0, 0, false
+	}
+	return userID, schemeID, true
+}
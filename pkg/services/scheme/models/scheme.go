@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// ReminderOffsets are the number of days before a scheme closes on which
+// deadline reminders are sent
+var ReminderOffsets = []int{7, 3, 1}
+
+type Scheme struct {
+	ID          int64      `json:"id" gorm:"column:id"`
+	Title       string     `json:"title" gorm:"column:title"`
+	Description string     `json:"description" gorm:"column:description"`
+	Eligibility string     `json:"eligibility" gorm:"column:eligibility"`
+	Tags        string     `json:"-" gorm:"column:tags"`
+	PdfURL      string     `json:"pdfUrl" gorm:"column:pdf_url"`
+	OpenDate    *time.Time `json:"openDate,omitempty" gorm:"column:open_date"`
+	CloseDate   *time.Time `json:"closeDate,omitempty" gorm:"column:close_date"`
+	Bookmarked  bool       `json:"bookmarked" gorm:"column:bookmarked"`
+}
+
+// SchemeResponse is the api view of a scheme with its application window status
+type SchemeResponse struct {
+	Scheme
+	Tags     []string `json:"tags"`
+	IsOpen   bool     `json:"isOpen"`
+	DaysLeft *int     `json:"daysLeft,omitempty"`
+}
+
+type ListSchemesRequest struct {
+	OpenOnly bool `form:"openOnly"`
+}
+
+// DueReminder is a user who must be reminded about a scheme closing in DaysBefore days
+type DueReminder struct {
+	SchemeID   int64     `gorm:"column:scheme_id"`
+	Title      string    `gorm:"column:title"`
+	CloseDate  time.Time `gorm:"column:close_date"`
+	UserID     int64     `gorm:"column:user_id"`
+	DaysBefore int       `gorm:"column:days_before"`
+}
@@ -2,23 +2,33 @@ package controller
 
 import (
 	"context"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/notification/sms"
 	"kisaanSathi/pkg/services/user/db"
 	"kisaanSathi/pkg/services/user/models"
 )
 
 type controller struct {
 	registerStore db.RegisterStore
+	cache         repo.RedisInterface
+	gateway       sms.SMSGateway
 }
 
 type RegisterController interface {
 	Login(ctx context.Context, request *models.LoginRequest) (data []*models.LoginResponse, err error)
 	Logout(ctx context.Context, request *models.LogoutRequest) (data []*models.LoginResponse, err error)
 	Register(ctx context.Context, request *models.RegisterRequest) (data []*models.RegisterResponse, err error)
+	SendOTP(ctx context.Context, client string, request *models.OTPRequest) (*models.OTPResponse, error)
+	VerifyOTP(ctx context.Context, request *models.VerifyOTPRequest) (*models.VerifyOTPResponse, error)
+	GetProfile(ctx context.Context, userID int64) (*models.Profile, error)
+	UpdateProfile(ctx context.Context, userID int64, request *models.UpdateProfileRequest) (*models.Profile, error)
 	//RefreshToken(ctx context.Context, request *models.DtlsRequest) (data []*models.DtlsResponse, err error)
 }
 
-func NewRegisterController(registerStore db.RegisterStore) RegisterController {
+func NewRegisterController(registerStore db.RegisterStore, cache repo.RedisInterface, gateway sms.SMSGateway) RegisterController {
 	return &controller{
 		registerStore: registerStore,
+		cache:         cache,
+		gateway:       gateway,
 	}
 }
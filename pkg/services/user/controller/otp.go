@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/notification/sms"
+	"kisaanSathi/pkg/services/user/models"
+	"math/big"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	otpLength   = 6
+	otpValidity = 5 * time.Minute
+	// otpCooldown is how long a number has to wait before another otp is sent
+	otpCooldown = time.Minute
+	// otpMaxAttempts wrong codes burn the otp, a new one has to be sent
+	otpMaxAttempts = 5
+	// otpClientLimit otps are sent to a client in each otpClientWindow, whatever the numbers
+	otpClientLimit  = 10
+	otpClientWindow = time.Hour
+)
+
+var (
+	ErrOTPCooldown    = errors.New("otp already sent, try again later")
+	ErrOTPRateLimited = errors.New("too many otp requests, try again later")
+	ErrOTPInvalid     = errors.New("otp is invalid or expired")
+	ErrOTPAttempts    = errors.New("too many wrong otp attempts, request a new otp")
+	ErrSMSUnavailable = errors.New("sms gateway is not configured")
+)
+
+func otpKey(mobile string) string {
+	return "otp:" + mobile
+}
+
+func otpCooldownKey(mobile string) string {
+	return "otp:cooldown:" + mobile
+}
+
+func otpAttemptsKey(mobile string) string {
+	return "otp:attempts:" + mobile
+}
+
+// otpUsedKey claims a code once it verified, so that it verifies a single time
+func otpUsedKey(mobile string, code string) string {
+	return "otp:used:" + mobile + ":" + code
+}
+
+func otpClientKey(client string) string {
+	return "otp:client:" + client
+}
+
+// SendOTP generates a one time password, keeps it in redis and sends it by sms,
+// client is the address the request came from and limits how many numbers one client can reach
+func (s *controller) SendOTP(ctx context.Context, client string, request *models.OTPRequest) (*models.OTPResponse, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	if s.gateway == nil {
+		return nil, ErrSMSUnavailable
+	}
+	sent, err := s.cache.IncrWindow(ctx, otpClientKey(client), otpClientWindow)
+	if err != nil {
+		return nil, err
+	}
+	if sent > otpClientLimit {
+		return nil, ErrOTPRateLimited
+	}
+	code, err := generateOTP()
+	if err != nil {
+		return nil, err
+	}
+	text, err := sms.Render(request.Language, sms.TemplateOTP, sms.TemplateData{Code: code, ValidMinutes: int(otpValidity.Minutes())})
+	if err != nil {
+		return nil, err
+	}
+	// the cooldown is claimed before sending so that concurrent requests for a number send one otp,
+	// the claim holds the new code and a request that finds another code lost the race
+	if err := s.cache.SetValue(ctx, otpCooldownKey(request.Mobile), code, int(otpCooldown.Milliseconds()), true); err != nil {
+		return nil, err
+	}
+	var claim string
+	if err := s.cache.GetValue(ctx, otpCooldownKey(request.Mobile), &claim); err != nil || claim != code {
+		return nil, ErrOTPCooldown
+	}
+	release := func() {
+		if err := s.cache.DeleteKey(ctx, otpCooldownKey(request.Mobile)); err != nil {
+			logger.Log(ctx).Error("failed to release otp cooldown", zap.Error(err))
+		}
+	}
+
+	if err := s.cache.SetValue(ctx, otpKey(request.Mobile), code, int(otpValidity.Milliseconds()), false); err != nil {
+		release()
+		return nil, err
+	}
+	// the new code gets its own attempts
+	if err := s.cache.DeleteKey(ctx, otpAttemptsKey(request.Mobile)); err != nil {
+		logger.Log(ctx).Error("failed to reset otp attempts", zap.Error(err))
+	}
+	if _, err := s.gateway.Send(ctx, sms.Message{To: request.Mobile, Text: text}); err != nil {
+		if deleteErr := s.cache.DeleteKey(ctx, otpKey(request.Mobile)); deleteErr != nil {
+			logger.Log(ctx).Error("failed to discard unsent otp", zap.Error(deleteErr))
+		}
+		release()
+		return nil, err
+	}
+	return &models.OTPResponse{ExpiresIn: int(otpValidity.Seconds())}, nil
+}
+
+// VerifyOTP checks the code sent to the number, a code verifies once and
+// otpMaxAttempts wrong codes discard it
+func (s *controller) VerifyOTP(ctx context.Context, request *models.VerifyOTPRequest) (*models.VerifyOTPResponse, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	attempts, err := s.cache.IncrWindow(ctx, otpAttemptsKey(request.Mobile), otpValidity)
+	if err != nil {
+		return nil, err
+	}
+	if attempts > otpMaxAttempts {
+		if err := s.cache.DeleteKey(ctx, otpKey(request.Mobile)); err != nil {
+			logger.Log(ctx).Error("failed to discard otp", zap.Error(err))
+		}
+		return nil, ErrOTPAttempts
+	}
+
+	var code string
+	if err := s.cache.GetValue(ctx, otpKey(request.Mobile), &code); err != nil {
+		if repo.IsCacheMiss(err) {
+			return nil, ErrOTPInvalid
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(code), []byte(request.Code)) != 1 {
+		return nil, ErrOTPInvalid
+	}
+	// concurrent requests with the right code can all read it before it is deleted, only the first claim verifies
+	claimed, err := s.cache.TryLock(ctx, otpUsedKey(request.Mobile, code), code, otpValidity)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrOTPInvalid
+	}
+	for _, key := range []string{otpKey(request.Mobile), otpAttemptsKey(request.Mobile)} {
+		if err := s.cache.DeleteKey(ctx, key); err != nil {
+			logger.Log(ctx).Error("failed to discard verified otp", zap.String("key", key), zap.Error(err))
+		}
+	}
+	return &models.VerifyOTPResponse{Verified: true}, nil
+}
+
+func generateOTP() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < otpLength; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", otpLength, n), nil
+}
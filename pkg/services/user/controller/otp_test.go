@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/notification/sms"
+	"kisaanSathi/pkg/services/user/models"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMobile = "9800000001"
+
+func newOTPController() (*controller, repo.RedisInterface) {
+	cache := repo.NewMemoryCache()
+	return NewRegisterController(nil, cache, sms.NewMockGateway()).(*controller), cache
+}
+
+func sendOTP(t *testing.T, s *controller, cache repo.RedisInterface) string {
+	_, err := s.SendOTP(context.Background(), "10.0.0.1", &models.OTPRequest{Mobile: testMobile})
+	require.NoError(t, err)
+	var code string
+	require.NoError(t, cache.GetValue(context.Background(), otpKey(testMobile), &code))
+	return code
+}
+
+// wrongCode is a code of the right form which is not code
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "111111"
+	}
+	return "000000"
+}
+
+func TestVerifyOTP(t *testing.T) {
+	s, cache := newOTPController()
+	code := sendOTP(t, s, cache)
+
+	_, err := s.VerifyOTP(context.Background(), &models.VerifyOTPRequest{Mobile: testMobile, Code: wrongCode(code)})
+	assert.ErrorIs(t, err, ErrOTPInvalid)
+
+	data, err := s.VerifyOTP(context.Background(), &models.VerifyOTPRequest{Mobile: testMobile, Code: code})
+	require.NoError(t, err)
+	assert.True(t, data.Verified)
+	assert.False(t, cache.KeyExists(context.Background(), otpKey(testMobile)), "a verified code is deleted")
+
+	_, err = s.VerifyOTP(context.Background(), &models.VerifyOTPRequest{Mobile: testMobile, Code: code})
+	assert.ErrorIs(t, err, ErrOTPInvalid, "a code verifies once")
+}
+
+func TestVerifyOTP_AttemptsBurnTheCode(t *testing.T) {
+	s, cache := newOTPController()
+	code := sendOTP(t, s, cache)
+	wrong := wrongCode(code)
+
+	for i := 0; i < otpMaxAttempts; i++ {
+		_, err := s.VerifyOTP(context.Background(), &models.VerifyOTPRequest{Mobile: testMobile, Code: wrong})
+		assert.ErrorIs(t, err, ErrOTPInvalid)
+	}
+	_, err := s.VerifyOTP(context.Background(), &models.VerifyOTPRequest{Mobile: testMobile, Code: code})
+	assert.ErrorIs(t, err, ErrOTPAttempts, "the right code is refused after the last attempt")
+	assert.False(t, cache.KeyExists(context.Background(), otpKey(testMobile)), "the code is discarded")
+}
+
+func TestVerifyOTP_ConcurrentRightCodes(t *testing.T) {
+	s, cache := newOTPController()
+	code := sendOTP(t, s, cache)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		verified int
+	)
+	for i := 0; i < otpMaxAttempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.VerifyOTP(context.Background(), &models.VerifyOTPRequest{Mobile: testMobile, Code: code}); err == nil {
+				mu.Lock()
+				verified++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, verified)
+}
+
+func TestSendOTP_ClientLimit(t *testing.T) {
+	s, _ := newOTPController()
+
+	for i := 0; i < otpClientLimit; i++ {
+		_, err := s.SendOTP(context.Background(), "10.0.0.1", &models.OTPRequest{Mobile: fmt.Sprintf("98000000%02d", i)})
+		require.NoError(t, err)
+	}
+	_, err := s.SendOTP(context.Background(), "10.0.0.1", &models.OTPRequest{Mobile: "9811111111"})
+	assert.ErrorIs(t, err, ErrOTPRateLimited, "a client cannot reach any number of phones")
+
+	_, err = s.SendOTP(context.Background(), "10.0.0.2", &models.OTPRequest{Mobile: "9811111111"})
+	assert.NoError(t, err, "other clients keep their own limit")
+}
@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"context"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/services/user/models"
+)
+
+func (s *controller) GetProfile(ctx context.Context, userID int64) (*models.Profile, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+	return s.registerStore.GetProfile(ctx, userID)
+}
+
+func (s *controller) UpdateProfile(ctx context.Context, userID int64, request *models.UpdateProfileRequest) (*models.Profile, error) {
+	logger.Log(ctx).Debug("START")
+	defer logger.Log(ctx).Debug("END")
+
+	updates := request.Updates()
+	if len(updates) == 0 {
+		return s.registerStore.GetProfile(ctx, userID)
+	}
+	return s.registerStore.UpdateProfile(ctx, userID, updates)
+}
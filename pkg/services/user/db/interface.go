@@ -15,6 +15,8 @@ type RegisterStore interface {
 	Login(context.Context, string) ([]*models.LoginResponse, error)
 	Logout(context.Context, string, string, string) ([]*models.LoginResponse, error)
 	Register(context.Context, string, string, string) ([]*models.RegisterResponse, error)
+	GetProfile(ctx context.Context, userID int64) (*models.Profile, error)
+	UpdateProfile(ctx context.Context, userID int64, updates map[string]interface{}) (*models.Profile, error)
 }
 
 func NewDBObject(store *gorm.DB) RegisterStore {
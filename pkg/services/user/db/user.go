@@ -9,6 +9,8 @@ import (
 	"go.uber.org/zap"
 )
 
+var ErrUserNotFound = errors.New("user not found")
+
 func (g *registerStore) Login(c context.Context, matchAccount string) ([]*models.LoginResponse, error) {
 	logger.Log(c).Debug("START")
 	logger.Log(c).Debug("END")
@@ -65,3 +67,38 @@ func (g *registerStore) Register(c context.Context, matchAccount string, sipProd
 	}
 	return items, nil
 }
+
+const profileColumns = `id, COALESCE(name, '') AS name, COALESCE(phone, '') AS phone, COALESCE(role, '') AS role,
+		COALESCE(language, '') AS language, COALESCE(soil_type, '') AS soil_type, COALESCE(district, '') AS district,
+		lat, lng, created_at`
+
+func (g *registerStore) GetProfile(c context.Context, userID int64) (*models.Profile, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	var profiles []models.Profile
+	err := g.store.WithContext(c).Raw(`SELECT `+profileColumns+` FROM kisan.users WHERE id = ?`, userID).Scan(&profiles).Error
+	if err != nil {
+		logger.Log(c).Error("Error executing query", zap.Error(err))
+		return nil, err
+	}
+	if len(profiles) == 0 {
+		return nil, ErrUserNotFound
+	}
+	return &profiles[0], nil
+}
+
+func (g *registerStore) UpdateProfile(c context.Context, userID int64, updates map[string]interface{}) (*models.Profile, error) {
+	logger.Log(c).Debug("START")
+	defer logger.Log(c).Debug("END")
+
+	result := g.store.WithContext(c).Table("kisan.users").Where("id = ?", userID).Updates(updates)
+	if result.Error != nil {
+		logger.Log(c).Error("Error updating profile", zap.Error(result.Error))
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrUserNotFound
+	}
+	return g.GetProfile(c, userID)
+}
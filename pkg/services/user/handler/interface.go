@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"kisaanSathi/pkg/logger"
 	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/notification/sms"
 	"kisaanSathi/pkg/services/user/controller"
 	"kisaanSathi/pkg/services/user/db"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 type handler struct {
@@ -17,6 +20,10 @@ type RegisterHandler interface {
 	Login(c *gin.Context)
 	//RefreshToken(c *gin.Context)
 	Logout(c *gin.Context)
+	SendOTP(c *gin.Context)
+	VerifyOTP(c *gin.Context)
+	GetProfile(c *gin.Context)
+	UpdateProfile(c *gin.Context)
 }
 
 func NewRegisterHandler(controller controller.RegisterController) RegisterHandler {
@@ -26,5 +33,11 @@ func NewRegisterHandler(controller controller.RegisterController) RegisterHandle
 }
 func RegisterController(repo repo.DataObject) controller.RegisterController {
 	store := db.NewDBObject(repo.Databases.PgDB)
-	return controller.NewRegisterController(store)
+	gateway, err := sms.NewSMSGateway()
+	if err != nil {
+		// otp requests fail until the gateway is configured, the rest of the module keeps working
+		logger.Log().Error("sms gateway unavailable", zap.Error(err))
+		gateway = nil
+	}
+	return controller.NewRegisterController(store, repo.Cache, gateway)
 }
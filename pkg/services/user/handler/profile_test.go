@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"kisaanSathi/pkg/config"
+	"kisaanSathi/pkg/logger"
+	"kisaanSathi/pkg/repo"
+	"kisaanSathi/pkg/services/user/controller"
+	"kisaanSathi/pkg/services/user/db"
+	"kisaanSathi/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+var profileRows = []string{"id", "name", "phone", "role", "language", "soil_type", "district", "lat", "lng", "created_at"}
+
+type ProfileSuite struct {
+	suite.Suite
+	sqlDB   *sql.DB
+	gormDB  *gorm.DB
+	sqlMock sqlmock.Sqlmock
+	router  *gin.Engine
+}
+
+func TestProfileSuite(t *testing.T) {
+	suite.Run(t, new(ProfileSuite))
+}
+
+func (suite *ProfileSuite) SetupSuite() {
+	logger.LoggerInit("", -1)
+	gin.SetMode(gin.TestMode)
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		utils.RegisterValidations(v)
+	}
+}
+
+func (suite *ProfileSuite) SetupTest() {
+	suite.sqlDB, suite.gormDB, suite.sqlMock = utils.NewMockDB()
+	h := NewRegisterHandler(controller.NewRegisterController(db.NewDBObject(suite.gormDB), repo.NewMemoryCache(), nil))
+	suite.router = gin.New()
+	suite.router.GET("/v1/user/me", h.GetProfile)
+	suite.router.PATCH("/v1/user/me", h.UpdateProfile)
+}
+
+func (suite *ProfileSuite) TearDownTest() {
+	suite.NoError(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *ProfileSuite) serve(method string, body string, userID string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	request := httptest.NewRequest(method, "/v1/user/me", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if userID != "" {
+		request.Header.Set(config.USERID, userID)
+	}
+	recorder := httptest.NewRecorder()
+	suite.router.ServeHTTP(recorder, request)
+
+	var response map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &response), recorder.Body.String())
+	return recorder, response
+}
+
+func (suite *ProfileSuite) expectProfile(name string, language string) {
+	suite.sqlMock.
+		ExpectQuery("^SELECT (.+) FROM kisan.users WHERE id = (.+)$").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows(profileRows).
+			AddRow(7, name, "9876543210", "farmer", language, "black", "Nashik", 20.0, 73.8, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func (suite *ProfileSuite) TestGetProfile() {
+	// Mocking and Setting Expected Result
+	suite.expectProfile("Ramesh", "hi")
+
+	// Triggering Function
+	recorder, response := suite.serve(http.MethodGet, "", "7")
+
+	// Validations
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Contains(recorder.Body.String(), `"name":"Ramesh"`)
+	suite.Contains(recorder.Body.String(), `"phone":"9876543210"`)
+	suite.NotNil(response)
+}
+
+func (suite *ProfileSuite) TestGetProfile_NotFound() {
+	// Mocking and Setting Expected Result
+	suite.sqlMock.
+		ExpectQuery("^SELECT (.+) FROM kisan.users WHERE id = (.+)$").
+		WillReturnRows(sqlmock.NewRows(profileRows))
+
+	// Triggering Function
+	recorder, _ := suite.serve(http.MethodGet, "", "7")
+
+	// Validations
+	suite.Equal(http.StatusNotFound, recorder.Code)
+}
+
+func (suite *ProfileSuite) TestGetProfile_NoUser() {
+	// Triggering Function
+	recorder, _ := suite.serve(http.MethodGet, "", "")
+
+	// Validations
+	suite.Equal(http.StatusUnauthorized, recorder.Code)
+}
+
+func (suite *ProfileSuite) TestUpdateProfile() {
+	// Mocking and Setting Expected Result
+	suite.sqlMock.ExpectBegin()
+	suite.sqlMock.
+		ExpectExec("^UPDATE kisan.users SET language=(.+),name=(.+) WHERE id = (.+)$").
+		WithArgs("en", "Ramesh Patil", 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlMock.ExpectCommit()
+	suite.expectProfile("Ramesh Patil", "en")
+
+	// Triggering Function
+	recorder, _ := suite.serve(http.MethodPatch, `{"name":"Ramesh Patil","language":"en"}`, "7")
+
+	// Validations
+	suite.Equal(http.StatusOK, recorder.Code, recorder.Body.String())
+	suite.Contains(recorder.Body.String(), `"name":"Ramesh Patil"`)
+}
+
+func (suite *ProfileSuite) TestUpdateProfile_NothingToChange() {
+	// Mocking and Setting Expected Result
+	suite.expectProfile("Ramesh", "hi")
+
+	// Triggering Function
+	recorder, _ := suite.serve(http.MethodPatch, `{}`, "7")
+
+	// Validations
+	suite.Equal(http.StatusOK, recorder.Code)
+}
+
+func (suite *ProfileSuite) TestUpdateProfile_NotFound() {
+	// Mocking and Setting Expected Result
+	suite.sqlMock.ExpectBegin()
+	suite.sqlMock.ExpectExec("^UPDATE (.+)").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlMock.ExpectCommit()
+
+	// Triggering Function
+	recorder, _ := suite.serve(http.MethodPatch, `{"district":"Nashik"}`, "7")
+
+	// Validations
+	suite.Equal(http.StatusNotFound, recorder.Code)
+}
+
+func (suite *ProfileSuite) TestUpdateProfile_Invalid() {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "NameWithDigits", body: `{"name":"Ramesh 2"}`},
+		{name: "UnknownLanguage", body: `{"language":"fr"}`},
+		{name: "UnknownSoil", body: `{"soilType":"gravel"}`},
+		{name: "LatWithoutLng", body: `{"lat":20.0}`},
+		{name: "LatOutOfRange", body: `{"lat":91.0,"lng":73.8}`},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			// Triggering Function
+			recorder, _ := suite.serve(http.MethodPatch, tt.body, "7")
+
+			// Validations
+			suite.Equal(http.StatusBadRequest, recorder.Code, recorder.Body.String())
+		})
+	}
+}
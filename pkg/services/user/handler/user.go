@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"kisaanSathi/pkg/logger"
 	"kisaanSathi/pkg/network"
+	"kisaanSathi/pkg/services/notification/sms"
+	"kisaanSathi/pkg/services/user/controller"
 	"kisaanSathi/pkg/services/user/models"
 	"net/http"
 
@@ -91,3 +94,63 @@ func (f *handler) Logout(c *gin.Context) {
 
 	c.JSON(http.StatusOK, network.SuccessResponse(data))
 }
+
+func (f *handler) SendOTP(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	var request models.OTPRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.SendOTP(c, c.ClientIP(), &request)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		switch {
+		case errors.Is(err, controller.ErrOTPCooldown), errors.Is(err, controller.ErrOTPRateLimited):
+			c.JSON(http.StatusTooManyRequests, network.FailureResponse(network.ApiErrors.BadRequest.WithErrorDescription(err.Error())))
+		case errors.Is(err, sms.ErrInvalidNumber):
+			c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		default:
+			c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.InternalServerError.WithErrorDescription(err.Error())))
+		}
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
+
+func (f *handler) VerifyOTP(c *gin.Context) {
+	logger.Log(c).Debug("SERVICE-START")
+	defer logger.Log(c).Debug("SERVICE-END")
+
+	var request models.VerifyOTPRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Log(c).Error("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, network.BadRequestResponse(err, request))
+		c.Abort()
+		return
+	}
+
+	data, err := f.controller.VerifyOTP(c, &request)
+	if err != nil {
+		logger.Log(c).Error("Something went wrong", zap.String("error", err.Error()))
+		switch {
+		case errors.Is(err, controller.ErrOTPInvalid):
+			c.JSON(http.StatusUnauthorized, network.FailureResponse(network.ApiErrors.Unauthorized.WithErrorDescription(err.Error())))
+		case errors.Is(err, controller.ErrOTPAttempts):
+			c.JSON(http.StatusTooManyRequests, network.FailureResponse(network.ApiErrors.BadRequest.WithErrorDescription(err.Error())))
+		default:
+			c.JSON(http.StatusInternalServerError, network.FailureResponse(network.ApiErrors.InternalServerError.WithErrorDescription(err.Error())))
+		}
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, network.SuccessResponse(data))
+}
@@ -4,6 +4,20 @@ type LoginRequest struct {
 	Email    string `json:"email" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
+type OTPRequest struct {
+	Mobile   string `json:"mobile" binding:"required,numeric,len=10"`
+	Language string `json:"language" binding:"omitempty,oneof=hi en"`
+}
+type OTPResponse struct {
+	ExpiresIn int `json:"expiresIn"`
+}
+type VerifyOTPRequest struct {
+	Mobile string `json:"mobile" binding:"required,numeric,len=10"`
+	Code   string `json:"code" binding:"required,numeric,len=6"`
+}
+type VerifyOTPResponse struct {
+	Verified bool `json:"verified"`
+}
 type LogoutRequest struct {
 	LogoutFlag string `json:"logoutFlag" binding:"required,oneof=Y N"`
 }
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+type Profile struct {
+	ID        int64     `json:"id" gorm:"column:id"`
+	Name      string    `json:"name" gorm:"column:name"`
+	Phone     string    `json:"phone" gorm:"column:phone"`
+	Role      string    `json:"role" gorm:"column:role"`
+	Language  string    `json:"language" gorm:"column:language"`
+	SoilType  string    `json:"soilType" gorm:"column:soil_type"`
+	District  string    `json:"district" gorm:"column:district"`
+	Lat       *float64  `json:"lat" gorm:"column:lat"`
+	Lng       *float64  `json:"lng" gorm:"column:lng"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+// UpdateProfileRequest is a partial update, only the fields present are changed.
+// Phone and role are not editable here.
+type UpdateProfileRequest struct {
+	Name     *string  `json:"name" binding:"omitempty,min=2,max=100,placename" error:"letters, spaces, dots and hyphens only"`
+	Language *string  `json:"language" binding:"omitempty,language"`
+	SoilType *string  `json:"soilType" binding:"omitempty,soiltype"`
+	District *string  `json:"district" binding:"omitempty,max=100,placename"`
+	Lat      *float64 `json:"lat" binding:"required_with=Lng,omitempty,latitude"`
+	Lng      *float64 `json:"lng" binding:"required_with=Lat,omitempty,longitude"`
+}
+
+// Updates maps the fields present in the request to kisan.users columns
+func (r *UpdateProfileRequest) Updates() map[string]interface{} {
+	updates := make(map[string]interface{})
+	if r.Name != nil {
+		updates["name"] = *r.Name
+	}
+	if r.Language != nil {
+		updates["language"] = *r.Language
+	}
+	if r.SoilType != nil {
+		updates["soil_type"] = *r.SoilType
+	}
+	if r.District != nil {
+		updates["district"] = *r.District
+	}
+	if r.Lat != nil && r.Lng != nil {
+		updates["lat"] = *r.Lat
+		updates["lng"] = *r.Lng
+	}
+	return updates
+}
@@ -93,7 +93,7 @@ func (a *aesCipher) Encryption(plainText string) (string, error) {
 	// Retrieve the block size of the AES cipher (usually 16 bytes for AES).
 	blockSize := cipherBlock.BlockSize()
 
-	staticIV := config.GetConfig().GetString("aes.iv")
+	staticIV := config.App().AES.IV
 
 	iv := []byte(staticIV)
 
@@ -166,7 +166,7 @@ func (a *aesCipher) Decryption(encryptedData string, staticIV string) (string, e
 
 func EncryptData(data interface{}) (string, error) {
 
-	encryptionKey := config.GetConfig().GetString("aes.secretKey")
+	encryptionKey := config.App().AES.SecretKey
 
 	cipher := NewAesCipherService(encryptionKey, true)
 
@@ -186,7 +186,7 @@ func EncryptData(data interface{}) (string, error) {
 
 func DecryptData(data string, c *gin.Context) (string, error) {
 
-	encryptionKey := config.GetConfig().GetString("aes.secretKey")
+	encryptionKey := config.App().AES.SecretKey
 
 	IV := c.GetHeader(config.IV256)
 
@@ -1,7 +1,12 @@
 package utils
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"kisaanSathi/pkg/config"
 	"kisaanSathi/pkg/logger"
+	"net/http"
 	"strconv"
 	"strings"
 
@@ -85,3 +90,40 @@ func Paginate(c *gin.Context, page string, limit string) func(db *gorm.DB) *gorm
 		return db.Offset(offset).Limit(pageSize)
 	}
 }
+
+/*
+GetUserID returns the id of the user making the request. The id is read from the
+gin context (set by the auth middleware) and falls back to the userId header used
+by session validation.
+*/
+func GetUserID(c *gin.Context) (int64, error) {
+	userID := c.GetString(config.USERID)
+	if userID == "" {
+		userID = c.GetHeader(config.USERID)
+	}
+	if userID == "" {
+		return 0, errors.New("user id not found in request")
+	}
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid user id [%s]", userID)
+	}
+	return id, nil
+}
+
+// GetInt64Param parses a positive numeric path parameter such as /schemes/:id
+func GetInt64Param(c *gin.Context, key string) (int64, error) {
+	id, err := strconv.ParseInt(c.Param(key), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s [%s]", key, c.Param(key))
+	}
+	return id, nil
+}
+
+/*
+BackgroundGinContext wraps ctx in a gin context so that code running outside of a
+request (workers, schedulers) can use helpers that take *gin.Context such as RestCaller
+*/
+func BackgroundGinContext(ctx context.Context) *gin.Context {
+	return &gin.Context{Request: (&http.Request{}).WithContext(ctx)}
+}
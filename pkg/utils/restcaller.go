@@ -50,11 +50,11 @@ var (
 //
 //	fetches the default retry count, retry timeout and retry wait time from config
 func GetRestCaller() RestCaller {
-	confg := config.GetConfig()
+	confg := config.App().Thirdparty.RestAPI
 	//populate the default variables
-	RetryCount = confg.GetInt("thirdparty.restapi.retrycount")
-	RetryWaitTime = confg.GetInt("thirdparty.restapi.retrywaittime")
-	DefaultTimeout = confg.GetInt("thirdparty.restapi.timeout")
+	RetryCount = confg.RetryCount
+	RetryWaitTime = confg.RetryWaitTime
+	DefaultTimeout = confg.Timeout
 
 	return &restCall{}
 }
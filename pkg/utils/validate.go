@@ -16,6 +16,13 @@ func RegisterValidations(v *validator.Validate) {
 	v.RegisterValidation("matchaccount", validations.ValidateMatchAccount)
 	v.RegisterValidation("userid", validations.ValidateUserId)
 
+	// profile and farm validations
+	v.RegisterValidation("language", validations.ValidateLanguage)
+	v.RegisterValidation("placename", validations.ValidatePlaceName)
+	v.RegisterValidation("soiltype", validations.ValidateSoilType)
+	v.RegisterValidation("irrigationtype", validations.ValidateIrrigationType)
+	v.RegisterValidation("geopolygon", validations.ValidateGeoPolygon)
+
 	// scheme validations
 	v.RegisterValidation("schemecode", validations.ValidateAlphanumericWithHyphen)
 	v.RegisterValidation("isin", validations.ValidateIsin)
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	RegisterValidations(v)
+	return v
+}
+
+func TestValidateGeoPolygon(t *testing.T) {
+	square := [][]float64{{77.1, 28.6}, {77.2, 28.6}, {77.2, 28.7}, {77.1, 28.7}, {77.1, 28.6}}
+	tests := []struct {
+		name  string
+		rings [][][]float64
+		want  bool
+	}{
+		{name: "ClosedSquare", rings: [][][]float64{square}, want: true},
+		{name: "WithHole", rings: [][][]float64{square, {{77.12, 28.62}, {77.15, 28.62}, {77.15, 28.65}, {77.12, 28.62}}}, want: true},
+		{name: "OnTheAntimeridian", rings: [][][]float64{{{180, 0}, {-180, 0}, {-180, 1}, {180, 0}}}, want: true},
+		{name: "NoRings", rings: [][][]float64{}, want: false},
+		{name: "Unclosed", rings: [][][]float64{{{77.1, 28.6}, {77.2, 28.6}, {77.2, 28.7}, {77.1, 28.7}}}, want: false},
+		{name: "UnclosedHole", rings: [][][]float64{square, {{77.12, 28.62}, {77.15, 28.62}, {77.15, 28.65}, {77.13, 28.63}}}, want: false},
+		{name: "TooFewPositions", rings: [][][]float64{{{77.1, 28.6}, {77.2, 28.6}, {77.1, 28.6}}}, want: false},
+		{name: "LongitudeOutOfRange", rings: [][][]float64{{{181, 28.6}, {77.2, 28.6}, {77.2, 28.7}, {181, 28.6}}}, want: false},
+		{name: "LatitudeOutOfRange", rings: [][][]float64{{{77.1, -91}, {77.2, 28.6}, {77.2, 28.7}, {77.1, -91}}}, want: false},
+		{name: "LatLngSwapped", rings: [][][]float64{{{28.6, 177.1}, {28.6, 177.2}, {28.7, 177.2}, {28.6, 177.1}}}, want: false},
+		{name: "ThreeDimensions", rings: [][][]float64{{{77.1, 28.6, 200}, {77.2, 28.6, 200}, {77.2, 28.7, 200}, {77.1, 28.6, 200}}}, want: false},
+	}
+	v := newValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Var(tt.rings, "geopolygon")
+			assert.Equal(t, tt.want, err == nil, "TestCase=[%v] Err=[%v]", tt.name, err)
+		})
+	}
+}
+
+func TestValidatePlaceName(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{name: "Latin", value: "Nashik", want: true},
+		{name: "WithSpaces", value: "Sri Ganganagar", want: true},
+		{name: "Devanagari", value: "नासिक", want: true},
+		{name: "DevanagariWithMatra", value: "हिसार", want: true},
+		{name: "Punctuation", value: "St. Thomas' Mount-Cantonment", want: true},
+		{name: "Empty", value: "", want: false},
+		{name: "LeadingSpace", value: " Nashik", want: false},
+		{name: "LeadingHyphen", value: "-Nashik", want: false},
+		{name: "Digits", value: "Sector 17", want: false},
+		{name: "Markup", value: "<script>", want: false},
+		{name: "Quote", value: `Nashik"`, want: false},
+	}
+	v := newValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Var(tt.value, "placename")
+			assert.Equal(t, tt.want, err == nil, "TestCase=[%v] Err=[%v]", tt.name, err)
+		})
+	}
+}
+
+func TestValidateLanguage(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{name: "Hindi", value: "hi", want: true},
+		{name: "English", value: "en", want: true},
+		{name: "Marathi", value: "mr", want: true},
+		{name: "UpperCase", value: "HI", want: true},
+		{name: "Padded", value: " en ", want: true},
+		{name: "Empty", value: "", want: false},
+		{name: "LanguageName", value: "hindi", want: false},
+		{name: "Region", value: "en-IN", want: false},
+		{name: "Unsupported", value: "fr", want: false},
+	}
+	v := newValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Var(tt.value, "language")
+			assert.Equal(t, tt.want, err == nil, "TestCase=[%v] Err=[%v]", tt.name, err)
+		})
+	}
+}
+
+func TestRegisterValidations_ProfileAndFarmTags(t *testing.T) {
+	v := newValidator()
+	for _, tag := range []string{"language", "placename", "soiltype", "irrigationtype"} {
+		// an unregistered tag panics
+		assert.NotPanics(t, func() { _ = v.Var("x", tag) }, "tag [%s]", tag)
+	}
+	assert.NotPanics(t, func() { _ = v.Var([][][]float64{}, "geopolygon") })
+}
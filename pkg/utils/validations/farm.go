@@ -0,0 +1,71 @@
+package validations
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// SoilTypes are the soil classes used across the app, matched case-insensitively
+var SoilTypes = []string{"alluvial", "black", "red", "laterite", "arid", "saline", "peaty", "forest", "loamy", "clay", "sandy", "silty"}
+
+// IrrigationTypes are the irrigation sources of a plot
+var IrrigationTypes = []string{"rainfed", "canal", "tubewell", "well", "drip", "sprinkler", "tank", "river"}
+
+// Languages are the ISO 639-1 codes of the languages the app has content for, the same codes
+// the otp and sms templates use
+var Languages = []string{"hi", "en", "mr", "pa", "bn", "gu", "ta", "te", "kn", "ml", "or"}
+
+var placeNamePattern = regexp.MustCompile(`^[\p{L}\p{M}][\p{L}\p{M}\s.'-]*$`)
+
+var ValidateSoilType validator.Func = func(fl validator.FieldLevel) bool {
+	return oneOfFold(fl.Field().String(), SoilTypes)
+}
+
+var ValidateIrrigationType validator.Func = func(fl validator.FieldLevel) bool {
+	return oneOfFold(fl.Field().String(), IrrigationTypes)
+}
+
+var ValidateLanguage validator.Func = func(fl validator.FieldLevel) bool {
+	return oneOfFold(fl.Field().String(), Languages)
+}
+
+// ValidatePlaceName allows letters of any script (names are often written in Devanagari),
+// spaces, dots, hyphens and apostrophes
+var ValidatePlaceName validator.Func = func(fl validator.FieldLevel) bool {
+	return placeNamePattern.MatchString(fl.Field().String())
+}
+
+// ValidateGeoPolygon checks the coordinates of a GeoJSON polygon: every ring is closed,
+// has at least four [lng, lat] positions and every position is on the globe
+var ValidateGeoPolygon validator.Func = func(fl validator.FieldLevel) bool {
+	rings, ok := fl.Field().Interface().([][][]float64)
+	if !ok || len(rings) == 0 {
+		return false
+	}
+	for _, ring := range rings {
+		if len(ring) < 4 {
+			return false
+		}
+		for _, position := range ring {
+			if len(position) != 2 || position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+				return false
+			}
+		}
+		first, last := ring[0], ring[len(ring)-1]
+		if first[0] != last[0] || first[1] != last[1] {
+			return false
+		}
+	}
+	return true
+}
+
+func oneOfFold(value string, allowed []string) bool {
+	for _, a := range allowed {
+		if strings.EqualFold(strings.TrimSpace(value), a) {
+			return true
+		}
+	}
+	return false
+}